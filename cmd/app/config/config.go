@@ -1,5 +1,7 @@
 package config
 
+import "errors"
+
 var SysConfig *Config
 
 type Config struct {
@@ -8,6 +10,8 @@ type Config struct {
 	CMDB    CMDBOptions    `mapstructure:"cmdb"`
 	Log     LogConfig      `mapstructure:"log"`
 	MCP     MCPConfig      `mapstructure:"mcp"`
+	OpenAI  OpenAIConfig   `mapstructure:"openai"`
+	AI      AIOptions      `mapstructure:"ai"`
 }
 
 type DefaultOptions struct {
@@ -74,3 +78,64 @@ type MCPServerTemplate struct {
 	Homepage    string            `mapstructure:"homepage"`
 	Tags        []string          `mapstructure:"tags"`
 }
+
+// OpenAIConfig OpenAI 兼容网关配置
+type OpenAIConfig struct {
+	Enable  bool           `mapstructure:"enable"`
+	APIKeys []OpenAIAPIKey `mapstructure:"apiKeys"`
+}
+
+type OpenAIAPIKey struct {
+	Name string `mapstructure:"name"` // 调用方名称，用于日志和统计
+	Key  string `mapstructure:"key"`
+	// 以下配额为 0 时不限制
+	RequestsPerMinute int   `mapstructure:"requestsPerMinute"` // 每分钟请求数
+	ConcurrentStreams int   `mapstructure:"concurrentStreams"` // 同时进行的请求数
+	DailyTokens       int64 `mapstructure:"dailyTokens"`       // 每日 token 额度
+}
+
+// Validate 检查 API Key 配置，没有可用的 Key 时返回错误
+func (o OpenAIConfig) Validate() error {
+	for _, k := range o.APIKeys {
+		if k.Key != "" {
+			return nil
+		}
+	}
+	return errors.New("未配置 API Key")
+}
+
+type AIOptions struct {
+	Catalog        CatalogOptions        `mapstructure:"catalog"`
+	EmbeddingCache EmbeddingCacheOptions `mapstructure:"embeddingCache"`
+	AnswerCache    AnswerCacheOptions    `mapstructure:"answerCache"`
+	Ingest         IngestOptions         `mapstructure:"ingest"`
+}
+
+// CatalogOptions 模型目录同步配置
+type CatalogOptions struct {
+	SyncEnable   bool `mapstructure:"syncEnable"`
+	SyncDuration int  `mapstructure:"syncDuration"`
+}
+
+// EmbeddingCacheOptions 向量缓存配置
+type EmbeddingCacheOptions struct {
+	Enable     bool `mapstructure:"enable"`
+	Capacity   int  `mapstructure:"capacity"`
+	Persistent bool `mapstructure:"persistent"`
+}
+
+// AnswerCacheOptions 知识库问答语义缓存配置
+type AnswerCacheOptions struct {
+	Enable     bool    `mapstructure:"enable"`
+	Threshold  float64 `mapstructure:"threshold"`
+	TTL        int     `mapstructure:"ttl"`
+	MaxEntries int     `mapstructure:"maxEntries"`
+}
+
+// IngestOptions 知识库文档入库任务配置
+type IngestOptions struct {
+	BatchSize     int `mapstructure:"batchSize"`
+	Concurrency   int `mapstructure:"concurrency"`
+	MaxRetries    int `mapstructure:"maxRetries"`
+	RetryInterval int `mapstructure:"retryInterval"`
+}
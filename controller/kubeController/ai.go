@@ -1,12 +1,15 @@
 package kubeController
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/noovertime7/kubemanage/middleware"
 	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
 	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
 )
 
 var AI ai
@@ -15,11 +18,11 @@ type ai struct{}
 
 // ChatWithKB 结合知识库进行聊天
 // @Summary      结合知识库进行聊天
-// @Description  查询知识库获取相关文档，然后使用模型基于文档内容回答问题
+// @Description  查询知识库获取相关文档，然后使用模型基于文档内容回答问题，可通过 template_id 引用提示词模板，stream=true 时以 SSE 返回（documents/message/done/error 事件）；启用语义缓存时遇到相似问题直接返回之前的回答并标记 cached=true，流式请求在一个 message 事件中返回，no_cache=true 时跳过缓存
 // @Tags         ai
 // @ID           /api/ai/chat_with_kb
 // @Accept       json
-// @Produce      json
+// @Produce      json,text/event-stream
 // @Param        body  body  kubeDto.ChatWithKBInput  true  "聊天参数"
 // @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
 // @Router       /api/ai/chat_with_kb [post]
@@ -30,8 +33,26 @@ func (a *ai) ChatWithKB(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
+	if err := resolvePromptTemplate(ctx, &params.PromptTemplateRef); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
 
-	data, err := kube.Knowledge.ChatWithKnowledgeBase(params)
+	if params.Stream {
+		w := newSSEWriter(ctx)
+		onDocuments := func(documents []string, topK int) error {
+			return w.Event("documents", gin.H{
+				"related_documents": documents,
+				"question":          params.Question,
+				"top_k":             topK,
+			})
+		}
+		w.Close(kube.Knowledge.ChatWithKnowledgeBaseStream(usageContext(ctx, "knowledge"), params, onDocuments, w.Message))
+		return
+	}
+
+	data, err := kube.Knowledge.ChatWithKnowledgeBase(usageContext(ctx, "knowledge"), params)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -39,3 +60,13 @@ func (a *ai) ChatWithKB(ctx *gin.Context) {
 	}
 	middleware.ResponseSuccess(ctx, data)
 }
+
+// usageContext 将当前登录用户附加到请求 context，推理用量归属于该用户
+func usageContext(ctx *gin.Context, source string) context.Context {
+	caller := kube.UsageCaller{Source: source}
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		caller.UserID = claims.ID
+		caller.UserName = claims.Username
+	}
+	return kube.WithUsageCaller(ctx.Request.Context(), caller)
+}
@@ -0,0 +1,36 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// RunAgent 运行智能体
+// @Summary      运行调用 MCP 工具的智能体
+// @Description  通过 Ollama tools 字段将选定的 MCP 工具声明给模型，执行模型返回的工具调用并回填结果，直到模型给出最终回答或达到最大轮数，返回完整的工具调用记录
+// @Tags         ai
+// @ID           /api/ai/agent/run
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIAgentRunInput  true  "智能体参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": dto.AIAgentRunOut}"
+// @Router       /api/ai/agent/run [post]
+func (a *ai) RunAgent(ctx *gin.Context) {
+	params := &dto.AIAgentRunInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().Agent().Run(usageContext(ctx, "agent"), params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
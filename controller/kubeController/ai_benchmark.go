@@ -0,0 +1,201 @@
+package kubeController
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// CreateBenchmark 创建模型基准测试
+// @Summary      创建模型基准测试
+// @Description  将同一组提示词并发发送给多个模型/部署，记录耗时、生成速度、错误及输出，任务在后台运行
+// @Tags         ai
+// @ID           /api/ai/benchmark/create
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIBenchmarkInput  true  "基准测试参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/benchmark/create [post]
+func (a *ai) CreateBenchmark(ctx *gin.Context) {
+	params := &dto.AIBenchmarkInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().Benchmark().CreateBenchmark(ctx, params, claims.ID, claims.AuthorityId, claims.Username)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// PageBenchmark 分页查询基准测试
+// @Summary      分页查询基准测试
+// @Description  分页查询当前用户创建的模型基准测试任务
+// @Tags         ai
+// @ID           /api/ai/benchmark/list
+// @Accept       json
+// @Produce      json
+// @Param        page      query  int     false  "页码"
+// @Param        pageSize  query  int     false  "每页大小"
+// @Param        keyword   query  string  false  "任务名称关键字"
+// @Param        status    query  string  false  "任务状态"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/benchmark/list [get]
+func (a *ai) PageBenchmark(ctx *gin.Context) {
+	params := &dto.PageListAIBenchmarkInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	params.UserID = claims.ID
+	data, err := v1.CoreV1.AI().Benchmark().PageBenchmark(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetBenchmark 获取基准测试结果
+// @Summary      获取基准测试结果
+// @Description  获取基准测试任务、各目标的汇总指标以及每条提示词在各目标上的结果对比
+// @Tags         ai
+// @ID           /api/ai/benchmark/detail
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "任务ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/benchmark/detail [get]
+func (a *ai) GetBenchmark(ctx *gin.Context) {
+	params := &dto.AIBenchmarkIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().Benchmark().GetBenchmark(ctx, params.InstanceID, claims.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// CancelBenchmark 取消基准测试
+// @Summary      取消基准测试
+// @Description  取消正在运行的基准测试任务，已完成的结果会保留
+// @Tags         ai
+// @ID           /api/ai/benchmark/cancel
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIBenchmarkIDInput  true  "任务ID"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "取消成功}"
+// @Router       /api/ai/benchmark/cancel [post]
+func (a *ai) CancelBenchmark(ctx *gin.Context) {
+	params := &dto.AIBenchmarkIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().Benchmark().CancelBenchmark(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "取消成功")
+}
+
+// DeleteBenchmark 删除基准测试
+// @Summary      删除基准测试
+// @Description  删除已结束的基准测试任务及其结果
+// @Tags         ai
+// @ID           /api/ai/benchmark/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "任务ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/ai/benchmark/del [delete]
+func (a *ai) DeleteBenchmark(ctx *gin.Context) {
+	params := &dto.AIBenchmarkIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().Benchmark().DeleteBenchmark(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// ExportBenchmark 导出基准测试结果
+// @Summary      导出基准测试结果
+// @Description  以 JSON 或 CSV 文件导出基准测试结果
+// @Tags         ai
+// @ID           /api/ai/benchmark/export
+// @Accept       json
+// @Produce      json,text/csv
+// @Param        instanceID  query  string  true  "任务ID"
+// @Param        format      query  string  true  "导出格式 json/csv"
+// @Success      200 {file}  file
+// @Router       /api/ai/benchmark/export [get]
+func (a *ai) ExportBenchmark(ctx *gin.Context) {
+	params := &dto.AIBenchmarkExportInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().Benchmark().ExportBenchmark(ctx, params.InstanceID, params.Format, claims.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	contentType := "application/json"
+	if params.Format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=benchmark-%s.%s", params.InstanceID, params.Format))
+	ctx.Data(http.StatusOK, contentType, data)
+}
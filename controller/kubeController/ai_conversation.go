@@ -0,0 +1,217 @@
+package kubeController
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// CreateConversation 创建对话
+// @Summary      创建对话
+// @Description  创建多轮对话，绑定模型、系统提示词及可选的知识库
+// @Tags         ai
+// @ID           /api/ai/conversation/create
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIConversationCreateInput  true  "对话参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/conversation/create [post]
+func (a *ai) CreateConversation(ctx *gin.Context) {
+	params := &dto.AIConversationCreateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().Conversation().Create(ctx, params, claims.ID, claims.Username, claims.AuthorityId)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// PageConversation 分页查询对话
+// @Summary      分页查询对话
+// @Description  分页查询当前用户的对话，按最近更新时间排序
+// @Tags         ai
+// @ID           /api/ai/conversation/list
+// @Accept       json
+// @Produce      json
+// @Param        page      query  int     false  "页码"
+// @Param        pageSize  query  int     false  "每页大小"
+// @Param        keyword   query  string  false  "标题关键字"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/conversation/list [get]
+func (a *ai) PageConversation(ctx *gin.Context) {
+	params := &dto.PageListAIConversationInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	params.UserID = claims.ID
+	data, err := v1.CoreV1.AI().Conversation().PageConversation(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetConversation 获取对话详情
+// @Summary      获取对话详情
+// @Description  获取对话配置及全部消息
+// @Tags         ai
+// @ID           /api/ai/conversation/detail
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "对话ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/conversation/detail [get]
+func (a *ai) GetConversation(ctx *gin.Context) {
+	params := &dto.AIConversationIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().Conversation().Get(ctx, params.InstanceID, claims.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// RenameConversation 重命名对话
+// @Summary      重命名对话
+// @Description  修改对话标题
+// @Tags         ai
+// @ID           /api/ai/conversation/rename
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIConversationRenameInput  true  "对话标题"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "修改成功}"
+// @Router       /api/ai/conversation/rename [put]
+func (a *ai) RenameConversation(ctx *gin.Context) {
+	params := &dto.AIConversationRenameInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().Conversation().Rename(ctx, params, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "修改成功")
+}
+
+// DeleteConversation 删除对话
+// @Summary      删除对话
+// @Description  删除对话及其全部消息
+// @Tags         ai
+// @ID           /api/ai/conversation/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "对话ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/ai/conversation/del [delete]
+func (a *ai) DeleteConversation(ctx *gin.Context) {
+	params := &dto.AIConversationIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().Conversation().Delete(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// ChatConversation 在对话中发送消息
+// @Summary      在对话中发送消息
+// @Description  追加用户消息并携带历史调用模型，绑定知识库时先检索相关文档；stream=true 时以 SSE 返回（documents/message/done/error 事件）
+// @Tags         ai
+// @ID           /api/ai/conversation/chat
+// @Accept       json
+// @Produce      json,text/event-stream
+// @Param        body  body  dto.AIConversationChatInput  true  "消息参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/conversation/chat [post]
+func (a *ai) ChatConversation(ctx *gin.Context) {
+	params := &dto.AIConversationChatInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if params.Stream {
+		w := newSSEWriter(ctx)
+		onDocuments := func(documents []string) error {
+			return w.Event("documents", gin.H{"related_documents": documents})
+		}
+		w.Close(v1.CoreV1.AI().Conversation().ChatStream(usageContext(ctx, "conversation"), params, claims.ID, onDocuments, w.Message))
+		return
+	}
+
+	data, err := v1.CoreV1.AI().Conversation().Chat(usageContext(ctx, "conversation"), params, claims.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// currentUser 获取当前登录用户，未登录时直接返回错误响应
+func currentUser(ctx *gin.Context) (*pkg.CustomClaims, bool) {
+	claims := utils.GetUserInfo(ctx)
+	if claims == nil {
+		err := errors.New("无法获取当前登录用户")
+		v1.Log.ErrorWithCode(globalError.AuthorizationError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.AuthorizationError, err))
+		return nil, false
+	}
+	return claims, true
+}
@@ -0,0 +1,55 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// GetEmbeddingCacheStats 查询向量缓存统计
+// @Summary      查询向量缓存统计
+// @Description  查询向量缓存的容量、条数及内存/持久化层命中次数，命中次数自服务启动起累计
+// @Tags         ai
+// @ID           /api/ai/embedding/cache/stats
+// @Accept       json
+// @Produce      json
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": dto.AIEmbeddingCacheStatsOut}"
+// @Router       /api/ai/embedding/cache/stats [get]
+func (a *ai) GetEmbeddingCacheStats(ctx *gin.Context) {
+	data, err := v1.CoreV1.AI().EmbeddingCache().Stats(ctx)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeleteEmbeddingCache 清除向量缓存
+// @Summary      清除向量缓存
+// @Description  清除指定向量模型的缓存，包括内存层与持久化层；不指定模型时清空全部缓存，更换或重新拉取向量模型后使用
+// @Tags         ai
+// @ID           /api/ai/embedding/cache/del
+// @Accept       json
+// @Produce      json
+// @Param        model  query  string  false  "向量模型"
+// @Success      200    {object}  middleware.Response"{"code": 200, msg="","data": dto.AIEmbeddingCacheDelOut}"
+// @Router       /api/ai/embedding/cache/del [delete]
+func (a *ai) DeleteEmbeddingCache(ctx *gin.Context) {
+	params := &dto.AIEmbeddingCacheDelInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().EmbeddingCache().Invalidate(ctx, params.Model)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
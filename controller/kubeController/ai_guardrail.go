@@ -0,0 +1,115 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// ListGuardrailRule 查询护栏规则
+// @Summary      查询护栏规则
+// @Description  查询所有护栏规则，按执行顺序排列
+// @Tags         ai
+// @ID           /api/ai/guardrail/list
+// @Accept       json
+// @Produce      json
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/ai/guardrail/list [get]
+func (a *ai) ListGuardrailRule(ctx *gin.Context) {
+	data, err := v1.CoreV1.AI().Guardrail().ListRules(ctx)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// SaveGuardrailRule 设置护栏规则
+// @Summary      设置护栏规则
+// @Description  创建或修改护栏规则，支持敏感信息（手机号、身份证号、邮箱）、关键词、正则表达式及最大字符数，命中后可拦截、脱敏或仅记录；规则对聊天、知识库问答、多轮对话、补全及 OpenAI 兼容接口生效
+// @Tags         ai
+// @ID           /api/ai/guardrail/save
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIGuardrailRuleInput  true  "规则参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/guardrail/save [post]
+func (a *ai) SaveGuardrailRule(ctx *gin.Context) {
+	params := &dto.AIGuardrailRuleInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().Guardrail().SaveRule(ctx, params, creator)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeleteGuardrailRule 删除护栏规则
+// @Summary      删除护栏规则
+// @Description  删除护栏规则，已有的命中记录保留
+// @Tags         ai
+// @ID           /api/ai/guardrail/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "规则ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/ai/guardrail/del [delete]
+func (a *ai) DeleteGuardrailRule(ctx *gin.Context) {
+	params := &dto.AIGuardrailRuleIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.AI().Guardrail().DeleteRule(ctx, params.InstanceID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// PageGuardrailViolation 分页查询护栏规则命中记录
+// @Summary      分页查询护栏规则命中记录
+// @Description  分页查询护栏规则的命中审计记录，命中内容已脱敏
+// @Tags         ai
+// @ID           /api/ai/guardrail/violation/list
+// @Accept       json
+// @Produce      json
+// @Param        page       query  int     false  "页码"
+// @Param        pageSize   query  int     false  "每页大小"
+// @Param        ruleID     query  string  false  "规则ID"
+// @Param        user_name  query  string  false  "用户名"
+// @Param        stage      query  string  false  "命中阶段 input/output"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/guardrail/violation/list [get]
+func (a *ai) PageGuardrailViolation(ctx *gin.Context) {
+	params := &dto.PageListAIGuardrailViolationInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().Guardrail().PageViolations(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
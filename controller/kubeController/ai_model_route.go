@@ -0,0 +1,85 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// ListModelRoute 查询虚拟模型
+// @Summary      查询虚拟模型
+// @Description  查询所有虚拟模型及其路由目标
+// @Tags         ai
+// @ID           /api/ai/route/list
+// @Accept       json
+// @Produce      json
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/ai/route/list [get]
+func (a *ai) ListModelRoute(ctx *gin.Context) {
+	data, err := v1.CoreV1.AI().ModelRoute().ListRoute(ctx)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// SaveModelRoute 设置虚拟模型
+// @Summary      设置虚拟模型
+// @Description  将虚拟模型名称映射到多个部署+模型目标，ordered 按顺序故障转移，weighted 按权重分配并在失败时尝试其余目标；同名时覆盖。聊天、向量嵌入、知识库问答及 OpenAI 兼容接口均可直接使用虚拟模型名称
+// @Tags         ai
+// @ID           /api/ai/route/save
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIModelRouteInput  true  "路由参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/route/save [post]
+func (a *ai) SaveModelRoute(ctx *gin.Context) {
+	params := &dto.AIModelRouteInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().ModelRoute().SaveRoute(ctx, params, creator)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeleteModelRoute 删除虚拟模型
+// @Summary      删除虚拟模型
+// @Description  删除虚拟模型，删除后该名称按普通模型处理
+// @Tags         ai
+// @ID           /api/ai/route/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "路由ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/ai/route/del [delete]
+func (a *ai) DeleteModelRoute(ctx *gin.Context) {
+	params := &dto.AIModelRouteIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.AI().ModelRoute().DeleteRoute(ctx, params.InstanceID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
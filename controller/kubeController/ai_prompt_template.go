@@ -0,0 +1,184 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// CreatePromptTemplate 创建提示词模板
+// @Summary      创建提示词模板
+// @Description  创建提示词模板及其第一个版本，内容中可使用 {{context}}、{{question}}、{{user}} 等变量
+// @Tags         ai
+// @ID           /api/ai/prompt/create
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIPromptTemplateInput  true  "模板参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/prompt/create [post]
+func (a *ai) CreatePromptTemplate(ctx *gin.Context) {
+	params := &dto.AIPromptTemplateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().PromptTemplate().Create(ctx, params, claims.ID, claims.Username)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// PagePromptTemplate 分页查询提示词模板
+// @Summary      分页查询提示词模板
+// @Description  分页查询当前用户可见的提示词模板：自己创建的、公开的以及对当前角色可见的
+// @Tags         ai
+// @ID           /api/ai/prompt/list
+// @Accept       json
+// @Produce      json
+// @Param        page      query  int     false  "页码"
+// @Param        pageSize  query  int     false  "每页大小"
+// @Param        keyword   query  string  false  "名称关键字"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/prompt/list [get]
+func (a *ai) PagePromptTemplate(ctx *gin.Context) {
+	params := &dto.PageListAIPromptTemplateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	params.UserID = claims.ID
+	params.AuthorityID = claims.AuthorityId
+	data, err := v1.CoreV1.AI().PromptTemplate().PageTemplate(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetPromptTemplate 获取提示词模板详情
+// @Summary      获取提示词模板详情
+// @Description  获取提示词模板指定版本的内容、变量及版本历史
+// @Tags         ai
+// @ID           /api/ai/prompt/detail
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true   "模板ID"
+// @Param        version     query  int     false  "版本号（默认最新版本）"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/prompt/detail [get]
+func (a *ai) GetPromptTemplate(ctx *gin.Context) {
+	params := &dto.AIPromptTemplateIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().PromptTemplate().Get(ctx, params.InstanceID, params.Version, claims.ID, claims.AuthorityId)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// UpdatePromptTemplate 修改提示词模板
+// @Summary      修改提示词模板
+// @Description  修改提示词模板的描述和可见范围，内容与最新版本不同时生成新版本，只有创建人可以修改
+// @Tags         ai
+// @ID           /api/ai/prompt/update
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIPromptTemplateUpdateInput  true  "模板参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/prompt/update [put]
+func (a *ai) UpdatePromptTemplate(ctx *gin.Context) {
+	params := &dto.AIPromptTemplateUpdateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().PromptTemplate().Update(ctx, params, claims.ID, claims.Username)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeletePromptTemplate 删除提示词模板
+// @Summary      删除提示词模板
+// @Description  删除提示词模板及其全部版本，只有创建人可以删除
+// @Tags         ai
+// @ID           /api/ai/prompt/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "模板ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/ai/prompt/del [delete]
+func (a *ai) DeletePromptTemplate(ctx *gin.Context) {
+	params := &dto.AIPromptTemplateIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().PromptTemplate().Delete(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// resolvePromptTemplate 请求引用了提示词模板时，校验可见范围并填充模板内容
+func resolvePromptTemplate(ctx *gin.Context, ref *kubeDto.PromptTemplateRef) error {
+	if ref.TemplateID == "" {
+		return nil
+	}
+	var userID int
+	var authorityID uint
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		userID, authorityID = claims.ID, claims.AuthorityId
+	}
+	version, err := v1.CoreV1.AI().PromptTemplate().Resolve(ctx, ref.TemplateID, ref.TemplateVersion, userID, authorityID)
+	if err != nil {
+		return err
+	}
+	ref.PromptTemplate = version.Content
+	return nil
+}
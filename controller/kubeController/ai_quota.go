@@ -0,0 +1,92 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// ListQuota 查询推理配额
+// @Summary      查询推理配额
+// @Description  查询绑定到角色或用户的推理配额
+// @Tags         ai
+// @ID           /api/ai/quota/list
+// @Accept       json
+// @Produce      json
+// @Param        subjectType  query  string  false  "绑定对象类型 authority/user"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/ai/quota/list [get]
+func (a *ai) ListQuota(ctx *gin.Context) {
+	params := &dto.AIQuotaListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().Quota().ListQuota(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// SaveQuota 设置推理配额
+// @Summary      设置推理配额
+// @Description  为角色或用户设置每分钟请求数、并发请求数和每日 token 额度，已存在时覆盖；用户配额优先于角色配额，0 表示不限制
+// @Tags         ai
+// @ID           /api/ai/quota/save
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIQuotaInput  true  "配额参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/quota/save [post]
+func (a *ai) SaveQuota(ctx *gin.Context) {
+	params := &dto.AIQuotaInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().Quota().SaveQuota(ctx, params, creator)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeleteQuota 删除推理配额
+// @Summary      删除推理配额
+// @Description  删除角色或用户的推理配额
+// @Tags         ai
+// @ID           /api/ai/quota/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "配额ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/ai/quota/del [delete]
+func (a *ai) DeleteQuota(ctx *gin.Context) {
+	params := &dto.AIQuotaIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.AI().Quota().DeleteQuota(ctx, params.InstanceID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
@@ -0,0 +1,75 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// UsageReport 推理用量报表
+// @Summary      推理用量报表
+// @Description  按用户、部门、模型、日期聚合聊天与向量嵌入调用的 token 用量及耗时，group_by 可组合多个维度，如 department,day
+// @Tags         ai
+// @ID           /api/ai/usage/report
+// @Accept       json
+// @Produce      json
+// @Param        group_by       query  string  true   "分组维度，逗号分隔：user、department、model、day"
+// @Param        start_date     query  string  false  "开始日期 2006-01-02"
+// @Param        end_date       query  string  false  "结束日期（含）2006-01-02"
+// @Param        user_name      query  string  false  "用户名"
+// @Param        department_id  query  int     false  "部门ID"
+// @Param        model          query  string  false  "模型名称"
+// @Param        namespace      query  string  false  "命名空间"
+// @Param        deployment     query  string  false  "Ollama部署名称"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/ai/usage/report [get]
+func (a *ai) UsageReport(ctx *gin.Context) {
+	params := &dto.AIUsageReportInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().Usage().Report(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// PageUsage 分页查询推理用量明细
+// @Summary      分页查询推理用量明细
+// @Description  分页查询每次聊天与向量嵌入调用的用量记录
+// @Tags         ai
+// @ID           /api/ai/usage/list
+// @Accept       json
+// @Produce      json
+// @Param        page           query  int     false  "页码"
+// @Param        pageSize       query  int     false  "每页大小"
+// @Param        start_date     query  string  false  "开始日期 2006-01-02"
+// @Param        end_date       query  string  false  "结束日期（含）2006-01-02"
+// @Param        user_name      query  string  false  "用户名"
+// @Param        department_id  query  int     false  "部门ID"
+// @Param        model          query  string  false  "模型名称"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/ai/usage/list [get]
+func (a *ai) PageUsage(ctx *gin.Context) {
+	params := &dto.PageListAIUsageInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().Usage().PageUsage(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
@@ -18,7 +18,7 @@ type knowledge struct{}
 
 // DeployKnowledge 部署知识库
 // @Summary      部署知识库到指定节点
-// @Description  在K8s集群的指定节点上部署知识库服务，支持绑定Ollama模型
+// @Description  在K8s集群的指定节点上部署知识库服务，Qdrant、pgvector 可按知识库类型使用内置模板，支持绑定Ollama模型及GPU、容忍、亲和性等调度选项
 // @Tags         knowledge
 // @ID           /api/k8s/knowledge/deploy
 // @Accept       json
@@ -43,17 +43,23 @@ func (k *knowledge) DeployKnowledge(ctx *gin.Context) {
 
 // UploadDocument 上传文档到知识库
 // @Summary      上传文档到知识库
-// @Description  向指定的知识库 Pod 上传文档文件，支持 ChromaDB、Milvus、Weaviate
+// @Description  向指定的知识库 Pod 上传文档文件，支持 ChromaDB、Milvus、Weaviate、Qdrant、pgvector
+// @Description  文件格式支持 PDF、Word(.docx)、Excel(.xlsx)、CSV、Markdown、HTML 及纯文本，页码、标题、工作表及行号写入分块元数据
+// @Description  未指定分块参数时使用集合的分块配置（/api/k8s/knowledge/chunking/save），均未配置时按段落、句子递归切分
+// @Description  文档解析、切分后立即返回入库任务，分块在后台分批生成向量写入，进度通过 /api/k8s/knowledge/ingest/detail 查询
 // @Tags         knowledge
 // @ID           /api/k8s/knowledge/document/upload
 // @Accept       multipart/form-data
 // @Produce      json
 // @Param        pod_name        formData  string  true   "知识库Pod名称"
 // @Param        namespace       formData  string  true   "命名空间"
-// @Param        knowledge_type  formData  string  true   "知识库类型: chromadb, milvus, weaviate"
-// @Param        file            formData  file    true   "文档文件"
+// @Param        knowledge_type  formData  string  true   "知识库类型: chromadb, milvus, weaviate, qdrant, pgvector"
+// @Param        file            formData  file    true   "文档文件（.pdf/.docx/.xlsx/.csv/.md/.html/.txt）"
 // @Param        collection_name formData  string  false  "集合名称（可选）"
-// @Param        chunk_size      formData  int     false  "分块大小（可选，默认1000）"
+// @Param        chunk_strategy  formData  string  false  "分块策略（可选）: fixed, recursive, markdown, token"
+// @Param        chunk_size      formData  int     false  "分块大小（可选，默认1000），token 策略下为 token 数"
+// @Param        chunk_overlap   formData  int     false  "相邻分块重叠长度（可选）"
+// @Param        max_tokens      formData  int     false  "单个分块 token 上限（可选），默认为向量模型的上限"
 // @Success      200             {object}  middleware.Response"{"code": 200, msg="","data": object}"
 // @Router       /api/k8s/knowledge/document/upload [post]
 func (k *knowledge) UploadDocument(ctx *gin.Context) {
@@ -86,20 +92,16 @@ func (k *knowledge) UploadDocument(ctx *gin.Context) {
 		return
 	}
 
-	collectionName := params.CollectionName
-	if collectionName == "" {
-		collectionName = file.Filename
+	if params.CollectionName == "" {
+		params.CollectionName = file.Filename
+	}
+	// 任务只有创建人可以查看及操作
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
 	}
 
-	data, err := kube.Knowledge.UploadDocument(
-		params.PodName,
-		params.NameSpace,
-		params.KnowledgeType,
-		fileContent,
-		file.Filename,
-		collectionName,
-		params.ChunkSize,
-	)
+	data, err := v1.CoreV1.AI().IngestJob().CreateIngestJob(ctx, params, fileContent, file.Filename, file.Header.Get("Content-Type"), claims.ID, claims.Username)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
@@ -110,7 +112,7 @@ func (k *knowledge) UploadDocument(ctx *gin.Context) {
 
 // QueryDocument 查询知识库
 // @Summary      查询知识库
-// @Description  在指定的知识库中查询相似文档，支持 ChromaDB、Milvus、Weaviate
+// @Description  在指定的知识库中查询相似文档，可按元数据过滤，支持 ChromaDB、Milvus、Weaviate、Qdrant、pgvector
 // @Tags         knowledge
 // @ID           /api/k8s/knowledge/query
 // @Accept       json
@@ -132,12 +134,14 @@ func (k *knowledge) QueryDocument(ctx *gin.Context) {
 	}
 
 	data, err := kube.Knowledge.QueryKnowledge(
+		usageContext(ctx, "knowledge"),
 		params.PodName,
 		params.NameSpace,
 		params.KnowledgeType,
 		params.CollectionName,
 		params.QueryText,
 		topK,
+		params.Filter,
 	)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
@@ -0,0 +1,93 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// ListChunking 查询集合分块配置
+// @Summary      查询集合分块配置
+// @Description  查询知识库集合的分块配置，可按命名空间及知识库部署名称过滤
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/chunking/list
+// @Accept       json
+// @Produce      json
+// @Param        namespace  query  string  false  "命名空间"
+// @Param        knowledge  query  string  false  "知识库部署名称"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/k8s/knowledge/chunking/list [get]
+func (k *knowledge) ListChunking(ctx *gin.Context) {
+	params := &dto.AIKnowledgeChunkingListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().KnowledgeChunking().ListChunking(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// SaveChunking 设置集合分块配置
+// @Summary      设置集合分块配置
+// @Description  设置知识库集合的分块策略：fixed 固定长度窗口、recursive 按段落及句子递归切分、markdown 按标题切分、token 按估算的 token 数切分；向该集合上传文档且未指定分块参数时使用，同一集合覆盖
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/chunking/save
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIKnowledgeChunkingInput  true  "分块配置"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/knowledge/chunking/save [post]
+func (k *knowledge) SaveChunking(ctx *gin.Context) {
+	params := &dto.AIKnowledgeChunkingInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().KnowledgeChunking().SaveChunking(ctx, params, creator)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeleteChunking 删除集合分块配置
+// @Summary      删除集合分块配置
+// @Description  删除知识库集合的分块配置，之后上传的文档使用默认分块策略
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/chunking/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "分块配置ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/knowledge/chunking/del [delete]
+func (k *knowledge) DeleteChunking(ctx *gin.Context) {
+	params := &dto.AIKnowledgeChunkingIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.AI().KnowledgeChunking().DeleteChunking(ctx, params.InstanceID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
@@ -0,0 +1,164 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// PageIngestJob 分页查询文档入库任务
+// @Summary      分页查询文档入库任务
+// @Description  分页查询当前用户创建的知识库文档入库任务及进度
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/ingest/list
+// @Accept       json
+// @Produce      json
+// @Param        page        query  int     false  "页码"
+// @Param        pageSize    query  int     false  "每页大小"
+// @Param        keyword     query  string  false  "文件名关键字"
+// @Param        status      query  string  false  "任务状态: pending, running, success, failed, canceled"
+// @Param        namespace   query  string  false  "命名空间"
+// @Param        pod_name    query  string  false  "知识库Pod名称"
+// @Param        collection  query  string  false  "集合名称"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/knowledge/ingest/list [get]
+func (k *knowledge) PageIngestJob(ctx *gin.Context) {
+	params := &dto.PageListAIIngestJobInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	params.UserID = claims.ID
+	data, err := v1.CoreV1.AI().IngestJob().PageIngestJob(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetIngestJob 获取文档入库任务详情
+// @Summary      获取文档入库任务详情
+// @Description  获取入库任务的进度、分块参数以及重试后仍失败的分块和错误信息
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/ingest/detail
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "任务ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/knowledge/ingest/detail [get]
+func (k *knowledge) GetIngestJob(ctx *gin.Context) {
+	params := &dto.AIIngestJobIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	data, err := v1.CoreV1.AI().IngestJob().GetIngestJob(ctx, params.InstanceID, claims.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// CancelIngestJob 取消文档入库任务
+// @Summary      取消文档入库任务
+// @Description  取消未结束的入库任务，已写入知识库的分块会保留，可通过重试继续写入剩余分块
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/ingest/cancel
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIIngestJobIDInput  true  "任务ID"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "取消成功}"
+// @Router       /api/k8s/knowledge/ingest/cancel [post]
+func (k *knowledge) CancelIngestJob(ctx *gin.Context) {
+	params := &dto.AIIngestJobIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().IngestJob().CancelIngestJob(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "取消成功")
+}
+
+// RetryIngestJob 重试文档入库任务
+// @Summary      重试文档入库任务
+// @Description  重新写入失败或已取消任务中失败及未写入的分块，已写入的分块不会重复写入
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/ingest/retry
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIIngestJobIDInput  true  "任务ID"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "重试成功}"
+// @Router       /api/k8s/knowledge/ingest/retry [post]
+func (k *knowledge) RetryIngestJob(ctx *gin.Context) {
+	params := &dto.AIIngestJobIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().IngestJob().RetryIngestJob(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "重试成功")
+}
+
+// DeleteIngestJob 删除文档入库任务
+// @Summary      删除文档入库任务
+// @Description  删除已结束的入库任务及其分块记录，已写入知识库的数据不受影响
+// @Tags         knowledge
+// @ID           /api/k8s/knowledge/ingest/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "任务ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/knowledge/ingest/del [delete]
+func (k *knowledge) DeleteIngestJob(ctx *gin.Context) {
+	params := &dto.AIIngestJobIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	if err := v1.CoreV1.AI().IngestJob().DeleteIngestJob(ctx, params.InstanceID, claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
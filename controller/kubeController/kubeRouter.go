@@ -2,6 +2,8 @@ package kubeController
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/middleware"
 )
 
 type kubeRouter struct{}
@@ -112,15 +114,37 @@ func (k *kubeRouter) initRoutes(ginEngine *gin.RouterGroup) {
 	{
 		k8sRoute.POST("/ollama/deploy", Ollama.DeployOllama)
 		k8sRoute.GET("/ollama/list", Ollama.GetOllamaList)
+		k8sRoute.DELETE("/ollama/del", Ollama.DeleteOllama)
+		k8sRoute.PUT("/ollama/scale", Ollama.ScaleOllama)
+		k8sRoute.PUT("/ollama/upgrade", Ollama.UpgradeOllama)
+		k8sRoute.PUT("/ollama/restart", Ollama.RestartOllama)
 		// 模型管理
 		k8sRoute.POST("/ollama/model/pull", Ollama.PullModel)
+		k8sRoute.GET("/ollama/model/pull/list", Ollama.PagePullJob)
+		k8sRoute.GET("/ollama/model/pull/detail", Ollama.GetPullJob)
+		k8sRoute.POST("/ollama/model/pull/cancel", Ollama.CancelPullJob)
 		k8sRoute.GET("/ollama/model/list", Ollama.GetModelList)
 		k8sRoute.DELETE("/ollama/model/del", Ollama.DeleteModel)
 		k8sRoute.GET("/ollama/model/detail", Ollama.GetModelDetail)
+		k8sRoute.POST("/ollama/model/create", Ollama.CreateModel)
+		k8sRoute.POST("/ollama/model/copy", Ollama.CopyModel)
+		k8sRoute.POST("/ollama/model/upload", Ollama.UploadModel)
+		// 模型驻留
+		k8sRoute.GET("/ollama/model/ps", Ollama.GetLoadedModels)
+		k8sRoute.POST("/ollama/model/load", Ollama.LoadModel)
+		k8sRoute.POST("/ollama/model/unload", Ollama.UnloadModel)
+		k8sRoute.GET("/ollama/residency", Ollama.GetResidency)
+		// 模型目录
+		k8sRoute.GET("/ollama/catalog/list", Ollama.ListCatalog)
+		k8sRoute.POST("/ollama/catalog/add", Ollama.AddCatalog)
+		k8sRoute.DELETE("/ollama/catalog/del", Ollama.DeleteCatalog)
+		k8sRoute.POST("/ollama/catalog/sync", Ollama.SyncCatalog)
 		// 聊天接口
-		k8sRoute.POST("/ollama/chat", Ollama.Chat)
+		k8sRoute.POST("/ollama/chat", middleware.AIQuota(), Ollama.Chat)
+		// 文本补全接口
+		k8sRoute.POST("/ollama/generate", middleware.AIQuota(), Ollama.Generate)
 		// 向量嵌入接口
-		k8sRoute.POST("/ollama/embeddings", Ollama.Embeddings)
+		k8sRoute.POST("/ollama/embeddings", middleware.AIQuota(), Ollama.Embeddings)
 	}
 
 	{
@@ -128,17 +152,66 @@ func (k *kubeRouter) initRoutes(ginEngine *gin.RouterGroup) {
 		k8sRoute.POST("/knowledge/deploy", Knowledge.DeployKnowledge)
 		k8sRoute.GET("/knowledge/list", Knowledge.ListKnowledge)
 		k8sRoute.GET("/knowledge/detail", Knowledge.GetKnowledgeDetail)
-		k8sRoute.POST("/knowledge/document/upload", Knowledge.UploadDocument)
-		k8sRoute.POST("/knowledge/query", Knowledge.QueryDocument)
+		k8sRoute.POST("/knowledge/document/upload", middleware.AIQuota(), Knowledge.UploadDocument)
+		k8sRoute.POST("/knowledge/query", middleware.AIQuota(), Knowledge.QueryDocument)
+		k8sRoute.GET("/knowledge/chunking/list", Knowledge.ListChunking)
+		k8sRoute.POST("/knowledge/chunking/save", Knowledge.SaveChunking)
+		k8sRoute.DELETE("/knowledge/chunking/del", Knowledge.DeleteChunking)
+		k8sRoute.GET("/knowledge/ingest/list", Knowledge.PageIngestJob)
+		k8sRoute.GET("/knowledge/ingest/detail", Knowledge.GetIngestJob)
+		k8sRoute.POST("/knowledge/ingest/cancel", Knowledge.CancelIngestJob)
+		k8sRoute.POST("/knowledge/ingest/retry", Knowledge.RetryIngestJob)
+		k8sRoute.DELETE("/knowledge/ingest/del", Knowledge.DeleteIngestJob)
 	}
 
 	// AI 相关接口
 	aiRoute := ginEngine.Group("/ai")
 	{
-		aiRoute.POST("/chat_with_kb", AI.ChatWithKB)
+		aiRoute.POST("/chat_with_kb", middleware.AIQuota(), AI.ChatWithKB)
 		aiRoute.GET("/mcp/servers", MCPServer.ListServers)
 		aiRoute.GET("/mcp/tools", MCPServer.ListServerTools)
 		aiRoute.POST("/mcp/servers", MCPServer.CreateServer)
+		// 推理用量
+		aiRoute.GET("/usage/report", AI.UsageReport)
+		aiRoute.GET("/usage/list", AI.PageUsage)
+		// 推理配额
+		aiRoute.GET("/quota/list", AI.ListQuota)
+		aiRoute.POST("/quota/save", AI.SaveQuota)
+		aiRoute.DELETE("/quota/del", AI.DeleteQuota)
+		// 多轮对话
+		aiRoute.POST("/conversation/create", AI.CreateConversation)
+		aiRoute.GET("/conversation/list", AI.PageConversation)
+		aiRoute.GET("/conversation/detail", AI.GetConversation)
+		aiRoute.PUT("/conversation/rename", AI.RenameConversation)
+		aiRoute.DELETE("/conversation/del", AI.DeleteConversation)
+		aiRoute.POST("/conversation/chat", middleware.AIQuota(), AI.ChatConversation)
+		// 提示词模板
+		aiRoute.POST("/prompt/create", AI.CreatePromptTemplate)
+		aiRoute.GET("/prompt/list", AI.PagePromptTemplate)
+		aiRoute.GET("/prompt/detail", AI.GetPromptTemplate)
+		aiRoute.PUT("/prompt/update", AI.UpdatePromptTemplate)
+		aiRoute.DELETE("/prompt/del", AI.DeletePromptTemplate)
+		// 模型基准测试
+		aiRoute.POST("/benchmark/create", middleware.AIQuota(), AI.CreateBenchmark)
+		aiRoute.GET("/benchmark/list", AI.PageBenchmark)
+		aiRoute.GET("/benchmark/detail", AI.GetBenchmark)
+		aiRoute.POST("/benchmark/cancel", AI.CancelBenchmark)
+		aiRoute.DELETE("/benchmark/del", AI.DeleteBenchmark)
+		aiRoute.GET("/benchmark/export", AI.ExportBenchmark)
+		// 虚拟模型路由
+		aiRoute.GET("/route/list", AI.ListModelRoute)
+		aiRoute.POST("/route/save", AI.SaveModelRoute)
+		aiRoute.DELETE("/route/del", AI.DeleteModelRoute)
+		// 智能体
+		aiRoute.POST("/agent/run", middleware.AIQuota(), AI.RunAgent)
+		// 护栏规则
+		aiRoute.GET("/guardrail/list", AI.ListGuardrailRule)
+		aiRoute.POST("/guardrail/save", AI.SaveGuardrailRule)
+		aiRoute.DELETE("/guardrail/del", AI.DeleteGuardrailRule)
+		aiRoute.GET("/guardrail/violation/list", AI.PageGuardrailViolation)
+		// 向量缓存
+		aiRoute.GET("/embedding/cache/stats", AI.GetEmbeddingCacheStats)
+		aiRoute.DELETE("/embedding/cache/del", AI.DeleteEmbeddingCache)
 	}
 
 }
@@ -1,12 +1,16 @@
 package kubeController
 
 import (
+	"io"
+
 	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/dto"
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/noovertime7/kubemanage/middleware"
 	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
 	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
 )
 
 var Ollama ollama
@@ -16,7 +20,7 @@ type ollama struct{}
 // DeployOllama 部署Ollama
 // ListPage godoc
 // @Summary      部署Ollama到指定节点
-// @Description  在K8s集群的指定节点上部署Ollama服务
+// @Description  在K8s集群的指定节点上部署Ollama服务，支持GPU、容忍、亲和性、优先级及额外环境变量
 // @Tags         ollama
 // @ID           /api/k8s/ollama/deploy
 // @Accept       json
@@ -42,7 +46,7 @@ func (o *ollama) DeployOllama(ctx *gin.Context) {
 // GetOllamaList 获取Ollama部署列表
 // ListPage godoc
 // @Summary      获取Ollama部署列表
-// @Description  获取Ollama部署列表，支持分页和过滤
+// @Description  获取Ollama部署列表，支持分页和过滤，声明了模型目录的部署附带 model_drift
 // @Tags         ollama
 // @ID           /api/k8s/ollama/list
 // @Accept       json
@@ -67,18 +71,125 @@ func (o *ollama) GetOllamaList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
 		return
 	}
+	// 附加模型目录最近一次同步得到的差异
+	for i := range data.Items {
+		data.Items[i].ModelDrift = v1.CoreV1.AI().Catalog().Drift(data.Items[i].Namespace, data.Items[i].Name)
+	}
 	middleware.ResponseSuccess(ctx, data)
 }
 
+// DeleteOllama 删除 Ollama 部署
+// @Summary      删除 Ollama 部署
+// @Description  删除 Ollama 的 Deployment/DaemonSet 及 Service，keep_pvc=true 时保留模型存储 PVC
+// @Tags         ollama
+// @ID           /api/k8s/ollama/del
+// @Accept       json
+// @Produce      json
+// @Param        name       query  string  true   "Ollama部署名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Param        keep_pvc   query  bool    false  "是否保留模型存储PVC"
+// @Success      200        {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/ollama/del [delete]
+func (o *ollama) DeleteOllama(ctx *gin.Context) {
+	params := &kubeDto.OllamaDeleteInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.DeleteOllama(params.Name, params.NameSpace, params.KeepPVC); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// ScaleOllama 调整 Ollama 副本数
+// @Summary      调整 Ollama 副本数
+// @Description  调整 Ollama Deployment 的副本数，DaemonSet 不支持
+// @Tags         ollama
+// @ID           /api/k8s/ollama/scale
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaScaleInput  true  "扩缩容参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": 1}"
+// @Router       /api/k8s/ollama/scale [put]
+func (o *ollama) ScaleOllama(ctx *gin.Context) {
+	params := &kubeDto.OllamaScaleInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	replicas, err := kube.Ollama.ScaleOllama(params.Name, params.NameSpace, params.Replicas)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, replicas)
+}
+
+// UpgradeOllama 升级 Ollama 镜像
+// @Summary      升级 Ollama 镜像
+// @Description  滚动升级 Ollama 到指定镜像
+// @Tags         ollama
+// @ID           /api/k8s/ollama/upgrade
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaUpgradeInput  true  "升级参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "升级成功}"
+// @Router       /api/k8s/ollama/upgrade [put]
+func (o *ollama) UpgradeOllama(ctx *gin.Context) {
+	params := &kubeDto.OllamaUpgradeInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.UpgradeOllama(params.Name, params.NameSpace, params.Image); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "升级成功")
+}
+
+// RestartOllama 重启 Ollama
+// @Summary      重启 Ollama
+// @Description  滚动重启 Ollama 的所有 Pod
+// @Tags         ollama
+// @ID           /api/k8s/ollama/restart
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaNameNS  true  "Ollama部署名称和命名空间"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "重启成功}"
+// @Router       /api/k8s/ollama/restart [put]
+func (o *ollama) RestartOllama(ctx *gin.Context) {
+	params := &kubeDto.OllamaNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.RestartOllama(params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "重启成功")
+}
+
 // PullModel 拉取模型到指定的 Pod
 // @Summary      拉取模型到指定的 Pod
-// @Description  在指定的 Pod 中拉取模型
+// @Description  在指定的 Pod 或部署的每个就绪副本中后台拉取模型，立即返回拉取任务列表，通过任务接口查询进度
 // @Tags         ollama
 // @ID           /api/k8s/ollama/model/pull
 // @Accept       json
 // @Produce      json
 // @Param        body  body  kubeDto.OllamaPullModelInput  true  "拉取模型参数"
-// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "拉取成功}"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
 // @Router       /api/k8s/ollama/model/pull [post]
 func (o *ollama) PullModel(ctx *gin.Context) {
 	params := &kubeDto.OllamaPullModelInput{}
@@ -87,12 +198,97 @@ func (o *ollama) PullModel(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Ollama.PullModel(params.PodName, params.NameSpace, params.ModelName); err != nil {
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().PullJob().CreatePullJob(ctx, params, creator)
+	if err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
 		return
 	}
-	middleware.ResponseSuccess(ctx, "拉取成功")
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// PagePullJob 分页查询模型拉取任务
+// @Summary      分页查询模型拉取任务
+// @Description  分页查询模型拉取任务及其进度
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/pull/list
+// @Accept       json
+// @Produce      json
+// @Param        page      query  int     false  "页码"
+// @Param        pageSize  query  int     false  "每页大小"
+// @Param        keyword   query  string  false  "模型名称关键字"
+// @Param        status    query  string  false  "任务状态"
+// @Success      200       {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/ollama/model/pull/list [get]
+func (o *ollama) PagePullJob(ctx *gin.Context) {
+	params := &dto.PageListAIPullJobInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().PullJob().PagePullJob(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetPullJob 获取模型拉取任务详情
+// @Summary      获取模型拉取任务详情
+// @Description  获取模型拉取任务的状态和下载进度
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/pull/detail
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "任务ID"
+// @Success      200         {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/ollama/model/pull/detail [get]
+func (o *ollama) GetPullJob(ctx *gin.Context) {
+	params := &dto.AIPullJobIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().PullJob().GetPullJob(ctx, params.InstanceID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// CancelPullJob 取消模型拉取任务
+// @Summary      取消模型拉取任务
+// @Description  取消正在运行的模型拉取任务
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/pull/cancel
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIPullJobIDInput  true  "任务ID"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "取消成功}"
+// @Router       /api/k8s/ollama/model/pull/cancel [post]
+func (o *ollama) CancelPullJob(ctx *gin.Context) {
+	params := &dto.AIPullJobIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.AI().PullJob().CancelPullJob(ctx, params.InstanceID); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "取消成功")
 }
 
 // GetModelList 获取指定 Pod 的模型列表
@@ -102,7 +298,8 @@ func (o *ollama) PullModel(ctx *gin.Context) {
 // @ID           /api/k8s/ollama/model/list
 // @Accept       json
 // @Produce      json
-// @Param        pod_name   query  string  true  "Pod名称"
+// @Param        pod_name   query  string  false  "Pod名称（与部署名称二选一）"
+// @Param        deployment query  string  false  "Ollama部署名称（与Pod名称二选一）"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data": object}"
 // @Router       /api/k8s/ollama/model/list [get]
@@ -113,7 +310,104 @@ func (o *ollama) GetModelList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Ollama.GetModelList(params.PodName, params.NameSpace)
+	data, err := kube.Ollama.GetModelList(ctx.Request.Context(), params.OllamaTarget)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetLoadedModels 获取已加载到内存的模型
+// @Summary      获取已加载到内存的模型
+// @Description  调用每个副本的 Ollama /api/ps，返回已加载到显存/内存的模型及其大小和过期时间
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/ps
+// @Accept       json
+// @Produce      json
+// @Param        pod_name   query  string  false  "Pod名称（与部署名称二选一）"
+// @Param        deployment query  string  false  "Ollama部署名称（与Pod名称二选一）"
+// @Param        namespace  query  string  true  "命名空间"
+// @Success      200        {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/k8s/ollama/model/ps [get]
+func (o *ollama) GetLoadedModels(ctx *gin.Context) {
+	params := &kubeDto.OllamaModelListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.Ollama.LoadedModels(ctx.Request.Context(), params.OllamaTarget)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// LoadModel 预加载模型
+// @Summary      预加载模型
+// @Description  在每个就绪副本上将模型加载到内存，并按 keep_alive 设置驻留时间，-1 表示常驻
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/load
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaLoadModelInput  true  "预加载参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "加载成功}"
+// @Router       /api/k8s/ollama/model/load [post]
+func (o *ollama) LoadModel(ctx *gin.Context) {
+	params := &kubeDto.OllamaLoadModelInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.LoadModel(ctx.Request.Context(), params.OllamaTarget, params.ModelName, params.KeepAlive); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "加载成功")
+}
+
+// UnloadModel 卸载模型
+// @Summary      卸载模型
+// @Description  立即从每个就绪副本的内存中卸载模型，模型文件不会被删除
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/unload
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaUnloadModelInput  true  "卸载参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "卸载成功}"
+// @Router       /api/k8s/ollama/model/unload [post]
+func (o *ollama) UnloadModel(ctx *gin.Context) {
+	params := &kubeDto.OllamaUnloadModelInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.UnloadModel(ctx.Request.Context(), params.OllamaTarget, params.ModelName); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "卸载成功")
+}
+
+// GetResidency 获取所有 Ollama 部署的模型驻留情况
+// @Summary      获取所有 Ollama 部署的模型驻留情况
+// @Description  汇总所有 kubemanage 管理的 Ollama 副本已加载的模型，按节点分组并返回节点可分配的内存和 GPU
+// @Tags         ollama
+// @ID           /api/k8s/ollama/residency
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/k8s/ollama/residency [get]
+func (o *ollama) GetResidency(ctx *gin.Context) {
+	data, err := kube.Ollama.ResidencyOverview(ctx.Request.Context())
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -129,7 +423,8 @@ func (o *ollama) GetModelList(ctx *gin.Context) {
 // @ID           /api/k8s/ollama/model/del
 // @Accept       json
 // @Produce      json
-// @Param        pod_name   query  string  true  "Pod名称"
+// @Param        pod_name   query  string  false  "Pod名称（与部署名称二选一）"
+// @Param        deployment query  string  false  "Ollama部署名称（与Pod名称二选一）"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        model_name query  string  true  "模型名称"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
@@ -141,7 +436,7 @@ func (o *ollama) DeleteModel(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Ollama.DeleteModel(params.PodName, params.NameSpace, params.ModelName); err != nil {
+	if err := kube.Ollama.DeleteModel(ctx.Request.Context(), params.OllamaTarget, params.ModelName); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -156,7 +451,8 @@ func (o *ollama) DeleteModel(ctx *gin.Context) {
 // @ID           /api/k8s/ollama/model/detail
 // @Accept       json
 // @Produce      json
-// @Param        pod_name   query  string  true  "Pod名称"
+// @Param        pod_name   query  string  false  "Pod名称（与部署名称二选一）"
+// @Param        deployment query  string  false  "Ollama部署名称（与Pod名称二选一）"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        model_name query  string  true  "模型名称"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data": object}"
@@ -168,7 +464,7 @@ func (o *ollama) GetModelDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Ollama.GetModelDetail(params.PodName, params.NameSpace, params.ModelName)
+	data, err := kube.Ollama.GetModelDetail(ctx.Request.Context(), params.OllamaTarget, params.ModelName)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -177,13 +473,103 @@ func (o *ollama) GetModelDetail(ctx *gin.Context) {
 	middleware.ResponseSuccess(ctx, data)
 }
 
+// CreateModel 创建自定义模型
+// @Summary      创建自定义模型
+// @Description  根据 Modelfile 或结构化参数（基础模型、系统提示词、参数、量化类型）调用 Ollama /api/create 创建模型，指定部署时在每个就绪副本上创建
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/create
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaCreateModelInput  true  "创建模型参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "创建成功}"
+// @Router       /api/k8s/ollama/model/create [post]
+func (o *ollama) CreateModel(ctx *gin.Context) {
+	params := &kubeDto.OllamaCreateModelInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.CreateModelFromInput(ctx.Request.Context(), params); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "创建成功")
+}
+
+// CopyModel 复制模型
+// @Summary      复制模型
+// @Description  调用 Ollama /api/copy 以新名称复制模型，指定部署时在每个就绪副本上复制
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/copy
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OllamaCopyModelInput  true  "复制模型参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": "复制成功}"
+// @Router       /api/k8s/ollama/model/copy [post]
+func (o *ollama) CopyModel(ctx *gin.Context) {
+	params := &kubeDto.OllamaCopyModelInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Ollama.CopyModel(ctx.Request.Context(), params.OllamaTarget, params.Source, params.Destination); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "复制成功")
+}
+
+// UploadModel 上传 GGUF 模型文件
+// @Summary      上传 GGUF 模型文件
+// @Description  通过 exec 将 GGUF 文件写入 Ollama 副本的模型目录，适用于无法访问模型仓库的集群；指定模型名称时上传后直接注册为模型，否则返回文件摘要供创建模型时引用
+// @Tags         ollama
+// @ID           /api/k8s/ollama/model/upload
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        pod_name    formData  string  false  "Pod名称（与部署名称二选一）"
+// @Param        deployment  formData  string  false  "Ollama部署名称（与Pod名称二选一）"
+// @Param        namespace   formData  string  true   "命名空间"
+// @Param        model_name  formData  string  false  "模型名称（可选）"
+// @Param        modelfile   formData  string  false  "Modelfile 内容（可选）"
+// @Param        file        formData  file    true   "GGUF 文件"
+// @Success      200         {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/ollama/model/upload [post]
+func (o *ollama) UploadModel(ctx *gin.Context) {
+	params := &kubeDto.OllamaUploadModelInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	open := func() (io.ReadCloser, error) {
+		return file.Open()
+	}
+	data, err := kube.Ollama.UploadModel(ctx.Request.Context(), params, file.Filename, open)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
 // Chat 调用指定 Pod 上的模型进行聊天
 // @Summary      调用指定 Pod 上的模型进行聊天
-// @Description  调用指定 Pod 上的 Ollama 模型进行对话
+// @Description  调用指定 Pod 上的 Ollama 模型进行对话，可通过 template_id 引用提示词模板作为系统提示词，stream=true 时以 SSE 逐块返回（message/done/error 事件）；format 为 JSON Schema 时校验输出并按 schema_retries 重试
 // @Tags         ollama
 // @ID           /api/k8s/ollama/chat
 // @Accept       json
-// @Produce      json
+// @Produce      json,text/event-stream
 // @Param        body  body  kubeDto.OllamaChatInput  true  "聊天参数"
 // @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
 // @Router       /api/k8s/ollama/chat [post]
@@ -194,7 +580,67 @@ func (o *ollama) Chat(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Ollama.Chat(params.PodName, params.NameSpace, params.Model, params.Messages, params.Stream)
+	if _, err := kube.CompileFormat(params.Format); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	reqCtx := kube.WithInferenceOptions(usageContext(ctx, "api"), params.OllamaInferenceOptions)
+	if params.TemplateID != "" {
+		var err error
+		if err = resolvePromptTemplate(ctx, &params.PromptTemplateRef); err == nil {
+			params.Messages, err = kube.ApplyPromptTemplate(reqCtx, params.Messages, params.PromptTemplateRef)
+		}
+		if err != nil {
+			v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+			middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+			return
+		}
+	}
+	if params.Stream {
+		w := newSSEWriter(ctx)
+		w.Close(kube.Ollama.ChatStream(reqCtx, params.OllamaTarget(), params.Model, params.Messages, w.Message))
+		return
+	}
+	data, err := kube.Ollama.Chat(reqCtx, params.OllamaTarget(), params.Model, params.Messages, params.Stream)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// Generate 调用指定 Pod 上的模型进行文本补全
+// @Summary      调用指定 Pod 上的模型进行文本补全
+// @Description  调用 Ollama /api/generate 进行单轮补全，支持 format（"json" 或 JSON Schema）与 options（temperature、num_ctx、seed、stop 等）透传；format 为 JSON Schema 时校验输出并按 schema_retries 重试，stream=true 时以 SSE 逐块返回且不做校验
+// @Tags         ollama
+// @ID           /api/k8s/ollama/generate
+// @Accept       json
+// @Produce      json,text/event-stream
+// @Param        body  body  kubeDto.OllamaGenerateInput  true  "补全参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/ollama/generate [post]
+func (o *ollama) Generate(ctx *gin.Context) {
+	params := &kubeDto.OllamaGenerateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if _, err := kube.CompileFormat(params.Format); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	reqCtx := kube.WithInferenceOptions(usageContext(ctx, "api"), params.OllamaInferenceOptions)
+	req := kube.GenerateRequest{Prompt: params.Prompt, System: params.System, Raw: params.Raw}
+	if params.Stream {
+		w := newSSEWriter(ctx)
+		w.Close(kube.Ollama.GenerateStream(reqCtx, params.OllamaTarget(), params.Model, req, w.Message))
+		return
+	}
+	data, err := kube.Ollama.Generate(reqCtx, params.OllamaTarget(), params.Model, req)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -220,7 +666,7 @@ func (o *ollama) Embeddings(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Ollama.Embeddings(params.PodName, params.NameSpace, params.Model, params.Prompt)
+	data, err := kube.Ollama.Embeddings(usageContext(ctx, "api"), params.OllamaTarget(), params.Model, params.Prompt)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -0,0 +1,123 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// ListCatalog 查询模型目录
+// @Summary      查询模型目录
+// @Description  查询 Ollama 部署声明的期望模型列表
+// @Tags         ollama
+// @ID           /api/k8s/ollama/catalog/list
+// @Accept       json
+// @Produce      json
+// @Param        namespace   query  string  false  "命名空间"
+// @Param        deployment  query  string  false  "Ollama部署名称"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": []}"
+// @Router       /api/k8s/ollama/catalog/list [get]
+func (o *ollama) ListCatalog(ctx *gin.Context) {
+	params := &dto.AIModelCatalogListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.AI().Catalog().ListModels(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// AddCatalog 向模型目录添加模型
+// @Summary      向模型目录添加模型
+// @Description  声明 Ollama 部署期望拥有的模型，同步任务会将其拉取到每个就绪副本
+// @Tags         ollama
+// @ID           /api/k8s/ollama/catalog/add
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIModelCatalogInput  true  "模型目录参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/ollama/catalog/add [post]
+func (o *ollama) AddCatalog(ctx *gin.Context) {
+	params := &dto.AIModelCatalogInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().Catalog().AddModel(ctx, params, creator)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DeleteCatalog 从模型目录移除模型
+// @Summary      从模型目录移除模型
+// @Description  从模型目录移除模型，不会删除副本上已拉取的模型，需要时通过同步接口的 prune 删除
+// @Tags         ollama
+// @ID           /api/k8s/ollama/catalog/del
+// @Accept       json
+// @Produce      json
+// @Param        instanceID  query  string  true  "模型目录ID"
+// @Success      200 {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/ollama/catalog/del [delete]
+func (o *ollama) DeleteCatalog(ctx *gin.Context) {
+	params := &dto.AIModelCatalogIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.AI().Catalog().RemoveModel(ctx, params.InstanceID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// SyncCatalog 立即同步模型目录
+// @Summary      立即同步模型目录
+// @Description  为部署的每个就绪副本拉取缺失的模型，prune=true 时删除未声明的模型，返回同步后的差异
+// @Tags         ollama
+// @ID           /api/k8s/ollama/catalog/sync
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.AIModelCatalogSyncInput  true  "同步参数"
+// @Success      200   {object}  middleware.Response"{"code": 200, msg="","data": object}"
+// @Router       /api/k8s/ollama/catalog/sync [post]
+func (o *ollama) SyncCatalog(ctx *gin.Context) {
+	params := &dto.AIModelCatalogSyncInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	var creator string
+	if claims := utils.GetUserInfo(ctx); claims != nil {
+		creator = claims.Username
+	}
+	data, err := v1.CoreV1.AI().Catalog().Sync(ctx, params, creator)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
@@ -0,0 +1,61 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// sseWriter 以 Server-Sent Events 的形式向客户端推送流式数据
+// 响应头在第一次推送时才写入，推送前出错仍按普通 JSON 返回
+type sseWriter struct {
+	ctx     *gin.Context
+	started bool
+}
+
+func newSSEWriter(ctx *gin.Context) *sseWriter {
+	return &sseWriter{ctx: ctx}
+}
+
+// Event 推送一个事件并立即刷新到客户端
+func (w *sseWriter) Event(name string, data interface{}) error {
+	if !w.started {
+		w.ctx.Header("Content-Type", "text/event-stream")
+		w.ctx.Header("Cache-Control", "no-cache")
+		w.ctx.Header("Connection", "keep-alive")
+		// 关闭 nginx 等反向代理的缓冲
+		w.ctx.Header("X-Accel-Buffering", "no")
+		w.started = true
+	}
+	w.ctx.SSEvent(name, data)
+	w.ctx.Writer.Flush()
+	// 客户端断开后停止推送，由调用方取消上游请求
+	return w.ctx.Request.Context().Err()
+}
+
+// Message 推送模型输出分片
+func (w *sseWriter) Message(chunk map[string]interface{}) error {
+	return w.Event("message", chunk)
+}
+
+// Close 结束推送，err 不为空时推送 error 事件
+func (w *sseWriter) Close(err error) {
+	if w.ctx.Request.Context().Err() != nil {
+		// 客户端已断开，无需再写入
+		v1.Log.Info("stream client disconnected")
+		return
+	}
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		if !w.started {
+			middleware.ResponseError(w.ctx, globalError.NewGlobalError(globalError.GetError, err))
+			return
+		}
+		w.ctx.SSEvent("error", gin.H{"message": err.Error()})
+		w.ctx.Writer.Flush()
+		return
+	}
+	_ = w.Event("done", gin.H{"done": true})
+}
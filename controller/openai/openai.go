@@ -0,0 +1,341 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/middleware"
+	v1 "github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type choice struct {
+	Index        int                        `json:"index"`
+	Message      *kubeDto.OllamaChatMessage `json:"message,omitempty"`
+	Delta        *kubeDto.OllamaChatMessage `json:"delta,omitempty"`
+	Text         *string                    `json:"text,omitempty"`
+	FinishReason *string                    `json:"finish_reason"`
+}
+
+type completionResponse struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []choice `json:"choices"`
+	Usage   *usage   `json:"usage,omitempty"`
+}
+
+// ListModels 列出所有托管 Ollama 上可用的模型
+// @Summary      列出可用模型
+// @Description  OpenAI 兼容接口，返回所有 managed=kubemanage 的 Ollama 上已拉取的模型
+// @Tags         openai
+// @ID           /v1/models
+// @Produce      json
+// @Router       /v1/models [get]
+func (o *openaiController) ListModels(ctx *gin.Context) {
+	endpoints, err := kube.Ollama.ListManagedModels(ctx.Request.Context())
+	if err != nil {
+		v1.Log.ErrorWithErr("list managed models failed", err)
+		middleware.ResponseOpenAIError(ctx, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	seen := make(map[string]bool)
+	data := make([]gin.H, 0)
+	for _, e := range endpoints {
+		if seen[e.Model] {
+			continue
+		}
+		seen[e.Model] = true
+		data = append(data, gin.H{"id": e.Model, "object": "model", "created": 0, "owned_by": "kubemanage"})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"object": "list", "data": data})
+}
+
+// ChatCompletions 对话补全
+// @Summary      对话补全
+// @Description  OpenAI 兼容接口，按 model 名称路由到托管的 Ollama，stream=true 时以 SSE 返回
+// @Tags         openai
+// @ID           /v1/chat/completions
+// @Accept       json
+// @Produce      json,text/event-stream
+// @Param        body  body  kubeDto.OpenAIChatCompletionInput  true  "请求参数"
+// @Router       /v1/chat/completions [post]
+func (o *openaiController) ChatCompletions(ctx *gin.Context) {
+	params := &kubeDto.OpenAIChatCompletionInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		middleware.ResponseOpenAIError(ctx, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	reqCtx, ok := inferenceContext(ctx, &params.OpenAISamplingParams)
+	if !ok {
+		return
+	}
+	endpoint, ok := o.resolve(ctx, params.Model)
+	if !ok {
+		return
+	}
+	if params.Stream {
+		o.stream(ctx, reqCtx, "chat.completion.chunk", params.Model, endpoint, params.Messages, func(content string) choice {
+			return choice{Delta: &kubeDto.OllamaChatMessage{Role: "assistant", Content: content}}
+		})
+		return
+	}
+
+	data, err := kube.Ollama.Chat(reqCtx, endpoint.Target(), endpoint.Model, params.Messages, false)
+	if err != nil {
+		v1.Log.ErrorWithErr("openai chat completions failed", err)
+		middleware.ResponseOpenAIError(ctx, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	resp, _ := data.(map[string]interface{})
+	finish := finishReason(resp)
+	ctx.JSON(http.StatusOK, &completionResponse{
+		ID:      "chatcmpl-" + utils.GetSnowflakeID(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   params.Model,
+		Choices: []choice{{
+			Message:      &kubeDto.OllamaChatMessage{Role: "assistant", Content: messageContent(resp)},
+			FinishReason: &finish,
+		}},
+		Usage: usageOf(resp),
+	})
+}
+
+// Completions 文本补全
+// @Summary      文本补全
+// @Description  OpenAI 兼容接口，prompt 作为单条用户消息发送给模型，stream=true 时以 SSE 返回
+// @Tags         openai
+// @ID           /v1/completions
+// @Accept       json
+// @Produce      json,text/event-stream
+// @Param        body  body  kubeDto.OpenAICompletionInput  true  "请求参数"
+// @Router       /v1/completions [post]
+func (o *openaiController) Completions(ctx *gin.Context) {
+	params := &kubeDto.OpenAICompletionInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		middleware.ResponseOpenAIError(ctx, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	reqCtx, ok := inferenceContext(ctx, &params.OpenAISamplingParams)
+	if !ok {
+		return
+	}
+	endpoint, ok := o.resolve(ctx, params.Model)
+	if !ok {
+		return
+	}
+	messages := []kubeDto.OllamaChatMessage{{Role: "user", Content: params.Prompt}}
+	if params.Stream {
+		o.stream(ctx, reqCtx, "text_completion", params.Model, endpoint, messages, func(content string) choice {
+			return choice{Text: &content}
+		})
+		return
+	}
+
+	data, err := kube.Ollama.Chat(reqCtx, endpoint.Target(), endpoint.Model, messages, false)
+	if err != nil {
+		v1.Log.ErrorWithErr("openai completions failed", err)
+		middleware.ResponseOpenAIError(ctx, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	resp, _ := data.(map[string]interface{})
+	text := messageContent(resp)
+	finish := finishReason(resp)
+	ctx.JSON(http.StatusOK, &completionResponse{
+		ID:      "cmpl-" + utils.GetSnowflakeID(),
+		Object:  "text_completion",
+		Created: time.Now().Unix(),
+		Model:   params.Model,
+		Choices: []choice{{Text: &text, FinishReason: &finish}},
+		Usage:   usageOf(resp),
+	})
+}
+
+// Embeddings 生成向量嵌入
+// @Summary      生成向量嵌入
+// @Description  OpenAI 兼容接口，input 支持字符串或字符串数组
+// @Tags         openai
+// @ID           /v1/embeddings
+// @Accept       json
+// @Produce      json
+// @Param        body  body  kubeDto.OpenAIEmbeddingInput  true  "请求参数"
+// @Router       /v1/embeddings [post]
+func (o *openaiController) Embeddings(ctx *gin.Context) {
+	params := &kubeDto.OpenAIEmbeddingInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		middleware.ResponseOpenAIError(ctx, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	inputs, err := params.Inputs()
+	if err != nil {
+		middleware.ResponseOpenAIError(ctx, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	endpoint, ok := o.resolve(ctx, params.Model)
+	if !ok {
+		return
+	}
+
+	data := make([]gin.H, 0, len(inputs))
+	var tokens usage
+	for i, input := range inputs {
+		result, err := kube.Ollama.Embeddings(usageContext(ctx), endpoint.Target(), endpoint.Model, input)
+		if err != nil {
+			v1.Log.ErrorWithErr("openai embeddings failed", err)
+			middleware.ResponseOpenAIError(ctx, http.StatusBadGateway, "api_error", err.Error())
+			return
+		}
+		resp, _ := result.(map[string]interface{})
+		data = append(data, gin.H{"object": "embedding", "index": i, "embedding": resp["embedding"]})
+		tokens.PromptTokens += kube.EmbeddingPromptTokens(resp, input)
+	}
+	tokens.TotalTokens = tokens.PromptTokens
+	ctx.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   data,
+		"model":  params.Model,
+		"usage":  tokens,
+	})
+}
+
+// resolve 将 OpenAI 的 model 名称映射到托管的 Ollama Pod，虚拟模型交由路由策略选择目标
+func (o *openaiController) resolve(ctx *gin.Context, model string) (*kube.ModelEndpoint, bool) {
+	if kube.Ollama.LookupRoute(ctx.Request.Context(), model) != nil {
+		return &kube.ModelEndpoint{Model: model}, true
+	}
+	endpoint, err := kube.Ollama.ResolveModel(ctx.Request.Context(), model)
+	if err != nil {
+		if errors.Is(err, kube.ErrModelNotFound) {
+			middleware.ResponseOpenAIError(ctx, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("the model `%s` does not exist", model))
+			return nil, false
+		}
+		v1.Log.ErrorWithErr("resolve model failed", err)
+		middleware.ResponseOpenAIError(ctx, http.StatusInternalServerError, "api_error", err.Error())
+		return nil, false
+	}
+	return endpoint, true
+}
+
+// stream 以 OpenAI SSE 格式（data: {...} 与 data: [DONE]）转发 Ollama 的流式输出
+func (o *openaiController) stream(ctx *gin.Context, reqCtx context.Context, object, model string, endpoint *kube.ModelEndpoint, messages []kubeDto.OllamaChatMessage, build func(content string) choice) {
+	id := "chatcmpl-" + utils.GetSnowflakeID()
+	if object == "text_completion" {
+		id = "cmpl-" + utils.GetSnowflakeID()
+	}
+	created := time.Now().Unix()
+	started := false
+	write := func(v interface{}) error {
+		if !started {
+			ctx.Header("Content-Type", "text/event-stream")
+			ctx.Header("Cache-Control", "no-cache")
+			ctx.Header("Connection", "keep-alive")
+			ctx.Header("X-Accel-Buffering", "no")
+			ctx.Status(http.StatusOK)
+			started = true
+		}
+		var payload string
+		if s, ok := v.(string); ok {
+			payload = s
+		} else {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			payload = string(b)
+		}
+		if _, err := fmt.Fprintf(ctx.Writer, "data: %s\n\n", payload); err != nil {
+			return err
+		}
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	}
+
+	err := kube.Ollama.ChatStream(reqCtx, endpoint.Target(), endpoint.Model, messages, func(chunk map[string]interface{}) error {
+		c := build(messageContent(chunk))
+		resp := &completionResponse{ID: id, Object: object, Created: created, Model: model}
+		if done, _ := chunk["done"].(bool); done {
+			finish := finishReason(chunk)
+			c.FinishReason = &finish
+			resp.Usage = usageOf(chunk)
+		}
+		resp.Choices = []choice{c}
+		return write(resp)
+	})
+	if ctx.Request.Context().Err() != nil {
+		// 客户端已断开
+		return
+	}
+	if err != nil {
+		v1.Log.ErrorWithErr("openai stream failed", err)
+		if !started {
+			middleware.ResponseOpenAIError(ctx, http.StatusBadGateway, "api_error", err.Error())
+			return
+		}
+		_ = write(&middleware.OpenAIErrorResponse{Error: middleware.OpenAIError{Message: err.Error(), Type: "api_error"}})
+		return
+	}
+	_ = write("[DONE]")
+}
+
+// usageContext 推理用量归属于通过认证的 API Key
+func usageContext(ctx *gin.Context) context.Context {
+	return kube.WithUsageCaller(ctx.Request.Context(), kube.UsageCaller{
+		UserName: ctx.GetString(middleware.OpenAIKeyName),
+		Source:   "openai",
+	})
+}
+
+// inferenceContext 将采样参数附加到推理 context，参数无效时返回 400
+func inferenceContext(ctx *gin.Context, params *kubeDto.OpenAISamplingParams) (context.Context, bool) {
+	opts, err := params.InferenceOptions()
+	if err != nil {
+		middleware.ResponseOpenAIError(ctx, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return nil, false
+	}
+	return kube.WithInferenceOptions(usageContext(ctx), opts), true
+}
+
+// messageContent 提取 Ollama chat 响应中的文本内容
+func messageContent(resp map[string]interface{}) string {
+	if msg, ok := resp["message"].(map[string]interface{}); ok {
+		if content, ok := msg["content"].(string); ok {
+			return content
+		}
+	}
+	return ""
+}
+
+// finishReason 将 Ollama 的 done_reason 转换为 OpenAI 的 finish_reason
+func finishReason(resp map[string]interface{}) string {
+	if reason, ok := resp["done_reason"].(string); ok && reason == "length" {
+		return "length"
+	}
+	return "stop"
+}
+
+// usageOf 根据 Ollama 的 prompt_eval_count / eval_count 计算 token 用量
+func usageOf(resp map[string]interface{}) *usage {
+	prompt, _ := resp["prompt_eval_count"].(float64)
+	completion, _ := resp["eval_count"].(float64)
+	return &usage{
+		PromptTokens:     int(prompt),
+		CompletionTokens: int(completion),
+		TotalTokens:      int(prompt + completion),
+	}
+}
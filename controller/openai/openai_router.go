@@ -0,0 +1,23 @@
+package openai
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/middleware"
+)
+
+type openaiController struct{}
+
+// NewOpenAIRouter 注册 OpenAI 兼容接口，使用独立的 API Key 认证而不是 JWT + Casbin
+func NewOpenAIRouter(ginEngine *gin.RouterGroup) {
+	o := openaiController{}
+	ginEngine.Use(middleware.Logger(), middleware.Cores(), middleware.Recovery(true), middleware.TranslationMiddleware(), middleware.OpenAIAuth(), middleware.OpenAIQuota())
+	o.initRoutes(ginEngine)
+}
+
+func (o *openaiController) initRoutes(ginEngine *gin.RouterGroup) {
+	ginEngine.GET("/models", o.ListModels)
+	ginEngine.POST("/chat/completions", o.ChatCompletions)
+	ginEngine.POST("/completions", o.Completions)
+	ginEngine.POST("/embeddings", o.Embeddings)
+}
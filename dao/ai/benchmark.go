@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+type BenchmarkI interface {
+	Save(ctx context.Context, in *model.AIBenchmark) error
+	Updates(ctx context.Context, opt common.UpdateOption, in *model.AIBenchmark) error
+	Find(ctx context.Context, search model.AIBenchmark) (model.AIBenchmark, error)
+	// Delete 删除任务及其全部结果
+	Delete(ctx context.Context, search model.AIBenchmark) error
+	// IncrProgress 累加已完成及失败的请求数
+	IncrProgress(ctx context.Context, instanceID string, failed bool) error
+
+	PageList(ctx context.Context, params runtime.Pager) ([]model.AIBenchmark, int64, error)
+
+	SaveResult(ctx context.Context, in *model.AIBenchmarkResult) error
+	// ListResults 按目标、提示词顺序返回任务的全部结果
+	ListResults(ctx context.Context, benchmarkID string) ([]model.AIBenchmarkResult, error)
+}
+
+type benchmark struct {
+	db *gorm.DB
+}
+
+func NewBenchmarkI(db *gorm.DB) BenchmarkI {
+	return &benchmark{db: db}
+}
+
+func (b *benchmark) Save(ctx context.Context, in *model.AIBenchmark) error {
+	return b.db.WithContext(ctx).Create(in).Error
+}
+
+func (b *benchmark) Updates(ctx context.Context, opt common.UpdateOption, in *model.AIBenchmark) error {
+	query := opt(b.db)
+	return query.WithContext(ctx).Updates(in).Error
+}
+
+func (b *benchmark) Find(ctx context.Context, search model.AIBenchmark) (model.AIBenchmark, error) {
+	var out model.AIBenchmark
+	return out, b.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (b *benchmark) Delete(ctx context.Context, search model.AIBenchmark) error {
+	return b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var list []model.AIBenchmark
+		if err := tx.Where(&search).Find(&list).Error; err != nil {
+			return err
+		}
+		for _, item := range list {
+			if err := tx.Where("benchmarkID = ?", item.InstanceID).Unscoped().Delete(&model.AIBenchmarkResult{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *benchmark) IncrProgress(ctx context.Context, instanceID string, failed bool) error {
+	updates := map[string]interface{}{"completed": gorm.Expr("completed + 1")}
+	if failed {
+		updates["failed"] = gorm.Expr("failed + 1")
+	}
+	return b.db.WithContext(ctx).Model(&model.AIBenchmark{}).Where("instanceID = ?", instanceID).Updates(updates).Error
+}
+
+func (b *benchmark) PageList(ctx context.Context, params runtime.Pager) ([]model.AIBenchmark, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := b.db.WithContext(ctx).Model(&model.AIBenchmark{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIBenchmark
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (b *benchmark) SaveResult(ctx context.Context, in *model.AIBenchmarkResult) error {
+	return b.db.WithContext(ctx).Create(in).Error
+}
+
+func (b *benchmark) ListResults(ctx context.Context, benchmarkID string) ([]model.AIBenchmarkResult, error) {
+	var out []model.AIBenchmarkResult
+	return out, b.db.WithContext(ctx).Where("benchmarkID = ?", benchmarkID).Order("targetIndex, promptIndex").Find(&out).Error
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+type ConversationI interface {
+	Save(ctx context.Context, in *model.AIConversation) error
+	Updates(ctx context.Context, opt common.UpdateOption, in *model.AIConversation) error
+	Find(ctx context.Context, search model.AIConversation) (model.AIConversation, error)
+	// Delete 删除对话及其全部消息
+	Delete(ctx context.Context, search model.AIConversation) error
+
+	PageList(ctx context.Context, params runtime.Pager) ([]model.AIConversation, int64, error)
+
+	SaveMessage(ctx context.Context, in *model.AIConversationMessage) error
+	// ListMessages 获取对话消息，按时间正序，limit 大于 0 时只返回最近的 limit 条
+	ListMessages(ctx context.Context, conversationID string, limit int) ([]model.AIConversationMessage, error)
+}
+
+type conversation struct {
+	db *gorm.DB
+}
+
+func NewConversationI(db *gorm.DB) ConversationI {
+	return &conversation{db: db}
+}
+
+func (c *conversation) Save(ctx context.Context, in *model.AIConversation) error {
+	return c.db.WithContext(ctx).Create(in).Error
+}
+
+func (c *conversation) Updates(ctx context.Context, opt common.UpdateOption, in *model.AIConversation) error {
+	query := opt(c.db)
+	return query.WithContext(ctx).Updates(in).Error
+}
+
+func (c *conversation) Find(ctx context.Context, search model.AIConversation) (model.AIConversation, error) {
+	var out model.AIConversation
+	return out, c.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (c *conversation) Delete(ctx context.Context, search model.AIConversation) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var list []model.AIConversation
+		if err := tx.Where(&search).Find(&list).Error; err != nil {
+			return err
+		}
+		for _, item := range list {
+			if err := tx.Where("conversationID = ?", item.InstanceID).Unscoped().Delete(&model.AIConversationMessage{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *conversation) PageList(ctx context.Context, params runtime.Pager) ([]model.AIConversation, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := c.db.WithContext(ctx).Model(&model.AIConversation{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIConversation
+	if err := query.Order("updated_at desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (c *conversation) SaveMessage(ctx context.Context, in *model.AIConversationMessage) error {
+	return c.db.WithContext(ctx).Create(in).Error
+}
+
+func (c *conversation) ListMessages(ctx context.Context, conversationID string, limit int) ([]model.AIConversationMessage, error) {
+	var out []model.AIConversationMessage
+	query := c.db.WithContext(ctx).Where("conversationID = ?", conversationID).Order("id desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&out).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+)
+
+type EmbeddingCacheI interface {
+	// Save 写入缓存，(模型, hash) 已存在时忽略
+	Save(ctx context.Context, in *model.AIEmbeddingCache) error
+	Find(ctx context.Context, search model.AIEmbeddingCache) (model.AIEmbeddingCache, error)
+	// DeleteByModel 物理删除模型的缓存，model 为空时删除全部
+	DeleteByModel(ctx context.Context, modelName string) (int64, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+type embeddingCache struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingCacheI(db *gorm.DB) EmbeddingCacheI {
+	return &embeddingCache{db: db}
+}
+
+func (e *embeddingCache) Save(ctx context.Context, in *model.AIEmbeddingCache) error {
+	return e.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(in).Error
+}
+
+func (e *embeddingCache) Find(ctx context.Context, search model.AIEmbeddingCache) (model.AIEmbeddingCache, error) {
+	var out model.AIEmbeddingCache
+	return out, e.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (e *embeddingCache) DeleteByModel(ctx context.Context, modelName string) (int64, error) {
+	query := e.db.WithContext(ctx).Unscoped()
+	if modelName != "" {
+		query = query.Where("model = ?", modelName)
+	} else {
+		query = query.Where("1 = 1")
+	}
+	result := query.Delete(&model.AIEmbeddingCache{})
+	return result.RowsAffected, result.Error
+}
+
+func (e *embeddingCache) Count(ctx context.Context) (int64, error) {
+	var total int64
+	return total, e.db.WithContext(ctx).Model(&model.AIEmbeddingCache{}).Count(&total).Error
+}
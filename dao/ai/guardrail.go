@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+type GuardrailI interface {
+	Save(ctx context.Context, in *model.AIGuardrailRule) error
+	Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	Find(ctx context.Context, search model.AIGuardrailRule) (model.AIGuardrailRule, error)
+	FindList(ctx context.Context, search model.AIGuardrailRule) ([]model.AIGuardrailRule, error)
+	Delete(ctx context.Context, search model.AIGuardrailRule, isDelete bool) error
+
+	SaveViolations(ctx context.Context, in []model.AIGuardrailViolation) error
+	PageViolations(ctx context.Context, params runtime.Pager) ([]model.AIGuardrailViolation, int64, error)
+}
+
+type guardrail struct {
+	db *gorm.DB
+}
+
+func NewGuardrailI(db *gorm.DB) GuardrailI {
+	return &guardrail{db: db}
+}
+
+func (g *guardrail) Save(ctx context.Context, in *model.AIGuardrailRule) error {
+	return g.db.WithContext(ctx).Create(in).Error
+}
+
+// Updates 使用 map 更新，允许将启用状态改为 false
+func (g *guardrail) Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(g.db)
+	return query.WithContext(ctx).Model(&model.AIGuardrailRule{}).Updates(in).Error
+}
+
+func (g *guardrail) Find(ctx context.Context, search model.AIGuardrailRule) (model.AIGuardrailRule, error) {
+	var out model.AIGuardrailRule
+	return out, g.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (g *guardrail) FindList(ctx context.Context, search model.AIGuardrailRule) ([]model.AIGuardrailRule, error) {
+	var out []model.AIGuardrailRule
+	return out, g.db.WithContext(ctx).Where(&search).Order("sort, id").Find(&out).Error
+}
+
+func (g *guardrail) Delete(ctx context.Context, search model.AIGuardrailRule, isDelete bool) error {
+	if isDelete {
+		return g.db.WithContext(ctx).Where(&search).Unscoped().Delete(&search).Error
+	}
+	return g.db.WithContext(ctx).Where(&search).Delete(&search).Error
+}
+
+func (g *guardrail) SaveViolations(ctx context.Context, in []model.AIGuardrailViolation) error {
+	return g.db.WithContext(ctx).Create(&in).Error
+}
+
+func (g *guardrail) PageViolations(ctx context.Context, params runtime.Pager) ([]model.AIGuardrailViolation, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := g.db.WithContext(ctx).Model(&model.AIGuardrailViolation{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIGuardrailViolation
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// ingestChunkBatch 批量写入分块时每条 INSERT 的行数
+const ingestChunkBatch = 200
+
+type IngestJobI interface {
+	// Save 在同一事务中保存任务及其全部分块
+	Save(ctx context.Context, in *model.AIIngestJob, chunks []model.AIIngestChunk) error
+	Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	Find(ctx context.Context, search model.AIIngestJob) (model.AIIngestJob, error)
+	// FindByStatus 返回处于任一状态的任务
+	FindByStatus(ctx context.Context, status ...string) ([]model.AIIngestJob, error)
+	// Claim 接管未结束且没有执行实例或租约已过期的任务，多个实例同时接管时只有一个成功
+	Claim(ctx context.Context, instanceID, runner string, leaseUntil time.Time) (bool, error)
+	// Renew 续约 runner 正在执行的任务，任务已结束或已被其他实例接管时返回 false
+	Renew(ctx context.Context, instanceID, runner string, leaseUntil time.Time) (bool, error)
+	// Delete 删除任务及其全部分块
+	Delete(ctx context.Context, instanceID string) error
+
+	PageList(ctx context.Context, params runtime.Pager) ([]model.AIIngestJob, int64, error)
+
+	// ListChunks 按序号返回任务中处于 status 状态的分块
+	ListChunks(ctx context.Context, jobID, status string) ([]model.AIIngestChunk, error)
+	UpdateChunks(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	// CountChunks 统计任务中各状态的分块数
+	CountChunks(ctx context.Context, jobID string) (map[string]int, error)
+}
+
+type ingestJob struct {
+	db *gorm.DB
+}
+
+func NewIngestJobI(db *gorm.DB) IngestJobI {
+	return &ingestJob{db: db}
+}
+
+func (i *ingestJob) Save(ctx context.Context, in *model.AIIngestJob, chunks []model.AIIngestChunk) error {
+	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(in).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(chunks, ingestChunkBatch).Error
+	})
+}
+
+func (i *ingestJob) Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(i.db)
+	return query.WithContext(ctx).Model(&model.AIIngestJob{}).Updates(in).Error
+}
+
+func (i *ingestJob) Find(ctx context.Context, search model.AIIngestJob) (model.AIIngestJob, error) {
+	var out model.AIIngestJob
+	return out, i.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (i *ingestJob) FindByStatus(ctx context.Context, status ...string) ([]model.AIIngestJob, error) {
+	var out []model.AIIngestJob
+	return out, i.db.WithContext(ctx).Where("status in ?", status).Order("id").Find(&out).Error
+}
+
+func (i *ingestJob) Claim(ctx context.Context, instanceID, runner string, leaseUntil time.Time) (bool, error) {
+	res := i.db.WithContext(ctx).Model(&model.AIIngestJob{}).
+		Where("instanceID = ? and status in ?", instanceID, []string{model.IngestJobPending, model.IngestJobRunning}).
+		Where("runner = '' or leaseUntil is null or leaseUntil < ?", time.Now()).
+		Updates(map[string]interface{}{"runner": runner, "leaseUntil": leaseUntil})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (i *ingestJob) Renew(ctx context.Context, instanceID, runner string, leaseUntil time.Time) (bool, error) {
+	res := i.db.WithContext(ctx).Model(&model.AIIngestJob{}).
+		Where("instanceID = ? and status in ? and runner = ?", instanceID, []string{model.IngestJobPending, model.IngestJobRunning}, runner).
+		Updates(map[string]interface{}{"leaseUntil": leaseUntil})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (i *ingestJob) Delete(ctx context.Context, instanceID string) error {
+	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("jobID = ?", instanceID).Unscoped().Delete(&model.AIIngestChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("instanceID = ?", instanceID).Unscoped().Delete(&model.AIIngestJob{}).Error
+	})
+}
+
+func (i *ingestJob) PageList(ctx context.Context, params runtime.Pager) ([]model.AIIngestJob, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := i.db.WithContext(ctx).Model(&model.AIIngestJob{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIIngestJob
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (i *ingestJob) ListChunks(ctx context.Context, jobID, status string) ([]model.AIIngestChunk, error) {
+	var out []model.AIIngestChunk
+	return out, i.db.WithContext(ctx).Where("jobID = ? and status = ?", jobID, status).Order("chunkIndex").Find(&out).Error
+}
+
+func (i *ingestJob) UpdateChunks(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(i.db)
+	return query.WithContext(ctx).Model(&model.AIIngestChunk{}).Updates(in).Error
+}
+
+func (i *ingestJob) CountChunks(ctx context.Context, jobID string) (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Total  int
+	}
+	if err := i.db.WithContext(ctx).Model(&model.AIIngestChunk{}).Select("status, count(*) as total").
+		Where("jobID = ?", jobID).Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]int, len(rows))
+	for _, row := range rows {
+		out[row.Status] = row.Total
+	}
+	return out, nil
+}
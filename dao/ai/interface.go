@@ -0,0 +1,76 @@
+package ai
+
+import "gorm.io/gorm"
+
+type AIFactory interface {
+	PullJob() PullJobI
+	ModelCatalog() ModelCatalogI
+	Usage() UsageI
+	Quota() QuotaI
+	Conversation() ConversationI
+	PromptTemplate() PromptTemplateI
+	Benchmark() BenchmarkI
+	ModelRoute() ModelRouteI
+	Guardrail() GuardrailI
+	EmbeddingCache() EmbeddingCacheI
+	KnowledgeChunking() KnowledgeChunkingI
+	IngestJob() IngestJobI
+}
+
+func NewAIFactory(db *gorm.DB) AIFactory {
+	return &aiFactory{db: db}
+}
+
+var _ AIFactory = &aiFactory{}
+
+type aiFactory struct {
+	db *gorm.DB
+}
+
+func (a *aiFactory) PullJob() PullJobI {
+	return NewPullJobI(a.db)
+}
+
+func (a *aiFactory) ModelCatalog() ModelCatalogI {
+	return NewModelCatalogI(a.db)
+}
+
+func (a *aiFactory) Usage() UsageI {
+	return NewUsageI(a.db)
+}
+
+func (a *aiFactory) Quota() QuotaI {
+	return NewQuotaI(a.db)
+}
+
+func (a *aiFactory) Conversation() ConversationI {
+	return NewConversationI(a.db)
+}
+
+func (a *aiFactory) PromptTemplate() PromptTemplateI {
+	return NewPromptTemplateI(a.db)
+}
+
+func (a *aiFactory) Benchmark() BenchmarkI {
+	return NewBenchmarkI(a.db)
+}
+
+func (a *aiFactory) ModelRoute() ModelRouteI {
+	return NewModelRouteI(a.db)
+}
+
+func (a *aiFactory) Guardrail() GuardrailI {
+	return NewGuardrailI(a.db)
+}
+
+func (a *aiFactory) EmbeddingCache() EmbeddingCacheI {
+	return NewEmbeddingCacheI(a.db)
+}
+
+func (a *aiFactory) KnowledgeChunking() KnowledgeChunkingI {
+	return NewKnowledgeChunkingI(a.db)
+}
+
+func (a *aiFactory) IngestJob() IngestJobI {
+	return NewIngestJobI(a.db)
+}
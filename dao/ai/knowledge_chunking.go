@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+)
+
+type KnowledgeChunkingI interface {
+	Save(ctx context.Context, in *model.AIKnowledgeChunking) error
+	Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	Find(ctx context.Context, search model.AIKnowledgeChunking) (model.AIKnowledgeChunking, error)
+	FindList(ctx context.Context, search model.AIKnowledgeChunking) ([]model.AIKnowledgeChunking, error)
+	Delete(ctx context.Context, search model.AIKnowledgeChunking, isDelete bool) error
+}
+
+type knowledgeChunking struct {
+	db *gorm.DB
+}
+
+func NewKnowledgeChunkingI(db *gorm.DB) KnowledgeChunkingI {
+	return &knowledgeChunking{db: db}
+}
+
+func (k *knowledgeChunking) Save(ctx context.Context, in *model.AIKnowledgeChunking) error {
+	return k.db.WithContext(ctx).Create(in).Error
+}
+
+func (k *knowledgeChunking) Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(k.db)
+	return query.WithContext(ctx).Model(&model.AIKnowledgeChunking{}).Updates(in).Error
+}
+
+func (k *knowledgeChunking) Find(ctx context.Context, search model.AIKnowledgeChunking) (model.AIKnowledgeChunking, error) {
+	var out model.AIKnowledgeChunking
+	return out, k.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (k *knowledgeChunking) FindList(ctx context.Context, search model.AIKnowledgeChunking) ([]model.AIKnowledgeChunking, error) {
+	var out []model.AIKnowledgeChunking
+	return out, k.db.WithContext(ctx).Where(&search).Order("namespace, knowledge, collection").Find(&out).Error
+}
+
+func (k *knowledgeChunking) Delete(ctx context.Context, search model.AIKnowledgeChunking, isDelete bool) error {
+	if isDelete {
+		return k.db.WithContext(ctx).Where(&search).Unscoped().Delete(&search).Error
+	}
+	return k.db.WithContext(ctx).Where(&search).Delete(&search).Error
+}
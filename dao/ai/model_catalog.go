@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+)
+
+type ModelCatalogI interface {
+	Save(ctx context.Context, in *model.AIModelCatalog) error
+	Find(ctx context.Context, search model.AIModelCatalog) (model.AIModelCatalog, error)
+	FindList(ctx context.Context, search model.AIModelCatalog) ([]model.AIModelCatalog, error)
+	Delete(ctx context.Context, search model.AIModelCatalog, isDelete bool) error
+}
+
+type modelCatalog struct {
+	db *gorm.DB
+}
+
+func NewModelCatalogI(db *gorm.DB) ModelCatalogI {
+	return &modelCatalog{db: db}
+}
+
+func (m *modelCatalog) Save(ctx context.Context, in *model.AIModelCatalog) error {
+	return m.db.WithContext(ctx).Create(in).Error
+}
+
+func (m *modelCatalog) Find(ctx context.Context, search model.AIModelCatalog) (model.AIModelCatalog, error) {
+	var out model.AIModelCatalog
+	return out, m.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (m *modelCatalog) FindList(ctx context.Context, search model.AIModelCatalog) ([]model.AIModelCatalog, error) {
+	var out []model.AIModelCatalog
+	return out, m.db.WithContext(ctx).Where(&search).Order("namespace, deployment, modelName").Find(&out).Error
+}
+
+func (m *modelCatalog) Delete(ctx context.Context, search model.AIModelCatalog, isDelete bool) error {
+	if isDelete {
+		return m.db.WithContext(ctx).Where(&search).Unscoped().Delete(&search).Error
+	}
+	return m.db.WithContext(ctx).Where(&search).Delete(&search).Error
+}
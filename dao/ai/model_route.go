@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+)
+
+type ModelRouteI interface {
+	Save(ctx context.Context, in *model.AIModelRoute) error
+	Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	Find(ctx context.Context, search model.AIModelRoute) (model.AIModelRoute, error)
+	FindList(ctx context.Context, search model.AIModelRoute) ([]model.AIModelRoute, error)
+	Delete(ctx context.Context, search model.AIModelRoute, isDelete bool) error
+}
+
+type modelRoute struct {
+	db *gorm.DB
+}
+
+func NewModelRouteI(db *gorm.DB) ModelRouteI {
+	return &modelRoute{db: db}
+}
+
+func (m *modelRoute) Save(ctx context.Context, in *model.AIModelRoute) error {
+	return m.db.WithContext(ctx).Create(in).Error
+}
+
+func (m *modelRoute) Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(m.db)
+	return query.WithContext(ctx).Model(&model.AIModelRoute{}).Updates(in).Error
+}
+
+func (m *modelRoute) Find(ctx context.Context, search model.AIModelRoute) (model.AIModelRoute, error) {
+	var out model.AIModelRoute
+	return out, m.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (m *modelRoute) FindList(ctx context.Context, search model.AIModelRoute) ([]model.AIModelRoute, error) {
+	var out []model.AIModelRoute
+	return out, m.db.WithContext(ctx).Where(&search).Order("name").Find(&out).Error
+}
+
+func (m *modelRoute) Delete(ctx context.Context, search model.AIModelRoute, isDelete bool) error {
+	if isDelete {
+		return m.db.WithContext(ctx).Where(&search).Unscoped().Delete(&search).Error
+	}
+	return m.db.WithContext(ctx).Where(&search).Delete(&search).Error
+}
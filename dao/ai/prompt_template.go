@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+type PromptTemplateI interface {
+	// Save 保存模板及其第一个版本
+	Save(ctx context.Context, in *model.AIPromptTemplate, version *model.AIPromptTemplateVersion) error
+	Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	Find(ctx context.Context, search model.AIPromptTemplate) (model.AIPromptTemplate, error)
+	// Delete 删除模板及其全部版本
+	Delete(ctx context.Context, search model.AIPromptTemplate) error
+
+	PageList(ctx context.Context, params runtime.Pager) ([]model.AIPromptTemplate, int64, error)
+
+	// SaveVersion 保存新版本并更新模板的最新版本号
+	SaveVersion(ctx context.Context, in *model.AIPromptTemplateVersion) error
+	// FindVersion 获取模板的指定版本，version 为 0 时返回最新版本
+	FindVersion(ctx context.Context, templateID string, version int) (model.AIPromptTemplateVersion, error)
+	ListVersions(ctx context.Context, templateID string) ([]model.AIPromptTemplateVersion, error)
+}
+
+type promptTemplate struct {
+	db *gorm.DB
+}
+
+func NewPromptTemplateI(db *gorm.DB) PromptTemplateI {
+	return &promptTemplate{db: db}
+}
+
+func (p *promptTemplate) Save(ctx context.Context, in *model.AIPromptTemplate, version *model.AIPromptTemplateVersion) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(in).Error; err != nil {
+			return err
+		}
+		return tx.Create(version).Error
+	})
+}
+
+func (p *promptTemplate) Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(p.db)
+	return query.WithContext(ctx).Model(&model.AIPromptTemplate{}).Updates(in).Error
+}
+
+func (p *promptTemplate) Find(ctx context.Context, search model.AIPromptTemplate) (model.AIPromptTemplate, error) {
+	var out model.AIPromptTemplate
+	return out, p.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (p *promptTemplate) Delete(ctx context.Context, search model.AIPromptTemplate) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var list []model.AIPromptTemplate
+		if err := tx.Where(&search).Find(&list).Error; err != nil {
+			return err
+		}
+		for _, item := range list {
+			if err := tx.Where("templateID = ?", item.InstanceID).Unscoped().Delete(&model.AIPromptTemplateVersion{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *promptTemplate) PageList(ctx context.Context, params runtime.Pager) ([]model.AIPromptTemplate, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := p.db.WithContext(ctx).Model(&model.AIPromptTemplate{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIPromptTemplate
+	if err := query.Order("updated_at desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (p *promptTemplate) SaveVersion(ctx context.Context, in *model.AIPromptTemplateVersion) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(in).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.AIPromptTemplate{}).Where("instanceID = ?", in.TemplateID).Update("latestVersion", in.Version).Error
+	})
+}
+
+func (p *promptTemplate) FindVersion(ctx context.Context, templateID string, version int) (model.AIPromptTemplateVersion, error) {
+	var out model.AIPromptTemplateVersion
+	query := p.db.WithContext(ctx).Where("templateID = ?", templateID)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	return out, query.Order("version desc").First(&out).Error
+}
+
+func (p *promptTemplate) ListVersions(ctx context.Context, templateID string) ([]model.AIPromptTemplateVersion, error) {
+	var out []model.AIPromptTemplateVersion
+	return out, p.db.WithContext(ctx).Where("templateID = ?", templateID).Order("version desc").Find(&out).Error
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+type PullJobI interface {
+	Save(ctx context.Context, in *model.AIPullJob) error
+	Updates(ctx context.Context, opt common.UpdateOption, in *model.AIPullJob) error
+	Find(ctx context.Context, search model.AIPullJob) (model.AIPullJob, error)
+	FindList(ctx context.Context, search model.AIPullJob) ([]model.AIPullJob, error)
+
+	PageList(ctx context.Context, params runtime.Pager) ([]model.AIPullJob, int64, error)
+}
+
+type pullJob struct {
+	db *gorm.DB
+}
+
+func NewPullJobI(db *gorm.DB) PullJobI {
+	return &pullJob{db: db}
+}
+
+func (p *pullJob) Save(ctx context.Context, in *model.AIPullJob) error {
+	return p.db.WithContext(ctx).Create(in).Error
+}
+
+func (p *pullJob) Updates(ctx context.Context, opt common.UpdateOption, in *model.AIPullJob) error {
+	query := opt(p.db)
+	return query.WithContext(ctx).Updates(in).Error
+}
+
+func (p *pullJob) Find(ctx context.Context, search model.AIPullJob) (model.AIPullJob, error) {
+	var out model.AIPullJob
+	return out, p.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (p *pullJob) FindList(ctx context.Context, search model.AIPullJob) ([]model.AIPullJob, error) {
+	var out []model.AIPullJob
+	return out, p.db.WithContext(ctx).Where(&search).Find(&out).Error
+}
+
+func (p *pullJob) PageList(ctx context.Context, params runtime.Pager) ([]model.AIPullJob, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := p.db.WithContext(ctx).Model(&model.AIPullJob{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIPullJob
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/common"
+	"github.com/noovertime7/kubemanage/dao/model"
+)
+
+type QuotaI interface {
+	Save(ctx context.Context, in *model.AIQuota) error
+	Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error
+	Find(ctx context.Context, search model.AIQuota) (model.AIQuota, error)
+	FindList(ctx context.Context, search model.AIQuota) ([]model.AIQuota, error)
+	Delete(ctx context.Context, search model.AIQuota, isDelete bool) error
+}
+
+type quota struct {
+	db *gorm.DB
+}
+
+func NewQuotaI(db *gorm.DB) QuotaI {
+	return &quota{db: db}
+}
+
+func (q *quota) Save(ctx context.Context, in *model.AIQuota) error {
+	return q.db.WithContext(ctx).Create(in).Error
+}
+
+// Updates 使用 map 更新，允许将限制项改回 0
+func (q *quota) Updates(ctx context.Context, opt common.UpdateOption, in map[string]interface{}) error {
+	query := opt(q.db)
+	return query.WithContext(ctx).Model(&model.AIQuota{}).Updates(in).Error
+}
+
+func (q *quota) Find(ctx context.Context, search model.AIQuota) (model.AIQuota, error) {
+	var out model.AIQuota
+	return out, q.db.WithContext(ctx).Where(&search).First(&out).Error
+}
+
+func (q *quota) FindList(ctx context.Context, search model.AIQuota) ([]model.AIQuota, error) {
+	var out []model.AIQuota
+	return out, q.db.WithContext(ctx).Where(&search).Order("subjectType, subjectID").Find(&out).Error
+}
+
+func (q *quota) Delete(ctx context.Context, search model.AIQuota, isDelete bool) error {
+	if isDelete {
+		return q.db.WithContext(ctx).Where(&search).Unscoped().Delete(&search).Error
+	}
+	return q.db.WithContext(ctx).Where(&search).Delete(&search).Error
+}
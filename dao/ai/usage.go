@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// usageGroupColumns 报表分组维度对应的列
+var usageGroupColumns = map[string][]string{
+	dto.AIUsageGroupUser:       {"userID", "userName"},
+	dto.AIUsageGroupDepartment: {"departmentID"},
+	dto.AIUsageGroupModel:      {"model"},
+	dto.AIUsageGroupDay:        {"DATE_FORMAT(created_at, '%Y-%m-%d') AS day"},
+}
+
+type UsageI interface {
+	Save(ctx context.Context, in *model.AIUsage) error
+	PageList(ctx context.Context, params runtime.Pager) ([]model.AIUsage, int64, error)
+	// Report 按维度聚合用量
+	Report(ctx context.Context, groupBy []string, filter *dto.AIUsageFilter) ([]model.AIUsageStat, error)
+	// SumTokens 统计用户自 since 起消耗的 token 数
+	SumTokens(ctx context.Context, userID int, since time.Time) (int64, error)
+	// SumCallerTokens 统计指定入口下调用方自 since 起消耗的 token 数，用于没有用户ID的调用方（如 /v1 接口的 API Key）
+	SumCallerTokens(ctx context.Context, userName, source string, since time.Time) (int64, error)
+}
+
+type usage struct {
+	db *gorm.DB
+}
+
+func NewUsageI(db *gorm.DB) UsageI {
+	return &usage{db: db}
+}
+
+func (u *usage) Save(ctx context.Context, in *model.AIUsage) error {
+	return u.db.WithContext(ctx).Create(in).Error
+}
+
+func (u *usage) PageList(ctx context.Context, params runtime.Pager) ([]model.AIUsage, int64, error) {
+	var total int64 = 0
+	limit := params.GetPageSize()
+	offset := limit * (params.GetPage() - 1)
+	query := u.db.WithContext(ctx).Model(&model.AIUsage{})
+	if params.IsFitter() {
+		params.Do(query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []model.AIUsage
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (u *usage) Report(ctx context.Context, groupBy []string, filter *dto.AIUsageFilter) ([]model.AIUsageStat, error) {
+	selects := []string{
+		"COUNT(*) AS requests",
+		"SUM(promptTokens) AS promptTokens",
+		"SUM(completionTokens) AS completionTokens",
+		"SUM(totalTokens) AS totalTokens",
+		"SUM(totalDuration) AS totalDuration",
+	}
+	var groups []string
+	for _, dim := range groupBy {
+		columns, ok := usageGroupColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("不支持的分组维度: %s", dim)
+		}
+		selects = append(selects, columns...)
+		if dim == dto.AIUsageGroupDay {
+			groups = append(groups, "day")
+			continue
+		}
+		groups = append(groups, columns...)
+	}
+
+	query := u.db.WithContext(ctx).Model(&model.AIUsage{}).Select(selects)
+	if filter != nil && filter.IsFitter() {
+		filter.Do(query)
+	}
+	for _, group := range groups {
+		query = query.Group(group)
+	}
+	if len(groups) > 0 && groups[0] == "day" {
+		query = query.Order("day")
+	}
+	var out []model.AIUsageStat
+	return out, query.Order("totalTokens desc").Scan(&out).Error
+}
+
+func (u *usage) SumTokens(ctx context.Context, userID int, since time.Time) (int64, error) {
+	var total int64
+	err := u.db.WithContext(ctx).Model(&model.AIUsage{}).
+		Select("COALESCE(SUM(totalTokens), 0)").
+		Where("userID = ? AND created_at >= ?", userID, since).
+		Scan(&total).Error
+	return total, err
+}
+
+func (u *usage) SumCallerTokens(ctx context.Context, userName, source string, since time.Time) (int64, error) {
+	var total int64
+	err := u.db.WithContext(ctx).Model(&model.AIUsage{}).
+		Select("COALESCE(SUM(totalTokens), 0)").
+		Where("userName = ? AND source = ? AND created_at >= ?", userName, source, since).
+		Scan(&total).Error
+	return total, err
+}
@@ -5,6 +5,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/noovertime7/kubemanage/dao/ai"
 	"github.com/noovertime7/kubemanage/dao/api"
 	"github.com/noovertime7/kubemanage/dao/authority"
 	"github.com/noovertime7/kubemanage/dao/cmdb"
@@ -27,6 +28,7 @@ type ShareDaoFactory interface {
 	BaseMenu() menu.BaseMenu
 	Opera() operation.Operation
 	CMDB() cmdb.CMDBFactory
+	AI() ai.AIFactory
 	Transactioner
 }
 
@@ -80,6 +82,10 @@ func (s *shareDaoFactory) CMDB() cmdb.CMDBFactory {
 	return cmdb.NewCMDBFactory(s.db)
 }
 
+func (s *shareDaoFactory) AI() ai.AIFactory {
+	return ai.NewAIFactory(s.db)
+}
+
 type Transactioner interface {
 	Begin(opts ...*sql.TxOptions)
 	Commit()
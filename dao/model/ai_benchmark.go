@@ -0,0 +1,108 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIBenchmark{})
+	RegisterInitializer(AIInitOrder, &AIBenchmarkResult{})
+}
+
+// 基准测试任务状态
+const (
+	BenchmarkPending  = "pending"
+	BenchmarkRunning  = "running"
+	BenchmarkSuccess  = "success"
+	BenchmarkFailed   = "failed"
+	BenchmarkCanceled = "canceled"
+)
+
+// AIBenchmark 模型基准测试任务，将同一组提示词发送给多个模型/部署并记录结果
+type AIBenchmark struct {
+	Id           uint       `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID   string     `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	Name         string     `json:"name" gorm:"size:128;column:name;comment:任务名称"`
+	Targets      string     `json:"targets" gorm:"type:text;column:targets;comment:测试目标，JSON数组"`
+	Prompts      string     `json:"prompts" gorm:"type:longtext;column:prompts;comment:提示词集合，JSON数组"`
+	SystemPrompt string     `json:"systemPrompt" gorm:"type:text;column:systemPrompt;comment:系统提示词"`
+	Concurrency  int        `json:"concurrency" gorm:"column:concurrency;comment:并发数"`
+	Status       string     `json:"status" gorm:"index;column:status;comment:任务状态"`
+	Message      string     `json:"message" gorm:"type:text;column:message;comment:错误信息"`
+	Total        int        `json:"total" gorm:"column:total;comment:请求总数"`
+	Completed    int        `json:"completed" gorm:"column:completed;comment:已完成请求数"`
+	Failed       int        `json:"failed" gorm:"column:failed;comment:失败请求数"`
+	UserID       int        `json:"userID" gorm:"index;column:userID;comment:创建人ID，只有创建人可以查看及操作任务"`
+	Creator      string     `json:"creator" gorm:"column:creator;comment:创建人"`
+	FinishedAt   *time.Time `json:"finishedAt" gorm:"column:finishedAt;comment:结束时间"`
+	CommonModel
+}
+
+func (a *AIBenchmark) TableName() string {
+	return "ai_benchmark"
+}
+
+// IsFinished 任务是否已结束
+func (a *AIBenchmark) IsFinished() bool {
+	return a.Status == BenchmarkSuccess || a.Status == BenchmarkFailed || a.Status == BenchmarkCanceled
+}
+
+func (a *AIBenchmark) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIBenchmark) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIBenchmark) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIBenchmark) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
+
+// AIBenchmarkResult 基准测试中一个目标对一条提示词的结果，时长单位为毫秒
+type AIBenchmarkResult struct {
+	Id               uint    `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	BenchmarkID      string  `json:"benchmarkID" gorm:"index;size:64;column:benchmarkID;comment:基准测试任务ID"`
+	TargetIndex      int     `json:"targetIndex" gorm:"column:targetIndex;comment:目标序号"`
+	PromptIndex      int     `json:"promptIndex" gorm:"column:promptIndex;comment:提示词序号"`
+	Namespace        string  `json:"namespace" gorm:"column:namespace;comment:命名空间"`
+	Deployment       string  `json:"deployment" gorm:"column:deployment;comment:部署名称"`
+	PodName          string  `json:"podName" gorm:"column:podName;comment:Pod名称"`
+	Model            string  `json:"model" gorm:"column:model;comment:模型名称"`
+	Output           string  `json:"output" gorm:"type:longtext;column:output;comment:模型输出"`
+	Error            string  `json:"error" gorm:"type:text;column:error;comment:错误信息"`
+	Latency          int64   `json:"latency" gorm:"column:latency;comment:端到端耗时"`
+	LoadDuration     int64   `json:"loadDuration" gorm:"column:loadDuration;comment:模型加载耗时"`
+	EvalDuration     int64   `json:"evalDuration" gorm:"column:evalDuration;comment:生成耗时"`
+	PromptTokens     int64   `json:"promptTokens" gorm:"column:promptTokens;comment:输入token数"`
+	CompletionTokens int64   `json:"completionTokens" gorm:"column:completionTokens;comment:输出token数"`
+	TokensPerSecond  float64 `json:"tokensPerSecond" gorm:"column:tokensPerSecond;comment:生成速度"`
+	CommonModel
+}
+
+func (a *AIBenchmarkResult) TableName() string {
+	return "ai_benchmark_result"
+}
+
+func (a *AIBenchmarkResult) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIBenchmarkResult) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIBenchmarkResult) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIBenchmarkResult) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
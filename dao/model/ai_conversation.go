@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIConversation{})
+	RegisterInitializer(AIInitOrder, &AIConversationMessage{})
+}
+
+// AIConversation 多轮对话，绑定模型、可选的知识库及系统提示词
+type AIConversation struct {
+	Id           uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID   string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	Title        string `json:"title" gorm:"size:256;column:title;comment:标题"`
+	UserID       int    `json:"userID" gorm:"index;column:userID;comment:所属用户ID"`
+	UserName     string `json:"userName" gorm:"column:userName;comment:所属用户名"`
+	Namespace    string `json:"namespace" gorm:"column:namespace;comment:Ollama命名空间"`
+	Deployment   string `json:"deployment" gorm:"column:deployment;comment:Ollama部署名称"`
+	PodName      string `json:"podName" gorm:"column:podName;comment:Ollama Pod名称"`
+	Model        string `json:"model" gorm:"column:model;comment:模型名称"`
+	SystemPrompt string `json:"systemPrompt" gorm:"type:text;column:systemPrompt;comment:系统提示词"`
+	// 提示词模板，TemplateVersion 为 0 时始终使用最新版本
+	TemplateID      string `json:"templateID" gorm:"column:templateID;comment:提示词模板ID"`
+	TemplateVersion int    `json:"templateVersion" gorm:"column:templateVersion;comment:提示词模板版本"`
+	// 知识库，KnowledgePodName 为空时不检索知识库
+	KnowledgePodName   string `json:"knowledgePodName" gorm:"column:knowledgePodName;comment:知识库Pod名称"`
+	KnowledgeNamespace string `json:"knowledgeNamespace" gorm:"column:knowledgeNamespace;comment:知识库命名空间"`
+	KnowledgeType      string `json:"knowledgeType" gorm:"column:knowledgeType;comment:知识库类型"`
+	CollectionName     string `json:"collectionName" gorm:"column:collectionName;comment:集合名称"`
+	TopK               int    `json:"topK" gorm:"column:topK;comment:检索文档数量"`
+	CommonModel
+}
+
+func (a *AIConversation) TableName() string {
+	return "ai_conversation"
+}
+
+func (a *AIConversation) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIConversation) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIConversation) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIConversation) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
+
+// AIConversationMessage 对话中的一条消息
+type AIConversationMessage struct {
+	Id               uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	ConversationID   string `json:"conversationID" gorm:"index;not null;column:conversationID;comment:对话ID"`
+	Role             string `json:"role" gorm:"size:32;column:role;comment:角色 user/assistant"`
+	Content          string `json:"content" gorm:"type:longtext;column:content;comment:消息内容"`
+	PromptTokens     int64  `json:"promptTokens" gorm:"column:promptTokens;comment:输入token数"`
+	CompletionTokens int64  `json:"completionTokens" gorm:"column:completionTokens;comment:输出token数"`
+	CommonModel
+}
+
+func (a *AIConversationMessage) TableName() string {
+	return "ai_conversation_message"
+}
+
+func (a *AIConversationMessage) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIConversationMessage) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIConversationMessage) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIConversationMessage) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
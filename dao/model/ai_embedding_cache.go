@@ -0,0 +1,41 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIEmbeddingCache{})
+}
+
+// AIEmbeddingCache 向量缓存持久化层，以 (模型, 文本sha256) 唯一
+type AIEmbeddingCache struct {
+	Id     uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	Model  string `json:"model" gorm:"size:128;not null;uniqueIndex:idx_model_hash;column:model;comment:向量模型"`
+	Hash   string `json:"hash" gorm:"size:64;not null;uniqueIndex:idx_model_hash;column:hash;comment:文本sha256"`
+	Dim    int    `json:"dim" gorm:"column:dim;comment:向量维度"`
+	Vector []byte `json:"-" gorm:"type:mediumblob;column:vector;comment:向量，小端序float64"`
+	CommonModel
+}
+
+func (a *AIEmbeddingCache) TableName() string {
+	return "ai_embedding_cache"
+}
+
+func (a *AIEmbeddingCache) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIEmbeddingCache) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIEmbeddingCache) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIEmbeddingCache) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIGuardrailRule{})
+	RegisterInitializer(AIInitOrder, &AIGuardrailViolation{})
+}
+
+// AIGuardrailRule 护栏规则，对聊天、知识库问答的输入与模型回答生效，按 sort 从小到大依次执行
+type AIGuardrailRule struct {
+	Id          uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID  string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	Name        string `json:"name" gorm:"size:128;not null;column:name;comment:规则名称"`
+	Type        string `json:"type" gorm:"size:32;column:type;comment:规则类型 pii/keyword/regex/max_length"`
+	Pattern     string `json:"pattern" gorm:"type:text;column:pattern;comment:敏感信息类型、关键词或正则表达式"`
+	MaxLength   int    `json:"maxLength" gorm:"column:maxLength;comment:最大字符数"`
+	Stage       string `json:"stage" gorm:"size:32;column:stage;comment:作用阶段 input/output/both"`
+	Action      string `json:"action" gorm:"size:32;column:action;comment:处理方式 block/mask/log"`
+	Enabled     bool   `json:"enabled" gorm:"column:enabled;comment:是否启用"`
+	Sort        int    `json:"sort" gorm:"column:sort;comment:执行顺序"`
+	Description string `json:"description" gorm:"column:description;comment:描述"`
+	Creator     string `json:"creator" gorm:"column:creator;comment:创建人"`
+	CommonModel
+}
+
+func (a *AIGuardrailRule) TableName() string {
+	return "ai_guardrail_rule"
+}
+
+func (a *AIGuardrailRule) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIGuardrailRule) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIGuardrailRule) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIGuardrailRule) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
+
+// AIGuardrailViolation 护栏规则命中审计记录，Snippet 为脱敏后的命中内容
+type AIGuardrailViolation struct {
+	Id       uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	RuleID   string `json:"ruleID" gorm:"index;column:ruleID;comment:规则ID"`
+	RuleName string `json:"ruleName" gorm:"column:ruleName;comment:规则名称"`
+	RuleType string `json:"ruleType" gorm:"size:32;column:ruleType;comment:规则类型"`
+	Stage    string `json:"stage" gorm:"size:32;column:stage;comment:命中阶段 input/output"`
+	Action   string `json:"action" gorm:"size:32;column:action;comment:处理方式"`
+	Snippet  string `json:"snippet" gorm:"type:text;column:snippet;comment:脱敏后的命中内容"`
+	UserID   int    `json:"userID" gorm:"index;column:userID;comment:用户ID"`
+	UserName string `json:"userName" gorm:"column:userName;comment:用户名"`
+	Source   string `json:"source" gorm:"size:32;column:source;comment:调用入口"`
+	Model    string `json:"model" gorm:"column:model;comment:模型名称"`
+	CommonModel
+}
+
+func (a *AIGuardrailViolation) TableName() string {
+	return "ai_guardrail_violation"
+}
+
+func (a *AIGuardrailViolation) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIGuardrailViolation) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIGuardrailViolation) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIGuardrailViolation) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIIngestJob{})
+	RegisterInitializer(AIInitOrder, &AIIngestChunk{})
+}
+
+// 文档入库任务状态
+const (
+	IngestJobPending  = "pending"
+	IngestJobRunning  = "running"
+	IngestJobSuccess  = "success"
+	IngestJobFailed   = "failed"
+	IngestJobCanceled = "canceled"
+)
+
+// 分块状态
+const (
+	IngestChunkPending = "pending"
+	IngestChunkSuccess = "success"
+	IngestChunkFailed  = "failed"
+)
+
+// AIIngestJob 文档入库任务，上传时解析并切分文档，分块保存在 AIIngestChunk 中，后台分批生成向量写入知识库
+type AIIngestJob struct {
+	Id            uint       `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID    string     `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	PodName       string     `json:"podName" gorm:"column:podName;comment:知识库Pod名称"`
+	Namespace     string     `json:"namespace" gorm:"index;column:namespace;comment:命名空间"`
+	KnowledgeType string     `json:"knowledgeType" gorm:"size:32;column:knowledgeType;comment:知识库类型"`
+	Collection    string     `json:"collection" gorm:"size:128;index;column:collection;comment:集合名称"`
+	FileName      string     `json:"fileName" gorm:"column:fileName;comment:文件名"`
+	Sections      int        `json:"sections" gorm:"column:sections;comment:解析出的段落数"`
+	Chunking      string     `json:"chunking" gorm:"type:text;column:chunking;comment:分块参数，JSON"`
+	Status        string     `json:"status" gorm:"index;column:status;comment:任务状态"`
+	Message       string     `json:"message" gorm:"type:text;column:message;comment:最近一条错误信息"`
+	Total         int        `json:"total" gorm:"column:total;comment:分块总数"`
+	Completed     int        `json:"completed" gorm:"column:completed;comment:已写入分块数"`
+	Failed        int        `json:"failed" gorm:"column:failed;comment:重试后仍失败的分块数"`
+	Percent       float64    `json:"percent" gorm:"column:percent;comment:进度百分比"`
+	UserID        int        `json:"userID" gorm:"index;column:userID;comment:创建人ID，只有创建人可以查看及操作任务"`
+	Creator       string     `json:"creator" gorm:"column:creator;comment:创建人"`
+	Runner        string     `json:"runner" gorm:"size:64;column:runner;comment:执行任务的服务实例"`
+	LeaseUntil    *time.Time `json:"leaseUntil" gorm:"column:leaseUntil;comment:执行租约到期时间，到期未续约时其他实例可接管"`
+	FinishedAt    *time.Time `json:"finishedAt" gorm:"column:finishedAt;comment:结束时间"`
+	CommonModel
+}
+
+func (a *AIIngestJob) TableName() string {
+	return "ai_ingest_job"
+}
+
+// IsFinished 任务是否已结束
+func (a *AIIngestJob) IsFinished() bool {
+	return a.Status == IngestJobSuccess || a.Status == IngestJobFailed || a.Status == IngestJobCanceled
+}
+
+func (a *AIIngestJob) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIIngestJob) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIIngestJob) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIIngestJob) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
+
+// AIIngestChunk 入库任务的一个分块及其写入状态
+type AIIngestChunk struct {
+	Id         uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	JobID      string `json:"jobID" gorm:"size:64;not null;uniqueIndex:idx_job_chunk;column:jobID;comment:入库任务ID"`
+	ChunkIndex int    `json:"chunkIndex" gorm:"not null;uniqueIndex:idx_job_chunk;column:chunkIndex;comment:分块序号"`
+	Text       string `json:"text" gorm:"type:mediumtext;column:text;comment:分块文本"`
+	Metadata   string `json:"metadata" gorm:"type:text;column:metadata;comment:分块元数据，JSON"`
+	Status     string `json:"status" gorm:"size:16;index;column:status;comment:分块状态"`
+	Attempts   int    `json:"attempts" gorm:"column:attempts;comment:已尝试次数"`
+	Error      string `json:"error" gorm:"type:text;column:error;comment:最近一次错误"`
+	CommonModel
+}
+
+func (a *AIIngestChunk) TableName() string {
+	return "ai_ingest_chunk"
+}
+
+func (a *AIIngestChunk) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIIngestChunk) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIIngestChunk) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIIngestChunk) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
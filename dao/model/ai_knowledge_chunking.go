@@ -0,0 +1,46 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIKnowledgeChunking{})
+}
+
+// AIKnowledgeChunking 知识库集合的分块配置，向集合上传文档时未指定分块参数则使用该配置
+type AIKnowledgeChunking struct {
+	Id           uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID   string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	NameSpace    string `json:"namespace" gorm:"size:128;not null;uniqueIndex:idx_kb_collection;column:namespace;comment:命名空间"`
+	Knowledge    string `json:"knowledge" gorm:"size:128;not null;uniqueIndex:idx_kb_collection;column:knowledge;comment:知识库部署名称"`
+	Collection   string `json:"collection" gorm:"size:128;not null;uniqueIndex:idx_kb_collection;column:collection;comment:集合名称"`
+	Strategy     string `json:"strategy" gorm:"size:32;column:strategy;comment:分块策略 fixed/recursive/markdown/token"`
+	ChunkSize    int    `json:"chunkSize" gorm:"column:chunkSize;comment:分块大小，token策略下为token数"`
+	ChunkOverlap int    `json:"chunkOverlap" gorm:"column:chunkOverlap;comment:相邻分块重叠长度"`
+	MaxTokens    int    `json:"maxTokens" gorm:"column:maxTokens;comment:单个分块token上限，0使用向量模型的上限"`
+	Creator      string `json:"creator" gorm:"column:creator;comment:创建人"`
+	CommonModel
+}
+
+func (a *AIKnowledgeChunking) TableName() string {
+	return "ai_knowledge_chunking"
+}
+
+func (a *AIKnowledgeChunking) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIKnowledgeChunking) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIKnowledgeChunking) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIKnowledgeChunking) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIModelCatalog{})
+}
+
+// AIModelCatalog Ollama 部署期望拥有的模型，由同步任务拉取到每个就绪副本
+type AIModelCatalog struct {
+	Id         uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	Namespace  string `json:"namespace" gorm:"uniqueIndex:idx_catalog_model;size:128;column:namespace;comment:命名空间"`
+	Deployment string `json:"deployment" gorm:"uniqueIndex:idx_catalog_model;size:128;column:deployment;comment:Ollama部署名称"`
+	ModelName  string `json:"modelName" gorm:"uniqueIndex:idx_catalog_model;size:128;column:modelName;comment:模型名称"`
+	Creator    string `json:"creator" gorm:"column:creator;comment:创建人"`
+	CommonModel
+}
+
+func (a *AIModelCatalog) TableName() string {
+	return "ai_model_catalog"
+}
+
+func (a *AIModelCatalog) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIModelCatalog) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIModelCatalog) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIModelCatalog) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
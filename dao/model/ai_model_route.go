@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIModelRoute{})
+}
+
+// AIModelRoute 虚拟模型路由，将一个模型名称映射到多个 Ollama 部署+模型目标
+type AIModelRoute struct {
+	Id          uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID  string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	Name        string `json:"name" gorm:"unique;size:128;not null;column:name;comment:虚拟模型名称"`
+	Strategy    string `json:"strategy" gorm:"size:32;column:strategy;comment:路由策略 ordered/weighted"`
+	Targets     string `json:"targets" gorm:"type:text;column:targets;comment:目标列表JSON"`
+	Description string `json:"description" gorm:"column:description;comment:描述"`
+	Creator     string `json:"creator" gorm:"column:creator;comment:创建人"`
+	CommonModel
+}
+
+func (a *AIModelRoute) TableName() string {
+	return "ai_model_route"
+}
+
+func (a *AIModelRoute) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIModelRoute) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIModelRoute) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIModelRoute) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIPromptTemplate{})
+	RegisterInitializer(AIInitOrder, &AIPromptTemplateVersion{})
+}
+
+// 提示词模板可见范围
+const (
+	AIPromptVisibilityPrivate   = "private"   // 仅创建人可见
+	AIPromptVisibilityAuthority = "authority" // 指定角色可见
+	AIPromptVisibilityPublic    = "public"    // 所有用户可见
+)
+
+// AIPromptTemplate 提示词模板，内容按版本保存在 AIPromptTemplateVersion 中
+type AIPromptTemplate struct {
+	Id            uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID    string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	Name          string `json:"name" gorm:"uniqueIndex;size:128;column:name;comment:模板名称"`
+	Description   string `json:"description" gorm:"size:512;column:description;comment:描述"`
+	Visibility    string `json:"visibility" gorm:"size:32;column:visibility;comment:可见范围 private/authority/public"`
+	AuthorityIDs  string `json:"authorityIDs" gorm:"column:authorityIDs;comment:可见角色ID，逗号分隔"`
+	OwnerID       int    `json:"ownerID" gorm:"index;column:ownerID;comment:创建人ID"`
+	OwnerName     string `json:"ownerName" gorm:"column:ownerName;comment:创建人"`
+	LatestVersion int    `json:"latestVersion" gorm:"column:latestVersion;comment:最新版本"`
+	CommonModel
+}
+
+func (a *AIPromptTemplate) TableName() string {
+	return "ai_prompt_template"
+}
+
+func (a *AIPromptTemplate) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIPromptTemplate) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIPromptTemplate) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIPromptTemplate) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
+
+// AIPromptTemplateVersion 提示词模板的一个版本，创建后不再修改
+type AIPromptTemplateVersion struct {
+	Id         uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	TemplateID string `json:"templateID" gorm:"uniqueIndex:idx_prompt_version;size:64;column:templateID;comment:模板ID"`
+	Version    int    `json:"version" gorm:"uniqueIndex:idx_prompt_version;column:version;comment:版本号"`
+	Content    string `json:"content" gorm:"type:text;column:content;comment:模板内容"`
+	Variables  string `json:"variables" gorm:"column:variables;comment:模板变量，逗号分隔"`
+	Comment    string `json:"comment" gorm:"size:512;column:comment;comment:版本说明"`
+	Creator    string `json:"creator" gorm:"column:creator;comment:创建人"`
+	CommonModel
+}
+
+func (a *AIPromptTemplateVersion) TableName() string {
+	return "ai_prompt_template_version"
+}
+
+func (a *AIPromptTemplateVersion) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIPromptTemplateVersion) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIPromptTemplateVersion) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIPromptTemplateVersion) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIPullJob{})
+}
+
+// 模型拉取任务状态
+const (
+	PullJobPending  = "pending"
+	PullJobRunning  = "running"
+	PullJobSuccess  = "success"
+	PullJobFailed   = "failed"
+	PullJobCanceled = "canceled"
+)
+
+// AIPullJob Ollama 模型拉取任务
+type AIPullJob struct {
+	Id         uint       `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID string     `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	PodName    string     `json:"podName" gorm:"column:podName;comment:Pod名称"`
+	Namespace  string     `json:"namespace" gorm:"column:namespace;comment:命名空间"`
+	ModelName  string     `json:"modelName" gorm:"index;column:modelName;comment:模型名称"`
+	Status     string     `json:"status" gorm:"index;column:status;comment:任务状态"`
+	Message    string     `json:"message" gorm:"column:message;type:text;comment:最近一条进度信息或错误信息"`
+	Completed  int64      `json:"completed" gorm:"column:completed;comment:已下载字节数"`
+	Total      int64      `json:"total" gorm:"column:total;comment:总字节数"`
+	Percent    float64    `json:"percent" gorm:"column:percent;comment:下载进度百分比"`
+	Creator    string     `json:"creator" gorm:"column:creator;comment:创建人"`
+	FinishedAt *time.Time `json:"finishedAt" gorm:"column:finishedAt;comment:结束时间"`
+	CommonModel
+}
+
+func (a *AIPullJob) TableName() string {
+	return "ai_pull_job"
+}
+
+// IsFinished 任务是否已结束
+func (a *AIPullJob) IsFinished() bool {
+	return a.Status == PullJobSuccess || a.Status == PullJobFailed || a.Status == PullJobCanceled
+}
+
+func (a *AIPullJob) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIPullJob) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIPullJob) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIPullJob) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIQuota{})
+}
+
+// 配额绑定对象类型
+const (
+	AIQuotaSubjectAuthority = "authority"
+	AIQuotaSubjectUser      = "user"
+)
+
+// AIQuota 推理配额，可绑定到角色或单个用户，用户配额优先于角色配额，各项为 0 表示不限制
+type AIQuota struct {
+	Id                uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	InstanceID        string `json:"instanceID" gorm:"unique;not null;index;column:instanceID;comment:唯一id"`
+	SubjectType       string `json:"subjectType" gorm:"uniqueIndex:idx_quota_subject;size:32;column:subjectType;comment:绑定对象类型 authority/user"`
+	SubjectID         uint   `json:"subjectID" gorm:"uniqueIndex:idx_quota_subject;column:subjectID;comment:角色ID或用户ID"`
+	RequestsPerMinute int    `json:"requestsPerMinute" gorm:"column:requestsPerMinute;comment:每分钟请求数"`
+	ConcurrentStreams int    `json:"concurrentStreams" gorm:"column:concurrentStreams;comment:并发请求数"`
+	DailyTokens       int64  `json:"dailyTokens" gorm:"column:dailyTokens;comment:每日token额度"`
+	Creator           string `json:"creator" gorm:"column:creator;comment:创建人"`
+	CommonModel
+}
+
+func (a *AIQuota) TableName() string {
+	return "ai_quota"
+}
+
+func (a *AIQuota) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIQuota) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIQuota) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIQuota) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(AIInitOrder, &AIUsage{})
+}
+
+// AIUsage 一次推理调用的 token 用量及耗时，用于按用户、部门、模型分摊 GPU 成本
+type AIUsage struct {
+	Id                 uint   `json:"id" gorm:"column:id;primary_key;AUTO_INCREMENT;not null"`
+	UserID             int    `json:"userID" gorm:"index;column:userID;comment:用户ID"`
+	UserName           string `json:"userName" gorm:"index;size:128;column:userName;comment:用户名或API Key名称"`
+	DepartmentID       uint   `json:"departmentID" gorm:"index;column:departmentID;comment:部门ID"`
+	Source             string `json:"source" gorm:"size:32;column:source;comment:调用入口"`
+	Kind               string `json:"kind" gorm:"size:32;column:kind;comment:调用类型"`
+	Model              string `json:"model" gorm:"index;size:128;column:model;comment:模型名称"`
+	Namespace          string `json:"namespace" gorm:"size:128;column:namespace;comment:命名空间"`
+	Deployment         string `json:"deployment" gorm:"size:128;column:deployment;comment:Ollama部署名称"`
+	PodName            string `json:"podName" gorm:"size:253;column:podName;comment:Pod名称"`
+	PromptTokens       int64  `json:"promptTokens" gorm:"column:promptTokens;comment:输入token数"`
+	CompletionTokens   int64  `json:"completionTokens" gorm:"column:completionTokens;comment:输出token数"`
+	TotalTokens        int64  `json:"totalTokens" gorm:"column:totalTokens;comment:总token数"`
+	TotalDuration      int64  `json:"totalDuration" gorm:"column:totalDuration;comment:总耗时(毫秒)"`
+	LoadDuration       int64  `json:"loadDuration" gorm:"column:loadDuration;comment:模型加载耗时(毫秒)"`
+	PromptEvalDuration int64  `json:"promptEvalDuration" gorm:"column:promptEvalDuration;comment:输入处理耗时(毫秒)"`
+	EvalDuration       int64  `json:"evalDuration" gorm:"column:evalDuration;comment:生成耗时(毫秒)"`
+	CommonModel
+}
+
+// AIUsageStat 用量聚合结果，未参与分组的维度为空
+type AIUsageStat struct {
+	UserID           int    `json:"userID" gorm:"column:userID"`
+	UserName         string `json:"userName" gorm:"column:userName"`
+	DepartmentID     uint   `json:"departmentID" gorm:"column:departmentID"`
+	DeptName         string `json:"deptName" gorm:"-"`
+	Model            string `json:"model" gorm:"column:model"`
+	Day              string `json:"day" gorm:"column:day"`
+	Requests         int64  `json:"requests" gorm:"column:requests"`
+	PromptTokens     int64  `json:"promptTokens" gorm:"column:promptTokens"`
+	CompletionTokens int64  `json:"completionTokens" gorm:"column:completionTokens"`
+	TotalTokens      int64  `json:"totalTokens" gorm:"column:totalTokens"`
+	TotalDuration    int64  `json:"totalDuration" gorm:"column:totalDuration"`
+}
+
+func (a *AIUsage) TableName() string {
+	return "ai_usage"
+}
+
+func (a *AIUsage) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&a)
+}
+
+func (a *AIUsage) InitData(ctx context.Context, db *gorm.DB) error {
+	return nil
+}
+
+func (a *AIUsage) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return false, nil
+}
+
+func (a *AIUsage) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(&a)
+}
@@ -22,6 +22,7 @@ const (
 	OperatorationOrder
 	WorkFlowOrder
 	CMDBInitOrder
+	AIInitOrder
 )
 
 // SysUserEntities 用户初始化数据
@@ -317,11 +318,30 @@ var SysApis = []SysApi{
 	// Ollama LLM相关接口
 	{Path: "/api/k8s/ollama/deploy", Description: "部署Ollama到指定节点", ApiGroup: "Kubernetes", Method: "POST"},
 	{Path: "/api/k8s/ollama/list", Description: "获取Ollama部署列表", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/del", Description: "删除Ollama部署", ApiGroup: "Kubernetes", Method: "DELETE"},
+	{Path: "/api/k8s/ollama/scale", Description: "调整Ollama副本数", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/ollama/upgrade", Description: "升级Ollama镜像", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/ollama/restart", Description: "重启Ollama", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/ollama/model/pull", Description: "拉取模型到Ollama部署", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/model/pull/list", Description: "分页查询模型拉取任务", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/model/pull/detail", Description: "获取模型拉取任务详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/model/pull/cancel", Description: "取消模型拉取任务", ApiGroup: "Kubernetes", Method: "POST"},
 	{Path: "/api/k8s/ollama/model/list", Description: "获取Ollama部署的模型列表", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/ollama/model/del", Description: "删除Pod中的Ollama模型", ApiGroup: "Kubernetes", Method: "DELETE"},
 	{Path: "/api/k8s/ollama/model/detail", Description: "获取Pod中Ollama模型的详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/model/create", Description: "创建自定义Ollama模型", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/model/copy", Description: "复制Ollama模型", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/model/upload", Description: "上传GGUF模型文件", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/model/ps", Description: "获取已加载到内存的Ollama模型", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/model/load", Description: "预加载Ollama模型", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/model/unload", Description: "卸载Ollama模型", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/residency", Description: "获取Ollama模型驻留情况", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/catalog/list", Description: "查询Ollama模型目录", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/ollama/catalog/add", Description: "向Ollama模型目录添加模型", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/catalog/del", Description: "从Ollama模型目录移除模型", ApiGroup: "Kubernetes", Method: "DELETE"},
+	{Path: "/api/k8s/ollama/catalog/sync", Description: "同步Ollama模型目录", ApiGroup: "Kubernetes", Method: "POST"},
 	{Path: "/api/k8s/ollama/chat", Description: "调用对应Pod上的模型进行聊天", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/ollama/generate", Description: "调用对应Pod上的模型进行文本补全", ApiGroup: "Kubernetes", Method: "POST"},
 	{Path: "/api/k8s/ollama/embeddings", Description: "调用对应Pod上的模型生成文本向量嵌入", ApiGroup: "Kubernetes", Method: "POST"},
 	// 知识库相关接口
 	{Path: "/api/k8s/knowledge/deploy", Description: "部署知识库到指定节点", ApiGroup: "Kubernetes", Method: "POST"},
@@ -329,11 +349,51 @@ var SysApis = []SysApi{
 	{Path: "/api/k8s/knowledge/detail", Description: "获取知识库详情", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/knowledge/document/upload", Description: "上传文档到知识库", ApiGroup: "Kubernetes", Method: "POST"},
 	{Path: "/api/k8s/knowledge/query", Description: "查询知识库", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/knowledge/chunking/list", Description: "查询集合分块配置", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/knowledge/chunking/save", Description: "设置集合分块配置", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/knowledge/chunking/del", Description: "删除集合分块配置", ApiGroup: "Kubernetes", Method: "DELETE"},
+	{Path: "/api/k8s/knowledge/ingest/list", Description: "分页查询文档入库任务", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/knowledge/ingest/detail", Description: "获取文档入库任务详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/knowledge/ingest/cancel", Description: "取消文档入库任务", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/knowledge/ingest/retry", Description: "重试文档入库任务", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/knowledge/ingest/del", Description: "删除文档入库任务", ApiGroup: "Kubernetes", Method: "DELETE"},
 	// AI 相关接口
 	{Path: "/api/ai/chat_with_kb", Description: "结合知识库进行聊天", ApiGroup: "AI", Method: "POST"},
 	{Path: "/api/ai/mcp/servers", Description: "返回MCP server配置", ApiGroup: "AI", Method: "GET"},
 	{Path: "/api/ai/mcp/tools", Description: "查看可用工具列表", ApiGroup: "AI", Method: "GET"},
 	{Path: "/api/ai/mcp/servers", Description: "启用新服务器", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/usage/report", Description: "推理用量报表", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/usage/list", Description: "分页查询推理用量明细", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/quota/list", Description: "查询推理配额", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/quota/save", Description: "设置角色或用户的推理配额", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/quota/del", Description: "删除推理配额", ApiGroup: "AI", Method: "DELETE"},
+	{Path: "/api/ai/conversation/create", Description: "创建对话", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/conversation/list", Description: "分页查询对话", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/conversation/detail", Description: "获取对话详情", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/conversation/rename", Description: "重命名对话", ApiGroup: "AI", Method: "PUT"},
+	{Path: "/api/ai/conversation/del", Description: "删除对话", ApiGroup: "AI", Method: "DELETE"},
+	{Path: "/api/ai/conversation/chat", Description: "在对话中发送消息", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/prompt/create", Description: "创建提示词模板", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/prompt/list", Description: "分页查询提示词模板", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/prompt/detail", Description: "获取提示词模板详情", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/prompt/update", Description: "修改提示词模板", ApiGroup: "AI", Method: "PUT"},
+	{Path: "/api/ai/prompt/del", Description: "删除提示词模板", ApiGroup: "AI", Method: "DELETE"},
+	{Path: "/api/ai/benchmark/create", Description: "创建模型基准测试", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/benchmark/list", Description: "分页查询基准测试", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/benchmark/detail", Description: "获取基准测试结果", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/benchmark/cancel", Description: "取消基准测试", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/benchmark/del", Description: "删除基准测试", ApiGroup: "AI", Method: "DELETE"},
+	{Path: "/api/ai/benchmark/export", Description: "导出基准测试结果", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/route/list", Description: "查询虚拟模型", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/route/save", Description: "设置虚拟模型路由", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/route/del", Description: "删除虚拟模型", ApiGroup: "AI", Method: "DELETE"},
+	{Path: "/api/ai/agent/run", Description: "运行调用 MCP 工具的智能体", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/guardrail/list", Description: "查询护栏规则", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/guardrail/save", Description: "设置护栏规则", ApiGroup: "AI", Method: "POST"},
+	{Path: "/api/ai/guardrail/del", Description: "删除护栏规则", ApiGroup: "AI", Method: "DELETE"},
+	{Path: "/api/ai/guardrail/violation/list", Description: "分页查询护栏规则命中记录", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/embedding/cache/stats", Description: "查询向量缓存统计", ApiGroup: "AI", Method: "GET"},
+	{Path: "/api/ai/embedding/cache/del", Description: "清除向量缓存", ApiGroup: "AI", Method: "DELETE"},
 }
 
 // CMDBHostGroupInitData 初始化主机组
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// AIAgentToolSet 智能体可使用的某个 MCP server 的工具
+type AIAgentToolSet struct {
+	ServerName string   `json:"server_name" comment:"MCP server 名称" validate:"required"`
+	Tools      []string `json:"tools" comment:"工具名称，为空表示该 server 的全部工具"`
+}
+
+type AIAgentRunInput struct {
+	kubeDto.OllamaRoutableTarget
+	Model    string                      `json:"model" comment:"模型名称或虚拟模型名称，需支持工具调用" validate:"required"`
+	Messages []kubeDto.OllamaChatMessage `json:"messages" comment:"消息列表" validate:"required,min=1"`
+	ToolSets []AIAgentToolSet            `json:"tool_sets" comment:"可使用的 MCP 工具" validate:"required,min=1,dive"`
+	MaxSteps int                         `json:"max_steps" comment:"最大推理轮数（默认5，最大20）" validate:"min=0,max=20"`
+	Options  map[string]interface{}      `json:"options" comment:"模型参数，如 temperature、num_ctx、seed"`
+}
+
+func (params *AIAgentRunInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// AIAgentToolTrace 一次工具调用记录，耗时单位为毫秒
+type AIAgentToolTrace struct {
+	Step       int                    `json:"step"`
+	Server     string                 `json:"server"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments"`
+	Result     string                 `json:"result,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// AIAgentRunOut 智能体运行结果，Finished 为 false 表示达到最大轮数时模型仍在调用工具
+type AIAgentRunOut struct {
+	Answer   string                      `json:"answer"`
+	Finished bool                        `json:"finished"`
+	Steps    int                         `json:"steps"`
+	Trace    []AIAgentToolTrace          `json:"trace"`
+	Messages []kubeDto.OllamaChatMessage `json:"messages"`
+}
@@ -0,0 +1,121 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// AIBenchmarkTarget 基准测试目标，同一部署上的不同模型作为不同目标
+type AIBenchmarkTarget struct {
+	kubeDto.OllamaTarget
+	Model string `json:"model" form:"model" comment:"模型名称" validate:"required"`
+	Label string `json:"label" form:"label" comment:"展示名称（可选，默认使用模型名称）"`
+}
+
+type AIBenchmarkInput struct {
+	Name         string              `json:"name" form:"name" comment:"任务名称" validate:"required,max=128"`
+	Targets      []AIBenchmarkTarget `json:"targets" form:"targets" comment:"测试目标" validate:"required,min=1,max=20,dive"`
+	Prompts      []string            `json:"prompts" form:"prompts" comment:"提示词集合" validate:"required,min=1,max=200,dive,required"`
+	SystemPrompt string              `json:"system_prompt" form:"system_prompt" comment:"系统提示词（可选）"`
+	Concurrency  int                 `json:"concurrency" form:"concurrency" comment:"并发请求数（默认4）" validate:"min=0,max=16"`
+}
+
+func (params *AIBenchmarkInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIBenchmarkIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"任务ID" validate:"required"`
+}
+
+func (params *AIBenchmarkIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIBenchmarkExportInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"任务ID" validate:"required"`
+	Format     string `json:"format" form:"format" comment:"导出格式 json/csv" validate:"required,oneof=json csv"`
+}
+
+func (params *AIBenchmarkExportInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// AIBenchmarkTargetSummary 单个目标的汇总指标，耗时单位为毫秒，只统计成功的请求
+type AIBenchmarkTargetSummary struct {
+	TargetIndex int `json:"targetIndex"`
+	AIBenchmarkTarget
+	Requests           int     `json:"requests"`
+	Errors             int     `json:"errors"`
+	AvgLatency         int64   `json:"avgLatency"`
+	P50Latency         int64   `json:"p50Latency"`
+	P95Latency         int64   `json:"p95Latency"`
+	AvgTokensPerSecond float64 `json:"avgTokensPerSecond"`
+	PromptTokens       int64   `json:"promptTokens"`
+	CompletionTokens   int64   `json:"completionTokens"`
+}
+
+// AIBenchmarkPromptRow 同一提示词在各目标上的结果，Results 按目标顺序排列，未完成的为 null
+type AIBenchmarkPromptRow struct {
+	PromptIndex int                        `json:"promptIndex"`
+	Prompt      string                     `json:"prompt"`
+	Results     []*model.AIBenchmarkResult `json:"results"`
+}
+
+type AIBenchmarkDetailOut struct {
+	Benchmark model.AIBenchmark          `json:"benchmark"`
+	Targets   []AIBenchmarkTarget        `json:"targets"`
+	Summary   []AIBenchmarkTargetSummary `json:"summary"`
+	Rows      []AIBenchmarkPromptRow     `json:"rows"`
+}
+
+type PageAIBenchmarkOut struct {
+	Total    int64               `json:"total"`
+	List     []model.AIBenchmark `json:"list"`
+	Page     int                 `json:"page" form:"page"`         // 页码
+	PageSize int                 `json:"pageSize" form:"pageSize"` // 每页大小
+}
+
+type PageListAIBenchmarkInput struct {
+	Page     int    `json:"page" form:"page"`         // 页码
+	PageSize int    `json:"pageSize" form:"pageSize"` // 每页大小
+	Keyword  string `json:"keyword" form:"keyword"`   // 任务名称关键字
+	Status   string `json:"status" form:"status"`     // 任务状态
+	UserID   int    `json:"-" form:"-"`               // 当前用户，由接口填充
+}
+
+func (p *PageListAIBenchmarkInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, p)
+}
+
+func (p *PageListAIBenchmarkInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIBenchmarkInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+func (p *PageListAIBenchmarkInput) IsFitter() bool {
+	return true
+}
+
+func (p *PageListAIBenchmarkInput) Do(tx *gorm.DB) {
+	tx.Where("userID = ?", p.UserID)
+	if p.Keyword != "" {
+		tx.Where("name like ?", "%"+p.Keyword+"%")
+	}
+	if p.Status != "" {
+		tx.Where("status = ?", p.Status)
+	}
+}
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIConversationCreateInput struct {
+	Title string `json:"title" form:"title" comment:"标题（可选，默认使用第一个问题）"`
+	kubeDto.OllamaRoutableTarget
+	Model           string `json:"model" form:"model" comment:"模型名称或虚拟模型名称" validate:"required"`
+	SystemPrompt    string `json:"system_prompt" form:"system_prompt" comment:"系统提示词（可选）"`
+	TemplateID      string `json:"template_id" form:"template_id" comment:"提示词模板ID（可选，设置后替代系统提示词）"`
+	TemplateVersion int    `json:"template_version" form:"template_version" comment:"模板版本（默认始终使用最新版本）"`
+	// 知识库（可选），绑定后每轮对话都会先检索知识库
+	KnowledgePodName   string `json:"knowledge_pod_name" form:"knowledge_pod_name" comment:"知识库Pod名称"`
+	KnowledgeNamespace string `json:"knowledge_namespace" form:"knowledge_namespace" comment:"知识库命名空间" validate:"required_with=KnowledgePodName"`
+	KnowledgeType      string `json:"knowledge_type" form:"knowledge_type" comment:"知识库类型: chromadb, milvus, weaviate, qdrant, pgvector" validate:"required_with=KnowledgePodName"`
+	CollectionName     string `json:"collection_name" form:"collection_name" comment:"集合名称" validate:"required_with=KnowledgePodName"`
+	TopK               int    `json:"top_k" form:"top_k" comment:"从知识库返回的相关文档数量（默认5）"`
+}
+
+func (params *AIConversationCreateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIConversationIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"对话ID" validate:"required"`
+}
+
+func (params *AIConversationIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIConversationRenameInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"对话ID" validate:"required"`
+	Title      string `json:"title" form:"title" comment:"标题" validate:"required,max=256"`
+}
+
+func (params *AIConversationRenameInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIConversationChatInput struct {
+	InstanceID string            `json:"instanceID" form:"instanceID" comment:"对话ID" validate:"required"`
+	Content    string            `json:"content" form:"content" comment:"用户消息" validate:"required"`
+	Stream     bool              `json:"stream" form:"stream" comment:"是否流式返回"`
+	Variables  map[string]string `json:"variables" form:"variables" comment:"提示词模板自定义变量"`
+}
+
+func (params *AIConversationChatInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIConversationChatOut struct {
+	Message          model.AIConversationMessage `json:"message"`
+	RelatedDocuments []string                    `json:"related_documents,omitempty"`
+}
+
+type AIConversationDetailOut struct {
+	model.AIConversation
+	Messages []model.AIConversationMessage `json:"messages"`
+}
+
+type PageAIConversationOut struct {
+	Total    int64                  `json:"total"`
+	List     []model.AIConversation `json:"list"`
+	Page     int                    `json:"page" form:"page"`         // 页码
+	PageSize int                    `json:"pageSize" form:"pageSize"` // 每页大小
+}
+
+type PageListAIConversationInput struct {
+	Page     int    `json:"page" form:"page"`         // 页码
+	PageSize int    `json:"pageSize" form:"pageSize"` // 每页大小
+	Keyword  string `json:"keyword" form:"keyword"`   // 标题关键字
+	UserID   int    `json:"-" form:"-"`               // 当前用户，由接口填充
+}
+
+func (p *PageListAIConversationInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, p)
+}
+
+func (p *PageListAIConversationInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIConversationInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+func (p *PageListAIConversationInput) IsFitter() bool {
+	return true
+}
+
+func (p *PageListAIConversationInput) Do(tx *gorm.DB) {
+	tx.Where("userID = ?", p.UserID)
+	if p.Keyword != "" {
+		tx.Where("title like ?", "%"+p.Keyword+"%")
+	}
+}
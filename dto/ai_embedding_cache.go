@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIEmbeddingCacheDelInput struct {
+	Model string `json:"model" form:"model" comment:"向量模型，为空时清空全部缓存"`
+}
+
+func (params *AIEmbeddingCacheDelInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// AIEmbeddingCacheStatsOut 向量缓存统计，命中次数自进程启动起累计
+type AIEmbeddingCacheStatsOut struct {
+	Enabled           bool    `json:"enabled"`
+	Persistent        bool    `json:"persistent"`
+	Capacity          int     `json:"capacity"`
+	MemoryEntries     int     `json:"memory_entries"`
+	PersistentEntries int64   `json:"persistent_entries"`
+	MemoryHits        int64   `json:"memory_hits"`
+	PersistentHits    int64   `json:"persistent_hits"`
+	Misses            int64   `json:"misses"`
+	HitRate           float64 `json:"hit_rate"`
+}
+
+type AIEmbeddingCacheDelOut struct {
+	Model   string `json:"model"`
+	Deleted int64  `json:"deleted"`
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIGuardrailRuleInput struct {
+	InstanceID  string `json:"instanceID" form:"instanceID" comment:"规则ID，为空时新建"`
+	Name        string `json:"name" form:"name" comment:"规则名称" validate:"required"`
+	Type        string `json:"type" form:"type" comment:"规则类型" validate:"required,oneof=pii keyword regex max_length"`
+	Pattern     string `json:"pattern" form:"pattern" comment:"pii 为逗号分隔的 phone、id_card、email（为空表示全部），keyword 为逗号或换行分隔的关键词，regex 为正则表达式"`
+	MaxLength   int    `json:"maxLength" form:"maxLength" comment:"最大字符数（max_length 规则）" validate:"min=0"`
+	Stage       string `json:"stage" form:"stage" comment:"作用阶段" validate:"required,oneof=input output both"`
+	Action      string `json:"action" form:"action" comment:"处理方式：block 拦截、mask 脱敏（max_length 为截断）、log 仅记录" validate:"required,oneof=block mask log"`
+	Enabled     bool   `json:"enabled" form:"enabled" comment:"是否启用"`
+	Sort        int    `json:"sort" form:"sort" comment:"执行顺序，从小到大"`
+	Description string `json:"description" form:"description" comment:"描述"`
+}
+
+func (params *AIGuardrailRuleInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIGuardrailRuleIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"规则ID" validate:"required"`
+}
+
+func (params *AIGuardrailRuleIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type PageListAIGuardrailViolationInput struct {
+	Page     int    `json:"page" form:"page"`           // 页码
+	PageSize int    `json:"pageSize" form:"pageSize"`   // 每页大小
+	RuleID   string `json:"ruleID" form:"ruleID"`       // 规则ID
+	UserName string `json:"user_name" form:"user_name"` // 用户名
+	Stage    string `json:"stage" form:"stage"`         // 命中阶段
+}
+
+func (p *PageListAIGuardrailViolationInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, p)
+}
+
+func (p *PageListAIGuardrailViolationInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIGuardrailViolationInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+func (p *PageListAIGuardrailViolationInput) IsFitter() bool {
+	return p.RuleID != "" || p.UserName != "" || p.Stage != ""
+}
+
+func (p *PageListAIGuardrailViolationInput) Do(tx *gorm.DB) {
+	if p.RuleID != "" {
+		tx.Where("ruleID = ?", p.RuleID)
+	}
+	if p.UserName != "" {
+		tx.Where("userName = ?", p.UserName)
+	}
+	if p.Stage != "" {
+		tx.Where("stage = ?", p.Stage)
+	}
+}
+
+type PageAIGuardrailViolationOut struct {
+	Total    int64                        `json:"total"`
+	List     []model.AIGuardrailViolation `json:"list"`
+	Page     int                          `json:"page" form:"page"`         // 页码
+	PageSize int                          `json:"pageSize" form:"pageSize"` // 每页大小
+}
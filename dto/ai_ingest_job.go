@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+)
+
+type AIIngestJobIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"任务ID" validate:"required"`
+}
+
+func (params *AIIngestJobIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// AIIngestJobDetailOut 入库任务详情，FailedChunks 为重试后仍失败的分块
+type AIIngestJobDetailOut struct {
+	Job          model.AIIngestJob     `json:"job"`
+	Chunking     chunker.Options       `json:"chunking"`
+	Pending      int                   `json:"pending"`
+	FailedChunks []model.AIIngestChunk `json:"failedChunks"`
+}
+
+type PageAIIngestJobOut struct {
+	Total    int64               `json:"total"`
+	List     []model.AIIngestJob `json:"list"`
+	Page     int                 `json:"page" form:"page"`         // 页码
+	PageSize int                 `json:"pageSize" form:"pageSize"` // 每页大小
+}
+
+type PageListAIIngestJobInput struct {
+	Page       int    `json:"page" form:"page"`             // 页码
+	PageSize   int    `json:"pageSize" form:"pageSize"`     // 每页大小
+	Keyword    string `json:"keyword" form:"keyword"`       // 文件名关键字
+	Status     string `json:"status" form:"status"`         // 任务状态
+	NameSpace  string `json:"namespace" form:"namespace"`   // 命名空间
+	PodName    string `json:"pod_name" form:"pod_name"`     // 知识库Pod名称
+	Collection string `json:"collection" form:"collection"` // 集合名称
+	UserID     int    `json:"-" form:"-"`                   // 当前用户，由接口填充
+}
+
+func (p *PageListAIIngestJobInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, p)
+}
+
+func (p *PageListAIIngestJobInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIIngestJobInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+func (p *PageListAIIngestJobInput) IsFitter() bool {
+	return true
+}
+
+func (p *PageListAIIngestJobInput) Do(tx *gorm.DB) {
+	tx.Where("userID = ?", p.UserID)
+	if p.Keyword != "" {
+		tx.Where("fileName like ?", "%"+p.Keyword+"%")
+	}
+	if p.Status != "" {
+		tx.Where("status = ?", p.Status)
+	}
+	if p.NameSpace != "" {
+		tx.Where("namespace = ?", p.NameSpace)
+	}
+	if p.PodName != "" {
+		tx.Where("podName = ?", p.PodName)
+	}
+	if p.Collection != "" {
+		tx.Where("collection = ?", p.Collection)
+	}
+}
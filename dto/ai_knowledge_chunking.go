@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIKnowledgeChunkingInput struct {
+	NameSpace      string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Knowledge      string `json:"knowledge" form:"knowledge" comment:"知识库部署名称" validate:"required"`
+	CollectionName string `json:"collection_name" form:"collection_name" comment:"集合名称" validate:"required"`
+	Strategy       string `json:"strategy" form:"strategy" comment:"分块策略 fixed/recursive/markdown/token" validate:"required,oneof=fixed recursive markdown token"`
+	ChunkSize      int    `json:"chunk_size" form:"chunk_size" comment:"分块大小，fixed/recursive/markdown 为字符数，token 为 token 数，0 使用默认值" validate:"min=0"`
+	ChunkOverlap   int    `json:"chunk_overlap" form:"chunk_overlap" comment:"相邻分块重叠长度，需小于分块大小" validate:"min=0"`
+	MaxTokens      int    `json:"max_tokens" form:"max_tokens" comment:"单个分块 token 上限，0 使用向量模型的上限" validate:"min=0"`
+}
+
+func (params *AIKnowledgeChunkingInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIKnowledgeChunkingListInput struct {
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间（可选）"`
+	Knowledge string `json:"knowledge" form:"knowledge" comment:"知识库部署名称（可选）"`
+}
+
+func (params *AIKnowledgeChunkingListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIKnowledgeChunkingIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"分块配置ID" validate:"required"`
+}
+
+func (params *AIKnowledgeChunkingIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIModelCatalogInput struct {
+	NameSpace  string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Deployment string `json:"deployment" form:"deployment" comment:"Ollama部署名称" validate:"required"`
+	ModelName  string `json:"model_name" form:"model_name" comment:"模型名称" validate:"required"`
+}
+
+func (params *AIModelCatalogInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIModelCatalogListInput struct {
+	NameSpace  string `json:"namespace" form:"namespace" comment:"命名空间"`
+	Deployment string `json:"deployment" form:"deployment" comment:"Ollama部署名称"`
+}
+
+func (params *AIModelCatalogListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIModelCatalogIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"模型目录ID" validate:"required"`
+}
+
+func (params *AIModelCatalogIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIModelCatalogSyncInput struct {
+	NameSpace  string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Deployment string `json:"deployment" form:"deployment" comment:"Ollama部署名称" validate:"required"`
+	Prune      bool   `json:"prune" form:"prune" comment:"是否删除未声明的模型"`
+}
+
+func (params *AIModelCatalogSyncInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIModelCatalogSyncOut struct {
+	Drift    *kubeDto.OllamaModelDrift `json:"drift"`
+	PullJobs []model.AIPullJob         `json:"pull_jobs"` // 本次创建的拉取任务
+	Pruned   map[string][]string       `json:"pruned"`    // 各副本删除的模型
+}
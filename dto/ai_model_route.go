@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// AIModelRouteTarget 虚拟模型的一个目标
+type AIModelRouteTarget struct {
+	NameSpace      string `json:"namespace" comment:"命名空间" validate:"required"`
+	Deployment     string `json:"deployment" comment:"Ollama部署名称（与Pod名称二选一）"`
+	PodName        string `json:"pod_name" comment:"Pod名称（与部署名称二选一）" validate:"required_without=Deployment"`
+	Model          string `json:"model" comment:"实际模型名称" validate:"required"`
+	Weight         int    `json:"weight" comment:"权重，weighted 策略下使用，默认 1" validate:"min=0"`
+	TimeoutSeconds int    `json:"timeout_seconds" comment:"超时时间（秒），流式请求为首个分片的超时，0 表示不限制" validate:"min=0"`
+}
+
+type AIModelRouteInput struct {
+	Name        string               `json:"name" form:"name" comment:"虚拟模型名称" validate:"required"`
+	Strategy    string               `json:"strategy" form:"strategy" comment:"路由策略 ordered/weighted" validate:"required,oneof=ordered weighted"`
+	Targets     []AIModelRouteTarget `json:"targets" form:"targets" comment:"目标列表" validate:"required,min=1,dive"`
+	Description string               `json:"description" form:"description" comment:"描述"`
+}
+
+func (params *AIModelRouteInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIModelRouteIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"路由ID" validate:"required"`
+}
+
+func (params *AIModelRouteIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// AIModelRouteOut 虚拟模型路由，Targets 为解析后的目标列表
+type AIModelRouteOut struct {
+	model.AIModelRoute
+	Targets []AIModelRouteTarget `json:"targets"`
+}
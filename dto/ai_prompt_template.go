@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIPromptTemplateInput struct {
+	Name         string `json:"name" form:"name" comment:"模板名称" validate:"required,max=128"`
+	Description  string `json:"description" form:"description" comment:"描述" validate:"max=512"`
+	Content      string `json:"content" form:"content" comment:"模板内容，变量格式为 {{name}}" validate:"required"`
+	Visibility   string `json:"visibility" form:"visibility" comment:"可见范围" validate:"required,oneof=private authority public"`
+	AuthorityIDs []uint `json:"authorityIDs" form:"authorityIDs" comment:"可见角色ID，可见范围为 authority 时必填"`
+	Comment      string `json:"comment" form:"comment" comment:"版本说明" validate:"max=512"`
+}
+
+func (params *AIPromptTemplateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIPromptTemplateUpdateInput struct {
+	InstanceID   string `json:"instanceID" form:"instanceID" comment:"模板ID" validate:"required"`
+	Description  string `json:"description" form:"description" comment:"描述" validate:"max=512"`
+	Content      string `json:"content" form:"content" comment:"模板内容，与最新版本不同时生成新版本（可选）"`
+	Visibility   string `json:"visibility" form:"visibility" comment:"可见范围" validate:"required,oneof=private authority public"`
+	AuthorityIDs []uint `json:"authorityIDs" form:"authorityIDs" comment:"可见角色ID，可见范围为 authority 时必填"`
+	Comment      string `json:"comment" form:"comment" comment:"版本说明" validate:"max=512"`
+}
+
+func (params *AIPromptTemplateUpdateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIPromptTemplateIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"模板ID" validate:"required"`
+	Version    int    `json:"version" form:"version" comment:"版本号（默认最新版本）"`
+}
+
+func (params *AIPromptTemplateIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIPromptTemplateDetailOut struct {
+	model.AIPromptTemplate
+	Version   model.AIPromptTemplateVersion   `json:"version"`
+	Variables []string                        `json:"variables"`
+	Versions  []model.AIPromptTemplateVersion `json:"versions"`
+}
+
+type PageAIPromptTemplateOut struct {
+	Total    int64                    `json:"total"`
+	List     []model.AIPromptTemplate `json:"list"`
+	Page     int                      `json:"page" form:"page"`         // 页码
+	PageSize int                      `json:"pageSize" form:"pageSize"` // 每页大小
+}
+
+type PageListAIPromptTemplateInput struct {
+	Page        int    `json:"page" form:"page"`         // 页码
+	PageSize    int    `json:"pageSize" form:"pageSize"` // 每页大小
+	Keyword     string `json:"keyword" form:"keyword"`   // 名称关键字
+	UserID      int    `json:"-" form:"-"`               // 当前用户，由接口填充
+	AuthorityID uint   `json:"-" form:"-"`               // 当前角色，由接口填充
+}
+
+func (p *PageListAIPromptTemplateInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, p)
+}
+
+func (p *PageListAIPromptTemplateInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIPromptTemplateInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+func (p *PageListAIPromptTemplateInput) IsFitter() bool {
+	return true
+}
+
+// Do 只返回当前用户可见的模板：自己创建的、公开的以及对当前角色可见的
+func (p *PageListAIPromptTemplateInput) Do(tx *gorm.DB) {
+	tx.Where("ownerID = ? OR visibility = ? OR (visibility = ? AND FIND_IN_SET(?, authorityIDs))",
+		p.UserID, model.AIPromptVisibilityPublic, model.AIPromptVisibilityAuthority, p.AuthorityID)
+	if p.Keyword != "" {
+		tx.Where("name like ?", "%"+p.Keyword+"%")
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIPullJobIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"任务ID" validate:"required"`
+}
+
+func (params *AIPullJobIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type PageAIPullJobOut struct {
+	Total    int64             `json:"total"`
+	List     []model.AIPullJob `json:"list"`
+	Page     int               `json:"page" form:"page"`         // 页码
+	PageSize int               `json:"pageSize" form:"pageSize"` // 每页大小
+}
+
+type PageListAIPullJobInput struct {
+	Page      int    `json:"page" form:"page"`           // 页码
+	PageSize  int    `json:"pageSize" form:"pageSize"`   // 每页大小
+	Keyword   string `json:"keyword" form:"keyword"`     // 模型名称关键字
+	Status    string `json:"status" form:"status"`       // 任务状态
+	NameSpace string `json:"namespace" form:"namespace"` // 命名空间
+	PodName   string `json:"pod_name" form:"pod_name"`   // Pod名称
+}
+
+func (p *PageListAIPullJobInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, p)
+}
+
+func (p *PageListAIPullJobInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIPullJobInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+func (p *PageListAIPullJobInput) IsFitter() bool {
+	return p.Keyword != "" || p.Status != "" || p.NameSpace != "" || p.PodName != ""
+}
+
+func (p *PageListAIPullJobInput) Do(tx *gorm.DB) {
+	if p.Keyword != "" {
+		tx.Where("modelName like ?", "%"+p.Keyword+"%")
+	}
+	if p.Status != "" {
+		tx.Where("status = ?", p.Status)
+	}
+	if p.NameSpace != "" {
+		tx.Where("namespace = ?", p.NameSpace)
+	}
+	if p.PodName != "" {
+		tx.Where("podName = ?", p.PodName)
+	}
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type AIQuotaInput struct {
+	SubjectType       string `json:"subjectType" form:"subjectType" comment:"绑定对象类型" validate:"required,oneof=authority user"`
+	SubjectID         uint   `json:"subjectID" form:"subjectID" comment:"角色ID或用户ID" validate:"required"`
+	RequestsPerMinute int    `json:"requestsPerMinute" form:"requestsPerMinute" comment:"每分钟请求数，0 表示不限制" validate:"min=0"`
+	ConcurrentStreams int    `json:"concurrentStreams" form:"concurrentStreams" comment:"并发请求数，0 表示不限制" validate:"min=0"`
+	DailyTokens       int64  `json:"dailyTokens" form:"dailyTokens" comment:"每日token额度，0 表示不限制" validate:"min=0"`
+}
+
+func (params *AIQuotaInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIQuotaListInput struct {
+	SubjectType string `json:"subjectType" form:"subjectType" comment:"绑定对象类型"`
+}
+
+func (params *AIQuotaListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+type AIQuotaIDInput struct {
+	InstanceID string `json:"instanceID" form:"instanceID" comment:"配额ID" validate:"required"`
+}
+
+func (params *AIQuotaIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
@@ -0,0 +1,121 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// 用量报表的分组维度
+const (
+	AIUsageGroupUser       = "user"
+	AIUsageGroupDepartment = "department"
+	AIUsageGroupModel      = "model"
+	AIUsageGroupDay        = "day"
+)
+
+const usageDateLayout = "2006-01-02"
+
+// AIUsageFilter 用量查询条件
+type AIUsageFilter struct {
+	StartDate    string `json:"start_date" form:"start_date"`       // 开始日期，格式 2006-01-02
+	EndDate      string `json:"end_date" form:"end_date"`           // 结束日期（含），格式 2006-01-02
+	UserName     string `json:"user_name" form:"user_name"`         // 用户名
+	DepartmentID uint   `json:"department_id" form:"department_id"` // 部门ID
+	Model        string `json:"model" form:"model"`                 // 模型名称
+	NameSpace    string `json:"namespace" form:"namespace"`         // 命名空间
+	Deployment   string `json:"deployment" form:"deployment"`       // Ollama部署名称
+}
+
+// Validate 校验日期格式
+func (f *AIUsageFilter) Validate() error {
+	for _, date := range []string{f.StartDate, f.EndDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.ParseInLocation(usageDateLayout, date, time.Local); err != nil {
+			return fmt.Errorf("日期 %s 格式错误，应为 %s", date, usageDateLayout)
+		}
+	}
+	return nil
+}
+
+func (f *AIUsageFilter) IsFitter() bool {
+	return f.StartDate != "" || f.EndDate != "" || f.UserName != "" || f.DepartmentID != 0 ||
+		f.Model != "" || f.NameSpace != "" || f.Deployment != ""
+}
+
+func (f *AIUsageFilter) Do(tx *gorm.DB) {
+	if start, err := time.ParseInLocation(usageDateLayout, f.StartDate, time.Local); err == nil {
+		tx.Where("created_at >= ?", start)
+	}
+	if end, err := time.ParseInLocation(usageDateLayout, f.EndDate, time.Local); err == nil {
+		tx.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+	if f.UserName != "" {
+		tx.Where("userName = ?", f.UserName)
+	}
+	if f.DepartmentID != 0 {
+		tx.Where("departmentID = ?", f.DepartmentID)
+	}
+	if f.Model != "" {
+		tx.Where("model = ?", f.Model)
+	}
+	if f.NameSpace != "" {
+		tx.Where("namespace = ?", f.NameSpace)
+	}
+	if f.Deployment != "" {
+		tx.Where("deployment = ?", f.Deployment)
+	}
+}
+
+type AIUsageReportInput struct {
+	GroupBy string `json:"group_by" form:"group_by" comment:"分组维度" validate:"required"` // 逗号分隔，可选 user、department、model、day
+	AIUsageFilter
+}
+
+func (params *AIUsageReportInput) BindingValidParams(c *gin.Context) error {
+	if err := pkg.DefaultGetValidParams(c, params); err != nil {
+		return err
+	}
+	return params.Validate()
+}
+
+type PageListAIUsageInput struct {
+	Page     int `json:"page" form:"page"`         // 页码
+	PageSize int `json:"pageSize" form:"pageSize"` // 每页大小
+	AIUsageFilter
+}
+
+func (p *PageListAIUsageInput) BindingValidParams(ctx *gin.Context) error {
+	if err := pkg.DefaultGetValidParams(ctx, p); err != nil {
+		return err
+	}
+	return p.Validate()
+}
+
+func (p *PageListAIUsageInput) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+func (p *PageListAIUsageInput) GetPageSize() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+type PageAIUsageOut struct {
+	Total    int64           `json:"total"`
+	List     []model.AIUsage `json:"list"`
+	Page     int             `json:"page" form:"page"`         // 页码
+	PageSize int             `json:"pageSize" form:"pageSize"` // 每页大小
+}
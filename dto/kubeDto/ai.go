@@ -8,23 +8,32 @@ import (
 // ChatWithKBInput 结合知识库的聊天输入参数
 type ChatWithKBInput struct {
 	// 知识库相关参数
-	KnowledgePodName   string `json:"knowledge_pod_name" form:"knowledge_pod_name" comment:"知识库Pod名称" validate:"required"`
-	KnowledgeNamespace string `json:"knowledge_namespace" form:"knowledge_namespace" comment:"知识库命名空间" validate:"required"`
-	KnowledgeType      string `json:"knowledge_type" form:"knowledge_type" comment:"知识库类型: chromadb, milvus, weaviate" validate:"required"`
-	CollectionName     string `json:"collection_name" form:"collection_name" comment:"集合名称" validate:"required"`
+	KnowledgePodName   string                 `json:"knowledge_pod_name" form:"knowledge_pod_name" comment:"知识库Pod名称" validate:"required"`
+	KnowledgeNamespace string                 `json:"knowledge_namespace" form:"knowledge_namespace" comment:"知识库命名空间" validate:"required"`
+	KnowledgeType      string                 `json:"knowledge_type" form:"knowledge_type" comment:"知识库类型: chromadb, milvus, weaviate, qdrant, pgvector" validate:"required"`
+	CollectionName     string                 `json:"collection_name" form:"collection_name" comment:"集合名称" validate:"required"`
+	Filter             map[string]interface{} `json:"filter" form:"filter" comment:"元数据过滤条件，键值全部相等时命中（可选）"`
 
 	// Ollama 相关参数
-	OllamaPodName   string `json:"ollama_pod_name" form:"ollama_pod_name" comment:"Ollama Pod名称" validate:"required"`
-	OllamaNamespace string `json:"ollama_namespace" form:"ollama_namespace" comment:"Ollama命名空间" validate:"required"`
-	OllamaModel     string `json:"ollama_model" form:"ollama_model" comment:"Ollama模型名称" validate:"required"`
+	OllamaPodName    string `json:"ollama_pod_name" form:"ollama_pod_name" comment:"Ollama Pod名称（与部署名称二选一，使用虚拟模型时可不填）"`
+	OllamaDeployment string `json:"ollama_deployment" form:"ollama_deployment" comment:"Ollama部署名称（与Pod名称二选一，使用虚拟模型时可不填）"`
+	OllamaNamespace  string `json:"ollama_namespace" form:"ollama_namespace" comment:"Ollama命名空间（使用虚拟模型时可不填）"`
+	OllamaModel      string `json:"ollama_model" form:"ollama_model" comment:"Ollama模型名称或虚拟模型名称" validate:"required"`
 
 	// 聊天相关参数
 	Question string `json:"question" form:"question" comment:"用户问题" validate:"required"`
 	TopK     int    `json:"top_k" form:"top_k" comment:"从知识库返回的相关文档数量（默认5）"`
 	Stream   bool   `json:"stream" form:"stream" comment:"是否流式返回"`
+	NoCache  bool   `json:"no_cache" form:"no_cache" comment:"跳过语义缓存，重新生成回答"`
 
 	// 可选参数
 	SystemPrompt string `json:"system_prompt" form:"system_prompt" comment:"自定义系统提示词（可选）"`
+	PromptTemplateRef
+}
+
+// OllamaTarget 返回聊天使用的 Ollama 目标
+func (params *ChatWithKBInput) OllamaTarget() OllamaTarget {
+	return OllamaTarget{PodName: params.OllamaPodName, Deployment: params.OllamaDeployment, NameSpace: params.OllamaNamespace}
 }
 
 func (params *ChatWithKBInput) BindingValidParams(c *gin.Context) error {
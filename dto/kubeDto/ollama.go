@@ -1,6 +1,9 @@
 package kubeDto
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/noovertime7/kubemanage/pkg"
 )
@@ -18,6 +21,7 @@ type OllamaDeployInput struct {
 	StorageSize  string            `json:"storage_size" form:"storage_size" comment:"存储大小"`
 	StorageClass string            `json:"storage_class" form:"storage_class" comment:"存储类"`
 	DeployType   string            `json:"deploy_type" form:"deploy_type" comment:"部署类型: deployment 或 daemonset" validate:"required"`
+	SchedulingOptions
 }
 
 // OllamaListInput Ollama 列表查询参数
@@ -35,54 +39,176 @@ type OllamaNameNS struct {
 	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
 }
 
+// OllamaDeleteInput 删除 Ollama 部署参数
+type OllamaDeleteInput struct {
+	Name      string `json:"name" form:"name" comment:"Ollama部署名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	KeepPVC   bool   `json:"keep_pvc" form:"keep_pvc" comment:"是否保留模型存储PVC"`
+}
+
+// OllamaScaleInput Ollama 扩缩容参数
+type OllamaScaleInput struct {
+	Name      string `json:"name" form:"name" comment:"Ollama部署名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Replicas  int32  `json:"replicas" form:"replicas" comment:"副本数" validate:"min=0"`
+}
+
+// OllamaUpgradeInput Ollama 升级镜像参数
+type OllamaUpgradeInput struct {
+	Name      string `json:"name" form:"name" comment:"Ollama部署名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Image     string `json:"image" form:"image" comment:"Ollama镜像" validate:"required"`
+}
+
+// OllamaTarget Ollama 推理目标
+// 指定 Deployment 时在其就绪副本间负载均衡并在失败时重试，指定 PodName 时直接访问该 Pod，便于调试
+type OllamaTarget struct {
+	PodName    string `json:"pod_name" form:"pod_name" comment:"Pod名称（与部署名称二选一）" validate:"required_without=Deployment"`
+	Deployment string `json:"deployment" form:"deployment" comment:"Ollama部署名称（与Pod名称二选一）"`
+	NameSpace  string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+}
+
+// OllamaRoutableTarget 与 OllamaTarget 字段相同，模型为虚拟模型时由路由策略选择目标，可以不填
+type OllamaRoutableTarget struct {
+	PodName    string `json:"pod_name" form:"pod_name" comment:"Pod名称（与部署名称二选一，使用虚拟模型时可不填）"`
+	Deployment string `json:"deployment" form:"deployment" comment:"Ollama部署名称（与Pod名称二选一，使用虚拟模型时可不填）"`
+	NameSpace  string `json:"namespace" form:"namespace" comment:"命名空间（使用虚拟模型时可不填）"`
+}
+
+// OllamaTarget 转换为推理目标
+func (t OllamaRoutableTarget) OllamaTarget() OllamaTarget {
+	return OllamaTarget{PodName: t.PodName, Deployment: t.Deployment, NameSpace: t.NameSpace}
+}
+
 // OllamaPullModelInput Ollama 拉取模型输入参数
 type OllamaPullModelInput struct {
-	PodName   string `json:"pod_name" form:"pod_name" comment:"Pod名称" validate:"required"`
-	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	OllamaTarget
 	ModelName string `json:"model_name" form:"model_name" comment:"模型名称" validate:"required"`
 }
 
 // OllamaModelListInput Ollama 模型列表查询参数
 type OllamaModelListInput struct {
-	PodName   string `json:"pod_name" form:"pod_name" comment:"Pod名称" validate:"required"`
-	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	OllamaTarget
 }
 
 // OllamaDeleteModelInput Ollama 删除模型输入参数
 type OllamaDeleteModelInput struct {
-	PodName   string `json:"pod_name" form:"pod_name" comment:"Pod名称" validate:"required"`
-	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	OllamaTarget
 	ModelName string `json:"model_name" form:"model_name" comment:"模型名称" validate:"required"`
 }
 
 // OllamaModelDetailInput Ollama 模型详情查询参数
 type OllamaModelDetailInput struct {
-	PodName   string `json:"pod_name" form:"pod_name" comment:"Pod名称" validate:"required"`
-	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	OllamaTarget
 	ModelName string `json:"model_name" form:"model_name" comment:"模型名称" validate:"required"`
 }
 
+// OllamaCreateModelInput Ollama 创建自定义模型输入参数，结构化字段会覆盖 Modelfile 中的同名指令
+type OllamaCreateModelInput struct {
+	OllamaTarget
+	ModelName  string                 `json:"model_name" form:"model_name" comment:"新模型名称" validate:"required"`
+	Modelfile  string                 `json:"modelfile" form:"modelfile" comment:"Modelfile 内容（与基础模型二选一）"`
+	From       string                 `json:"from" form:"from" comment:"基础模型名称或已上传文件的 sha256 摘要" validate:"required_without=Modelfile"`
+	System     string                 `json:"system" form:"system" comment:"系统提示词"`
+	Template   string                 `json:"template" form:"template" comment:"提示词模板"`
+	Parameters map[string]interface{} `json:"parameters" form:"parameters" comment:"模型参数，如 temperature、num_ctx"`
+	Quantize   string                 `json:"quantize" form:"quantize" comment:"量化类型，如 q4_K_M"`
+}
+
+// OllamaCopyModelInput Ollama 复制模型输入参数
+type OllamaCopyModelInput struct {
+	OllamaTarget
+	Source      string `json:"source" form:"source" comment:"源模型名称" validate:"required"`
+	Destination string `json:"destination" form:"destination" comment:"目标模型名称" validate:"required"`
+}
+
+// OllamaUploadModelInput 上传 GGUF 模型文件输入参数，指定模型名称时上传后直接注册为模型
+type OllamaUploadModelInput struct {
+	PodName    string `form:"pod_name" comment:"Pod名称（与部署名称二选一）" validate:"required_without=Deployment"`
+	Deployment string `form:"deployment" comment:"Ollama部署名称（与Pod名称二选一）"`
+	NameSpace  string `form:"namespace" comment:"命名空间" validate:"required"`
+	ModelName  string `form:"model_name" comment:"模型名称（可选，为空时只上传文件）"`
+	Modelfile  string `form:"modelfile" comment:"Modelfile 内容（可选，FROM 指令会被上传的文件替代）"`
+}
+
+// OllamaTarget 返回上传的 Ollama 目标
+func (params *OllamaUploadModelInput) OllamaTarget() OllamaTarget {
+	return OllamaTarget{PodName: params.PodName, Deployment: params.Deployment, NameSpace: params.NameSpace}
+}
+
 // OllamaChatMessage Ollama 聊天消息
 type OllamaChatMessage struct {
 	Role    string `json:"role" comment:"角色: user, assistant, system" validate:"required"`
 	Content string `json:"content" comment:"消息内容" validate:"required"`
+	// 工具调用相关字段，仅在智能体对话中使用
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty" comment:"模型返回的工具调用"`
+	ToolName  string           `json:"tool_name,omitempty" comment:"工具结果对应的工具名称（role 为 tool 时）"`
+}
+
+// OllamaTool 通过 Ollama tools 字段声明给模型的函数
+type OllamaTool struct {
+	Type     string             `json:"type"`
+	Function OllamaToolFunction `json:"function"`
+}
+
+// OllamaToolFunction 函数名称、描述及参数的 JSON Schema
+type OllamaToolFunction struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty"`
+}
+
+// OllamaToolCall 模型返回的一次函数调用
+type OllamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"function"`
+}
+
+// OllamaInferenceOptions 透传给 Ollama 的输出格式与模型参数
+// Format 为 JSON Schema 时服务端会校验模型输出，不符合时按 SchemaRetries 重试，仍不符合则返回校验错误
+type OllamaInferenceOptions struct {
+	Format        json.RawMessage        `json:"format,omitempty" swaggertype:"object" comment:"输出格式：\"json\" 或 JSON Schema 对象"`
+	Options       map[string]interface{} `json:"options,omitempty" comment:"模型参数，如 temperature、num_ctx、seed、stop"`
+	SchemaRetries int                    `json:"schema_retries,omitempty" comment:"输出不符合 Schema 时的重试次数，默认不重试" validate:"min=0,max=5"`
+	// Tools 声明给模型的工具，由智能体在服务端填充，不接受客户端直接传入
+	Tools []OllamaTool `json:"-"`
 }
 
 // OllamaChatInput Ollama 聊天输入参数
 type OllamaChatInput struct {
-	PodName   string              `json:"pod_name" form:"pod_name" comment:"Pod名称" validate:"required"`
-	NameSpace string              `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
-	Model     string              `json:"model" form:"model" comment:"模型名称" validate:"required"`
-	Messages  []OllamaChatMessage `json:"messages" form:"messages" comment:"消息列表" validate:"required,min=1"`
-	Stream    bool                `json:"stream" form:"stream" comment:"是否流式返回"`
+	OllamaRoutableTarget
+	Model    string              `json:"model" form:"model" comment:"模型名称或虚拟模型名称" validate:"required"`
+	Messages []OllamaChatMessage `json:"messages" form:"messages" comment:"消息列表" validate:"required,min=1"`
+	Stream   bool                `json:"stream" form:"stream" comment:"是否流式返回（流式输出不做 Schema 校验）"`
+	PromptTemplateRef
+	OllamaInferenceOptions
 }
 
 // OllamaEmbeddingsInput Ollama 向量嵌入输入参数
 type OllamaEmbeddingsInput struct {
-	PodName   string `json:"pod_name" form:"pod_name" comment:"Pod名称" validate:"required"`
-	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
-	Model     string `json:"model" form:"model" comment:"模型名称" validate:"required"`
-	Prompt    string `json:"prompt" form:"prompt" comment:"要嵌入的文本" validate:"required"`
+	OllamaRoutableTarget
+	Model  string `json:"model" form:"model" comment:"模型名称或虚拟模型名称" validate:"required"`
+	Prompt string `json:"prompt" form:"prompt" comment:"要嵌入的文本" validate:"required"`
+}
+
+// OllamaModelDrift Ollama 部署的模型目录与各副本实际模型的差异
+type OllamaModelDrift struct {
+	Desired   []string         `json:"desired"`
+	Pods      []OllamaPodDrift `json:"pods"`
+	InSync    bool             `json:"in_sync"`
+	Message   string           `json:"message,omitempty"`
+	CheckedAt time.Time        `json:"checked_at"`
+}
+
+// OllamaPodDrift 单个副本的模型差异
+type OllamaPodDrift struct {
+	PodName string   `json:"pod_name"`
+	Missing []string `json:"missing"`           // 已声明但未拉取的模型
+	Extra   []string `json:"extra"`             // 已拉取但未声明的模型
+	Pulling []string `json:"pulling,omitempty"` // 正在拉取的模型
+	Error   string   `json:"error,omitempty"`
 }
 
 func (params *OllamaDeployInput) BindingValidParams(c *gin.Context) error {
@@ -97,6 +223,18 @@ func (params *OllamaNameNS) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
 
+func (params *OllamaDeleteInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *OllamaScaleInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *OllamaUpgradeInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
 func (params *OllamaPullModelInput) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
@@ -113,6 +251,18 @@ func (params *OllamaModelDetailInput) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
 
+func (params *OllamaCreateModelInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *OllamaCopyModelInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *OllamaUploadModelInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
 func (params *OllamaChatInput) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
@@ -120,3 +270,72 @@ func (params *OllamaChatInput) BindingValidParams(c *gin.Context) error {
 func (params *OllamaEmbeddingsInput) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
+
+// OllamaGenerateInput Ollama 文本补全输入参数
+type OllamaGenerateInput struct {
+	OllamaRoutableTarget
+	Model  string `json:"model" form:"model" comment:"模型名称或虚拟模型名称" validate:"required"`
+	Prompt string `json:"prompt" form:"prompt" comment:"提示词" validate:"required"`
+	System string `json:"system" form:"system" comment:"系统提示词（可选，覆盖模型默认值）"`
+	Raw    bool   `json:"raw" form:"raw" comment:"不套用模型的提示词模板，原样发送 prompt"`
+	Stream bool   `json:"stream" form:"stream" comment:"是否流式返回（流式输出不做 Schema 校验）"`
+	OllamaInferenceOptions
+}
+
+func (params *OllamaGenerateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// OllamaLoadModelInput 预加载模型输入参数
+type OllamaLoadModelInput struct {
+	OllamaTarget
+	ModelName string `json:"model_name" form:"model_name" comment:"模型名称" validate:"required"`
+	KeepAlive string `json:"keep_alive" form:"keep_alive" comment:"驻留时间，如 10m、24h，-1 表示常驻，默认使用 Ollama 配置"`
+}
+
+func (params *OllamaLoadModelInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// OllamaUnloadModelInput 卸载模型输入参数
+type OllamaUnloadModelInput struct {
+	OllamaTarget
+	ModelName string `json:"model_name" form:"model_name" comment:"模型名称" validate:"required"`
+}
+
+func (params *OllamaUnloadModelInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// OllamaLoadedModel Ollama /api/ps 返回的已加载模型，大小单位为字节
+type OllamaLoadedModel struct {
+	Name      string                 `json:"name"`
+	Model     string                 `json:"model"`
+	Digest    string                 `json:"digest"`
+	Size      int64                  `json:"size"`
+	SizeVRAM  int64                  `json:"size_vram"`
+	ExpiresAt time.Time              `json:"expires_at"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// OllamaPodResidency 单个 Ollama 副本当前加载的模型
+type OllamaPodResidency struct {
+	PodName    string              `json:"pod_name"`
+	Namespace  string              `json:"namespace"`
+	Deployment string              `json:"deployment"`
+	NodeName   string              `json:"node_name"`
+	Models     []OllamaLoadedModel `json:"models"`
+	Size       int64               `json:"size"`
+	SizeVRAM   int64               `json:"size_vram"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// OllamaNodeResidency 节点上所有 Ollama 副本加载的模型及节点可分配资源
+type OllamaNodeResidency struct {
+	NodeName          string               `json:"node_name"`
+	MemoryAllocatable int64                `json:"memory_allocatable"`
+	GPUAllocatable    int64                `json:"gpu_allocatable"`
+	Size              int64                `json:"size"`
+	SizeVRAM          int64                `json:"size_vram"`
+	Pods              []OllamaPodResidency `json:"pods"`
+}
@@ -0,0 +1,103 @@
+package kubeDto
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// OpenAISamplingParams OpenAI 的采样参数，转换为 Ollama 的 options
+type OpenAISamplingParams struct {
+	Temperature *float64 `json:"temperature" comment:"采样温度" validate:"omitempty,min=0,max=2"`
+	TopP        *float64 `json:"top_p" comment:"核采样概率" validate:"omitempty,min=0,max=1"`
+	MaxTokens   *int     `json:"max_tokens" comment:"最多生成的 token 数" validate:"omitempty,min=1"`
+	// Stop 支持单个字符串或字符串数组
+	Stop json.RawMessage `json:"stop" swaggertype:"array,string" comment:"停止词"`
+	Seed *int            `json:"seed" comment:"随机种子"`
+}
+
+// OpenAIChatCompletionInput OpenAI /v1/chat/completions 请求参数
+type OpenAIChatCompletionInput struct {
+	OpenAISamplingParams
+	Model    string              `json:"model" comment:"模型名称" validate:"required"`
+	Messages []OllamaChatMessage `json:"messages" comment:"消息列表" validate:"required,min=1"`
+	Stream   bool                `json:"stream" comment:"是否流式输出"`
+	User     string              `json:"user" comment:"调用方用户标识"`
+}
+
+// OpenAICompletionInput OpenAI /v1/completions 请求参数
+type OpenAICompletionInput struct {
+	OpenAISamplingParams
+	Model  string `json:"model" comment:"模型名称" validate:"required"`
+	Prompt string `json:"prompt" comment:"提示词" validate:"required"`
+	Stream bool   `json:"stream" comment:"是否流式输出"`
+	User   string `json:"user" comment:"调用方用户标识"`
+}
+
+// OpenAIEmbeddingInput OpenAI /v1/embeddings 请求参数
+type OpenAIEmbeddingInput struct {
+	Model string `json:"model" comment:"模型名称" validate:"required"`
+	// Input 支持单个字符串或字符串数组
+	Input json.RawMessage `json:"input" comment:"输入文本" validate:"required"`
+	User  string          `json:"user" comment:"调用方用户标识"`
+}
+
+func (params *OpenAIChatCompletionInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *OpenAICompletionInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *OpenAIEmbeddingInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// InferenceOptions 将采样参数转换为 Ollama options：max_tokens 对应 num_predict，其余同名
+func (params *OpenAISamplingParams) InferenceOptions() (OllamaInferenceOptions, error) {
+	options := map[string]interface{}{}
+	if params.Temperature != nil {
+		options["temperature"] = *params.Temperature
+	}
+	if params.TopP != nil {
+		options["top_p"] = *params.TopP
+	}
+	if params.MaxTokens != nil {
+		options["num_predict"] = *params.MaxTokens
+	}
+	if params.Seed != nil {
+		options["seed"] = *params.Seed
+	}
+	if len(params.Stop) > 0 && string(params.Stop) != "null" {
+		var single string
+		var multi []string
+		if err := json.Unmarshal(params.Stop, &single); err == nil {
+			multi = []string{single}
+		} else if err := json.Unmarshal(params.Stop, &multi); err != nil {
+			return OllamaInferenceOptions{}, errors.New("stop 必须是字符串或字符串数组")
+		}
+		if len(multi) > 0 {
+			options["stop"] = multi
+		}
+	}
+	return OllamaInferenceOptions{Options: options}, nil
+}
+
+// Inputs 将 input 统一转换为字符串数组
+func (params *OpenAIEmbeddingInput) Inputs() ([]string, error) {
+	var single string
+	if err := json.Unmarshal(params.Input, &single); err == nil {
+		return []string{single}, nil
+	}
+	var multi []string
+	if err := json.Unmarshal(params.Input, &multi); err != nil {
+		return nil, errors.New("input 必须是字符串或字符串数组")
+	}
+	if len(multi) == 0 {
+		return nil, errors.New("input 不能为空")
+	}
+	return multi, nil
+}
@@ -0,0 +1,10 @@
+package kubeDto
+
+// PromptTemplateRef 引用提示词模板，设置后替代请求中的系统提示词
+type PromptTemplateRef struct {
+	TemplateID      string            `json:"template_id" form:"template_id" comment:"提示词模板ID（可选）"`
+	TemplateVersion int               `json:"template_version" form:"template_version" comment:"模板版本（默认最新版本）"`
+	Variables       map[string]string `json:"variables" form:"variables" comment:"模板自定义变量"`
+	// PromptTemplate 模板内容，由接口根据 TemplateID 校验可见范围后填充
+	PromptTemplate string `json:"-" form:"-"`
+}
@@ -0,0 +1,17 @@
+package kubeDto
+
+import (
+	coreV1 "k8s.io/api/core/v1"
+)
+
+// SchedulingOptions 工作负载的调度与资源选项，Ollama 与知识库部署共用
+type SchedulingOptions struct {
+	GPU               string                  `json:"gpu" form:"gpu" comment:"nvidia.com/gpu 数量"`
+	ExtendedResources map[string]string       `json:"extended_resources" form:"extended_resources" comment:"扩展资源，如 amd.com/gpu: 1"`
+	Tolerations       []coreV1.Toleration     `json:"tolerations" form:"tolerations" comment:"污点容忍"`
+	NodeAffinity      *coreV1.NodeAffinity    `json:"node_affinity" form:"node_affinity" comment:"节点亲和性"`
+	PodAffinity       *coreV1.PodAffinity     `json:"pod_affinity" form:"pod_affinity" comment:"Pod亲和性"`
+	PodAntiAffinity   *coreV1.PodAntiAffinity `json:"pod_anti_affinity" form:"pod_anti_affinity" comment:"Pod反亲和性"`
+	PriorityClassName string                  `json:"priority_class_name" form:"priority_class_name" comment:"优先级类"`
+	Env               []coreV1.EnvVar         `json:"env" form:"env" comment:"额外环境变量，如 OLLAMA_NUM_PARALLEL、OLLAMA_KEEP_ALIVE"`
+}
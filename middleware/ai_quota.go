@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/cmd/app/config"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/ai"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// AIQuota 在调用 Ollama 前检查当前用户的请求频率、并发数及每日 token 额度
+func AIQuota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := utils.GetUserInfo(c)
+		if claims == nil {
+			return
+		}
+		release, err := v1.CoreV1.AI().Quota().Acquire(c, claims.ID, claims.AuthorityId)
+		if err != nil {
+			code := globalError.ServerError
+			switch {
+			case errors.Is(err, ai.ErrRateLimited):
+				code = globalError.AIRateLimitError
+			case errors.Is(err, ai.ErrConcurrencyLimit):
+				code = globalError.AIConcurrencyError
+			case errors.Is(err, ai.ErrTokenQuota):
+				code = globalError.AITokenQuotaError
+			}
+			ResponseError(c, globalError.NewGlobalError(code, err))
+			c.Abort()
+			return
+		}
+		// 流式响应在 handler 返回时才结束，此时再释放并发名额
+		defer release()
+		c.Next()
+	}
+}
+
+// OpenAIQuota 按 API Key 配置的请求频率、并发数及每日 token 额度限制 /v1 接口，需在 OpenAIAuth 之后使用
+func OpenAIQuota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, _ := c.Get(openAIKey)
+		key, ok := v.(config.OpenAIAPIKey)
+		if !ok {
+			return
+		}
+		release, err := v1.CoreV1.AI().Quota().AcquireAPIKey(c, key.Name, model.AIQuota{
+			RequestsPerMinute: key.RequestsPerMinute,
+			ConcurrentStreams: key.ConcurrentStreams,
+			DailyTokens:       key.DailyTokens,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, ai.ErrRateLimited), errors.Is(err, ai.ErrConcurrencyLimit):
+				ResponseOpenAIError(c, http.StatusTooManyRequests, "rate_limit_exceeded", err.Error())
+			case errors.Is(err, ai.ErrTokenQuota):
+				ResponseOpenAIError(c, http.StatusTooManyRequests, "insufficient_quota", err.Error())
+			default:
+				ResponseOpenAIError(c, http.StatusInternalServerError, "api_error", err.Error())
+			}
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/cmd/app/config"
+)
+
+// OpenAIKeyName 通过认证的 API Key 名称在上下文中的 key
+const OpenAIKeyName = "openai_key_name"
+
+// openAIKey 通过认证的 API Key 配置在上下文中的 key
+const openAIKey = "openai_key"
+
+// OpenAIErrorResponse OpenAI 格式的错误响应
+type OpenAIErrorResponse struct {
+	Error OpenAIError `json:"error"`
+}
+
+type OpenAIError struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Param   interface{} `json:"param"`
+	Code    interface{} `json:"code"`
+}
+
+// ResponseOpenAIError 按 OpenAI 协议返回错误，便于 SDK 正确识别
+func ResponseOpenAIError(c *gin.Context, status int, errType, message string) {
+	c.AbortWithStatusJSON(status, &OpenAIErrorResponse{Error: OpenAIError{Message: message, Type: errType}})
+}
+
+// OpenAIAuth 校验 Authorization: Bearer <key>
+func OpenAIAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if key == "" {
+			ResponseOpenAIError(c, http.StatusUnauthorized, "invalid_request_error", "missing api key")
+			return
+		}
+		for _, k := range config.SysConfig.OpenAI.APIKeys {
+			if k.Key != "" && subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+				c.Set(OpenAIKeyName, k.Name)
+				c.Set(openAIKey, k)
+				c.Next()
+				return
+			}
+		}
+		ResponseOpenAIError(c, http.StatusUnauthorized, "invalid_request_error", "invalid api key")
+	}
+}
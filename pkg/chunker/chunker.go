@@ -0,0 +1,140 @@
+// Package chunker 将文档文本切分为适合向量化的分块，长度按字符（rune）或估算的 token 数计算，不会截断多字节字符
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 分块策略
+const (
+	// StrategyFixed 固定长度窗口，相邻窗口按 ChunkOverlap 重叠
+	StrategyFixed = "fixed"
+	// StrategyRecursive 依次按段落、换行、句子、短语、空白切分，尽量保持语义完整
+	StrategyRecursive = "recursive"
+	// StrategyMarkdown 按 Markdown 标题切分，代码块不拆开，标题路径写入 Chunk.Heading
+	StrategyMarkdown = "markdown"
+	// StrategyToken 与 recursive 相同，但 ChunkSize 及 ChunkOverlap 按估算的 token 数计算
+	StrategyToken = "token"
+)
+
+const (
+	// DefaultChunkSize 未指定分块大小时的默认值，token 策略下为 token 数
+	DefaultChunkSize = 1000
+	// defaultTokenChunkSize token 策略未指定分块大小且没有 token 上限时的默认值
+	defaultTokenChunkSize = 512
+)
+
+// Options 分块参数，零值字段使用默认值
+type Options struct {
+	Strategy     string `json:"strategy"`
+	ChunkSize    int    `json:"chunk_size"`
+	ChunkOverlap int    `json:"chunk_overlap"`
+	// MaxTokens 单个分块估算 token 数的上限，通常为向量模型的上下文长度，超出的分块会继续切分，0 表示不限制
+	MaxTokens int `json:"max_tokens"`
+}
+
+// Chunk 一个分块，Heading 为所在的标题路径，仅 markdown 策略设置
+type Chunk struct {
+	Text    string
+	Heading string
+}
+
+// Strategies 返回支持的分块策略
+func Strategies() []string {
+	return []string{StrategyFixed, StrategyRecursive, StrategyMarkdown, StrategyToken}
+}
+
+// Normalize 补全默认值并校验参数
+func (o Options) Normalize() (Options, error) {
+	if o.Strategy == "" {
+		o.Strategy = StrategyRecursive
+	}
+	switch o.Strategy {
+	case StrategyFixed, StrategyRecursive, StrategyMarkdown, StrategyToken:
+	default:
+		return o, fmt.Errorf("不支持的分块策略: %s，支持的策略: %s", o.Strategy, strings.Join(Strategies(), ", "))
+	}
+	if o.ChunkSize < 0 || o.ChunkOverlap < 0 || o.MaxTokens < 0 {
+		return o, fmt.Errorf("分块大小、重叠长度及 token 上限不能为负数")
+	}
+	if o.ChunkSize == 0 {
+		o.ChunkSize = DefaultChunkSize
+		if o.Strategy == StrategyToken {
+			o.ChunkSize = defaultTokenChunkSize
+			if o.MaxTokens > 0 && o.MaxTokens < o.ChunkSize {
+				o.ChunkSize = o.MaxTokens
+			}
+		}
+	}
+	if o.ChunkOverlap >= o.ChunkSize {
+		return o, fmt.Errorf("分块重叠长度 %d 必须小于分块大小 %d", o.ChunkOverlap, o.ChunkSize)
+	}
+	return o, nil
+}
+
+// Split 按 opts 切分文本，返回去除首尾空白后的非空分块
+func Split(text string, opts Options) ([]Chunk, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []Chunk
+	switch opts.Strategy {
+	case StrategyFixed:
+		for _, s := range fixedWindows(text, opts.ChunkSize, opts.ChunkOverlap) {
+			chunks = append(chunks, Chunk{Text: s})
+		}
+	case StrategyRecursive:
+		for _, s := range splitRecursive(text, opts.ChunkSize, opts.ChunkOverlap, utf8.RuneCountInString) {
+			chunks = append(chunks, Chunk{Text: s})
+		}
+	case StrategyToken:
+		for _, s := range splitRecursive(text, opts.ChunkSize, opts.ChunkOverlap, EstimateTokens) {
+			chunks = append(chunks, Chunk{Text: s})
+		}
+	case StrategyMarkdown:
+		chunks = splitMarkdown(text, opts.ChunkSize, opts.ChunkOverlap)
+	}
+
+	if opts.MaxTokens > 0 {
+		chunks = limitTokens(chunks, opts.MaxTokens)
+	}
+	return chunks, nil
+}
+
+// limitTokens 将估算 token 数超过 maxTokens 的分块按 token 继续切分
+func limitTokens(chunks []Chunk, maxTokens int) []Chunk {
+	out := make([]Chunk, 0, len(chunks))
+	for _, c := range chunks {
+		if EstimateTokens(c.Text) <= maxTokens {
+			out = append(out, c)
+			continue
+		}
+		for _, s := range splitRecursive(c.Text, maxTokens, 0, EstimateTokens) {
+			out = append(out, Chunk{Text: s, Heading: c.Heading})
+		}
+	}
+	return out
+}
+
+// fixedWindows 按 rune 切分固定长度的窗口，步长为 size-overlap
+func fixedWindows(text string, size, overlap int) []string {
+	runes := []rune(text)
+	var out []string
+	for start := 0; start < len(runes); start += size - overlap {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			out = append(out, s)
+		}
+		if end == len(runes) {
+			break
+		}
+	}
+	return out
+}
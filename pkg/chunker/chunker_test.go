@@ -0,0 +1,159 @@
+package chunker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const (
+	zhText = "容器平台支持一键部署知识库。上传文档后系统会自动切分并生成向量！查询时按相似度返回最相关的片段？此外还支持多种向量数据库，包括 Milvus 和 Qdrant。"
+	enText = "Kubernetes schedules pods onto nodes. Each pod gets its own IP address! Services provide stable endpoints? Ingress exposes HTTP routes to the outside world."
+)
+
+func texts(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Text
+	}
+	return out
+}
+
+func split(t *testing.T, text string, opts Options) []string {
+	t.Helper()
+	chunks, err := Split(text, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		if !utf8.ValidString(c.Text) {
+			t.Fatalf("invalid utf-8 chunk %q", c.Text)
+		}
+	}
+	return texts(chunks)
+}
+
+func TestFixed(t *testing.T) {
+	got := split(t, zhText, Options{Strategy: StrategyFixed, ChunkSize: 30, ChunkOverlap: 8})
+	want := []string{
+		"容器平台支持一键部署知识库。上传文档后系统会自动切分并生成向",
+		"自动切分并生成向量！查询时按相似度返回最相关的片段？此外还支",
+		"的片段？此外还支持多种向量数据库，包括 Milvus 和 Q",
+		"lvus 和 Qdrant。",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks =\n%q\nwant\n%q", got, want)
+	}
+
+	got = split(t, "abcdefghij", Options{Strategy: StrategyFixed, ChunkSize: 4, ChunkOverlap: 1})
+	if want := []string{"abcd", "defg", "ghij"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks = %q, want %q", got, want)
+	}
+}
+
+func TestRecursive(t *testing.T) {
+	got := split(t, zhText, Options{ChunkSize: 40, ChunkOverlap: 20})
+	want := []string{
+		"容器平台支持一键部署知识库。上传文档后系统会自动切分并生成向量！",
+		"上传文档后系统会自动切分并生成向量！查询时按相似度返回最相关的片段？",
+		"此外还支持多种向量数据库，包括 Milvus 和 Qdrant。",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("zh chunks =\n%q\nwant\n%q", got, want)
+	}
+
+	got = split(t, enText, Options{Strategy: StrategyRecursive, ChunkSize: 40})
+	want = []string{
+		"Kubernetes schedules pods onto nodes.",
+		"Each pod gets its own IP address!",
+		"Services provide stable endpoints?",
+		"Ingress exposes HTTP routes to the",
+		"outside world.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("en chunks =\n%q\nwant\n%q", got, want)
+	}
+
+	// 段落优先于句子
+	got = split(t, "第一段第一句。第一段第二句。\n\n第二段。", Options{ChunkSize: 16})
+	if want := []string{"第一段第一句。第一段第二句。", "第二段。"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("paragraph chunks = %q, want %q", got, want)
+	}
+
+	// 没有任何分隔符时按字符切分
+	got = split(t, strings.Repeat("字", 25), Options{ChunkSize: 10})
+	if len(got) != 3 || got[2] != strings.Repeat("字", 5) {
+		t.Fatalf("rune chunks = %q", got)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	doc := "前言\n\n# 安装\n\n## 离线安装 ##\n下载安装包后解压。\n\n```bash\n# 不是标题\n\ntar xzf pkg.tgz\n```\n\n## 在线\n执行 install.sh 即可。\n# Upgrade\nRun the upgrade job. Then verify the pods."
+	chunks, err := Split(doc, Options{Strategy: StrategyMarkdown, ChunkSize: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Chunk{
+		{Text: "前言"},
+		{Text: "## 离线安装 ##\n下载安装包后解压。", Heading: "安装 > 离线安装"},
+		{Text: "```bash\n# 不是标题\n\ntar xzf pkg.tgz\n```", Heading: "安装 > 离线安装"},
+		{Text: "## 在线\n执行 install.sh 即可。", Heading: "安装 > 在线"},
+		{Text: "# Upgrade\nRun the upgrade job.", Heading: "Upgrade"},
+		{Text: "Then verify the pods.", Heading: "Upgrade"},
+	}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Fatalf("chunks =\n%q\nwant\n%q", chunks, want)
+	}
+}
+
+func TestTokenBudget(t *testing.T) {
+	got := split(t, enText, Options{Strategy: StrategyToken, ChunkSize: 20})
+	for _, s := range got {
+		if n := EstimateTokens(s); n > 20 {
+			t.Errorf("chunk %q has %d tokens", s, n)
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("token chunks = %q", got)
+	}
+
+	// 其它策略的分块超过 MaxTokens 时继续切分，中文每字一个 token
+	got = split(t, zhText, Options{Strategy: StrategyFixed, ChunkSize: 60, MaxTokens: 20})
+	for _, s := range got {
+		if n := EstimateTokens(s); n > 20 {
+			t.Errorf("chunk %q has %d tokens", s, n)
+		}
+	}
+	if strings.Join(got, "") != zhText {
+		t.Fatalf("chunks lost text: %q", got)
+	}
+
+	if n := EstimateTokens("安装 Kubernetes v1.28，然后运行 kubectl。"); n != 16 {
+		t.Errorf("EstimateTokens = %d", n)
+	}
+	for model, want := range map[string]int{"bge-m3": 8192, "nomic-embed-text:latest": 8192, "registry.local/library/all-minilm:33m": 256, "qwen2.5:7b": 0} {
+		if got := ModelTokenLimit(model); got != want {
+			t.Errorf("ModelTokenLimit(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	opts, err := Options{}.Normalize()
+	if err != nil || opts.Strategy != StrategyRecursive || opts.ChunkSize != DefaultChunkSize {
+		t.Fatalf("defaults = %+v, %v", opts, err)
+	}
+	if opts, _ := (Options{Strategy: StrategyToken, MaxTokens: 256}).Normalize(); opts.ChunkSize != 256 {
+		t.Errorf("token chunk size = %d", opts.ChunkSize)
+	}
+	for _, bad := range []Options{
+		{Strategy: "semantic"},
+		{ChunkSize: 100, ChunkOverlap: 100},
+		{ChunkSize: -1},
+	} {
+		if _, err := bad.Normalize(); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package chunker
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var mdHeading = regexp.MustCompile(`^(#{1,6})[ \t]+(.+?)(?:[ \t]+#+)?[ \t]*$`)
+
+// mdBlock 一个标题及其下的内容，pieces 为段落，代码块作为一个整体
+type mdBlock struct {
+	heading string
+	pieces  []string
+	// hasContent 标题下是否有正文，只有标题的块不生成分块
+	hasContent bool
+}
+
+// splitMarkdown 按标题将文本分为若干块，块内合并相邻段落，分块不跨越标题，超长的段落或代码块按 separators 继续切分
+func splitMarkdown(text string, size, overlap int) []Chunk {
+	var (
+		blocks []mdBlock
+		cur    mdBlock
+		para   strings.Builder
+		levels [6]string
+		fence  string
+	)
+	flushPara := func() {
+		if para.Len() > 0 {
+			cur.pieces = append(cur.pieces, para.String())
+			para.Reset()
+		}
+	}
+	flushBlock := func() {
+		flushPara()
+		if cur.hasContent {
+			blocks = append(blocks, cur)
+		}
+		cur = mdBlock{}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			cur.hasContent = true
+			para.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				flushPara()
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flushPara()
+			fence = trimmed[:3]
+			cur.hasContent = true
+			para.WriteString(line)
+		case mdHeading.MatchString(trimmed):
+			flushBlock()
+			m := mdHeading.FindStringSubmatch(trimmed)
+			level := len(m[1])
+			levels[level-1] = strings.TrimSpace(m[2])
+			for i := level; i < len(levels); i++ {
+				levels[i] = ""
+			}
+			var path []string
+			for _, title := range levels[:level] {
+				if title != "" {
+					path = append(path, title)
+				}
+			}
+			cur.heading = strings.Join(path, " > ")
+			para.WriteString(line)
+		case trimmed == "":
+			para.WriteString(line)
+			flushPara()
+		default:
+			cur.hasContent = true
+			para.WriteString(line)
+		}
+	}
+	flushBlock()
+
+	var chunks []Chunk
+	for _, b := range blocks {
+		var pieces []string
+		for _, p := range b.pieces {
+			pieces = append(pieces, atoms(p, size, utf8.RuneCountInString, separators)...)
+		}
+		for _, s := range merge(pieces, size, overlap, utf8.RuneCountInString) {
+			chunks = append(chunks, Chunk{Text: s, Heading: b.heading})
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,111 @@
+package chunker
+
+import (
+	"strings"
+)
+
+// lengthFunc 计算文本长度，按 rune 或估算的 token 数
+type lengthFunc func(string) int
+
+// separators 递归切分依次使用的分隔符组：段落、换行、句子、分句、短语、空白，最后按字符切分
+// 分隔符保留在前一个片段的末尾
+var separators = [][]string{
+	{"\n\n"},
+	{"\n"},
+	{"。", "！", "？", ". ", "! ", "? "},
+	{"；", "; "},
+	{"，", "、", ", "},
+	{" "},
+}
+
+func splitRecursive(text string, size, overlap int, length lengthFunc) []string {
+	return merge(atoms(text, size, length, separators), size, overlap, length)
+}
+
+// atoms 使用 text 中出现的第一组分隔符切分，超过 size 的片段使用后续分隔符继续切分，
+// 返回的片段均不超过 size 且首尾相接等于 text
+func atoms(text string, size int, length lengthFunc, seps [][]string) []string {
+	if length(text) <= size {
+		return []string{text}
+	}
+
+	var pieces []string
+	for len(seps) > 0 && len(pieces) <= 1 {
+		pieces = splitAfterAny(text, seps[0])
+		seps = seps[1:]
+	}
+	if len(pieces) <= 1 {
+		pieces = pieces[:0]
+		for _, r := range text {
+			pieces = append(pieces, string(r))
+		}
+	}
+
+	var out []string
+	for _, p := range pieces {
+		if length(p) <= size {
+			out = append(out, p)
+		} else {
+			out = append(out, atoms(p, size, length, seps)...)
+		}
+	}
+	return out
+}
+
+// splitAfterAny 在 seps 中任一分隔符之后切分 text
+func splitAfterAny(text string, seps []string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(text); {
+		matched := false
+		for _, sep := range seps {
+			if strings.HasPrefix(text[i:], sep) {
+				i += len(sep)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+			continue
+		}
+		out = append(out, text[start:i])
+		start = i
+	}
+	if start < len(text) {
+		out = append(out, text[start:])
+	}
+	return out
+}
+
+// merge 将片段依次合并为不超过 size 的分块，每个分块以上一个分块末尾不超过 overlap 的片段开头
+func merge(pieces []string, size, overlap int, length lengthFunc) []string {
+	var (
+		out    []string
+		window []string
+		lens   []int
+		total  int
+	)
+	flush := func() {
+		if s := strings.TrimSpace(strings.Join(window, "")); s != "" {
+			out = append(out, s)
+		}
+	}
+	for _, p := range pieces {
+		l := length(p)
+		if total+l > size && len(window) > 0 {
+			flush()
+			for len(window) > 0 && (total > overlap || total+l > size) {
+				total -= lens[0]
+				window, lens = window[1:], lens[1:]
+			}
+		}
+		window = append(window, p)
+		lens = append(lens, l)
+		total += l
+	}
+	if len(window) > 0 {
+		flush()
+	}
+	return out
+}
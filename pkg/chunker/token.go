@@ -0,0 +1,61 @@
+package chunker
+
+import (
+	"strings"
+	"unicode"
+)
+
+// modelTokenLimits 常见向量模型的最大输入 token 数，key 为去掉标签后的模型名称
+var modelTokenLimits = map[string]int{
+	"nomic-embed-text":        8192,
+	"mxbai-embed-large":       512,
+	"bge-m3":                  8192,
+	"bge-large":               512,
+	"all-minilm":              256,
+	"snowflake-arctic-embed":  512,
+	"snowflake-arctic-embed2": 8192,
+	"paraphrase-multilingual": 128,
+	"granite-embedding":       512,
+}
+
+// ModelTokenLimit 返回向量模型的最大输入 token 数，未知模型返回 0
+func ModelTokenLimit(model string) int {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	return modelTokenLimits[name]
+}
+
+// EstimateTokens 估算文本的 token 数，不依赖具体模型的分词器，结果偏保守：
+// 中日韩字符每字计 1 个，连续的字母数字每 4 个字符计 1 个，其余标点符号每个计 1 个
+func EstimateTokens(s string) int {
+	tokens, word := 0, 0
+	flush := func() {
+		tokens += (word + 3) / 4
+		word = 0
+	}
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			flush()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/ai"
+)
+
+type AIGetter interface {
+	AI() AIService
+}
+
+type AIService interface {
+	PullJob() ai.PullJobService
+	Catalog() ai.CatalogService
+	Usage() ai.UsageService
+	Quota() ai.QuotaService
+	Conversation() ai.ConversationService
+	PromptTemplate() ai.PromptTemplateService
+	Benchmark() ai.BenchmarkService
+	ModelRoute() ai.ModelRouteService
+	Agent() ai.AgentService
+	Guardrail() ai.GuardrailService
+	EmbeddingCache() ai.EmbeddingCacheService
+	KnowledgeChunking() ai.KnowledgeChunkingService
+	IngestJob() ai.IngestJobService
+}
+
+type aiService struct {
+	factory dao.ShareDaoFactory
+}
+
+func (a *aiService) PullJob() ai.PullJobService {
+	return ai.NewPullJobService(a.factory)
+}
+
+func (a *aiService) Catalog() ai.CatalogService {
+	return ai.NewCatalogService(a.factory)
+}
+
+func (a *aiService) Usage() ai.UsageService {
+	return ai.NewUsageService(a.factory)
+}
+
+func (a *aiService) Quota() ai.QuotaService {
+	return ai.NewQuotaService(a.factory)
+}
+
+func (a *aiService) Conversation() ai.ConversationService {
+	return ai.NewConversationService(a.factory)
+}
+
+func (a *aiService) PromptTemplate() ai.PromptTemplateService {
+	return ai.NewPromptTemplateService(a.factory)
+}
+
+func (a *aiService) Benchmark() ai.BenchmarkService {
+	return ai.NewBenchmarkService(a.factory)
+}
+
+func (a *aiService) ModelRoute() ai.ModelRouteService {
+	return ai.NewModelRouteService(a.factory)
+}
+
+func (a *aiService) Agent() ai.AgentService {
+	return ai.NewAgentService()
+}
+
+func (a *aiService) Guardrail() ai.GuardrailService {
+	return ai.NewGuardrailService(a.factory)
+}
+
+func (a *aiService) EmbeddingCache() ai.EmbeddingCacheService {
+	return ai.NewEmbeddingCacheService(a.factory)
+}
+
+func (a *aiService) KnowledgeChunking() ai.KnowledgeChunkingService {
+	return ai.NewKnowledgeChunkingService(a.factory)
+}
+
+func (a *aiService) IngestJob() ai.IngestJobService {
+	return ai.NewIngestJobService(a.factory)
+}
+
+func NewAIService(factory dao.ShareDaoFactory) AIService {
+	return &aiService{factory: factory}
+}
@@ -0,0 +1,214 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/mcpclient"
+)
+
+const (
+	// defaultAgentSteps 未指定时的最大推理轮数
+	defaultAgentSteps = 5
+	// agentToolResultLimit 回填给模型的工具结果最大字符数，避免撑满上下文
+	agentToolResultLimit = 8000
+)
+
+// agentToolTimeout 单次工具调用的超时时间
+var agentToolTimeout = 60 * time.Second
+
+// agentToolClient 智能体使用的 MCP 客户端，由 mcpclient.Client 实现
+type agentToolClient interface {
+	ListTools(ctx context.Context) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error)
+}
+
+var (
+	agentChat   = kube.Ollama.Chat
+	agentClient = func(name string) (agentToolClient, error) {
+		return mcpclient.ClientByName(name)
+	}
+)
+
+// agentStepLimitPrompt 达到最大轮数后要求模型直接作答
+const agentStepLimitPrompt = "已达到工具调用次数上限，请不要再调用工具，根据已有信息直接给出最终回答。"
+
+type AgentService interface {
+	// Run 将选定的 MCP 工具声明给模型，循环执行模型返回的工具调用并回填结果，直到模型给出最终回答或达到最大轮数
+	Run(ctx context.Context, in *dto.AIAgentRunInput) (dto.AIAgentRunOut, error)
+}
+
+func NewAgentService() AgentService {
+	return &agentService{}
+}
+
+type agentService struct{}
+
+// agentTool 声明给模型的工具名称对应的 MCP server 与工具
+type agentTool struct {
+	server string
+	tool   string
+	client agentToolClient
+}
+
+func (a *agentService) Run(ctx context.Context, in *dto.AIAgentRunInput) (dto.AIAgentRunOut, error) {
+	registry, tools, err := a.tools(ctx, in.ToolSets)
+	if err != nil {
+		return dto.AIAgentRunOut{}, err
+	}
+	maxSteps := in.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultAgentSteps
+	}
+
+	out := dto.AIAgentRunOut{Trace: []dto.AIAgentToolTrace{}}
+	messages := append([]kubeDto.OllamaChatMessage(nil), in.Messages...)
+	toolCtx := kube.WithInferenceOptions(ctx, kubeDto.OllamaInferenceOptions{Options: in.Options, Tools: tools})
+	for out.Steps < maxSteps {
+		out.Steps++
+		msg, err := a.chat(toolCtx, in, messages)
+		if err != nil {
+			return dto.AIAgentRunOut{}, fmt.Errorf("第 %d 轮调用模型失败: %v", out.Steps, err)
+		}
+		messages = append(messages, msg)
+		if len(msg.ToolCalls) == 0 {
+			out.Answer = msg.Content
+			out.Finished = true
+			out.Messages = messages
+			return out, nil
+		}
+		for _, call := range msg.ToolCalls {
+			trace := a.callTool(ctx, registry, call)
+			trace.Step = out.Steps
+			out.Trace = append(out.Trace, trace)
+			content := trace.Result
+			if trace.Error != "" {
+				content = "工具调用失败: " + trace.Error
+			}
+			messages = append(messages, kubeDto.OllamaChatMessage{Role: "tool", Content: content, ToolName: call.Function.Name})
+		}
+	}
+
+	// 达到最大轮数，不再声明工具，让模型根据已有结果作答
+	messages = append(messages, kubeDto.OllamaChatMessage{Role: "user", Content: agentStepLimitPrompt})
+	msg, err := a.chat(kube.WithInferenceOptions(ctx, kubeDto.OllamaInferenceOptions{Options: in.Options}), in, messages)
+	if err != nil {
+		return dto.AIAgentRunOut{}, fmt.Errorf("生成最终回答失败: %v", err)
+	}
+	out.Answer = msg.Content
+	out.Messages = append(messages, msg)
+	return out, nil
+}
+
+func (a *agentService) chat(ctx context.Context, in *dto.AIAgentRunInput, messages []kubeDto.OllamaChatMessage) (kubeDto.OllamaChatMessage, error) {
+	result, err := agentChat(ctx, in.OllamaTarget(), in.Model, messages, false)
+	if err != nil {
+		return kubeDto.OllamaChatMessage{}, err
+	}
+	return kube.ChatMessage(result)
+}
+
+// tools 获取选定的 MCP 工具，不同 server 的工具重名时以 server__tool 区分
+func (a *agentService) tools(ctx context.Context, sets []dto.AIAgentToolSet) (map[string]agentTool, []kubeDto.OllamaTool, error) {
+	type candidate struct {
+		agentTool
+		description string
+		schema      interface{}
+	}
+	var candidates []candidate
+	counts := make(map[string]int)
+	for _, set := range sets {
+		client, err := agentClient(set.ServerName)
+		if err != nil {
+			return nil, nil, err
+		}
+		list, err := client.ListTools(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取 %s 的工具列表失败: %v", set.ServerName, err)
+		}
+		wanted := make(map[string]bool, len(set.Tools))
+		for _, name := range set.Tools {
+			wanted[name] = false
+		}
+		for _, tool := range list.Tools {
+			// 未指定工具时使用该 server 的全部工具
+			if _, ok := wanted[tool.Name]; len(set.Tools) > 0 && !ok {
+				continue
+			}
+			wanted[tool.Name] = true
+			counts[tool.Name]++
+			candidates = append(candidates, candidate{
+				agentTool:   agentTool{server: set.ServerName, tool: tool.Name, client: client},
+				description: tool.Description,
+				schema:      tool.InputSchema,
+			})
+		}
+		for name, found := range wanted {
+			if !found {
+				return nil, nil, fmt.Errorf("MCP server %s 没有名为 %s 的工具", set.ServerName, name)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("选定的 MCP server 没有可用的工具")
+	}
+
+	registry := make(map[string]agentTool, len(candidates))
+	tools := make([]kubeDto.OllamaTool, 0, len(candidates))
+	for _, c := range candidates {
+		name := c.tool
+		if counts[name] > 1 {
+			name = c.server + "__" + c.tool
+		}
+		registry[name] = c.agentTool
+		tools = append(tools, kubeDto.OllamaTool{
+			Type:     "function",
+			Function: kubeDto.OllamaToolFunction{Name: name, Description: c.description, Parameters: c.schema},
+		})
+	}
+	return registry, tools, nil
+}
+
+// callTool 执行模型返回的工具调用，失败时记录错误并回填给模型，由模型决定是否重试
+func (a *agentService) callTool(ctx context.Context, registry map[string]agentTool, call kubeDto.OllamaToolCall) dto.AIAgentToolTrace {
+	trace := dto.AIAgentToolTrace{Tool: call.Function.Name, Arguments: call.Function.Arguments}
+	tool, ok := registry[call.Function.Name]
+	if !ok {
+		trace.Error = fmt.Sprintf("工具 %s 不存在", call.Function.Name)
+		return trace
+	}
+	trace.Server, trace.Tool = tool.server, tool.tool
+
+	callCtx, cancel := context.WithTimeout(ctx, agentToolTimeout)
+	defer cancel()
+	start := time.Now()
+	result, err := tool.client.CallTool(callCtx, tool.tool, call.Function.Arguments)
+	trace.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	text := mcpclient.TextContent(result.Content)
+	if text == "" && result.StructuredContent != nil {
+		data, _ := json.Marshal(result.StructuredContent)
+		text = string(data)
+	}
+	text = truncateRunes(text, agentToolResultLimit)
+	// 工具执行失败时 MCP 通过 isError 返回错误信息
+	if result.IsError {
+		if text == "" {
+			text = "工具返回错误"
+		}
+		trace.Error = text
+		return trace
+	}
+	trace.Result = text
+	return trace
+}
@@ -0,0 +1,226 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+)
+
+// fakeToolClient 模拟 MCP server，calls 记录工具调用的名称
+type fakeToolClient struct {
+	tools map[string]func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error)
+	calls []string
+}
+
+func (f *fakeToolClient) ListTools(ctx context.Context) (*mcp.ListToolsResult, error) {
+	out := &mcp.ListToolsResult{}
+	for name := range f.tools {
+		out.Tools = append(out.Tools, &mcp.Tool{Name: name, Description: name})
+	}
+	return out, nil
+}
+
+func (f *fakeToolClient) CallTool(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	f.calls = append(f.calls, name)
+	return f.tools[name](ctx, args)
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+}
+
+// chatReply 构造 Ollama chat 响应，tools 为模型返回的工具调用
+func chatReply(t *testing.T, content string, tools ...string) interface{} {
+	t.Helper()
+	calls := make([]map[string]interface{}, len(tools))
+	for i, name := range tools {
+		calls[i] = map[string]interface{}{"function": map[string]interface{}{"name": name, "arguments": map[string]interface{}{"namespace": "ai"}}}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"message": map[string]interface{}{"role": "assistant", "content": content, "tool_calls": calls}})
+	var resp map[string]interface{}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// stubAgent 使用 replies 依次作为模型的回复，返回每次调用模型时的消息列表
+func stubAgent(t *testing.T, client *fakeToolClient, replies ...interface{}) *[][]kubeDto.OllamaChatMessage {
+	t.Helper()
+	oldChat, oldClient := agentChat, agentClient
+	t.Cleanup(func() { agentChat, agentClient = oldChat, oldClient })
+	var requests [][]kubeDto.OllamaChatMessage
+	agentChat = func(ctx context.Context, target kubeDto.OllamaTarget, model string, messages []kubeDto.OllamaChatMessage, stream bool) (interface{}, error) {
+		requests = append(requests, append([]kubeDto.OllamaChatMessage(nil), messages...))
+		if len(requests) > len(replies) {
+			t.Fatalf("unexpected chat call %d", len(requests))
+		}
+		return replies[len(requests)-1], nil
+	}
+	agentClient = func(name string) (agentToolClient, error) {
+		if name != "k8s" {
+			return nil, errors.New("server not found")
+		}
+		return client, nil
+	}
+	return &requests
+}
+
+func agentInput(maxSteps int, tools ...string) *dto.AIAgentRunInput {
+	return &dto.AIAgentRunInput{
+		Model:    "qwen3",
+		Messages: []kubeDto.OllamaChatMessage{{Role: "user", Content: "ai 命名空间有几个 Pod？"}},
+		ToolSets: []dto.AIAgentToolSet{{ServerName: "k8s", Tools: tools}},
+		MaxSteps: maxSteps,
+	}
+}
+
+func TestAgentToolLoop(t *testing.T) {
+	client := &fakeToolClient{tools: map[string]func(context.Context, map[string]any) (*mcp.CallToolResult, error){
+		"list_pods": func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+			if args["namespace"] != "ai" {
+				t.Errorf("args = %v", args)
+			}
+			return textResult("ollama-0, ollama-1"), nil
+		},
+		"get_node": func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+			result := textResult("nodes \"ai\" not found")
+			result.IsError = true
+			return result, nil
+		},
+		"delete_pod": func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+			t.Error("tool not selected should not be called")
+			return nil, nil
+		},
+	}}
+	requests := stubAgent(t, client,
+		chatReply(t, "", "list_pods", "missing", "get_node"),
+		chatReply(t, "共有 2 个 Pod"),
+	)
+
+	out, err := NewAgentService().Run(context.Background(), agentInput(0, "list_pods", "get_node"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Finished || out.Answer != "共有 2 个 Pod" || out.Steps != 2 {
+		t.Fatalf("out = %+v", out)
+	}
+	if len(client.calls) != 2 || len(out.Trace) != 3 {
+		t.Fatalf("calls = %v, trace = %+v", client.calls, out.Trace)
+	}
+	if trace := out.Trace[0]; trace.Step != 1 || trace.Server != "k8s" || trace.Result != "ollama-0, ollama-1" {
+		t.Errorf("trace = %+v", trace)
+	}
+	if out.Trace[1].Error == "" {
+		t.Error("unknown tool should be reported to the model")
+	}
+	// 工具通过 isError 返回的错误同样作为失败回填
+	if trace := out.Trace[2]; trace.Error != `nodes "ai" not found` || trace.Result != "" {
+		t.Errorf("error result trace = %+v", trace)
+	}
+	// 第二轮请求包含模型的工具调用及工具结果
+	second := (*requests)[1]
+	if len(second) != 5 || second[2].Role != "tool" || second[2].ToolName != "list_pods" || !strings.HasPrefix(second[3].Content, "工具调用失败") {
+		t.Errorf("second request = %+v", second)
+	}
+	if tool := second[4]; tool.ToolName != "get_node" || tool.Content != `工具调用失败: nodes "ai" not found` {
+		t.Errorf("error result message = %+v", tool)
+	}
+	if len(out.Messages) != 6 {
+		t.Errorf("messages = %+v", out.Messages)
+	}
+
+	if _, err := NewAgentService().Run(context.Background(), agentInput(0, "scale")); err == nil {
+		t.Error("unknown tool in tool set should be rejected")
+	}
+}
+
+func TestAgentStepLimit(t *testing.T) {
+	client := &fakeToolClient{tools: map[string]func(context.Context, map[string]any) (*mcp.CallToolResult, error){
+		"list_pods": func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+			return textResult("ollama-0"), nil
+		},
+	}}
+	requests := stubAgent(t, client,
+		chatReply(t, "", "list_pods"),
+		chatReply(t, "", "list_pods"),
+		chatReply(t, "根据已有信息，共有 1 个 Pod"),
+	)
+
+	out, err := NewAgentService().Run(context.Background(), agentInput(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Finished || out.Steps != 2 || len(client.calls) != 2 {
+		t.Fatalf("out = %+v, calls = %v", out, client.calls)
+	}
+	// 达到最大轮数后要求模型直接作答
+	last := (*requests)[2]
+	if len(*requests) != 3 || last[len(last)-1].Content != agentStepLimitPrompt {
+		t.Fatalf("final request = %+v", last)
+	}
+	if out.Answer != "根据已有信息，共有 1 个 Pod" {
+		t.Errorf("answer = %q", out.Answer)
+	}
+}
+
+func TestAgentToolTimeoutAndTruncate(t *testing.T) {
+	oldTimeout := agentToolTimeout
+	agentToolTimeout = 20 * time.Millisecond
+	defer func() { agentToolTimeout = oldTimeout }()
+
+	client := &fakeToolClient{tools: map[string]func(context.Context, map[string]any) (*mcp.CallToolResult, error){
+		"get_logs": func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+			return textResult(strings.Repeat("日志", agentToolResultLimit)), nil
+		},
+		"exec": func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}}
+	requests := stubAgent(t, client,
+		chatReply(t, "", "get_logs", "exec"),
+		chatReply(t, "完成"),
+	)
+
+	out, err := NewAgentService().Run(context.Background(), agentInput(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Trace) != 2 {
+		t.Fatalf("trace = %+v", out.Trace)
+	}
+	logs, exec := out.Trace[0], out.Trace[1]
+	if n := utf8.RuneCountInString(logs.Result); n != agentToolResultLimit+len("...") {
+		t.Errorf("truncated result has %d runes", n)
+	}
+	if !strings.Contains(exec.Error, context.DeadlineExceeded.Error()) {
+		t.Errorf("exec trace = %+v", exec)
+	}
+	if tool := (*requests)[1][3]; tool.ToolName != "exec" || !strings.HasPrefix(tool.Content, "工具调用失败") {
+		t.Errorf("tool message = %+v", tool)
+	}
+}
+
+func TestAgentChatMessage(t *testing.T) {
+	msg, err := kube.ChatMessage(chatReply(t, "", "list_pods"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].Function.Name != "list_pods" || msg.ToolCalls[0].Function.Arguments["namespace"] != "ai" {
+		t.Errorf("msg = %+v", msg)
+	}
+	if _, err := kube.ChatMessage(map[string]interface{}{}); err == nil {
+		t.Error("expected error without message")
+	}
+}
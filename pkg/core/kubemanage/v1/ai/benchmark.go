@@ -0,0 +1,380 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// defaultBenchmarkConcurrency 未指定并发数时同时进行的请求数
+const defaultBenchmarkConcurrency = 4
+
+// errBenchmarkCanceled 用户主动取消任务
+var errBenchmarkCanceled = errors.New("benchmark canceled")
+
+// benchmarkCancels 正在运行的基准测试任务，key 为任务 InstanceID，value 为 context.CancelCauseFunc
+var benchmarkCancels sync.Map
+
+type BenchmarkService interface {
+	// CreateBenchmark 创建任务并在后台运行，用量归属于创建人，每次调用模型都计入创建人的配额
+	CreateBenchmark(ctx context.Context, in *dto.AIBenchmarkInput, userID int, authorityID uint, creator string) (model.AIBenchmark, error)
+	// GetBenchmark 获取任务、各目标的汇总指标及按提示词对比的结果，只能获取 userID 创建的任务，下同
+	GetBenchmark(ctx context.Context, instanceID string, userID int) (dto.AIBenchmarkDetailOut, error)
+	PageBenchmark(ctx context.Context, in *dto.PageListAIBenchmarkInput) (dto.PageAIBenchmarkOut, error)
+	CancelBenchmark(ctx context.Context, instanceID string, userID int) error
+	DeleteBenchmark(ctx context.Context, instanceID string, userID int) error
+	// ExportBenchmark 导出任务结果，format 为 json 或 csv
+	ExportBenchmark(ctx context.Context, instanceID, format string, userID int) ([]byte, error)
+	// FailInterruptedJobs 将服务重启前未完成的任务标记为失败
+	FailInterruptedJobs(ctx context.Context) error
+}
+
+func NewBenchmarkService(factory dao.ShareDaoFactory) BenchmarkService {
+	return &benchmarkService{factory: factory, log: logger.New(logger.LG)}
+}
+
+type benchmarkService struct {
+	factory dao.ShareDaoFactory
+	log     logger.Logger
+}
+
+func (b *benchmarkService) CreateBenchmark(ctx context.Context, in *dto.AIBenchmarkInput, userID int, authorityID uint, creator string) (model.AIBenchmark, error) {
+	targets, err := json.Marshal(in.Targets)
+	if err != nil {
+		return model.AIBenchmark{}, err
+	}
+	prompts, err := json.Marshal(in.Prompts)
+	if err != nil {
+		return model.AIBenchmark{}, err
+	}
+	concurrency := in.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBenchmarkConcurrency
+	}
+	job := model.AIBenchmark{
+		InstanceID:   utils.GetSnowflakeID(),
+		Name:         in.Name,
+		Targets:      string(targets),
+		Prompts:      string(prompts),
+		SystemPrompt: in.SystemPrompt,
+		Concurrency:  concurrency,
+		Status:       model.BenchmarkPending,
+		Total:        len(in.Targets) * len(in.Prompts),
+		UserID:       userID,
+		Creator:      creator,
+	}
+	if err := b.factory.AI().Benchmark().Save(ctx, &job); err != nil {
+		return model.AIBenchmark{}, err
+	}
+
+	jobCtx, cancel := context.WithCancelCause(kube.WithUsageCaller(runtime.SystemContext, kube.UsageCaller{
+		UserID:   userID,
+		UserName: creator,
+		Source:   "benchmark",
+	}))
+	benchmarkCancels.Store(job.InstanceID, cancel)
+	acquire := func(ctx context.Context) (func(), error) {
+		return NewQuotaService(b.factory).Wait(ctx, userID, authorityID)
+	}
+	go b.run(jobCtx, job, in.Targets, in.Prompts, acquire)
+	return job, nil
+}
+
+func (b *benchmarkService) GetBenchmark(ctx context.Context, instanceID string, userID int) (dto.AIBenchmarkDetailOut, error) {
+	job, err := b.find(ctx, instanceID, userID)
+	if err != nil {
+		return dto.AIBenchmarkDetailOut{}, err
+	}
+	var targets []dto.AIBenchmarkTarget
+	if err := json.Unmarshal([]byte(job.Targets), &targets); err != nil {
+		return dto.AIBenchmarkDetailOut{}, fmt.Errorf("解析测试目标失败: %v", err)
+	}
+	var prompts []string
+	if err := json.Unmarshal([]byte(job.Prompts), &prompts); err != nil {
+		return dto.AIBenchmarkDetailOut{}, fmt.Errorf("解析提示词失败: %v", err)
+	}
+	results, err := b.factory.AI().Benchmark().ListResults(ctx, instanceID)
+	if err != nil {
+		return dto.AIBenchmarkDetailOut{}, err
+	}
+
+	rows := make([]dto.AIBenchmarkPromptRow, len(prompts))
+	for i, prompt := range prompts {
+		rows[i] = dto.AIBenchmarkPromptRow{PromptIndex: i, Prompt: prompt, Results: make([]*model.AIBenchmarkResult, len(targets))}
+	}
+	byTarget := make([][]model.AIBenchmarkResult, len(targets))
+	for i := range results {
+		r := &results[i]
+		if r.PromptIndex < len(rows) && r.TargetIndex < len(targets) {
+			rows[r.PromptIndex].Results[r.TargetIndex] = r
+			byTarget[r.TargetIndex] = append(byTarget[r.TargetIndex], *r)
+		}
+	}
+	summary := make([]dto.AIBenchmarkTargetSummary, len(targets))
+	for i, target := range targets {
+		summary[i] = summarize(i, target, byTarget[i])
+	}
+	return dto.AIBenchmarkDetailOut{Benchmark: job, Targets: targets, Summary: summary, Rows: rows}, nil
+}
+
+func (b *benchmarkService) PageBenchmark(ctx context.Context, in *dto.PageListAIBenchmarkInput) (dto.PageAIBenchmarkOut, error) {
+	list, total, err := b.factory.AI().Benchmark().PageList(ctx, in)
+	if err != nil {
+		return dto.PageAIBenchmarkOut{}, err
+	}
+	return dto.PageAIBenchmarkOut{Total: total, List: list, Page: in.GetPage(), PageSize: in.GetPageSize()}, nil
+}
+
+func (b *benchmarkService) CancelBenchmark(ctx context.Context, instanceID string, userID int) error {
+	job, err := b.find(ctx, instanceID, userID)
+	if err != nil {
+		return err
+	}
+	if job.IsFinished() {
+		return fmt.Errorf("任务已结束，当前状态为 %s", job.Status)
+	}
+	cancel, ok := benchmarkCancels.Load(instanceID)
+	if !ok {
+		// 任务不在当前实例中运行（例如服务已重启），直接标记为取消
+		b.finish(instanceID, model.BenchmarkCanceled, "任务已取消")
+		return nil
+	}
+	cancel.(context.CancelCauseFunc)(errBenchmarkCanceled)
+	return nil
+}
+
+func (b *benchmarkService) DeleteBenchmark(ctx context.Context, instanceID string, userID int) error {
+	job, err := b.find(ctx, instanceID, userID)
+	if err != nil {
+		return err
+	}
+	if !job.IsFinished() {
+		return errors.New("任务运行中，请先取消任务")
+	}
+	return b.factory.AI().Benchmark().Delete(ctx, model.AIBenchmark{InstanceID: instanceID})
+}
+
+func (b *benchmarkService) ExportBenchmark(ctx context.Context, instanceID, format string, userID int) ([]byte, error) {
+	detail, err := b.GetBenchmark(ctx, instanceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if format == "json" {
+		return json.MarshalIndent(detail, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"target_index", "label", "model", "namespace", "deployment", "pod_name", "prompt_index", "prompt",
+		"latency_ms", "load_ms", "eval_ms", "prompt_tokens", "completion_tokens", "tokens_per_second", "error", "output"})
+	for _, row := range detail.Rows {
+		for i, r := range row.Results {
+			if r == nil {
+				continue
+			}
+			_ = w.Write([]string{
+				strconv.Itoa(i), targetLabel(detail.Targets[i]), r.Model, r.Namespace, r.Deployment, r.PodName,
+				strconv.Itoa(row.PromptIndex), row.Prompt,
+				strconv.FormatInt(r.Latency, 10), strconv.FormatInt(r.LoadDuration, 10), strconv.FormatInt(r.EvalDuration, 10),
+				strconv.FormatInt(r.PromptTokens, 10), strconv.FormatInt(r.CompletionTokens, 10),
+				strconv.FormatFloat(r.TokensPerSecond, 'f', 2, 64), r.Error, r.Output,
+			})
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (b *benchmarkService) FailInterruptedJobs(ctx context.Context) error {
+	now := time.Now()
+	return b.factory.AI().Benchmark().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status in ?", []string{model.BenchmarkPending, model.BenchmarkRunning})
+	}, &model.AIBenchmark{Status: model.BenchmarkFailed, Message: "服务重启，任务中断", FinishedAt: &now})
+}
+
+func (b *benchmarkService) find(ctx context.Context, instanceID string, userID int) (model.AIBenchmark, error) {
+	return b.factory.AI().Benchmark().Find(ctx, model.AIBenchmark{InstanceID: instanceID, UserID: userID})
+}
+
+// run 按并发数将每条提示词发送给每个目标，逐条保存结果，每次调用前通过 acquire 获取配额
+func (b *benchmarkService) run(ctx context.Context, job model.AIBenchmark, targets []dto.AIBenchmarkTarget, prompts []string, acquire func(ctx context.Context) (func(), error)) {
+	defer benchmarkCancels.Delete(job.InstanceID)
+
+	b.update(job.InstanceID, &model.AIBenchmark{Status: model.BenchmarkRunning})
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	sem := make(chan struct{}, job.Concurrency)
+	for ti := range targets {
+		for pi := range prompts {
+			select {
+			case <-ctx.Done():
+			case sem <- struct{}{}:
+				wg.Add(1)
+				go func(ti, pi int) {
+					defer wg.Done()
+					defer func() { <-sem }()
+					result := b.call(ctx, job, ti, targets[ti], pi, prompts[pi], acquire)
+					if ctx.Err() != nil {
+						// 取消导致的失败不记录
+						return
+					}
+					if result.Error != "" {
+						mu.Lock()
+						failed++
+						mu.Unlock()
+					}
+					if err := b.factory.AI().Benchmark().SaveResult(context.Background(), &result); err != nil {
+						b.log.ErrorWithErr("save benchmark result failed", err)
+					}
+					if err := b.factory.AI().Benchmark().IncrProgress(context.Background(), job.InstanceID, result.Error != ""); err != nil {
+						b.log.ErrorWithErr("update benchmark progress failed", err)
+					}
+				}(ti, pi)
+			}
+		}
+	}
+	wg.Wait()
+
+	switch {
+	case errors.Is(context.Cause(ctx), errBenchmarkCanceled):
+		b.finish(job.InstanceID, model.BenchmarkCanceled, "任务已取消")
+	case ctx.Err() != nil:
+		b.finish(job.InstanceID, model.BenchmarkFailed, ctx.Err().Error())
+	case failed == job.Total:
+		b.finish(job.InstanceID, model.BenchmarkFailed, "全部请求失败")
+	case failed > 0:
+		b.finish(job.InstanceID, model.BenchmarkSuccess, fmt.Sprintf("%d 个请求失败", failed))
+	default:
+		b.finish(job.InstanceID, model.BenchmarkSuccess, "")
+	}
+}
+
+// call 调用一次模型，根据响应中的 eval_count、eval_duration 计算生成速度
+// 请求频率或并发数超限时等待名额，token 额度用完时该请求失败
+func (b *benchmarkService) call(ctx context.Context, job model.AIBenchmark, ti int, target dto.AIBenchmarkTarget, pi int, prompt string, acquire func(ctx context.Context) (func(), error)) model.AIBenchmarkResult {
+	result := model.AIBenchmarkResult{
+		BenchmarkID: job.InstanceID,
+		TargetIndex: ti,
+		PromptIndex: pi,
+		Namespace:   target.NameSpace,
+		Deployment:  target.Deployment,
+		PodName:     target.PodName,
+		Model:       target.Model,
+	}
+	var messages []kubeDto.OllamaChatMessage
+	if job.SystemPrompt != "" {
+		messages = append(messages, kubeDto.OllamaChatMessage{Role: "system", Content: job.SystemPrompt})
+	}
+	messages = append(messages, kubeDto.OllamaChatMessage{Role: "user", Content: prompt})
+
+	release, err := acquire(ctx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer release()
+
+	start := time.Now()
+	data, err := kube.Ollama.Chat(ctx, target.OllamaTarget, target.Model, messages, false)
+	result.Latency = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	resp, _ := data.(map[string]interface{})
+	if msg, ok := resp["message"].(map[string]interface{}); ok {
+		result.Output, _ = msg["content"].(string)
+	}
+	evalCount, _ := resp["eval_count"].(float64)
+	evalDuration, _ := resp["eval_duration"].(float64)
+	loadDuration, _ := resp["load_duration"].(float64)
+	promptCount, _ := resp["prompt_eval_count"].(float64)
+	result.PromptTokens = int64(promptCount)
+	result.CompletionTokens = int64(evalCount)
+	result.EvalDuration = int64(evalDuration) / int64(time.Millisecond)
+	result.LoadDuration = int64(loadDuration) / int64(time.Millisecond)
+	if evalDuration > 0 {
+		result.TokensPerSecond = evalCount / (evalDuration / float64(time.Second))
+	}
+	return result
+}
+
+func (b *benchmarkService) update(instanceID string, in *model.AIBenchmark) {
+	// 任务上下文可能已被取消，使用独立的 context 写库
+	if err := b.factory.AI().Benchmark().Updates(context.Background(), func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", instanceID)
+	}, in); err != nil {
+		b.log.ErrorWithErr("update benchmark failed", err)
+	}
+}
+
+func (b *benchmarkService) finish(instanceID, status, message string) {
+	now := time.Now()
+	b.update(instanceID, &model.AIBenchmark{Status: status, Message: message, FinishedAt: &now})
+}
+
+// summarize 汇总单个目标的结果，耗时及速度只统计成功的请求
+func summarize(index int, target dto.AIBenchmarkTarget, results []model.AIBenchmarkResult) dto.AIBenchmarkTargetSummary {
+	summary := dto.AIBenchmarkTargetSummary{TargetIndex: index, AIBenchmarkTarget: target, Requests: len(results)}
+	var latencies []int64
+	var latencySum int64
+	var speedSum float64
+	for _, r := range results {
+		if r.Error != "" {
+			summary.Errors++
+			continue
+		}
+		latencies = append(latencies, r.Latency)
+		latencySum += r.Latency
+		speedSum += r.TokensPerSecond
+		summary.PromptTokens += r.PromptTokens
+		summary.CompletionTokens += r.CompletionTokens
+	}
+	if n := len(latencies); n > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		summary.AvgLatency = latencySum / int64(n)
+		summary.P50Latency = percentile(latencies, 0.5)
+		summary.P95Latency = percentile(latencies, 0.95)
+		summary.AvgTokensPerSecond = speedSum / float64(n)
+	}
+	return summary
+}
+
+// percentile 计算已排序数据的百分位数（最近秩法）
+func percentile(sorted []int64, q float64) int64 {
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func targetLabel(target dto.AIBenchmarkTarget) string {
+	if target.Label != "" {
+		return target.Label
+	}
+	return target.Model
+}
@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// catalogSyncCreator 同步任务创建拉取任务时使用的创建人
+const catalogSyncCreator = "catalog-sync"
+
+var (
+	// catalogDrifts 最近一次同步得到的模型差异，key 为 namespace/deployment
+	catalogDrifts sync.Map
+	// catalogSyncMu 避免定时同步与手动同步同时为同一模型创建拉取任务
+	catalogSyncMu sync.Mutex
+)
+
+type CatalogService interface {
+	AddModel(ctx context.Context, in *dto.AIModelCatalogInput, creator string) (model.AIModelCatalog, error)
+	RemoveModel(ctx context.Context, instanceID string) error
+	ListModels(ctx context.Context, in *dto.AIModelCatalogListInput) ([]model.AIModelCatalog, error)
+	// Sync 同步单个部署，prune 为 true 时删除副本上未声明的模型
+	Sync(ctx context.Context, in *dto.AIModelCatalogSyncInput, creator string) (dto.AIModelCatalogSyncOut, error)
+	// Reconcile 同步所有声明了模型目录的部署，只拉取缺失的模型
+	Reconcile(ctx context.Context) error
+	// Drift 返回部署最近一次同步得到的模型差异，未同步过时返回 nil
+	Drift(namespace, deployment string) *kubeDto.OllamaModelDrift
+}
+
+func NewCatalogService(factory dao.ShareDaoFactory) CatalogService {
+	return &catalogService{factory: factory, pullJob: NewPullJobService(factory), log: logger.New(logger.LG)}
+}
+
+type catalogService struct {
+	factory dao.ShareDaoFactory
+	pullJob PullJobService
+	log     logger.Logger
+}
+
+func (c *catalogService) AddModel(ctx context.Context, in *dto.AIModelCatalogInput, creator string) (model.AIModelCatalog, error) {
+	search := model.AIModelCatalog{Namespace: in.NameSpace, Deployment: in.Deployment, ModelName: in.ModelName}
+	if _, err := c.factory.AI().ModelCatalog().Find(ctx, search); err == nil {
+		return model.AIModelCatalog{}, fmt.Errorf("模型 %s 已在 %s/%s 的模型目录中", in.ModelName, in.NameSpace, in.Deployment)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.AIModelCatalog{}, err
+	}
+
+	entry := search
+	entry.InstanceID = utils.GetSnowflakeID()
+	entry.Creator = creator
+	if err := c.factory.AI().ModelCatalog().Save(ctx, &entry); err != nil {
+		return model.AIModelCatalog{}, err
+	}
+	return entry, nil
+}
+
+func (c *catalogService) RemoveModel(ctx context.Context, instanceID string) error {
+	entry, err := c.factory.AI().ModelCatalog().Find(ctx, model.AIModelCatalog{InstanceID: instanceID})
+	if err != nil {
+		return err
+	}
+	if err := c.factory.AI().ModelCatalog().Delete(ctx, model.AIModelCatalog{InstanceID: instanceID}, true); err != nil {
+		return err
+	}
+	// 目录变更后之前的差异已失效，等待下次同步
+	catalogDrifts.Delete(catalogKey(entry.Namespace, entry.Deployment))
+	return nil
+}
+
+func (c *catalogService) ListModels(ctx context.Context, in *dto.AIModelCatalogListInput) ([]model.AIModelCatalog, error) {
+	return c.factory.AI().ModelCatalog().FindList(ctx, model.AIModelCatalog{Namespace: in.NameSpace, Deployment: in.Deployment})
+}
+
+func (c *catalogService) Sync(ctx context.Context, in *dto.AIModelCatalogSyncInput, creator string) (dto.AIModelCatalogSyncOut, error) {
+	entries, err := c.factory.AI().ModelCatalog().FindList(ctx, model.AIModelCatalog{Namespace: in.NameSpace, Deployment: in.Deployment})
+	if err != nil {
+		return dto.AIModelCatalogSyncOut{}, err
+	}
+	if len(entries) == 0 {
+		// 目录为空时 prune 会删除所有模型，直接拒绝
+		return dto.AIModelCatalogSyncOut{}, fmt.Errorf("%s/%s 未声明任何模型", in.NameSpace, in.Deployment)
+	}
+	desired := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		desired = append(desired, entry.ModelName)
+	}
+
+	catalogSyncMu.Lock()
+	defer catalogSyncMu.Unlock()
+	return c.syncDeployment(ctx, in.NameSpace, in.Deployment, desired, in.Prune, creator)
+}
+
+func (c *catalogService) Reconcile(ctx context.Context) error {
+	entries, err := c.factory.AI().ModelCatalog().FindList(ctx, model.AIModelCatalog{})
+	if err != nil {
+		return err
+	}
+	desired := make(map[string][]string)
+	var keys [][2]string
+	for _, entry := range entries {
+		key := catalogKey(entry.Namespace, entry.Deployment)
+		if _, ok := desired[key]; !ok {
+			keys = append(keys, [2]string{entry.Namespace, entry.Deployment})
+		}
+		desired[key] = append(desired[key], entry.ModelName)
+	}
+
+	catalogSyncMu.Lock()
+	defer catalogSyncMu.Unlock()
+	// 清理已不存在模型目录的部署
+	catalogDrifts.Range(func(key, _ interface{}) bool {
+		if _, ok := desired[key.(string)]; !ok {
+			catalogDrifts.Delete(key)
+		}
+		return true
+	})
+	for _, key := range keys {
+		if _, err := c.syncDeployment(ctx, key[0], key[1], desired[catalogKey(key[0], key[1])], false, catalogSyncCreator); err != nil {
+			// 单个部署失败不影响其他部署
+			c.log.ErrorWithErr(fmt.Sprintf("sync model catalog of %s/%s failed", key[0], key[1]), err)
+		}
+	}
+	return nil
+}
+
+func (c *catalogService) Drift(namespace, deployment string) *kubeDto.OllamaModelDrift {
+	if drift, ok := catalogDrifts.Load(catalogKey(namespace, deployment)); ok {
+		return drift.(*kubeDto.OllamaModelDrift)
+	}
+	return nil
+}
+
+// syncDeployment 为每个就绪副本拉取缺失的模型，prune 为 true 时删除未声明的模型
+// 调用方需持有 catalogSyncMu
+func (c *catalogService) syncDeployment(ctx context.Context, namespace, deployment string, desired []string, prune bool, creator string) (dto.AIModelCatalogSyncOut, error) {
+	key := catalogKey(namespace, deployment)
+	drift, err := kube.Ollama.ModelDrift(ctx, kubeDto.OllamaTarget{Deployment: deployment, NameSpace: namespace}, desired)
+	if err != nil {
+		catalogDrifts.Store(key, &kubeDto.OllamaModelDrift{Desired: desired, Message: err.Error(), CheckedAt: time.Now()})
+		return dto.AIModelCatalogSyncOut{}, err
+	}
+
+	out := dto.AIModelCatalogSyncOut{Drift: drift, PullJobs: []model.AIPullJob{}, Pruned: map[string][]string{}}
+	for i := range drift.Pods {
+		pod := &drift.Pods[i]
+		for _, modelName := range pod.Missing {
+			pulling, err := c.isPulling(ctx, pod.PodName, namespace, modelName)
+			if err != nil {
+				return out, err
+			}
+			if !pulling {
+				jobs, err := c.pullJob.CreatePullJob(ctx, &kubeDto.OllamaPullModelInput{
+					OllamaTarget: kubeDto.OllamaTarget{PodName: pod.PodName, NameSpace: namespace},
+					ModelName:    modelName,
+				}, creator)
+				if err != nil {
+					return out, err
+				}
+				out.PullJobs = append(out.PullJobs, jobs...)
+			}
+			pod.Pulling = append(pod.Pulling, modelName)
+		}
+
+		if !prune {
+			continue
+		}
+		var kept []string
+		for _, modelName := range pod.Extra {
+			if err := kube.Ollama.DeleteModel(ctx, kubeDto.OllamaTarget{PodName: pod.PodName, NameSpace: namespace}, modelName); err != nil {
+				c.log.ErrorWithErr(fmt.Sprintf("prune model %s on %s/%s failed", modelName, namespace, pod.PodName), err)
+				kept = append(kept, modelName)
+				continue
+			}
+			out.Pruned[pod.PodName] = append(out.Pruned[pod.PodName], modelName)
+		}
+		pod.Extra = append([]string{}, kept...)
+	}
+
+	drift.InSync = true
+	for _, pod := range drift.Pods {
+		if len(pod.Missing) > 0 || len(pod.Extra) > 0 || pod.Error != "" {
+			drift.InSync = false
+		}
+	}
+	catalogDrifts.Store(key, drift)
+	return out, nil
+}
+
+// isPulling 判断副本上是否已有该模型未结束的拉取任务
+func (c *catalogService) isPulling(ctx context.Context, podName, namespace, modelName string) (bool, error) {
+	jobs, err := c.factory.AI().PullJob().FindList(ctx, model.AIPullJob{PodName: podName, Namespace: namespace, ModelName: modelName})
+	if err != nil {
+		return false, err
+	}
+	for _, job := range jobs {
+		if !job.IsFinished() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func catalogKey(namespace, deployment string) string {
+	return namespace + "/" + deployment
+}
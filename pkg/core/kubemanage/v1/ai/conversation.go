@@ -0,0 +1,270 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+const (
+	// historyMaxMessages 每轮对话最多携带的历史消息数
+	historyMaxMessages = 20
+	// historyMaxRunes 历史消息的总字符数上限，超出时丢弃较早的消息
+	historyMaxRunes = 12000
+	// titleMaxRunes 根据第一条消息生成标题时的最大长度
+	titleMaxRunes = 30
+)
+
+type ConversationService interface {
+	// Create 创建对话，引用提示词模板时校验当前用户是否可见
+	Create(ctx context.Context, in *dto.AIConversationCreateInput, userID int, userName string, authorityID uint) (model.AIConversation, error)
+	PageConversation(ctx context.Context, pager runtime.Pager) (dto.PageAIConversationOut, error)
+	// Get 获取对话及全部消息，只能获取自己的对话
+	Get(ctx context.Context, instanceID string, userID int) (dto.AIConversationDetailOut, error)
+	Rename(ctx context.Context, in *dto.AIConversationRenameInput, userID int) error
+	Delete(ctx context.Context, instanceID string, userID int) error
+	// Chat 追加用户消息，将裁剪后的历史发送给模型并保存回答
+	Chat(ctx context.Context, in *dto.AIConversationChatInput, userID int) (dto.AIConversationChatOut, error)
+	// ChatStream 流式对话，绑定知识库时先通过 onDocuments 返回检索到的文档
+	ChatStream(ctx context.Context, in *dto.AIConversationChatInput, userID int, onDocuments func(documents []string) error, onChunk func(chunk map[string]interface{}) error) error
+}
+
+func NewConversationService(factory dao.ShareDaoFactory) ConversationService {
+	return &conversationService{factory: factory}
+}
+
+type conversationService struct {
+	factory dao.ShareDaoFactory
+}
+
+func (c *conversationService) Create(ctx context.Context, in *dto.AIConversationCreateInput, userID int, userName string, authorityID uint) (model.AIConversation, error) {
+	if in.TemplateID != "" {
+		if _, err := NewPromptTemplateService(c.factory).Resolve(ctx, in.TemplateID, in.TemplateVersion, userID, authorityID); err != nil {
+			return model.AIConversation{}, err
+		}
+	}
+	conv := model.AIConversation{
+		InstanceID:         utils.GetSnowflakeID(),
+		Title:              in.Title,
+		UserID:             userID,
+		UserName:           userName,
+		Namespace:          in.NameSpace,
+		Deployment:         in.Deployment,
+		PodName:            in.PodName,
+		Model:              in.Model,
+		SystemPrompt:       in.SystemPrompt,
+		TemplateID:         in.TemplateID,
+		TemplateVersion:    in.TemplateVersion,
+		KnowledgePodName:   in.KnowledgePodName,
+		KnowledgeNamespace: in.KnowledgeNamespace,
+		KnowledgeType:      in.KnowledgeType,
+		CollectionName:     in.CollectionName,
+		TopK:               in.TopK,
+	}
+	return conv, c.factory.AI().Conversation().Save(ctx, &conv)
+}
+
+func (c *conversationService) PageConversation(ctx context.Context, pager runtime.Pager) (dto.PageAIConversationOut, error) {
+	list, total, err := c.factory.AI().Conversation().PageList(ctx, pager)
+	if err != nil {
+		return dto.PageAIConversationOut{}, err
+	}
+	return dto.PageAIConversationOut{Total: total, List: list, Page: pager.GetPage(), PageSize: pager.GetPageSize()}, nil
+}
+
+func (c *conversationService) Get(ctx context.Context, instanceID string, userID int) (dto.AIConversationDetailOut, error) {
+	conv, err := c.find(ctx, instanceID, userID)
+	if err != nil {
+		return dto.AIConversationDetailOut{}, err
+	}
+	messages, err := c.factory.AI().Conversation().ListMessages(ctx, instanceID, 0)
+	if err != nil {
+		return dto.AIConversationDetailOut{}, err
+	}
+	return dto.AIConversationDetailOut{AIConversation: conv, Messages: messages}, nil
+}
+
+func (c *conversationService) Rename(ctx context.Context, in *dto.AIConversationRenameInput, userID int) error {
+	if _, err := c.find(ctx, in.InstanceID, userID); err != nil {
+		return err
+	}
+	return c.factory.AI().Conversation().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", in.InstanceID)
+	}, &model.AIConversation{Title: in.Title})
+}
+
+func (c *conversationService) Delete(ctx context.Context, instanceID string, userID int) error {
+	if _, err := c.find(ctx, instanceID, userID); err != nil {
+		return err
+	}
+	return c.factory.AI().Conversation().Delete(ctx, model.AIConversation{InstanceID: instanceID})
+}
+
+func (c *conversationService) Chat(ctx context.Context, in *dto.AIConversationChatInput, userID int) (dto.AIConversationChatOut, error) {
+	conv, messages, documents, err := c.prepare(ctx, in, userID)
+	if err != nil {
+		return dto.AIConversationChatOut{}, err
+	}
+	result, err := kube.Ollama.Chat(ctx, conversationTarget(conv), conv.Model, messages, false)
+	if err != nil {
+		return dto.AIConversationChatOut{}, err
+	}
+	resp, _ := result.(map[string]interface{})
+	var content string
+	if msg, ok := resp["message"].(map[string]interface{}); ok {
+		content, _ = msg["content"].(string)
+	}
+	answer, err := c.appendMessages(ctx, conv, in.Content, content, resp)
+	if err != nil {
+		return dto.AIConversationChatOut{}, err
+	}
+	return dto.AIConversationChatOut{Message: answer, RelatedDocuments: documents}, nil
+}
+
+func (c *conversationService) ChatStream(ctx context.Context, in *dto.AIConversationChatInput, userID int, onDocuments func(documents []string) error, onChunk func(chunk map[string]interface{}) error) error {
+	conv, messages, documents, err := c.prepare(ctx, in, userID)
+	if err != nil {
+		return err
+	}
+	if len(documents) > 0 {
+		if err := onDocuments(documents); err != nil {
+			return err
+		}
+	}
+
+	var content strings.Builder
+	var last map[string]interface{}
+	err = kube.Ollama.ChatStream(ctx, conversationTarget(conv), conv.Model, messages, func(chunk map[string]interface{}) error {
+		if msg, ok := chunk["message"].(map[string]interface{}); ok {
+			text, _ := msg["content"].(string)
+			content.WriteString(text)
+		}
+		last = chunk
+		return onChunk(chunk)
+	})
+	if err != nil {
+		// 回答不完整时不保存，避免污染后续的历史
+		return err
+	}
+	_, err = c.appendMessages(ctx, conv, in.Content, content.String(), last)
+	return err
+}
+
+// prepare 构建发送给模型的消息：系统提示词（绑定知识库时包含检索结果）+ 裁剪后的历史 + 本轮用户消息
+func (c *conversationService) prepare(ctx context.Context, in *dto.AIConversationChatInput, userID int) (model.AIConversation, []kubeDto.OllamaChatMessage, []string, error) {
+	conv, err := c.find(ctx, in.InstanceID, userID)
+	if err != nil {
+		return conv, nil, nil, err
+	}
+	history, err := c.factory.AI().Conversation().ListMessages(ctx, conv.InstanceID, historyMaxMessages)
+	if err != nil {
+		return conv, nil, nil, err
+	}
+
+	// 引用的模板在创建对话时已校验可见范围
+	ref := kubeDto.PromptTemplateRef{Variables: in.Variables}
+	if conv.TemplateID != "" {
+		version, err := c.factory.AI().PromptTemplate().FindVersion(ctx, conv.TemplateID, conv.TemplateVersion)
+		if err != nil {
+			return conv, nil, nil, err
+		}
+		ref.PromptTemplate = version.Content
+	}
+
+	systemPrompt := conv.SystemPrompt
+	var documents []string
+	if conv.KnowledgePodName != "" {
+		systemPrompt, documents, _, err = kube.Knowledge.RetrieveSystemPrompt(ctx, &kubeDto.ChatWithKBInput{
+			KnowledgePodName:   conv.KnowledgePodName,
+			KnowledgeNamespace: conv.KnowledgeNamespace,
+			KnowledgeType:      conv.KnowledgeType,
+			CollectionName:     conv.CollectionName,
+			Question:           in.Content,
+			TopK:               conv.TopK,
+			SystemPrompt:       conv.SystemPrompt,
+			PromptTemplateRef:  ref,
+		})
+		if err != nil {
+			return conv, nil, nil, err
+		}
+	}
+
+	var messages []kubeDto.OllamaChatMessage
+	if systemPrompt != "" {
+		messages = append(messages, kubeDto.OllamaChatMessage{Role: "system", Content: systemPrompt})
+	}
+	for _, msg := range trimHistory(history, historyMaxRunes) {
+		messages = append(messages, kubeDto.OllamaChatMessage{Role: msg.Role, Content: msg.Content})
+	}
+	messages = append(messages, kubeDto.OllamaChatMessage{Role: "user", Content: in.Content})
+	if ref.PromptTemplate != "" && conv.KnowledgePodName == "" {
+		if messages, err = kube.ApplyPromptTemplate(ctx, messages, ref); err != nil {
+			return conv, nil, nil, err
+		}
+	}
+	return conv, messages, documents, nil
+}
+
+// appendMessages 保存本轮的问题和回答，并更新对话的标题和更新时间
+func (c *conversationService) appendMessages(ctx context.Context, conv model.AIConversation, question, answer string, resp map[string]interface{}) (model.AIConversationMessage, error) {
+	prompt, _ := resp["prompt_eval_count"].(float64)
+	completion, _ := resp["eval_count"].(float64)
+	userMsg := model.AIConversationMessage{ConversationID: conv.InstanceID, Role: "user", Content: question, PromptTokens: int64(prompt)}
+	if err := c.factory.AI().Conversation().SaveMessage(ctx, &userMsg); err != nil {
+		return model.AIConversationMessage{}, err
+	}
+	assistantMsg := model.AIConversationMessage{ConversationID: conv.InstanceID, Role: "assistant", Content: answer, CompletionTokens: int64(completion)}
+	if err := c.factory.AI().Conversation().SaveMessage(ctx, &assistantMsg); err != nil {
+		return model.AIConversationMessage{}, err
+	}
+
+	update := &model.AIConversation{CommonModel: model.CommonModel{UpdatedAt: time.Now()}}
+	if conv.Title == "" {
+		update.Title = truncateRunes(question, titleMaxRunes)
+	}
+	err := c.factory.AI().Conversation().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", conv.InstanceID)
+	}, update)
+	return assistantMsg, err
+}
+
+func (c *conversationService) find(ctx context.Context, instanceID string, userID int) (model.AIConversation, error) {
+	return c.factory.AI().Conversation().Find(ctx, model.AIConversation{InstanceID: instanceID, UserID: userID})
+}
+
+// trimHistory 从最新的消息往前保留，直到超出字符数上限
+func trimHistory(history []model.AIConversationMessage, maxRunes int) []model.AIConversationMessage {
+	total := 0
+	start := len(history)
+	for i := len(history) - 1; i >= 0; i-- {
+		total += utf8.RuneCountInString(history[i].Content)
+		if total > maxRunes {
+			break
+		}
+		start = i
+	}
+	return history[start:]
+}
+
+func truncateRunes(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+func conversationTarget(conv model.AIConversation) kubeDto.OllamaTarget {
+	return kubeDto.OllamaTarget{PodName: conv.PodName, Deployment: conv.Deployment, NameSpace: conv.Namespace}
+}
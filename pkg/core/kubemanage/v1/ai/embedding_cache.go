@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+)
+
+type EmbeddingCacheService interface {
+	// kube.EmbeddingStore 向量缓存的 MySQL 持久化层
+	kube.EmbeddingStore
+	Stats(ctx context.Context) (dto.AIEmbeddingCacheStatsOut, error)
+	// Invalidate 清除内存层及持久化层中模型的缓存，model 为空时清空全部
+	Invalidate(ctx context.Context, model string) (dto.AIEmbeddingCacheDelOut, error)
+}
+
+func NewEmbeddingCacheService(factory dao.ShareDaoFactory) EmbeddingCacheService {
+	return &embeddingCacheService{factory: factory}
+}
+
+type embeddingCacheService struct {
+	factory dao.ShareDaoFactory
+}
+
+func (e *embeddingCacheService) GetEmbedding(ctx context.Context, modelName, hash string) ([]float64, bool, error) {
+	out, err := e.factory.AI().EmbeddingCache().Find(ctx, model.AIEmbeddingCache{Model: modelName, Hash: hash})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	vector, err := decodeVector(out.Vector, out.Dim)
+	if err != nil {
+		return nil, false, err
+	}
+	return vector, true, nil
+}
+
+func (e *embeddingCacheService) PutEmbedding(ctx context.Context, modelName, hash string, vector []float64) error {
+	return e.factory.AI().EmbeddingCache().Save(ctx, &model.AIEmbeddingCache{
+		Model:  modelName,
+		Hash:   hash,
+		Dim:    len(vector),
+		Vector: encodeVector(vector),
+	})
+}
+
+func (e *embeddingCacheService) DeleteEmbeddings(ctx context.Context, modelName string) (int64, error) {
+	return e.factory.AI().EmbeddingCache().DeleteByModel(ctx, modelName)
+}
+
+func (e *embeddingCacheService) Stats(ctx context.Context) (dto.AIEmbeddingCacheStatsOut, error) {
+	stats := kube.GetEmbeddingCacheStats()
+	out := dto.AIEmbeddingCacheStatsOut{
+		Enabled:        stats.Enabled,
+		Persistent:     stats.Persistent,
+		Capacity:       stats.Capacity,
+		MemoryEntries:  stats.Entries,
+		MemoryHits:     stats.MemoryHits,
+		PersistentHits: stats.PersistentHits,
+		Misses:         stats.Misses,
+		HitRate:        stats.HitRate,
+	}
+	if stats.Persistent {
+		total, err := e.factory.AI().EmbeddingCache().Count(ctx)
+		if err != nil {
+			return out, err
+		}
+		out.PersistentEntries = total
+	}
+	return out, nil
+}
+
+func (e *embeddingCacheService) Invalidate(ctx context.Context, modelName string) (dto.AIEmbeddingCacheDelOut, error) {
+	deleted, err := kube.InvalidateEmbeddingCache(ctx, modelName)
+	if err != nil {
+		return dto.AIEmbeddingCacheDelOut{}, err
+	}
+	return dto.AIEmbeddingCacheDelOut{Model: modelName, Deleted: deleted}, nil
+}
+
+// encodeVector 按小端序 float64 编码向量
+func encodeVector(vector []float64) []byte {
+	buf := make([]byte, 8*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint64(buf[i*8:], math.Float64bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte, dim int) ([]float64, error) {
+	if len(buf) != dim*8 {
+		return nil, fmt.Errorf("向量缓存长度 %d 与维度 %d 不一致", len(buf), dim)
+	}
+	vector := make([]float64, dim)
+	for i := range vector {
+		vector[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:]))
+	}
+	return vector, nil
+}
@@ -0,0 +1,179 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// guardrailCacheTTL 护栏规则缓存时间，修改规则时会立即失效
+const guardrailCacheTTL = 30 * time.Second
+
+// guardrailRules 已启用并编译的护栏规则缓存
+var guardrailRules struct {
+	mu       sync.Mutex
+	rules    []*kube.GuardrailRule
+	expireAt time.Time
+}
+
+type GuardrailService interface {
+	// SaveRule 创建或更新护栏规则，instanceID 为空时新建
+	SaveRule(ctx context.Context, in *dto.AIGuardrailRuleInput, creator string) (model.AIGuardrailRule, error)
+	DeleteRule(ctx context.Context, instanceID string) error
+	ListRules(ctx context.Context) ([]model.AIGuardrailRule, error)
+	PageViolations(ctx context.Context, pager runtime.Pager) (dto.PageAIGuardrailViolationOut, error)
+	// Check 对内容执行已启用的规则并异步记录命中，作为 kube.SetGuardrail 的回调
+	Check(ctx context.Context, check kube.GuardrailCheck) (string, error)
+}
+
+func NewGuardrailService(factory dao.ShareDaoFactory) GuardrailService {
+	return &guardrailService{factory: factory, log: logger.New(logger.LG)}
+}
+
+type guardrailService struct {
+	factory dao.ShareDaoFactory
+	log     logger.Logger
+}
+
+func (g *guardrailService) SaveRule(ctx context.Context, in *dto.AIGuardrailRuleInput, creator string) (model.AIGuardrailRule, error) {
+	if _, err := kube.NewGuardrailRule(in.InstanceID, in.Name, in.Type, in.Pattern, in.MaxLength, in.Stage, in.Action); err != nil {
+		return model.AIGuardrailRule{}, err
+	}
+	defer invalidateGuardrails()
+
+	if in.InstanceID == "" {
+		rule := model.AIGuardrailRule{
+			InstanceID:  utils.GetSnowflakeID(),
+			Name:        in.Name,
+			Type:        in.Type,
+			Pattern:     in.Pattern,
+			MaxLength:   in.MaxLength,
+			Stage:       in.Stage,
+			Action:      in.Action,
+			Enabled:     in.Enabled,
+			Sort:        in.Sort,
+			Description: in.Description,
+			Creator:     creator,
+		}
+		return rule, g.factory.AI().Guardrail().Save(ctx, &rule)
+	}
+
+	if _, err := g.factory.AI().Guardrail().Find(ctx, model.AIGuardrailRule{InstanceID: in.InstanceID}); err != nil {
+		return model.AIGuardrailRule{}, err
+	}
+	if err := g.factory.AI().Guardrail().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", in.InstanceID)
+	}, map[string]interface{}{
+		"name":        in.Name,
+		"type":        in.Type,
+		"pattern":     in.Pattern,
+		"maxLength":   in.MaxLength,
+		"stage":       in.Stage,
+		"action":      in.Action,
+		"enabled":     in.Enabled,
+		"sort":        in.Sort,
+		"description": in.Description,
+	}); err != nil {
+		return model.AIGuardrailRule{}, err
+	}
+	return g.factory.AI().Guardrail().Find(ctx, model.AIGuardrailRule{InstanceID: in.InstanceID})
+}
+
+func (g *guardrailService) DeleteRule(ctx context.Context, instanceID string) error {
+	if _, err := g.factory.AI().Guardrail().Find(ctx, model.AIGuardrailRule{InstanceID: instanceID}); err != nil {
+		return err
+	}
+	defer invalidateGuardrails()
+	return g.factory.AI().Guardrail().Delete(ctx, model.AIGuardrailRule{InstanceID: instanceID}, true)
+}
+
+func (g *guardrailService) ListRules(ctx context.Context) ([]model.AIGuardrailRule, error) {
+	return g.factory.AI().Guardrail().FindList(ctx, model.AIGuardrailRule{})
+}
+
+func (g *guardrailService) PageViolations(ctx context.Context, pager runtime.Pager) (dto.PageAIGuardrailViolationOut, error) {
+	list, total, err := g.factory.AI().Guardrail().PageViolations(ctx, pager)
+	if err != nil {
+		return dto.PageAIGuardrailViolationOut{}, err
+	}
+	return dto.PageAIGuardrailViolationOut{Total: total, List: list, Page: pager.GetPage(), PageSize: pager.GetPageSize()}, nil
+}
+
+func (g *guardrailService) Check(ctx context.Context, check kube.GuardrailCheck) (string, error) {
+	rules := g.rules(ctx)
+	if len(rules) == 0 {
+		return check.Text, nil
+	}
+	text, hits, err := kube.EvaluateGuardrails(rules, check.Stage, check.Text, check.Offset)
+	if len(hits) > 0 {
+		g.record(check, hits)
+	}
+	return text, err
+}
+
+// rules 返回已启用的规则，查询失败时继续使用上一次的规则
+func (g *guardrailService) rules(ctx context.Context) []*kube.GuardrailRule {
+	guardrailRules.mu.Lock()
+	defer guardrailRules.mu.Unlock()
+	if time.Now().Before(guardrailRules.expireAt) {
+		return guardrailRules.rules
+	}
+
+	list, err := g.factory.AI().Guardrail().FindList(ctx, model.AIGuardrailRule{Enabled: true})
+	if err != nil {
+		g.log.ErrorWithErr("查询护栏规则失败", err)
+		return guardrailRules.rules
+	}
+	rules := make([]*kube.GuardrailRule, 0, len(list))
+	for _, item := range list {
+		rule, err := kube.NewGuardrailRule(item.InstanceID, item.Name, item.Type, item.Pattern, item.MaxLength, item.Stage, item.Action)
+		if err != nil {
+			g.log.ErrorWithErr("护栏规则 "+item.Name+" 无效", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	guardrailRules.rules = rules
+	guardrailRules.expireAt = time.Now().Add(guardrailCacheTTL)
+	return rules
+}
+
+// record 异步保存规则命中记录，不阻塞推理请求
+func (g *guardrailService) record(check kube.GuardrailCheck, hits []kube.GuardrailHit) {
+	violations := make([]model.AIGuardrailViolation, 0, len(hits))
+	for _, hit := range hits {
+		violations = append(violations, model.AIGuardrailViolation{
+			RuleID:   hit.Rule.ID,
+			RuleName: hit.Rule.Name,
+			RuleType: hit.Rule.Type,
+			Stage:    check.Stage,
+			Action:   hit.Rule.Action,
+			Snippet:  hit.Match,
+			UserID:   check.Caller.UserID,
+			UserName: check.Caller.UserName,
+			Source:   check.Caller.Source,
+			Model:    check.Model,
+		})
+	}
+	go func() {
+		if err := g.factory.AI().Guardrail().SaveViolations(context.Background(), violations); err != nil {
+			g.log.ErrorWithErr("save guardrail violations failed", err)
+		}
+	}()
+}
+
+func invalidateGuardrails() {
+	guardrailRules.mu.Lock()
+	defer guardrailRules.mu.Unlock()
+	guardrailRules.expireAt = time.Time{}
+}
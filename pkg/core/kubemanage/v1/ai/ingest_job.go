@@ -0,0 +1,490 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+	"github.com/noovertime7/kubemanage/pkg/vectorstore"
+	"github.com/noovertime7/kubemanage/runtime"
+	"github.com/noovertime7/kubemanage/runtime/wait"
+)
+
+// 文档入库默认参数
+const (
+	defaultIngestBatchSize     = 16
+	defaultIngestConcurrency   = 2
+	defaultIngestMaxRetries    = 3
+	defaultIngestRetryInterval = 2 * time.Second
+	// ingestRetryCap 重试间隔的上限
+	ingestRetryCap = time.Minute
+	// ingestLease 执行任务的租约时长，执行期间每隔 1/3 租约续约一次，实例停止后租约到期由其他实例接管
+	ingestLease = 30 * time.Second
+)
+
+// IngestOptions 文档入库参数
+type IngestOptions struct {
+	// BatchSize 每批生成向量并写入的分块数
+	BatchSize int
+	// Concurrency 同一任务同时写入的批次数
+	Concurrency int
+	// MaxRetries 批次失败后的重试次数，重试间隔从 RetryInterval 开始按指数增长
+	MaxRetries    int
+	RetryInterval time.Duration
+}
+
+var ingestOptions = IngestOptions{
+	BatchSize:     defaultIngestBatchSize,
+	Concurrency:   defaultIngestConcurrency,
+	MaxRetries:    defaultIngestMaxRetries,
+	RetryInterval: defaultIngestRetryInterval,
+}
+
+// ConfigureIngest 设置文档入库参数，零值字段使用默认值
+func ConfigureIngest(opts IngestOptions) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultIngestBatchSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultIngestConcurrency
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultIngestMaxRetries
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultIngestRetryInterval
+	}
+	ingestOptions = opts
+}
+
+var (
+	// errIngestCanceled 用户主动取消任务
+	errIngestCanceled = errors.New("ingest job canceled")
+	// errIngestLeaseLost 续约失败，任务已结束或已被其他实例接管
+	errIngestLeaseLost = errors.New("ingest job lease lost")
+)
+
+// ingestCancels 正在运行的入库任务，key 为任务 InstanceID，value 为 context.CancelCauseFunc
+var ingestCancels sync.Map
+
+// ingestProgressMu 同一任务的多个批次并发更新进度，统计与写入需串行，避免较早的统计结果覆盖较新的
+var ingestProgressMu sync.Mutex
+
+// ingestRunner 当前服务实例的标识，用于认领任务
+var ingestRunner = utils.GetSnowflakeID()
+
+// ingestChunks 为一批分块生成向量并写入知识库
+var ingestChunks = kube.Knowledge.IngestChunks
+
+type IngestJobService interface {
+	// CreateIngestJob 解析并切分文档，保存任务及全部分块后在后台分批写入知识库
+	CreateIngestJob(ctx context.Context, in *kubeDto.KnowledgeUploadDocumentInput, content []byte, fileName, contentType string, userID int, creator string) (model.AIIngestJob, error)
+	// GetIngestJob 获取任务详情，只能获取 userID 创建的任务，下同
+	GetIngestJob(ctx context.Context, instanceID string, userID int) (dto.AIIngestJobDetailOut, error)
+	PageIngestJob(ctx context.Context, in *dto.PageListAIIngestJobInput) (dto.PageAIIngestJobOut, error)
+	CancelIngestJob(ctx context.Context, instanceID string, userID int) error
+	// RetryIngestJob 重新执行已结束任务中失败及未完成的分块
+	RetryIngestJob(ctx context.Context, instanceID string, userID int) error
+	DeleteIngestJob(ctx context.Context, instanceID string, userID int) error
+	// ResumeJobs 接管没有实例执行的未完成任务（如执行任务的实例已停止），已写入的分块不会重复写入
+	// 多个实例同时调用时每个任务只会被一个实例接管
+	ResumeJobs(ctx context.Context) error
+}
+
+func NewIngestJobService(factory dao.ShareDaoFactory) IngestJobService {
+	return &ingestJobService{factory: factory, log: logger.New(logger.LG)}
+}
+
+type ingestJobService struct {
+	factory dao.ShareDaoFactory
+	log     logger.Logger
+}
+
+func (i *ingestJobService) CreateIngestJob(ctx context.Context, in *kubeDto.KnowledgeUploadDocumentInput, content []byte, fileName, contentType string, userID int, creator string) (model.AIIngestJob, error) {
+	plan, err := kube.Knowledge.PrepareIngest(ctx, in.PodName, in.NameSpace, in.KnowledgeType, content, fileName, contentType, in.CollectionName, chunker.Options{
+		Strategy:     in.ChunkStrategy,
+		ChunkSize:    in.ChunkSize,
+		ChunkOverlap: in.ChunkOverlap,
+		MaxTokens:    in.MaxTokens,
+	})
+	if err != nil {
+		return model.AIIngestJob{}, err
+	}
+	chunking, err := json.Marshal(plan.Chunking)
+	if err != nil {
+		return model.AIIngestJob{}, err
+	}
+
+	job := model.AIIngestJob{
+		InstanceID:    utils.GetSnowflakeID(),
+		PodName:       in.PodName,
+		Namespace:     in.NameSpace,
+		KnowledgeType: plan.KnowledgeType,
+		Collection:    plan.Collection,
+		FileName:      fileName,
+		Sections:      plan.Sections,
+		Chunking:      string(chunking),
+		Status:        model.IngestJobPending,
+		Total:         len(plan.Chunks),
+		UserID:        userID,
+		Creator:       creator,
+		Runner:        ingestRunner,
+	}
+	leaseUntil := time.Now().Add(ingestLease)
+	job.LeaseUntil = &leaseUntil
+	chunks := make([]model.AIIngestChunk, len(plan.Chunks))
+	for n, chunk := range plan.Chunks {
+		metadata, err := json.Marshal(chunk.Metadata)
+		if err != nil {
+			return model.AIIngestJob{}, err
+		}
+		chunks[n] = model.AIIngestChunk{
+			JobID:      job.InstanceID,
+			ChunkIndex: chunk.Index,
+			Text:       chunk.Text,
+			Metadata:   string(metadata),
+			Status:     model.IngestChunkPending,
+		}
+	}
+	if err := i.factory.AI().IngestJob().Save(ctx, &job, chunks); err != nil {
+		return model.AIIngestJob{}, err
+	}
+
+	i.start(job)
+	return job, nil
+}
+
+func (i *ingestJobService) GetIngestJob(ctx context.Context, instanceID string, userID int) (dto.AIIngestJobDetailOut, error) {
+	job, err := i.find(ctx, instanceID, userID)
+	if err != nil {
+		return dto.AIIngestJobDetailOut{}, err
+	}
+	out := dto.AIIngestJobDetailOut{Job: job, Pending: job.Total - job.Completed - job.Failed}
+	_ = json.Unmarshal([]byte(job.Chunking), &out.Chunking)
+	if out.FailedChunks, err = i.factory.AI().IngestJob().ListChunks(ctx, instanceID, model.IngestChunkFailed); err != nil {
+		return dto.AIIngestJobDetailOut{}, err
+	}
+	return out, nil
+}
+
+func (i *ingestJobService) PageIngestJob(ctx context.Context, in *dto.PageListAIIngestJobInput) (dto.PageAIIngestJobOut, error) {
+	list, total, err := i.factory.AI().IngestJob().PageList(ctx, in)
+	if err != nil {
+		return dto.PageAIIngestJobOut{}, err
+	}
+	return dto.PageAIIngestJobOut{Total: total, List: list, Page: in.GetPage(), PageSize: in.GetPageSize()}, nil
+}
+
+func (i *ingestJobService) CancelIngestJob(ctx context.Context, instanceID string, userID int) error {
+	job, err := i.find(ctx, instanceID, userID)
+	if err != nil {
+		return err
+	}
+	if job.IsFinished() {
+		return fmt.Errorf("任务已结束，当前状态为 %s", job.Status)
+	}
+	cancel, ok := ingestCancels.Load(instanceID)
+	if !ok {
+		// 任务不在当前实例中运行，直接标记为取消，执行任务的实例续约失败后停止
+		i.finish(instanceID, model.IngestJobCanceled, "任务已取消")
+		return nil
+	}
+	cancel.(context.CancelCauseFunc)(errIngestCanceled)
+	return nil
+}
+
+func (i *ingestJobService) RetryIngestJob(ctx context.Context, instanceID string, userID int) error {
+	job, err := i.find(ctx, instanceID, userID)
+	if err != nil {
+		return err
+	}
+	if !job.IsFinished() {
+		return errors.New("任务运行中，无需重试")
+	}
+	if job.Status == model.IngestJobSuccess {
+		return errors.New("任务已成功，无需重试")
+	}
+	if err := i.factory.AI().IngestJob().UpdateChunks(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("jobID = ? and status = ?", instanceID, model.IngestChunkFailed)
+	}, map[string]interface{}{"status": model.IngestChunkPending, "error": ""}); err != nil {
+		return err
+	}
+	// 已结束的任务不会被其他实例接管，直接由当前实例认领
+	if err := i.factory.AI().IngestJob().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", instanceID)
+	}, map[string]interface{}{
+		"status":     model.IngestJobPending,
+		"message":    "",
+		"finishedAt": nil,
+		"runner":     ingestRunner,
+		"leaseUntil": time.Now().Add(ingestLease),
+	}); err != nil {
+		return err
+	}
+	i.refreshProgress(instanceID, job.Total)
+	i.start(job)
+	return nil
+}
+
+func (i *ingestJobService) DeleteIngestJob(ctx context.Context, instanceID string, userID int) error {
+	job, err := i.find(ctx, instanceID, userID)
+	if err != nil {
+		return err
+	}
+	if !job.IsFinished() {
+		return errors.New("任务运行中，请先取消任务")
+	}
+	return i.factory.AI().IngestJob().Delete(ctx, instanceID)
+}
+
+func (i *ingestJobService) ResumeJobs(ctx context.Context) error {
+	jobs, err := i.factory.AI().IngestJob().FindByStatus(ctx, model.IngestJobPending, model.IngestJobRunning)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if _, ok := ingestCancels.Load(job.InstanceID); ok {
+			continue
+		}
+		claimed, err := i.factory.AI().IngestJob().Claim(ctx, job.InstanceID, ingestRunner, time.Now().Add(ingestLease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			// 其他实例正在执行或已先一步接管
+			continue
+		}
+		i.log.Info(fmt.Sprintf("resume ingest job %s: %s -> %s/%s", job.InstanceID, job.FileName, job.Namespace, job.Collection))
+		i.start(job)
+	}
+	return nil
+}
+
+func (i *ingestJobService) find(ctx context.Context, instanceID string, userID int) (model.AIIngestJob, error) {
+	return i.factory.AI().IngestJob().Find(ctx, model.AIIngestJob{InstanceID: instanceID, UserID: userID})
+}
+
+// start 在后台运行已由当前实例认领的任务，向量生成的用量归属于任务创建人
+func (i *ingestJobService) start(job model.AIIngestJob) {
+	ctx, cancel := context.WithCancelCause(kube.WithUsageCaller(runtime.SystemContext, kube.UsageCaller{
+		UserID:   job.UserID,
+		UserName: job.Creator,
+		Source:   "knowledge",
+	}))
+	if _, loaded := ingestCancels.LoadOrStore(job.InstanceID, cancel); loaded {
+		// 任务已在当前实例中运行
+		cancel(nil)
+		return
+	}
+	go i.run(ctx, job)
+}
+
+// run 按并发数分批写入任务中未完成的分块，运行期间定期续约，续约失败时停止
+func (i *ingestJobService) run(ctx context.Context, job model.AIIngestJob) {
+	defer ingestCancels.Delete(job.InstanceID)
+
+	done := make(chan struct{})
+	defer close(done)
+	go i.renew(ctx, job.InstanceID, done)
+
+	i.update(job.InstanceID, map[string]interface{}{"status": model.IngestJobRunning})
+
+	chunks, err := i.factory.AI().IngestJob().ListChunks(ctx, job.InstanceID, model.IngestChunkPending)
+	if err != nil {
+		i.finish(job.InstanceID, model.IngestJobFailed, fmt.Sprintf("查询待写入分块失败: %v", err))
+		return
+	}
+
+	opts := ingestOptions
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opts.Concurrency)
+	for start := 0; start < len(chunks) && ctx.Err() == nil; start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(batch []model.AIIngestChunk) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				i.ingestBatch(ctx, job, batch, opts)
+			}(chunks[start:end])
+		}
+	}
+	wg.Wait()
+
+	if errors.Is(context.Cause(ctx), errIngestLeaseLost) {
+		i.log.Info(fmt.Sprintf("ingest job %s stopped: lease lost", job.InstanceID))
+		return
+	}
+	completed, failed := i.refreshProgress(job.InstanceID, job.Total)
+	switch {
+	case errors.Is(context.Cause(ctx), errIngestCanceled):
+		i.finish(job.InstanceID, model.IngestJobCanceled, "任务已取消")
+	case ctx.Err() != nil:
+		// 服务停止，保持运行状态，租约到期后由 ResumeJobs 继续
+	case failed > 0:
+		i.finish(job.InstanceID, model.IngestJobFailed, fmt.Sprintf("%d 个分块写入失败，已写入 %d 个，可重试失败的分块", failed, completed))
+	default:
+		i.finish(job.InstanceID, model.IngestJobSuccess, "success")
+	}
+}
+
+// renew 定期续约，任务已结束（如在其他实例中取消）或已被其他实例接管时取消 ctx
+func (i *ingestJobService) renew(ctx context.Context, instanceID string, done <-chan struct{}) {
+	ticker := time.NewTicker(ingestLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ok, err := i.factory.AI().IngestJob().Renew(context.Background(), instanceID, ingestRunner, time.Now().Add(ingestLease))
+			if err != nil {
+				// 数据库暂时不可用时等待下次续约，租约到期前恢复即可
+				i.log.ErrorWithErr("renew ingest job lease failed", err)
+				continue
+			}
+			if !ok {
+				if cancel, ok := ingestCancels.Load(instanceID); ok {
+					cancel.(context.CancelCauseFunc)(errIngestLeaseLost)
+				}
+				return
+			}
+		}
+	}
+}
+
+// ingestBatch 写入一批分块，失败时按指数退避重试，仍失败时逐个写入，避免个别分块导致整批失败
+// 向量数据库拒绝的请求（如向量维度与集合不一致）重试也不会成功，不再重试
+func (i *ingestJobService) ingestBatch(ctx context.Context, job model.AIIngestJob, batch []model.AIIngestChunk, opts IngestOptions) {
+	chunks := make([]kube.IngestChunk, len(batch))
+	for n, c := range batch {
+		chunks[n] = kube.IngestChunk{Index: c.ChunkIndex, Text: c.Text, Metadata: decodeMetadata(c.Metadata)}
+	}
+
+	var (
+		attempts int
+		lastErr  error
+	)
+	backoff := wait.Backoff{Duration: opts.RetryInterval, Factor: 2, Cap: ingestRetryCap, Steps: opts.MaxRetries + 1}
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		attempts++
+		lastErr = ingestChunks(ctx, job.PodName, job.Namespace, job.KnowledgeType, job.Collection, job.FileName, chunks)
+		if vectorstore.IsPermanent(lastErr) {
+			return false, lastErr
+		}
+		return lastErr == nil, nil
+	})
+	if ctx.Err() != nil {
+		// 取消或服务停止，分块保持待写入状态
+		return
+	}
+	if err == nil {
+		i.markChunks(job, batch, attempts, nil)
+		return
+	}
+
+	i.log.ErrorWithErr(fmt.Sprintf("ingest %s chunks %d-%d failed after %d attempts", job.FileName, batch[0].ChunkIndex, batch[len(batch)-1].ChunkIndex, attempts), lastErr)
+	i.update(job.InstanceID, map[string]interface{}{"message": lastErr.Error()})
+	if len(batch) == 1 {
+		i.markChunks(job, batch, attempts, lastErr)
+		return
+	}
+	for n := range batch {
+		err := ingestChunks(ctx, job.PodName, job.Namespace, job.KnowledgeType, job.Collection, job.FileName, chunks[n:n+1])
+		if ctx.Err() != nil {
+			return
+		}
+		i.markChunks(job, batch[n:n+1], attempts+1, err)
+	}
+}
+
+// markChunks 记录分块的写入结果并更新任务进度，运行期间即可查询到每批的进度
+func (i *ingestJobService) markChunks(job model.AIIngestJob, batch []model.AIIngestChunk, attempts int, err error) {
+	indexes := make([]int, len(batch))
+	for n, c := range batch {
+		indexes[n] = c.ChunkIndex
+	}
+	updates := map[string]interface{}{
+		"status":   model.IngestChunkSuccess,
+		"error":    "",
+		"attempts": gorm.Expr("attempts + ?", attempts),
+	}
+	if err != nil {
+		updates["status"] = model.IngestChunkFailed
+		updates["error"] = err.Error()
+	}
+	if err := i.factory.AI().IngestJob().UpdateChunks(context.Background(), func(db *gorm.DB) *gorm.DB {
+		return db.Where("jobID = ? and chunkIndex in ?", job.InstanceID, indexes)
+	}, updates); err != nil {
+		i.log.ErrorWithErr("update ingest chunks failed", err)
+		return
+	}
+	i.refreshProgress(job.InstanceID, job.Total)
+}
+
+// refreshProgress 按分块状态重新统计任务进度，返回已写入及失败的分块数
+func (i *ingestJobService) refreshProgress(jobID string, total int) (completed, failed int) {
+	ingestProgressMu.Lock()
+	defer ingestProgressMu.Unlock()
+	counts, err := i.factory.AI().IngestJob().CountChunks(context.Background(), jobID)
+	if err != nil {
+		i.log.ErrorWithErr("count ingest chunks failed", err)
+		return 0, 0
+	}
+	completed, failed = counts[model.IngestChunkSuccess], counts[model.IngestChunkFailed]
+	var percent float64
+	if total > 0 {
+		percent = float64(completed+failed) * 100 / float64(total)
+	}
+	i.update(jobID, map[string]interface{}{"completed": completed, "failed": failed, "percent": percent})
+	return completed, failed
+}
+
+func (i *ingestJobService) update(instanceID string, in map[string]interface{}) {
+	// 任务上下文可能已被取消，使用独立的 context 写库
+	if err := i.factory.AI().IngestJob().Updates(context.Background(), func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", instanceID)
+	}, in); err != nil {
+		i.log.ErrorWithErr("update ingest job failed", err)
+	}
+}
+
+func (i *ingestJobService) finish(instanceID, status, message string) {
+	i.update(instanceID, map[string]interface{}{
+		"status":     status,
+		"message":    message,
+		"finishedAt": time.Now(),
+		"runner":     "",
+		"leaseUntil": nil,
+	})
+}
+
+// decodeMetadata 解析分块元数据，数字保留为 json.Number，写入向量数据库时不会变为浮点数
+func decodeMetadata(s string) map[string]interface{} {
+	var metadata map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	_ = dec.Decode(&metadata)
+	return metadata
+}
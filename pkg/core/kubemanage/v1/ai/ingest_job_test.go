@@ -0,0 +1,311 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/vectorstore"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// newIngestTest 使用 SQLite 保存任务，分块写入由 stub 代替
+func newIngestTest(t *testing.T, opts IngestOptions, stub func(ctx context.Context, chunks []kube.IngestChunk) error) (*ingestJobService, dao.ShareDaoFactory) {
+	t.Helper()
+	logger.LG = zap.NewNop()
+	if runtime.SystemContext == nil {
+		runtime.SetupContext(make(chan struct{}))
+	}
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ai.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&model.AIIngestJob{}, &model.AIIngestChunk{}); err != nil {
+		t.Fatal(err)
+	}
+
+	oldChunks, oldOpts := ingestChunks, ingestOptions
+	t.Cleanup(func() { ingestChunks, ingestOptions = oldChunks, oldOpts })
+	ingestChunks = func(ctx context.Context, podName, namespace, knowledgeType, collection, fileName string, chunks []kube.IngestChunk) error {
+		return stub(ctx, chunks)
+	}
+	ConfigureIngest(opts)
+
+	factory := dao.NewShareDaoFactory(db)
+	return NewIngestJobService(factory).(*ingestJobService), factory
+}
+
+// saveIngestJob 保存一个包含 n 个分块的任务，与 CreateIngestJob 相同由当前实例认领
+func saveIngestJob(t *testing.T, factory dao.ShareDaoFactory, instanceID string, n int, mutate func(job *model.AIIngestJob)) model.AIIngestJob {
+	t.Helper()
+	leaseUntil := time.Now().Add(ingestLease)
+	job := model.AIIngestJob{
+		InstanceID: instanceID,
+		FileName:   "guide.md",
+		Collection: "docs",
+		Status:     model.IngestJobPending,
+		Total:      n,
+		UserID:     1,
+		Runner:     ingestRunner,
+		LeaseUntil: &leaseUntil,
+	}
+	if mutate != nil {
+		mutate(&job)
+	}
+	chunks := make([]model.AIIngestChunk, n)
+	for i := range chunks {
+		chunks[i] = model.AIIngestChunk{JobID: instanceID, ChunkIndex: i, Text: "text", Metadata: "{}", Status: model.IngestChunkPending}
+	}
+	if err := factory.AI().IngestJob().Save(context.Background(), &job, chunks); err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// waitIngestJob 等待任务在当前实例中运行结束
+func waitIngestJob(t *testing.T, factory dao.ShareDaoFactory, instanceID string) model.AIIngestJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, running := ingestCancels.Load(instanceID); !running {
+			job, err := factory.AI().IngestJob().Find(context.Background(), model.AIIngestJob{InstanceID: instanceID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ingest job %s did not finish", instanceID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestIngestJobBatches(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches []int
+	)
+	svc, factory := newIngestTest(t, IngestOptions{BatchSize: 2, Concurrency: 1}, func(ctx context.Context, chunks []kube.IngestChunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, len(chunks))
+		return nil
+	})
+	job := saveIngestJob(t, factory, "batch", 5, nil)
+	svc.start(job)
+
+	job = waitIngestJob(t, factory, "batch")
+	if job.Status != model.IngestJobSuccess || job.Completed != 5 || job.Percent != 100 {
+		t.Fatalf("job = %+v", job)
+	}
+	if job.Runner != "" || job.LeaseUntil != nil {
+		t.Errorf("finished job should release its lease: %+v", job)
+	}
+	if len(batches) != 3 || batches[0] != 2 || batches[1] != 2 || batches[2] != 1 {
+		t.Errorf("batches = %v", batches)
+	}
+
+	// 只有创建人可以查看及操作任务
+	if _, err := svc.GetIngestJob(context.Background(), "batch", 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("other user get job: %v", err)
+	}
+	if err := svc.DeleteIngestJob(context.Background(), "batch", 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("other user delete job: %v", err)
+	}
+	page, err := svc.PageIngestJob(context.Background(), &dto.PageListAIIngestJobInput{UserID: 2})
+	if err != nil || page.Total != 0 {
+		t.Errorf("other user page = %+v, err = %v", page, err)
+	}
+	if err := svc.DeleteIngestJob(context.Background(), "batch", 1); err != nil {
+		t.Errorf("delete job: %v", err)
+	}
+}
+
+func TestIngestJobProgress(t *testing.T) {
+	second, release := make(chan struct{}), make(chan struct{})
+	svc, factory := newIngestTest(t, IngestOptions{BatchSize: 1, Concurrency: 1}, func(ctx context.Context, chunks []kube.IngestChunk) error {
+		if chunks[0].Index == 1 {
+			close(second)
+			<-release
+		}
+		return nil
+	})
+	job := saveIngestJob(t, factory, "progress", 4, nil)
+	svc.start(job)
+
+	// 第一批写入后即可查询到进度
+	<-second
+	detail, err := svc.GetIngestJob(context.Background(), "progress", 1)
+	close(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Job.Status != model.IngestJobRunning || detail.Job.Completed != 1 || detail.Job.Percent != 25 || detail.Pending != 3 {
+		t.Fatalf("running job = %+v", detail)
+	}
+	if job = waitIngestJob(t, factory, "progress"); job.Completed != 4 || job.Percent != 100 {
+		t.Fatalf("job = %+v", job)
+	}
+}
+
+func TestIngestJobRetry(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls = map[int]int{}
+	)
+	svc, factory := newIngestTest(t, IngestOptions{BatchSize: 2, Concurrency: 1, MaxRetries: 3, RetryInterval: time.Millisecond}, func(ctx context.Context, chunks []kube.IngestChunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		first := chunks[0].Index
+		calls[first]++
+		switch {
+		case first == 0 && calls[first] == 1:
+			// 暂时性错误，重试后成功
+			return errors.New("connection reset by peer")
+		case first == 2 && len(chunks) > 1:
+			// 向量维度与集合不一致，不重试，逐个写入分块
+			return &vectorstore.StatusError{Code: http.StatusBadRequest, Body: "wrong vector dimension"}
+		case first == 3:
+			return &vectorstore.StatusError{Code: http.StatusBadRequest, Body: "wrong vector dimension"}
+		}
+		return nil
+	})
+	job := saveIngestJob(t, factory, "retry", 4, nil)
+	svc.start(job)
+
+	job = waitIngestJob(t, factory, "retry")
+	if job.Status != model.IngestJobFailed || job.Completed != 3 || job.Failed != 1 {
+		t.Fatalf("job = %+v", job)
+	}
+	if calls[0] != 2 || calls[2] != 2 || calls[3] != 1 {
+		t.Errorf("calls = %v", calls)
+	}
+	detail, err := svc.GetIngestJob(context.Background(), "retry", 1)
+	if err != nil || len(detail.FailedChunks) != 1 || detail.FailedChunks[0].ChunkIndex != 3 || detail.FailedChunks[0].Attempts != 2 {
+		t.Fatalf("detail = %+v, err = %v", detail, err)
+	}
+
+	// 重试只写入失败的分块
+	mu.Lock()
+	calls = map[int]int{}
+	mu.Unlock()
+	ingestChunks = func(ctx context.Context, podName, namespace, knowledgeType, collection, fileName string, chunks []kube.IngestChunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[chunks[0].Index]++
+		return nil
+	}
+	if err := svc.RetryIngestJob(context.Background(), "retry", 1); err != nil {
+		t.Fatal(err)
+	}
+	job = waitIngestJob(t, factory, "retry")
+	if job.Status != model.IngestJobSuccess || job.Completed != 4 || job.Failed != 0 {
+		t.Fatalf("job = %+v", job)
+	}
+	if len(calls) != 1 || calls[3] != 1 {
+		t.Errorf("retry calls = %v", calls)
+	}
+}
+
+func TestIngestJobCancel(t *testing.T) {
+	started := make(chan struct{})
+	svc, factory := newIngestTest(t, IngestOptions{BatchSize: 1, Concurrency: 1}, func(ctx context.Context, chunks []kube.IngestChunk) error {
+		if chunks[0].Index == 0 {
+			return nil
+		}
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	job := saveIngestJob(t, factory, "cancel", 3, nil)
+	svc.start(job)
+
+	<-started
+	if err := svc.CancelIngestJob(context.Background(), "cancel", 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("other user cancel job: %v", err)
+	}
+	if err := svc.CancelIngestJob(context.Background(), "cancel", 1); err != nil {
+		t.Fatal(err)
+	}
+	job = waitIngestJob(t, factory, "cancel")
+	if job.Status != model.IngestJobCanceled || job.Completed != 1 {
+		t.Fatalf("job = %+v", job)
+	}
+	// 被取消的分块保持待写入，重试时继续
+	pending, err := factory.AI().IngestJob().ListChunks(context.Background(), "cancel", model.IngestChunkPending)
+	if err != nil || len(pending) != 2 {
+		t.Errorf("pending = %+v, err = %v", pending, err)
+	}
+}
+
+func TestIngestJobResume(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		chunks int
+	)
+	svc, factory := newIngestTest(t, IngestOptions{BatchSize: 2, Concurrency: 2}, func(ctx context.Context, batch []kube.IngestChunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks += len(batch)
+		return nil
+	})
+	expired := time.Now().Add(-time.Second)
+	live := time.Now().Add(time.Minute)
+	// 执行实例已停止，租约过期
+	saveIngestJob(t, factory, "orphan", 3, func(job *model.AIIngestJob) {
+		job.Status, job.Runner, job.LeaseUntil = model.IngestJobRunning, "stopped", &expired
+	})
+	// 其他实例正在执行
+	saveIngestJob(t, factory, "busy", 3, func(job *model.AIIngestJob) {
+		job.Status, job.Runner, job.LeaseUntil = model.IngestJobRunning, "other", &live
+	})
+
+	if err := svc.ResumeJobs(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	job := waitIngestJob(t, factory, "orphan")
+	if job.Status != model.IngestJobSuccess || job.Completed != 3 {
+		t.Fatalf("orphan = %+v", job)
+	}
+	busy, err := factory.AI().IngestJob().Find(context.Background(), model.AIIngestJob{InstanceID: "busy"})
+	if err != nil || busy.Runner != "other" || busy.Status != model.IngestJobRunning {
+		t.Fatalf("busy = %+v, err = %v", busy, err)
+	}
+	if chunks != 3 {
+		t.Errorf("ingested %d chunks, want 3", chunks)
+	}
+
+	// 多个实例同时接管同一任务时只有一个成功
+	saveIngestJob(t, factory, "race", 1, func(job *model.AIIngestJob) {
+		job.Status, job.Runner, job.LeaseUntil = model.IngestJobRunning, "stopped", &expired
+	})
+	var wins int
+	for _, runner := range []string{"a", "b", "c"} {
+		ok, err := factory.AI().IngestJob().Claim(context.Background(), "race", runner, time.Now().Add(ingestLease))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			wins++
+		}
+	}
+	if wins != 1 {
+		t.Errorf("claimed by %d runners", wins)
+	}
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+type KnowledgeChunkingService interface {
+	// SaveChunking 创建或更新集合的分块配置，按 (命名空间, 知识库, 集合) 覆盖已存在的配置
+	SaveChunking(ctx context.Context, in *dto.AIKnowledgeChunkingInput, creator string) (model.AIKnowledgeChunking, error)
+	DeleteChunking(ctx context.Context, instanceID string) error
+	ListChunking(ctx context.Context, in *dto.AIKnowledgeChunkingListInput) ([]model.AIKnowledgeChunking, error)
+	// Lookup 查找集合的分块配置，作为 kube.SetChunkingLookup 的回调，未配置时返回 nil
+	Lookup(ctx context.Context, namespace, knowledge, collection string) (*chunker.Options, error)
+}
+
+func NewKnowledgeChunkingService(factory dao.ShareDaoFactory) KnowledgeChunkingService {
+	return &knowledgeChunkingService{factory: factory}
+}
+
+type knowledgeChunkingService struct {
+	factory dao.ShareDaoFactory
+}
+
+func (k *knowledgeChunkingService) SaveChunking(ctx context.Context, in *dto.AIKnowledgeChunkingInput, creator string) (model.AIKnowledgeChunking, error) {
+	opts := chunker.Options{Strategy: in.Strategy, ChunkSize: in.ChunkSize, ChunkOverlap: in.ChunkOverlap, MaxTokens: in.MaxTokens}
+	if _, err := opts.Normalize(); err != nil {
+		return model.AIKnowledgeChunking{}, err
+	}
+	// 与上传时一致，按向量数据库中的实际集合名称保存
+	key := model.AIKnowledgeChunking{
+		NameSpace:  in.NameSpace,
+		Knowledge:  in.Knowledge,
+		Collection: kube.SanitizeCollectionName(in.CollectionName),
+	}
+
+	old, err := k.factory.AI().KnowledgeChunking().Find(ctx, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		chunking := key
+		chunking.InstanceID = utils.GetSnowflakeID()
+		chunking.Strategy = in.Strategy
+		chunking.ChunkSize = in.ChunkSize
+		chunking.ChunkOverlap = in.ChunkOverlap
+		chunking.MaxTokens = in.MaxTokens
+		chunking.Creator = creator
+		if err := k.factory.AI().KnowledgeChunking().Save(ctx, &chunking); err != nil {
+			return model.AIKnowledgeChunking{}, err
+		}
+		return chunking, nil
+	}
+	if err != nil {
+		return model.AIKnowledgeChunking{}, err
+	}
+
+	if err := k.factory.AI().KnowledgeChunking().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", old.InstanceID)
+	}, map[string]interface{}{
+		"strategy":     in.Strategy,
+		"chunkSize":    in.ChunkSize,
+		"chunkOverlap": in.ChunkOverlap,
+		"maxTokens":    in.MaxTokens,
+	}); err != nil {
+		return model.AIKnowledgeChunking{}, err
+	}
+	return k.factory.AI().KnowledgeChunking().Find(ctx, model.AIKnowledgeChunking{InstanceID: old.InstanceID})
+}
+
+func (k *knowledgeChunkingService) DeleteChunking(ctx context.Context, instanceID string) error {
+	if _, err := k.factory.AI().KnowledgeChunking().Find(ctx, model.AIKnowledgeChunking{InstanceID: instanceID}); err != nil {
+		return err
+	}
+	return k.factory.AI().KnowledgeChunking().Delete(ctx, model.AIKnowledgeChunking{InstanceID: instanceID}, true)
+}
+
+func (k *knowledgeChunkingService) ListChunking(ctx context.Context, in *dto.AIKnowledgeChunkingListInput) ([]model.AIKnowledgeChunking, error) {
+	out, err := k.factory.AI().KnowledgeChunking().FindList(ctx, model.AIKnowledgeChunking{NameSpace: in.NameSpace, Knowledge: in.Knowledge})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []model.AIKnowledgeChunking{}
+	}
+	return out, nil
+}
+
+func (k *knowledgeChunkingService) Lookup(ctx context.Context, namespace, knowledge, collection string) (*chunker.Options, error) {
+	record, err := k.factory.AI().KnowledgeChunking().Find(ctx, model.AIKnowledgeChunking{
+		NameSpace:  namespace,
+		Knowledge:  knowledge,
+		Collection: collection,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chunker.Options{
+		Strategy:     record.Strategy,
+		ChunkSize:    record.ChunkSize,
+		ChunkOverlap: record.ChunkOverlap,
+		MaxTokens:    record.MaxTokens,
+	}, nil
+}
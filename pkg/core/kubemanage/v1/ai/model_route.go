@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// routeCacheTTL 虚拟模型缓存时间，每次推理请求都会查找，修改路由时会立即失效
+const routeCacheTTL = 30 * time.Second
+
+type cachedRoute struct {
+	// route 为 nil 表示该名称不是虚拟模型
+	route    *kube.ModelRoute
+	expireAt time.Time
+}
+
+// routeCache 虚拟模型缓存，key 为虚拟模型名称
+var routeCache sync.Map
+
+type ModelRouteService interface {
+	// SaveRoute 创建或更新虚拟模型，按名称覆盖已存在的路由
+	SaveRoute(ctx context.Context, in *dto.AIModelRouteInput, creator string) (dto.AIModelRouteOut, error)
+	DeleteRoute(ctx context.Context, instanceID string) error
+	ListRoute(ctx context.Context) ([]dto.AIModelRouteOut, error)
+	// Lookup 查找虚拟模型，作为 kube.SetModelRouter 的回调，不是虚拟模型时返回 nil
+	Lookup(ctx context.Context, name string) *kube.ModelRoute
+}
+
+func NewModelRouteService(factory dao.ShareDaoFactory) ModelRouteService {
+	return &modelRouteService{factory: factory, log: logger.New(logger.LG)}
+}
+
+type modelRouteService struct {
+	factory dao.ShareDaoFactory
+	log     logger.Logger
+}
+
+func (m *modelRouteService) SaveRoute(ctx context.Context, in *dto.AIModelRouteInput, creator string) (dto.AIModelRouteOut, error) {
+	for _, target := range in.Targets {
+		if target.Model == in.Name {
+			return dto.AIModelRouteOut{}, fmt.Errorf("目标模型不能与虚拟模型 %s 同名", in.Name)
+		}
+	}
+	targets, err := json.Marshal(in.Targets)
+	if err != nil {
+		return dto.AIModelRouteOut{}, err
+	}
+	defer routeCache.Delete(in.Name)
+
+	old, err := m.factory.AI().ModelRoute().Find(ctx, model.AIModelRoute{Name: in.Name})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		route := model.AIModelRoute{
+			InstanceID:  utils.GetSnowflakeID(),
+			Name:        in.Name,
+			Strategy:    in.Strategy,
+			Targets:     string(targets),
+			Description: in.Description,
+			Creator:     creator,
+		}
+		if err := m.factory.AI().ModelRoute().Save(ctx, &route); err != nil {
+			return dto.AIModelRouteOut{}, err
+		}
+		return toRouteOut(route), nil
+	}
+	if err != nil {
+		return dto.AIModelRouteOut{}, err
+	}
+
+	if err := m.factory.AI().ModelRoute().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", old.InstanceID)
+	}, map[string]interface{}{
+		"strategy":    in.Strategy,
+		"targets":     string(targets),
+		"description": in.Description,
+	}); err != nil {
+		return dto.AIModelRouteOut{}, err
+	}
+	route, err := m.factory.AI().ModelRoute().Find(ctx, model.AIModelRoute{InstanceID: old.InstanceID})
+	if err != nil {
+		return dto.AIModelRouteOut{}, err
+	}
+	return toRouteOut(route), nil
+}
+
+func (m *modelRouteService) DeleteRoute(ctx context.Context, instanceID string) error {
+	route, err := m.factory.AI().ModelRoute().Find(ctx, model.AIModelRoute{InstanceID: instanceID})
+	if err != nil {
+		return err
+	}
+	defer routeCache.Delete(route.Name)
+	return m.factory.AI().ModelRoute().Delete(ctx, model.AIModelRoute{InstanceID: instanceID}, true)
+}
+
+func (m *modelRouteService) ListRoute(ctx context.Context) ([]dto.AIModelRouteOut, error) {
+	routes, err := m.factory.AI().ModelRoute().FindList(ctx, model.AIModelRoute{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.AIModelRouteOut, 0, len(routes))
+	for _, route := range routes {
+		out = append(out, toRouteOut(route))
+	}
+	return out, nil
+}
+
+func (m *modelRouteService) Lookup(ctx context.Context, name string) *kube.ModelRoute {
+	if v, ok := routeCache.Load(name); ok {
+		if cached := v.(cachedRoute); time.Now().Before(cached.expireAt) {
+			return cached.route
+		}
+	}
+
+	var route *kube.ModelRoute
+	record, err := m.factory.AI().ModelRoute().Find(ctx, model.AIModelRoute{Name: name})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		// 查询失败时按普通模型处理，不缓存结果
+		m.log.ErrorWithErr("查询虚拟模型失败", err)
+		return nil
+	default:
+		route = &kube.ModelRoute{Name: record.Name, Strategy: record.Strategy}
+		for _, target := range toRouteOut(record).Targets {
+			route.Targets = append(route.Targets, kube.RouteTarget{
+				OllamaTarget: kubeDto.OllamaTarget{PodName: target.PodName, Deployment: target.Deployment, NameSpace: target.NameSpace},
+				Model:        target.Model,
+				Weight:       target.Weight,
+				Timeout:      time.Duration(target.TimeoutSeconds) * time.Second,
+			})
+		}
+	}
+	routeCache.Store(name, cachedRoute{route: route, expireAt: time.Now().Add(routeCacheTTL)})
+	return route
+}
+
+func toRouteOut(route model.AIModelRoute) dto.AIModelRouteOut {
+	out := dto.AIModelRouteOut{AIModelRoute: route, Targets: []dto.AIModelRouteTarget{}}
+	_ = json.Unmarshal([]byte(route.Targets), &out.Targets)
+	return out
+}
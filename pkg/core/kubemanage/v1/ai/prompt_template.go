@@ -0,0 +1,196 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+var (
+	ErrPromptNotVisible  = errors.New("无权使用该提示词模板")
+	ErrPromptNotOwner    = errors.New("只有创建人可以修改或删除提示词模板")
+	ErrPromptNoAuthority = errors.New("可见范围为 authority 时必须指定角色")
+)
+
+type PromptTemplateService interface {
+	Create(ctx context.Context, in *dto.AIPromptTemplateInput, userID int, userName string) (model.AIPromptTemplate, error)
+	// Update 修改模板属性，内容与最新版本不同时生成新版本，只有创建人可以修改
+	Update(ctx context.Context, in *dto.AIPromptTemplateUpdateInput, userID int, userName string) (model.AIPromptTemplate, error)
+	Delete(ctx context.Context, instanceID string, userID int) error
+	PageTemplate(ctx context.Context, pager runtime.Pager) (dto.PageAIPromptTemplateOut, error)
+	// Get 获取模板指定版本（0 为最新版本）的内容及版本历史
+	Get(ctx context.Context, instanceID string, version int, userID int, authorityID uint) (dto.AIPromptTemplateDetailOut, error)
+	// Resolve 校验可见范围后返回模板指定版本，供聊天接口引用
+	Resolve(ctx context.Context, instanceID string, version int, userID int, authorityID uint) (model.AIPromptTemplateVersion, error)
+}
+
+func NewPromptTemplateService(factory dao.ShareDaoFactory) PromptTemplateService {
+	return &promptTemplateService{factory: factory}
+}
+
+type promptTemplateService struct {
+	factory dao.ShareDaoFactory
+}
+
+func (p *promptTemplateService) Create(ctx context.Context, in *dto.AIPromptTemplateInput, userID int, userName string) (model.AIPromptTemplate, error) {
+	authorityIDs, err := joinAuthorityIDs(in.Visibility, in.AuthorityIDs)
+	if err != nil {
+		return model.AIPromptTemplate{}, err
+	}
+	tpl := model.AIPromptTemplate{
+		InstanceID:    utils.GetSnowflakeID(),
+		Name:          in.Name,
+		Description:   in.Description,
+		Visibility:    in.Visibility,
+		AuthorityIDs:  authorityIDs,
+		OwnerID:       userID,
+		OwnerName:     userName,
+		LatestVersion: 1,
+	}
+	version := newPromptVersion(tpl.InstanceID, 1, in.Content, in.Comment, userName)
+	return tpl, p.factory.AI().PromptTemplate().Save(ctx, &tpl, &version)
+}
+
+func (p *promptTemplateService) Update(ctx context.Context, in *dto.AIPromptTemplateUpdateInput, userID int, userName string) (model.AIPromptTemplate, error) {
+	tpl, err := p.owned(ctx, in.InstanceID, userID)
+	if err != nil {
+		return model.AIPromptTemplate{}, err
+	}
+	authorityIDs, err := joinAuthorityIDs(in.Visibility, in.AuthorityIDs)
+	if err != nil {
+		return model.AIPromptTemplate{}, err
+	}
+	if err := p.factory.AI().PromptTemplate().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", tpl.InstanceID)
+	}, map[string]interface{}{
+		"description":  in.Description,
+		"visibility":   in.Visibility,
+		"authorityIDs": authorityIDs,
+	}); err != nil {
+		return model.AIPromptTemplate{}, err
+	}
+
+	if in.Content != "" {
+		latest, err := p.factory.AI().PromptTemplate().FindVersion(ctx, tpl.InstanceID, 0)
+		if err != nil {
+			return model.AIPromptTemplate{}, err
+		}
+		if latest.Content != in.Content {
+			version := newPromptVersion(tpl.InstanceID, latest.Version+1, in.Content, in.Comment, userName)
+			if err := p.factory.AI().PromptTemplate().SaveVersion(ctx, &version); err != nil {
+				return model.AIPromptTemplate{}, err
+			}
+		}
+	}
+	return p.factory.AI().PromptTemplate().Find(ctx, model.AIPromptTemplate{InstanceID: tpl.InstanceID})
+}
+
+func (p *promptTemplateService) Delete(ctx context.Context, instanceID string, userID int) error {
+	if _, err := p.owned(ctx, instanceID, userID); err != nil {
+		return err
+	}
+	return p.factory.AI().PromptTemplate().Delete(ctx, model.AIPromptTemplate{InstanceID: instanceID})
+}
+
+func (p *promptTemplateService) PageTemplate(ctx context.Context, pager runtime.Pager) (dto.PageAIPromptTemplateOut, error) {
+	list, total, err := p.factory.AI().PromptTemplate().PageList(ctx, pager)
+	if err != nil {
+		return dto.PageAIPromptTemplateOut{}, err
+	}
+	return dto.PageAIPromptTemplateOut{Total: total, List: list, Page: pager.GetPage(), PageSize: pager.GetPageSize()}, nil
+}
+
+func (p *promptTemplateService) Get(ctx context.Context, instanceID string, version int, userID int, authorityID uint) (dto.AIPromptTemplateDetailOut, error) {
+	tpl, err := p.visible(ctx, instanceID, userID, authorityID)
+	if err != nil {
+		return dto.AIPromptTemplateDetailOut{}, err
+	}
+	current, err := p.factory.AI().PromptTemplate().FindVersion(ctx, instanceID, version)
+	if err != nil {
+		return dto.AIPromptTemplateDetailOut{}, err
+	}
+	versions, err := p.factory.AI().PromptTemplate().ListVersions(ctx, instanceID)
+	if err != nil {
+		return dto.AIPromptTemplateDetailOut{}, err
+	}
+	return dto.AIPromptTemplateDetailOut{
+		AIPromptTemplate: tpl,
+		Version:          current,
+		Variables:        kube.ExtractPromptVariables(current.Content),
+		Versions:         versions,
+	}, nil
+}
+
+func (p *promptTemplateService) Resolve(ctx context.Context, instanceID string, version int, userID int, authorityID uint) (model.AIPromptTemplateVersion, error) {
+	if _, err := p.visible(ctx, instanceID, userID, authorityID); err != nil {
+		return model.AIPromptTemplateVersion{}, err
+	}
+	return p.factory.AI().PromptTemplate().FindVersion(ctx, instanceID, version)
+}
+
+// visible 获取模板并校验当前用户是否可见
+func (p *promptTemplateService) visible(ctx context.Context, instanceID string, userID int, authorityID uint) (model.AIPromptTemplate, error) {
+	tpl, err := p.factory.AI().PromptTemplate().Find(ctx, model.AIPromptTemplate{InstanceID: instanceID})
+	if err != nil {
+		return tpl, err
+	}
+	switch {
+	case tpl.OwnerID == userID, tpl.Visibility == model.AIPromptVisibilityPublic:
+		return tpl, nil
+	case tpl.Visibility == model.AIPromptVisibilityAuthority:
+		for _, id := range strings.Split(tpl.AuthorityIDs, ",") {
+			if id == strconv.FormatUint(uint64(authorityID), 10) {
+				return tpl, nil
+			}
+		}
+	}
+	return tpl, ErrPromptNotVisible
+}
+
+// owned 获取模板并校验当前用户是否为创建人
+func (p *promptTemplateService) owned(ctx context.Context, instanceID string, userID int) (model.AIPromptTemplate, error) {
+	tpl, err := p.factory.AI().PromptTemplate().Find(ctx, model.AIPromptTemplate{InstanceID: instanceID})
+	if err != nil {
+		return tpl, err
+	}
+	if tpl.OwnerID != userID {
+		return tpl, ErrPromptNotOwner
+	}
+	return tpl, nil
+}
+
+func newPromptVersion(templateID string, version int, content, comment, creator string) model.AIPromptTemplateVersion {
+	return model.AIPromptTemplateVersion{
+		TemplateID: templateID,
+		Version:    version,
+		Content:    content,
+		Variables:  strings.Join(kube.ExtractPromptVariables(content), ","),
+		Comment:    comment,
+		Creator:    creator,
+	}
+}
+
+// joinAuthorityIDs 将可见角色转换为逗号分隔的字符串，便于按角色过滤
+func joinAuthorityIDs(visibility string, ids []uint) (string, error) {
+	if visibility != model.AIPromptVisibilityAuthority {
+		return "", nil
+	}
+	if len(ids) == 0 {
+		return "", ErrPromptNoAuthority
+	}
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(parts, ","), nil
+}
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// progressInterval 进度写库的最小间隔，避免每条进度都更新数据库
+const progressInterval = time.Second
+
+// errPullCanceled 用户主动取消任务
+var errPullCanceled = errors.New("pull job canceled")
+
+// pullCancels 正在运行的拉取任务，key 为任务 InstanceID，value 为 context.CancelCauseFunc
+var pullCancels sync.Map
+
+type PullJobService interface {
+	CreatePullJob(ctx context.Context, in *kubeDto.OllamaPullModelInput, creator string) ([]model.AIPullJob, error)
+	GetPullJob(ctx context.Context, instanceID string) (model.AIPullJob, error)
+	PagePullJob(ctx context.Context, pager runtime.Pager) (dto.PageAIPullJobOut, error)
+	CancelPullJob(ctx context.Context, instanceID string) error
+	// FailInterruptedJobs 将服务重启前未完成的任务标记为失败
+	FailInterruptedJobs(ctx context.Context) error
+}
+
+func NewPullJobService(factory dao.ShareDaoFactory) PullJobService {
+	return &pullJobService{factory: factory, log: logger.New(logger.LG)}
+}
+
+type pullJobService struct {
+	factory dao.ShareDaoFactory
+	log     logger.Logger
+}
+
+func (p *pullJobService) CreatePullJob(ctx context.Context, in *kubeDto.OllamaPullModelInput, creator string) ([]model.AIPullJob, error) {
+	// 指定部署时每个就绪副本各拉取一次，保证所有副本都能提供该模型
+	pods, err := kube.Ollama.ReadyPods(ctx, in.OllamaTarget)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]model.AIPullJob, 0, len(pods))
+	for _, pod := range pods {
+		job := model.AIPullJob{
+			InstanceID: utils.GetSnowflakeID(),
+			PodName:    pod,
+			Namespace:  in.NameSpace,
+			ModelName:  in.ModelName,
+			Status:     model.PullJobPending,
+			Creator:    creator,
+		}
+		if err := p.factory.AI().PullJob().Save(ctx, &job); err != nil {
+			return nil, err
+		}
+
+		jobCtx, cancel := context.WithCancelCause(runtime.SystemContext)
+		pullCancels.Store(job.InstanceID, cancel)
+		go p.run(jobCtx, job.InstanceID, job.PodName, job.Namespace, job.ModelName)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (p *pullJobService) GetPullJob(ctx context.Context, instanceID string) (model.AIPullJob, error) {
+	return p.factory.AI().PullJob().Find(ctx, model.AIPullJob{InstanceID: instanceID})
+}
+
+func (p *pullJobService) PagePullJob(ctx context.Context, pager runtime.Pager) (dto.PageAIPullJobOut, error) {
+	list, total, err := p.factory.AI().PullJob().PageList(ctx, pager)
+	if err != nil {
+		return dto.PageAIPullJobOut{}, err
+	}
+	return dto.PageAIPullJobOut{Total: total, List: list, Page: pager.GetPage(), PageSize: pager.GetPageSize()}, nil
+}
+
+func (p *pullJobService) CancelPullJob(ctx context.Context, instanceID string) error {
+	job, err := p.GetPullJob(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	if job.IsFinished() {
+		return fmt.Errorf("任务已结束，当前状态为 %s", job.Status)
+	}
+	cancel, ok := pullCancels.Load(instanceID)
+	if !ok {
+		// 任务不在当前实例中运行（例如服务已重启），直接标记为取消
+		return p.finish(instanceID, model.PullJobCanceled, "任务已取消")
+	}
+	cancel.(context.CancelCauseFunc)(errPullCanceled)
+	return nil
+}
+
+func (p *pullJobService) FailInterruptedJobs(ctx context.Context) error {
+	now := time.Now()
+	return p.factory.AI().PullJob().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status in ?", []string{model.PullJobPending, model.PullJobRunning})
+	}, &model.AIPullJob{Status: model.PullJobFailed, Message: "服务重启，任务中断", FinishedAt: &now})
+}
+
+// run 执行拉取任务，解析 Ollama 返回的进度流并定期写入数据库
+func (p *pullJobService) run(ctx context.Context, instanceID, podName, namespace, modelName string) {
+	defer pullCancels.Delete(instanceID)
+
+	p.update(instanceID, &model.AIPullJob{Status: model.PullJobRunning, Message: "开始拉取模型"})
+
+	progress := newPullProgress()
+	lastUpdate := time.Now()
+	lastStatus := ""
+	err := kube.Ollama.PullModelStream(ctx, podName, namespace, modelName, func(chunk map[string]interface{}) error {
+		status := progress.add(chunk)
+		// 状态变化或距离上次写库超过间隔时更新
+		if status != lastStatus || time.Since(lastUpdate) >= progressInterval {
+			lastStatus = status
+			lastUpdate = time.Now()
+			p.update(instanceID, progress.job(status))
+		}
+		return nil
+	})
+
+	switch {
+	case errors.Is(context.Cause(ctx), errPullCanceled):
+		_ = p.finish(instanceID, model.PullJobCanceled, "任务已取消")
+	case err != nil:
+		p.log.ErrorWithErr(fmt.Sprintf("pull model %s on %s/%s failed", modelName, namespace, podName), err)
+		_ = p.finish(instanceID, model.PullJobFailed, err.Error())
+	case lastStatus != "success":
+		_ = p.finish(instanceID, model.PullJobFailed, fmt.Sprintf("拉取未完成，最后状态: %s", lastStatus))
+	default:
+		job := progress.job(lastStatus)
+		job.Percent = 100
+		p.update(instanceID, job)
+		_ = p.finish(instanceID, model.PullJobSuccess, "success")
+	}
+}
+
+func (p *pullJobService) update(instanceID string, in *model.AIPullJob) {
+	// 任务上下文可能已被取消，使用独立的 context 写库
+	if err := p.factory.AI().PullJob().Updates(context.Background(), func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", instanceID)
+	}, in); err != nil {
+		p.log.ErrorWithErr("update pull job failed", err)
+	}
+}
+
+func (p *pullJobService) finish(instanceID, status, message string) error {
+	now := time.Now()
+	err := p.factory.AI().PullJob().Updates(context.Background(), func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", instanceID)
+	}, &model.AIPullJob{Status: status, Message: message, FinishedAt: &now})
+	if err != nil {
+		p.log.ErrorWithErr("finish pull job failed", err)
+	}
+	return err
+}
+
+// pullProgress 按 digest 累计每一层的下载进度，得到整体进度
+type pullProgress struct {
+	layers map[string][2]int64
+	order  []string
+}
+
+func newPullProgress() *pullProgress {
+	return &pullProgress{layers: make(map[string][2]int64)}
+}
+
+// add 记录一条进度信息并返回其中的 status 字段
+func (p *pullProgress) add(chunk map[string]interface{}) string {
+	status, _ := chunk["status"].(string)
+	digest, _ := chunk["digest"].(string)
+	if digest == "" {
+		return status
+	}
+	total, _ := chunk["total"].(float64)
+	completed, _ := chunk["completed"].(float64)
+	if _, ok := p.layers[digest]; !ok {
+		p.order = append(p.order, digest)
+	}
+	p.layers[digest] = [2]int64{int64(completed), int64(total)}
+	return status
+}
+
+func (p *pullProgress) job(status string) *model.AIPullJob {
+	var completed, total int64
+	for _, digest := range p.order {
+		completed += p.layers[digest][0]
+		total += p.layers[digest][1]
+	}
+	job := &model.AIPullJob{Status: model.PullJobRunning, Message: status, Completed: completed, Total: total}
+	if total > 0 {
+		job.Percent = float64(completed) * 100 / float64(total)
+	}
+	return job
+}
@@ -0,0 +1,251 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+const (
+	// quotaCacheTTL 配额配置缓存时间，修改配额时会立即失效
+	quotaCacheTTL = 30 * time.Second
+	// tokenCacheTTL 当日 token 用量缓存时间，用量异步写库，本身就有少量延迟
+	tokenCacheTTL = 10 * time.Second
+	// quotaWaitInterval Wait 在频率或并发超限时重试的间隔
+	quotaWaitInterval = time.Second
+)
+
+var (
+	ErrRateLimited      = errors.New("请求过于频繁")
+	ErrConcurrencyLimit = errors.New("并发请求数超出限制")
+	ErrTokenQuota       = errors.New("今日 token 额度已用完")
+)
+
+type cachedQuota struct {
+	quota    *model.AIQuota
+	expireAt time.Time
+}
+
+type userLimiter struct {
+	limiter *rate.Limiter
+	rpm     int
+}
+
+type cachedTokens struct {
+	day      string
+	used     int64
+	expireAt time.Time
+}
+
+var (
+	// quotaCache 配额配置缓存，key 为 subjectType/subjectID
+	quotaCache sync.Map
+	// rateLimiters 每个调用方的令牌桶，key 为 user/用户ID 或 apikey/Key 名称
+	rateLimiters sync.Map
+	// inflight 每个调用方正在进行的推理请求数，key 同 rateLimiters
+	inflight sync.Map
+	// tokenUsed 每个调用方当日已消耗的 token 数，key 同 rateLimiters
+	tokenUsed sync.Map
+)
+
+type QuotaService interface {
+	// SaveQuota 创建或更新角色/用户的配额
+	SaveQuota(ctx context.Context, in *dto.AIQuotaInput, creator string) (model.AIQuota, error)
+	DeleteQuota(ctx context.Context, instanceID string) error
+	ListQuota(ctx context.Context, in *dto.AIQuotaListInput) ([]model.AIQuota, error)
+	// Acquire 在调用 Ollama 前检查用户配额，通过时返回 release，请求结束后必须调用
+	// 绑定到角色的配额对该角色下每个用户单独计算
+	Acquire(ctx context.Context, userID int, authorityID uint) (release func(), err error)
+	// Wait 与 Acquire 相同，但请求频率或并发数超限时等待名额，用于后台任务逐次调用模型
+	Wait(ctx context.Context, userID int, authorityID uint) (release func(), err error)
+	// AcquireAPIKey 检查 /v1 接口 API Key 的配额，quota 中为 0 的项不限制
+	AcquireAPIKey(ctx context.Context, name string, quota model.AIQuota) (release func(), err error)
+}
+
+func NewQuotaService(factory dao.ShareDaoFactory) QuotaService {
+	return &quotaService{factory: factory}
+}
+
+type quotaService struct {
+	factory dao.ShareDaoFactory
+}
+
+func (q *quotaService) SaveQuota(ctx context.Context, in *dto.AIQuotaInput, creator string) (model.AIQuota, error) {
+	search := model.AIQuota{SubjectType: in.SubjectType, SubjectID: in.SubjectID}
+	defer quotaCache.Delete(quotaKey(in.SubjectType, in.SubjectID))
+
+	old, err := q.factory.AI().Quota().Find(ctx, search)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		quota := model.AIQuota{
+			InstanceID:        utils.GetSnowflakeID(),
+			SubjectType:       in.SubjectType,
+			SubjectID:         in.SubjectID,
+			RequestsPerMinute: in.RequestsPerMinute,
+			ConcurrentStreams: in.ConcurrentStreams,
+			DailyTokens:       in.DailyTokens,
+			Creator:           creator,
+		}
+		return quota, q.factory.AI().Quota().Save(ctx, &quota)
+	}
+	if err != nil {
+		return model.AIQuota{}, err
+	}
+
+	if err := q.factory.AI().Quota().Updates(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("instanceID = ?", old.InstanceID)
+	}, map[string]interface{}{
+		"requestsPerMinute": in.RequestsPerMinute,
+		"concurrentStreams": in.ConcurrentStreams,
+		"dailyTokens":       in.DailyTokens,
+	}); err != nil {
+		return model.AIQuota{}, err
+	}
+	return q.factory.AI().Quota().Find(ctx, model.AIQuota{InstanceID: old.InstanceID})
+}
+
+func (q *quotaService) DeleteQuota(ctx context.Context, instanceID string) error {
+	quota, err := q.factory.AI().Quota().Find(ctx, model.AIQuota{InstanceID: instanceID})
+	if err != nil {
+		return err
+	}
+	defer quotaCache.Delete(quotaKey(quota.SubjectType, quota.SubjectID))
+	return q.factory.AI().Quota().Delete(ctx, model.AIQuota{InstanceID: instanceID}, true)
+}
+
+func (q *quotaService) ListQuota(ctx context.Context, in *dto.AIQuotaListInput) ([]model.AIQuota, error) {
+	return q.factory.AI().Quota().FindList(ctx, model.AIQuota{SubjectType: in.SubjectType})
+}
+
+func (q *quotaService) Acquire(ctx context.Context, userID int, authorityID uint) (func(), error) {
+	quota, err := q.effectiveQuota(ctx, userID, authorityID)
+	if err != nil || quota == nil {
+		return func() {}, err
+	}
+	return q.acquire(ctx, "user/"+strconv.Itoa(userID), quota, func(ctx context.Context, since time.Time) (int64, error) {
+		return q.factory.AI().Usage().SumTokens(ctx, userID, since)
+	})
+}
+
+func (q *quotaService) Wait(ctx context.Context, userID int, authorityID uint) (func(), error) {
+	for {
+		release, err := q.Acquire(ctx, userID, authorityID)
+		if !errors.Is(err, ErrRateLimited) && !errors.Is(err, ErrConcurrencyLimit) {
+			return release, err
+		}
+		select {
+		case <-ctx.Done():
+			return func() {}, context.Cause(ctx)
+		case <-time.After(quotaWaitInterval):
+		}
+	}
+}
+
+func (q *quotaService) AcquireAPIKey(ctx context.Context, name string, quota model.AIQuota) (func(), error) {
+	return q.acquire(ctx, "apikey/"+name, &quota, func(ctx context.Context, since time.Time) (int64, error) {
+		return q.factory.AI().Usage().SumCallerTokens(ctx, name, "openai", since)
+	})
+}
+
+// acquire 按配额检查调用方 subject 的 token 额度、并发数及请求频率，sumTokens 统计调用方自 since 起消耗的 token 数
+func (q *quotaService) acquire(ctx context.Context, subject string, quota *model.AIQuota, sumTokens func(ctx context.Context, since time.Time) (int64, error)) (func(), error) {
+	noop := func() {}
+	if quota.DailyTokens > 0 {
+		used, err := q.tokensToday(ctx, subject, sumTokens)
+		if err != nil {
+			return noop, err
+		}
+		if used >= quota.DailyTokens {
+			return noop, fmt.Errorf("%w: 已使用 %d / %d", ErrTokenQuota, used, quota.DailyTokens)
+		}
+	}
+
+	release := noop
+	if quota.ConcurrentStreams > 0 {
+		counter, _ := inflight.LoadOrStore(subject, new(int64))
+		if n := atomic.AddInt64(counter.(*int64), 1); n > int64(quota.ConcurrentStreams) {
+			atomic.AddInt64(counter.(*int64), -1)
+			return noop, fmt.Errorf("%w: 最多同时进行 %d 个请求", ErrConcurrencyLimit, quota.ConcurrentStreams)
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { atomic.AddInt64(counter.(*int64), -1) })
+		}
+	}
+
+	if quota.RequestsPerMinute > 0 && !q.limiter(subject, quota.RequestsPerMinute).Allow() {
+		release()
+		return noop, fmt.Errorf("%w: 每分钟最多 %d 次", ErrRateLimited, quota.RequestsPerMinute)
+	}
+	return release, nil
+}
+
+// effectiveQuota 获取用户生效的配额，用户配额优先于角色配额，都没有时返回 nil
+func (q *quotaService) effectiveQuota(ctx context.Context, userID int, authorityID uint) (*model.AIQuota, error) {
+	quota, err := q.findQuota(ctx, model.AIQuotaSubjectUser, uint(userID))
+	if err != nil || quota != nil {
+		return quota, err
+	}
+	return q.findQuota(ctx, model.AIQuotaSubjectAuthority, authorityID)
+}
+
+func (q *quotaService) findQuota(ctx context.Context, subjectType string, subjectID uint) (*model.AIQuota, error) {
+	key := quotaKey(subjectType, subjectID)
+	if cached, ok := quotaCache.Load(key); ok && time.Now().Before(cached.(cachedQuota).expireAt) {
+		return cached.(cachedQuota).quota, nil
+	}
+	quota, err := q.factory.AI().Quota().Find(ctx, model.AIQuota{SubjectType: subjectType, SubjectID: subjectID})
+	var out *model.AIQuota
+	switch {
+	case err == nil:
+		out = &quota
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	quotaCache.Store(key, cachedQuota{quota: out, expireAt: time.Now().Add(quotaCacheTTL)})
+	return out, nil
+}
+
+// limiter 获取调用方的令牌桶，配额变化时重建
+func (q *quotaService) limiter(subject string, rpm int) *rate.Limiter {
+	if v, ok := rateLimiters.Load(subject); ok && v.(*userLimiter).rpm == rpm {
+		return v.(*userLimiter).limiter
+	}
+	l := &userLimiter{limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(rpm)), rpm), rpm: rpm}
+	rateLimiters.Store(subject, l)
+	return l.limiter
+}
+
+// tokensToday 获取调用方当日已消耗的 token 数
+func (q *quotaService) tokensToday(ctx context.Context, subject string, sumTokens func(ctx context.Context, since time.Time) (int64, error)) (int64, error) {
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	if v, ok := tokenUsed.Load(subject); ok {
+		cached := v.(cachedTokens)
+		if cached.day == day && now.Before(cached.expireAt) {
+			return cached.used, nil
+		}
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	used, err := sumTokens(ctx, start)
+	if err != nil {
+		return 0, err
+	}
+	tokenUsed.Store(subject, cachedTokens{day: day, used: used, expireAt: now.Add(tokenCacheTTL)})
+	return used, nil
+}
+
+func quotaKey(subjectType string, subjectID uint) string {
+	return subjectType + "/" + strconv.FormatUint(uint64(subjectID), 10)
+}
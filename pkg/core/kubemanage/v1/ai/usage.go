@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/runtime"
+)
+
+// userDeptTTL 用户所属部门的缓存时间，避免每次推理都查询用户表
+const userDeptTTL = 5 * time.Minute
+
+type userDept struct {
+	deptID   uint
+	expireAt time.Time
+}
+
+// userDepts 用户所属部门缓存，key 为用户ID
+var userDepts sync.Map
+
+type UsageService interface {
+	// Record 异步保存一次推理调用的用量，作为 kube.SetUsageRecorder 的回调
+	Record(record kube.UsageRecord)
+	PageUsage(ctx context.Context, pager runtime.Pager) (dto.PageAIUsageOut, error)
+	Report(ctx context.Context, in *dto.AIUsageReportInput) ([]model.AIUsageStat, error)
+}
+
+func NewUsageService(factory dao.ShareDaoFactory) UsageService {
+	return &usageService{factory: factory, log: logger.New(logger.LG)}
+}
+
+type usageService struct {
+	factory dao.ShareDaoFactory
+	log     logger.Logger
+}
+
+func (u *usageService) Record(record kube.UsageRecord) {
+	go func() {
+		ctx := context.Background()
+		usage := &model.AIUsage{
+			UserID:             record.Caller.UserID,
+			UserName:           record.Caller.UserName,
+			DepartmentID:       u.departmentOf(ctx, record.Caller.UserID),
+			Source:             record.Caller.Source,
+			Kind:               record.Kind,
+			Model:              record.Model,
+			Namespace:          record.Namespace,
+			Deployment:         record.Deployment,
+			PodName:            record.PodName,
+			PromptTokens:       record.PromptTokens,
+			CompletionTokens:   record.CompletionTokens,
+			TotalTokens:        record.PromptTokens + record.CompletionTokens,
+			TotalDuration:      record.TotalDuration,
+			LoadDuration:       record.LoadDuration,
+			PromptEvalDuration: record.PromptEvalDuration,
+			EvalDuration:       record.EvalDuration,
+		}
+		if err := u.factory.AI().Usage().Save(ctx, usage); err != nil {
+			u.log.ErrorWithErr("save ai usage failed", err)
+		}
+	}()
+}
+
+func (u *usageService) PageUsage(ctx context.Context, pager runtime.Pager) (dto.PageAIUsageOut, error) {
+	list, total, err := u.factory.AI().Usage().PageList(ctx, pager)
+	if err != nil {
+		return dto.PageAIUsageOut{}, err
+	}
+	return dto.PageAIUsageOut{Total: total, List: list, Page: pager.GetPage(), PageSize: pager.GetPageSize()}, nil
+}
+
+func (u *usageService) Report(ctx context.Context, in *dto.AIUsageReportInput) ([]model.AIUsageStat, error) {
+	var groupBy []string
+	byDept := false
+	for _, dim := range strings.Split(in.GroupBy, ",") {
+		dim = strings.TrimSpace(dim)
+		if dim == "" {
+			continue
+		}
+		switch dim {
+		case dto.AIUsageGroupUser, dto.AIUsageGroupModel, dto.AIUsageGroupDay:
+		case dto.AIUsageGroupDepartment:
+			byDept = true
+		default:
+			return nil, fmt.Errorf("不支持的分组维度: %s，可选 user、department、model、day", dim)
+		}
+		groupBy = append(groupBy, dim)
+	}
+
+	stats, err := u.factory.AI().Usage().Report(ctx, groupBy, &in.AIUsageFilter)
+	if err != nil {
+		return nil, err
+	}
+	if byDept {
+		depts, err := u.factory.Department().FindList(ctx, &model.Department{})
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[uint]string, len(depts))
+		for _, dept := range depts {
+			names[dept.DeptId] = dept.DeptName
+		}
+		for i := range stats {
+			stats[i].DeptName = names[stats[i].DepartmentID]
+		}
+	}
+	return stats, nil
+}
+
+// departmentOf 获取用户所属部门，API Key 等非登录用户返回 0
+func (u *usageService) departmentOf(ctx context.Context, userID int) uint {
+	if userID == 0 {
+		return 0
+	}
+	if cached, ok := userDepts.Load(userID); ok && time.Now().Before(cached.(userDept).expireAt) {
+		return cached.(userDept).deptID
+	}
+	user, err := u.factory.User().Find(ctx, &model.SysUser{ID: userID})
+	if err != nil {
+		u.log.ErrorWithErr("find user department failed", err)
+		return 0
+	}
+	userDepts.Store(userID, userDept{deptID: user.DepartmentID, expireAt: time.Now().Add(userDeptTTL)})
+	return user.DepartmentID
+}
@@ -11,6 +11,7 @@ type CoreService interface {
 	CloudGetter
 	SystemGetter
 	CMDBGetter
+	AIGetter
 }
 
 func New(cfg *config.Config, factory dao.ShareDaoFactory) CoreService {
@@ -44,3 +45,7 @@ func (c *KubeManage) System() SystemInterface {
 func (c *KubeManage) CMDB() CMDBService {
 	return NewCMDBService(c.Factory)
 }
+
+func (c *KubeManage) AI() AIService {
+	return NewAIService(c.Factory)
+}
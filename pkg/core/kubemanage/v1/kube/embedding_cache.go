@@ -0,0 +1,222 @@
+package kube
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// EmbeddingStore 向量缓存的持久化层，由上层实现
+type EmbeddingStore interface {
+	GetEmbedding(ctx context.Context, model, hash string) ([]float64, bool, error)
+	PutEmbedding(ctx context.Context, model, hash string, vector []float64) error
+	// DeleteEmbeddings 删除模型的缓存，model 为空时删除全部
+	DeleteEmbeddings(ctx context.Context, model string) (int64, error)
+}
+
+// EmbeddingCacheStats 向量缓存命中统计，自进程启动起累计
+type EmbeddingCacheStats struct {
+	Enabled        bool    `json:"enabled"`
+	Persistent     bool    `json:"persistent"`
+	Capacity       int     `json:"capacity"`
+	Entries        int     `json:"entries"`
+	MemoryHits     int64   `json:"memory_hits"`
+	PersistentHits int64   `json:"persistent_hits"`
+	Misses         int64   `json:"misses"`
+	HitRate        float64 `json:"hit_rate"`
+}
+
+type embeddingEntry struct {
+	key    string
+	model  string
+	vector []float64
+}
+
+// embeddingCache 以 (模型, sha256(文本)) 为 key 的两级向量缓存，内存层为 LRU
+type embeddingCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	store    EmbeddingStore
+
+	memoryHits     int64
+	persistentHits int64
+	misses         int64
+}
+
+var embeddings = &embeddingCache{ll: list.New(), items: map[string]*list.Element{}}
+
+// ConfigureEmbeddingCache 设置内存层容量（条数）及持久化层，容量为 0 且没有持久化层时不启用缓存
+func ConfigureEmbeddingCache(capacity int, store EmbeddingStore) {
+	embeddings.mu.Lock()
+	defer embeddings.mu.Unlock()
+	embeddings.capacity = capacity
+	embeddings.store = store
+	embeddings.ll.Init()
+	embeddings.items = map[string]*list.Element{}
+}
+
+// GetEmbeddingCacheStats 返回向量缓存命中统计
+func GetEmbeddingCacheStats() EmbeddingCacheStats {
+	embeddings.mu.Lock()
+	stats := EmbeddingCacheStats{
+		Enabled:    embeddings.enabled(),
+		Persistent: embeddings.store != nil,
+		Capacity:   embeddings.capacity,
+		Entries:    embeddings.ll.Len(),
+	}
+	embeddings.mu.Unlock()
+	stats.MemoryHits = atomic.LoadInt64(&embeddings.memoryHits)
+	stats.PersistentHits = atomic.LoadInt64(&embeddings.persistentHits)
+	stats.Misses = atomic.LoadInt64(&embeddings.misses)
+	if total := stats.MemoryHits + stats.PersistentHits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.MemoryHits+stats.PersistentHits) / float64(total)
+	}
+	return stats
+}
+
+// InvalidateEmbeddingCache 清除模型的缓存，model 为空时清除全部，返回持久化层删除的条数
+func InvalidateEmbeddingCache(ctx context.Context, model string) (int64, error) {
+	embeddings.mu.Lock()
+	for key, elem := range embeddings.items {
+		if model == "" || elem.Value.(*embeddingEntry).model == model {
+			embeddings.ll.Remove(elem)
+			delete(embeddings.items, key)
+		}
+	}
+	store := embeddings.store
+	embeddings.mu.Unlock()
+	if store == nil {
+		return 0, nil
+	}
+	return store.DeleteEmbeddings(ctx, model)
+}
+
+// enabled 调用方需持有 mu
+func (c *embeddingCache) enabled() bool {
+	return c.capacity > 0 || c.store != nil
+}
+
+func (c *embeddingCache) get(ctx context.Context, model, hash string) ([]float64, bool) {
+	key := model + "/" + hash
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		c.mu.Unlock()
+		atomic.AddInt64(&c.memoryHits, 1)
+		return elem.Value.(*embeddingEntry).vector, true
+	}
+	store := c.store
+	c.mu.Unlock()
+
+	if store != nil {
+		// 持久化层不可用时按未命中处理，不影响推理
+		if vector, ok, err := store.GetEmbedding(ctx, model, hash); err == nil && ok {
+			atomic.AddInt64(&c.persistentHits, 1)
+			c.add(model, hash, vector)
+			return vector, true
+		}
+	}
+	atomic.AddInt64(&c.misses, 1)
+	return nil, false
+}
+
+func (c *embeddingCache) put(ctx context.Context, model, hash string, vector []float64) {
+	c.add(model, hash, vector)
+	c.mu.Lock()
+	store := c.store
+	c.mu.Unlock()
+	if store != nil {
+		_ = store.PutEmbedding(ctx, model, hash, vector)
+	}
+}
+
+// add 写入内存层，超出容量时淘汰最久未使用的条目
+func (c *embeddingCache) add(model, hash string, vector []float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity <= 0 {
+		return
+	}
+	key := model + "/" + hash
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&embeddingEntry{key: key, model: model, vector: vector})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*embeddingEntry).key)
+	}
+}
+
+// EmbedText 生成文本的向量，启用缓存时按 (模型, sha256(文本)) 复用已有结果
+// model 为虚拟模型时按路由选出的目标模型读写缓存，不同目标模型生成的向量不能混用
+// 返回的向量可能与其他调用方共享，不能修改
+func (o *ollama) EmbedText(ctx context.Context, target kubeDto.OllamaTarget, model, text string) (vector []float64, err error) {
+	if route := o.LookupRoute(ctx, model); route != nil {
+		err = o.route(ctx, route, func(ctx context.Context, t RouteTarget, _ func()) error {
+			vector, err = o.cachedEmbedText(ctx, t.OllamaTarget, t.Model, text)
+			return err
+		})
+		return vector, err
+	}
+	return o.cachedEmbedText(ctx, target, model, text)
+}
+
+func (o *ollama) cachedEmbedText(ctx context.Context, target kubeDto.OllamaTarget, model, text string) ([]float64, error) {
+	embeddings.mu.Lock()
+	enabled := embeddings.enabled()
+	embeddings.mu.Unlock()
+	if !enabled {
+		return o.embedText(ctx, target, model, text)
+	}
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+	if vector, ok := embeddings.get(ctx, model, hash); ok {
+		return vector, nil
+	}
+	vector, err := o.embedText(ctx, target, model, text)
+	if err != nil {
+		return nil, err
+	}
+	embeddings.put(ctx, model, hash, vector)
+	return vector, nil
+}
+
+// embedText 在指定目标上生成向量，model 为实际的模型名
+func (o *ollama) embedText(ctx context.Context, target kubeDto.OllamaTarget, model, text string) ([]float64, error) {
+	result, err := o.embeddingsTarget(ctx, target, model, text)
+	if err != nil {
+		return nil, err
+	}
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ollama 响应格式错误")
+	}
+	embeddingInterface, ok := resultMap["embedding"]
+	if !ok {
+		return nil, fmt.Errorf("ollama 响应中缺少 embedding 字段")
+	}
+	embeddingSlice, ok := embeddingInterface.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("embedding 格式错误")
+	}
+	embedding := make([]float64, len(embeddingSlice))
+	for i, v := range embeddingSlice {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("embedding 元素类型错误")
+		}
+		embedding[i] = f
+	}
+	return embedding, nil
+}
@@ -0,0 +1,100 @@
+package kube
+
+import (
+	"container/list"
+	"context"
+	"testing"
+)
+
+type memoryEmbeddingStore map[string][]float64
+
+func (m memoryEmbeddingStore) GetEmbedding(_ context.Context, model, hash string) ([]float64, bool, error) {
+	v, ok := m[model+"/"+hash]
+	return v, ok, nil
+}
+
+func (m memoryEmbeddingStore) PutEmbedding(_ context.Context, model, hash string, vector []float64) error {
+	m[model+"/"+hash] = vector
+	return nil
+}
+
+func (m memoryEmbeddingStore) DeleteEmbeddings(_ context.Context, model string) (int64, error) {
+	var n int64
+	for key := range m {
+		if model == "" || key[:len(model)+1] == model+"/" {
+			delete(m, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newTestEmbeddingCache(capacity int, store EmbeddingStore) *embeddingCache {
+	return &embeddingCache{capacity: capacity, store: store, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+func TestEmbeddingCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := newTestEmbeddingCache(2, nil)
+	c.put(ctx, "bge-m3", "a", []float64{1})
+	c.put(ctx, "bge-m3", "b", []float64{2})
+	// 访问 a 后 b 成为最久未使用的条目
+	if _, ok := c.get(ctx, "bge-m3", "a"); !ok {
+		t.Fatal("expected hit for a")
+	}
+	c.put(ctx, "bge-m3", "c", []float64{3})
+	if _, ok := c.get(ctx, "bge-m3", "b"); ok {
+		t.Fatal("b should have been evicted")
+	}
+	if v, ok := c.get(ctx, "bge-m3", "c"); !ok || v[0] != 3 {
+		t.Fatalf("unexpected c: %v %v", v, ok)
+	}
+	if _, ok := c.get(ctx, "nomic-embed-text", "a"); ok {
+		t.Fatal("cache key must include the model")
+	}
+	if c.memoryHits != 2 || c.misses != 2 {
+		t.Fatalf("hits=%d misses=%d", c.memoryHits, c.misses)
+	}
+}
+
+func TestEmbeddingCachePersistentTier(t *testing.T) {
+	ctx := context.Background()
+	store := memoryEmbeddingStore{}
+	c := newTestEmbeddingCache(1, store)
+	c.put(ctx, "bge-m3", "a", []float64{1})
+	c.put(ctx, "bge-m3", "b", []float64{2})
+	// a 已被内存层淘汰，从持久化层读取后重新进入内存层
+	if v, ok := c.get(ctx, "bge-m3", "a"); !ok || v[0] != 1 {
+		t.Fatalf("unexpected a: %v %v", v, ok)
+	}
+	if c.persistentHits != 1 {
+		t.Fatalf("persistentHits=%d", c.persistentHits)
+	}
+	if _, ok := c.get(ctx, "bge-m3", "a"); !ok || c.memoryHits != 1 {
+		t.Fatalf("expected memory hit, memoryHits=%d", c.memoryHits)
+	}
+}
+
+func TestInvalidateEmbeddingCache(t *testing.T) {
+	ctx := context.Background()
+	store := memoryEmbeddingStore{}
+	saved := embeddings
+	embeddings = newTestEmbeddingCache(10, store)
+	defer func() { embeddings = saved }()
+
+	embeddings.put(ctx, "bge-m3", "a", []float64{1})
+	embeddings.put(ctx, "nomic-embed-text", "a", []float64{2})
+	deleted, err := InvalidateEmbeddingCache(ctx, "bge-m3")
+	if err != nil || deleted != 1 {
+		t.Fatalf("deleted=%d err=%v", deleted, err)
+	}
+	if stats := GetEmbeddingCacheStats(); stats.Entries != 1 || !stats.Persistent {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if _, ok := embeddings.get(ctx, "bge-m3", "a"); ok {
+		t.Fatal("bge-m3 entry should be invalidated")
+	}
+	if _, ok := embeddings.get(ctx, "nomic-embed-text", "a"); !ok {
+		t.Fatal("other models must be kept")
+	}
+}
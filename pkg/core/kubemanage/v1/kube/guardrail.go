@@ -0,0 +1,417 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// 护栏规则类型
+const (
+	GuardrailTypePII       = "pii"        // 个人敏感信息，pattern 为逗号分隔的 phone、id_card、email，为空表示全部
+	GuardrailTypeKeyword   = "keyword"    // 关键词，pattern 为逗号或换行分隔的关键词，不区分大小写
+	GuardrailTypeRegex     = "regex"      // 正则表达式
+	GuardrailTypeMaxLength = "max_length" // 最大字符数，limit 为上限
+)
+
+// 护栏规则作用阶段
+const (
+	GuardrailStageInput  = "input"
+	GuardrailStageOutput = "output"
+	GuardrailStageBoth   = "both"
+)
+
+// 护栏规则命中后的处理方式
+const (
+	GuardrailActionBlock = "block" // 拦截请求或回答
+	GuardrailActionMask  = "mask"  // 脱敏后继续，max_length 规则为截断
+	GuardrailActionLog   = "log"   // 仅记录
+)
+
+// ErrGuardrailBlocked 内容命中拦截规则
+var ErrGuardrailBlocked = errors.New("内容违反安全规则")
+
+// piiPatterns 内置的个人敏感信息识别规则，含捕获组时只有第一个捕获组是敏感信息
+// 手机号前后只要求不是数字，+86、86 前缀及紧跟字母的号码都能识别
+var piiPatterns = map[string]*regexp.Regexp{
+	"phone":   regexp.MustCompile(`(?:^|\D)((?:\+?86[- ]?)?1[3-9]\d{9})(?:\D|$)`),
+	"id_card": regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+	"email":   regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+}
+
+// GuardrailRule 编译后的护栏规则
+type GuardrailRule struct {
+	ID     string
+	Name   string
+	Type   string
+	Stage  string
+	Action string
+	Limit  int
+
+	patterns map[string]*regexp.Regexp
+}
+
+// GuardrailHit 一次规则命中，Match 为脱敏后的命中内容
+type GuardrailHit struct {
+	Rule  *GuardrailRule
+	Match string
+}
+
+// GuardrailCheck 交给护栏检查的内容
+type GuardrailCheck struct {
+	Caller UsageCaller
+	Model  string
+	Stage  string
+	Text   string
+	// Offset 流式输出中 Text 之前已输出的字符数，max_length 规则按累计长度检查
+	Offset int
+}
+
+// guardrail 护栏检查回调，由上层在启动时注入，返回处理后的内容，命中拦截规则时返回错误
+var guardrail func(ctx context.Context, check GuardrailCheck) (string, error)
+
+// SetGuardrail 设置护栏检查回调，回调需自行缓存规则，每次推理请求都会调用
+func SetGuardrail(fn func(ctx context.Context, check GuardrailCheck) (string, error)) {
+	guardrail = fn
+}
+
+// NewGuardrailRule 校验并编译护栏规则
+func NewGuardrailRule(id, name, ruleType, pattern string, limit int, stage, action string) (*GuardrailRule, error) {
+	rule := &GuardrailRule{ID: id, Name: name, Type: ruleType, Stage: stage, Action: action, Limit: limit, patterns: map[string]*regexp.Regexp{}}
+	switch ruleType {
+	case GuardrailTypePII:
+		for _, kind := range splitPattern(pattern, ",") {
+			re, ok := piiPatterns[kind]
+			if !ok {
+				return nil, fmt.Errorf("不支持的敏感信息类型: %s", kind)
+			}
+			rule.patterns[kind] = re
+		}
+		if len(rule.patterns) == 0 {
+			for kind, re := range piiPatterns {
+				rule.patterns[kind] = re
+			}
+		}
+	case GuardrailTypeKeyword:
+		var quoted []string
+		for _, keyword := range splitPattern(pattern, ",\n") {
+			quoted = append(quoted, regexp.QuoteMeta(keyword))
+		}
+		if len(quoted) == 0 {
+			return nil, fmt.Errorf("关键词不能为空")
+		}
+		rule.patterns[ruleType] = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	case GuardrailTypeRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式错误: %v", err)
+		}
+		rule.patterns[ruleType] = re
+	case GuardrailTypeMaxLength:
+		if limit <= 0 {
+			return nil, fmt.Errorf("最大字符数必须大于 0")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的规则类型: %s", ruleType)
+	}
+	return rule, nil
+}
+
+// EvaluateGuardrails 按顺序对内容执行适用于 stage 的规则，返回处理后的内容及所有命中
+// offset 为此前已输出的字符数，命中拦截规则时立即返回 ErrGuardrailBlocked
+func EvaluateGuardrails(rules []*GuardrailRule, stage, text string, offset int) (string, []GuardrailHit, error) {
+	var hits []GuardrailHit
+	for _, rule := range rules {
+		if rule.Stage != stage && rule.Stage != GuardrailStageBoth {
+			continue
+		}
+		var ruleHits []GuardrailHit
+		if rule.Type == GuardrailTypeMaxLength {
+			if n := offset + utf8.RuneCountInString(text); n > rule.Limit {
+				ruleHits = append(ruleHits, GuardrailHit{Rule: rule, Match: fmt.Sprintf("%d 个字符，超过上限 %d", n, rule.Limit)})
+				if rule.Action == GuardrailActionMask {
+					keep := rule.Limit - offset
+					if keep < 0 {
+						keep = 0
+					}
+					text = string([]rune(text)[:keep])
+				}
+			}
+		} else {
+			for kind, re := range rule.patterns {
+				replace := func(match string) string {
+					masked := maskMatch(kind, match)
+					ruleHits = append(ruleHits, GuardrailHit{Rule: rule, Match: masked})
+					if rule.Action == GuardrailActionMask {
+						return masked
+					}
+					return match
+				}
+				if rule.Type == GuardrailTypePII {
+					text = replacePII(re, text, replace)
+				} else {
+					text = re.ReplaceAllStringFunc(text, replace)
+				}
+			}
+		}
+		hits = append(hits, ruleHits...)
+		if len(ruleHits) > 0 && rule.Action == GuardrailActionBlock {
+			return "", hits, fmt.Errorf("%w「%s」", ErrGuardrailBlocked, rule.Name)
+		}
+	}
+	return text, hits, nil
+}
+
+// replacePII 替换敏感信息，规则含捕获组时只替换第一个捕获组
+// 下一次匹配从捕获组结束处开始，边界字符可以同时作为相邻两个号码的边界
+func replacePII(re *regexp.Regexp, text string, fn func(match string) string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllStringFunc(text, fn)
+	}
+	var out strings.Builder
+	pos := 0
+	for pos < len(text) {
+		loc := re.FindStringSubmatchIndex(text[pos:])
+		if loc == nil || loc[2] < 0 {
+			break
+		}
+		start, end := pos+loc[2], pos+loc[3]
+		out.WriteString(text[pos:start])
+		out.WriteString(fn(text[start:end]))
+		pos = end
+	}
+	out.WriteString(text[pos:])
+	return out.String()
+}
+
+// maskMatch 对命中内容脱敏，手机号与身份证号保留首尾，邮箱保留首字符与域名，其余全部替换
+func maskMatch(kind, match string) string {
+	runes := []rune(match)
+	switch kind {
+	case "phone":
+		if len(runes) >= 11 {
+			return string(runes[:len(runes)-8]) + "****" + string(runes[len(runes)-4:])
+		}
+	case "id_card":
+		return string(runes[:6]) + strings.Repeat("*", len(runes)-10) + string(runes[len(runes)-4:])
+	case "email":
+		if at := strings.LastIndex(match, "@"); at > 0 {
+			return match[:1] + "***" + match[at:]
+		}
+	}
+	return strings.Repeat("*", len(runes))
+}
+
+func splitPattern(pattern, seps string) []string {
+	var parts []string
+	for _, part := range strings.FieldsFunc(pattern, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// checkGuardrail 调用护栏检查，未设置护栏时原样返回
+func checkGuardrail(ctx context.Context, model, stage, text string) (string, error) {
+	return checkGuardrailAt(ctx, model, stage, text, 0)
+}
+
+func checkGuardrailAt(ctx context.Context, model, stage, text string, offset int) (string, error) {
+	if guardrail == nil || text == "" {
+		return text, nil
+	}
+	caller, _ := ctx.Value(usageCallerKey{}).(UsageCaller)
+	return guardrail(ctx, GuardrailCheck{Caller: caller, Model: model, Stage: stage, Text: text, Offset: offset})
+}
+
+// guardInput 检查全部消息，历史消息及系统提示词同样由调用方提交，不能假定已检查过
+func guardInput(ctx context.Context, model string, messages []kubeDto.OllamaChatMessage) ([]kubeDto.OllamaChatMessage, error) {
+	var guarded []kubeDto.OllamaChatMessage
+	for i, msg := range messages {
+		content, err := checkGuardrail(ctx, model, GuardrailStageInput, msg.Content)
+		if err != nil {
+			return messages, err
+		}
+		if content == msg.Content {
+			continue
+		}
+		if guarded == nil {
+			guarded = append([]kubeDto.OllamaChatMessage(nil), messages...)
+		}
+		guarded[i].Content = content
+	}
+	if guarded == nil {
+		return messages, nil
+	}
+	return guarded, nil
+}
+
+// guardOutput 检查 chat 响应的 message.content 或 generate 响应的 response，并原地替换为处理后的内容
+func guardOutput(ctx context.Context, model string, result interface{}) error {
+	resp, ok := result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	field, text, ok := chunkText(resp)
+	if !ok {
+		return nil
+	}
+	guarded, err := checkGuardrail(ctx, model, GuardrailStageOutput, text)
+	if err != nil {
+		return err
+	}
+	setChunkText(resp, field, guarded)
+	return nil
+}
+
+// guardStreamWindow 流式输出缓冲中没有句子结尾时，超过该字符数后在空白处切分
+const guardStreamWindow = 256
+
+// chunkText 返回 chat 分片的 message.content 或 generate 分片的 response
+func chunkText(chunk map[string]interface{}) (field, text string, ok bool) {
+	if msg, isMsg := chunk["message"].(map[string]interface{}); isMsg {
+		text, _ = msg["content"].(string)
+		return "message", text, true
+	}
+	if text, ok = chunk["response"].(string); ok {
+		return "response", text, true
+	}
+	return "", "", false
+}
+
+func setChunkText(chunk map[string]interface{}, field, text string) {
+	if field != "message" {
+		chunk["response"] = text
+		return
+	}
+	msg, ok := chunk["message"].(map[string]interface{})
+	if !ok {
+		msg = map[string]interface{}{"role": "assistant"}
+		chunk["message"] = msg
+	}
+	msg["content"] = text
+}
+
+// streamGuard 缓冲流式输出，在句子结尾处切出片段检查后再输出，每段内容只检查一次
+// 单个分片通常只有一个 token，逐片检查无法识别手机号、多词关键词等
+type streamGuard struct {
+	ctx   context.Context
+	model string
+	field string
+	buf   []rune
+	// offset 已输出的字符数
+	offset int
+	// truncated max_length 规则已截断输出，之后的内容直接丢弃
+	truncated bool
+}
+
+// push 追加分片内容，返回检查后可以输出的内容，done 时输出全部缓冲
+func (g *streamGuard) push(text string, done bool) (string, error) {
+	if g.truncated {
+		return "", nil
+	}
+	g.buf = append(g.buf, []rune(text)...)
+	cut := len(g.buf)
+	if !done {
+		cut = streamCut(g.buf)
+	}
+	if cut == 0 {
+		return "", nil
+	}
+	segment := string(g.buf[:cut])
+	g.buf = append(g.buf[:0], g.buf[cut:]...)
+	guarded, err := checkGuardrailAt(g.ctx, g.model, GuardrailStageOutput, segment, g.offset)
+	if err != nil {
+		return "", err
+	}
+	if guarded == "" && segment != "" {
+		// 只有截断会使非空内容变为空
+		g.truncated = true
+	}
+	g.offset += utf8.RuneCountInString(guarded)
+	return guarded, nil
+}
+
+// streamCut 返回缓冲中可以切出检查的长度，优先在换行、句末标点处切分，为 0 表示继续缓冲
+// 手机号、身份证号、邮箱都不含空白及中文标点，不会被切开
+func streamCut(buf []rune) int {
+	for i := len(buf) - 1; i >= 0; i-- {
+		r := buf[i]
+		if r == '\n' || strings.ContainsRune("。！？；", r) || unicode.IsSpace(r) && i > 0 && strings.ContainsRune(".!?;", buf[i-1]) {
+			return i + 1
+		}
+	}
+	if len(buf) <= guardStreamWindow {
+		return 0
+	}
+	for i := len(buf) - 1; i > 0; i-- {
+		if unicode.IsSpace(buf[i]) || buf[i] == '，' || buf[i] == '、' {
+			return i + 1
+		}
+	}
+	return guardStreamWindow
+}
+
+// guardStream 缓冲流式输出并按句子检查，缓冲中的内容随后续分片或最后一个分片一起输出
+func guardStream(ctx context.Context, model string, onChunk func(chunk map[string]interface{}) error) func(chunk map[string]interface{}) error {
+	if guardrail == nil {
+		return onChunk
+	}
+	g := &streamGuard{ctx: ctx, model: model}
+	return func(chunk map[string]interface{}) error {
+		done, _ := chunk["done"].(bool)
+		field, text, ok := chunkText(chunk)
+		if !ok && !done {
+			return onChunk(chunk)
+		}
+		if ok {
+			g.field = field
+		}
+		out, err := g.push(text, done)
+		if err != nil {
+			return err
+		}
+		if msg, _ := chunk["message"].(map[string]interface{}); out == "" && !done && msg["tool_calls"] == nil {
+			return nil
+		}
+		setChunkText(chunk, g.field, out)
+		return onChunk(chunk)
+	}
+}
+
+// guardRawStream 检查 stream=true 时 Chat 返回的原始 NDJSON 响应
+func guardRawStream(ctx context.Context, model, raw string) (string, error) {
+	if guardrail == nil {
+		return raw, nil
+	}
+	var lines []string
+	onChunk := guardStream(ctx, model, func(chunk map[string]interface{}) error {
+		b, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(b))
+		return nil
+	})
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var chunk map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return "", fmt.Errorf("解析响应失败: %v", err)
+		}
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,186 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+func mustRule(t *testing.T, name, ruleType, pattern string, limit int, stage, action string) *GuardrailRule {
+	t.Helper()
+	rule, err := NewGuardrailRule(name, name, ruleType, pattern, limit, stage, action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+func TestEvaluateGuardrailsMask(t *testing.T) {
+	rules := []*GuardrailRule{
+		mustRule(t, "pii", GuardrailTypePII, "", 0, GuardrailStageInput, GuardrailActionMask),
+		mustRule(t, "audit", GuardrailTypeKeyword, "工资, salary", 0, GuardrailStageBoth, GuardrailActionLog),
+	}
+	text, hits, err := EvaluateGuardrails(rules, GuardrailStageInput,
+		"我的手机13812345678，身份证11010119900307123X，邮箱zhang.san@example.com，想问SALARY", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "我的手机138****5678，身份证110101********123X，邮箱z***@example.com，想问SALARY"
+	if text != want {
+		t.Errorf("text = %s", text)
+	}
+	if len(hits) != 4 {
+		t.Errorf("hits = %+v", hits)
+	}
+
+	// 号码前后只要求不是数字，国际区号及紧跟字母的号码同样脱敏
+	phone := []*GuardrailRule{mustRule(t, "phone", GuardrailTypePII, "phone", 0, GuardrailStageInput, GuardrailActionMask)}
+	for in, want := range map[string]string{
+		"+8613812345678":          "+86138****5678",
+		"8613812345678":           "86138****5678",
+		"tel:13812345678abc":      "tel:138****5678abc",
+		"13812345678,13912345678": "138****5678,139****5678",
+		"订单号 213812345678 不是手机号":  "订单号 213812345678 不是手机号",
+	} {
+		if text, _, _ := EvaluateGuardrails(phone, GuardrailStageInput, in, 0); text != want {
+			t.Errorf("mask %q = %q, want %q", in, text, want)
+		}
+	}
+
+	// 输出阶段只执行 stage 为 output 或 both 的规则
+	text, hits, _ = EvaluateGuardrails(rules, GuardrailStageOutput, "13812345678", 0)
+	if text != "13812345678" || len(hits) != 0 {
+		t.Errorf("text = %s, hits = %+v", text, hits)
+	}
+}
+
+func TestEvaluateGuardrailsBlock(t *testing.T) {
+	rules := []*GuardrailRule{
+		mustRule(t, "length", GuardrailTypeMaxLength, "", 5, GuardrailStageInput, GuardrailActionMask),
+		mustRule(t, "deny", GuardrailTypeRegex, `(?i)drop\s+table`, 0, GuardrailStageInput, GuardrailActionBlock),
+	}
+	text, _, err := EvaluateGuardrails(rules, GuardrailStageInput, "你好，世界！再见", 0)
+	if err != nil || text != "你好，世界" {
+		t.Errorf("text = %s, err = %v", text, err)
+	}
+	_, hits, err := EvaluateGuardrails(rules[1:], GuardrailStageInput, "please DROP  TABLE users", 0)
+	if !errors.Is(err, ErrGuardrailBlocked) || len(hits) != 1 {
+		t.Errorf("hits = %+v, err = %v", hits, err)
+	}
+
+	for _, c := range []struct{ ruleType, pattern string }{
+		{GuardrailTypePII, "passport"},
+		{GuardrailTypeKeyword, " , "},
+		{GuardrailTypeRegex, "("},
+		{GuardrailTypeMaxLength, ""},
+	} {
+		if _, err := NewGuardrailRule("x", "x", c.ruleType, c.pattern, 0, GuardrailStageInput, GuardrailActionLog); err == nil {
+			t.Errorf("expected error for %s %q", c.ruleType, c.pattern)
+		}
+	}
+}
+
+func TestGuardInput(t *testing.T) {
+	defer SetGuardrail(nil)
+	rules := []*GuardrailRule{mustRule(t, "pii", GuardrailTypePII, "email", 0, GuardrailStageBoth, GuardrailActionMask)}
+	SetGuardrail(func(ctx context.Context, check GuardrailCheck) (string, error) {
+		text, _, err := EvaluateGuardrails(rules, check.Stage, check.Text, check.Offset)
+		return text, err
+	})
+
+	// 历史消息及系统提示词由调用方提交，同样需要检查
+	messages := []kubeDto.OllamaChatMessage{
+		{Role: "system", Content: "联系 admin@example.com"},
+		{Role: "assistant", Content: "好的"},
+		{Role: "user", Content: "发给 bob@example.com"},
+	}
+	guarded, err := guardInput(context.Background(), "llama3", messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guarded[0].Content != "联系 a***@example.com" || guarded[1].Content != "好的" || guarded[2].Content != "发给 b***@example.com" {
+		t.Errorf("guarded = %+v", guarded)
+	}
+	if messages[2].Content != "发给 bob@example.com" {
+		t.Error("input messages must not be modified")
+	}
+
+	resp := map[string]interface{}{"message": map[string]interface{}{"role": "assistant", "content": "alice@example.com"}}
+	if err := guardOutput(context.Background(), "llama3", resp); err != nil {
+		t.Fatal(err)
+	}
+	if chatContent(resp) != "a***@example.com" {
+		t.Errorf("resp = %v", resp)
+	}
+}
+
+func TestGuardStream(t *testing.T) {
+	defer SetGuardrail(nil)
+	rules := []*GuardrailRule{
+		mustRule(t, "pii", GuardrailTypePII, "phone", 0, GuardrailStageOutput, GuardrailActionMask),
+		mustRule(t, "length", GuardrailTypeMaxLength, "", 30, GuardrailStageOutput, GuardrailActionMask),
+	}
+	var checks int
+	SetGuardrail(func(ctx context.Context, check GuardrailCheck) (string, error) {
+		checks++
+		text, _, err := EvaluateGuardrails(rules, check.Stage, check.Text, check.Offset)
+		return text, err
+	})
+
+	// 手机号被拆成多个分片，逐片检查时无法识别
+	tokens := []string{"电话", "138", "1234", "5678", "。", "请尽快", "联系我们的客服人员", "，谢谢", "配合", "。"}
+	var out strings.Builder
+	onChunk := guardStream(context.Background(), "llama3", func(chunk map[string]interface{}) error {
+		out.WriteString(chatContent(chunk))
+		return nil
+	})
+	for _, token := range tokens {
+		chunk := map[string]interface{}{"message": map[string]interface{}{"role": "assistant", "content": token}, "done": false}
+		if err := onChunk(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := onChunk(map[string]interface{}{"message": map[string]interface{}{"role": "assistant", "content": ""}, "done": true}); err != nil {
+		t.Fatal(err)
+	}
+	// max_length 按累计长度截断为 30 个字符
+	if want := "电话138****5678。请尽快联系我们的客服人员，谢谢配"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if checks != 2 {
+		t.Errorf("checks = %d, want one per sentence", checks)
+	}
+
+	raw := `{"message":{"role":"assistant","content":"call 1381234"},"done":false}` + "\n" +
+		`{"message":{"role":"assistant","content":"5678 now"},"done":false}` + "\n" +
+		`{"message":{"role":"assistant","content":""},"done":true}`
+	guarded, err := guardRawStream(context.Background(), "llama3", raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(guarded, "call 138****5678 now") || strings.Count(guarded, "\n") != 0 {
+		t.Errorf("raw stream = %s", guarded)
+	}
+}
+
+func TestStreamCut(t *testing.T) {
+	cases := []struct {
+		text string
+		want int
+	}{
+		{"mail a@b.com", 0},
+		{"mail a@b.com. Then", len([]rune("mail a@b.com. "))},
+		{"第一句。第二", len([]rune("第一句。"))},
+		{"line\nnext", len("line\n")},
+		{strings.Repeat("word ", 60), 300},
+		{strings.Repeat("字", 300), guardStreamWindow},
+	}
+	for _, c := range cases {
+		if got := streamCut([]rune(c.text)); got != c.want {
+			t.Errorf("streamCut(%q) = %d, want %d", c.text, got, c.want)
+		}
+	}
+}
@@ -17,7 +17,7 @@ var K8s k8s
 
 type k8s struct {
 	Config    *rest.Config
-	ClientSet *kubernetes.Clientset
+	ClientSet kubernetes.Interface
 }
 
 func (k *k8s) Init() error {
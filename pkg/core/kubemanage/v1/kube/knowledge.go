@@ -2,7 +2,6 @@ package kube
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -14,6 +13,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+	"github.com/noovertime7/kubemanage/pkg/docparse"
+	"github.com/noovertime7/kubemanage/pkg/vectorstore"
 )
 
 var Knowledge knowledge
@@ -33,6 +35,15 @@ func (k *knowledge) DeployKnowledge(data *kubeDto.KnowledgeDeployInput) error {
 		labels[k] = v
 	}
 
+	if err := validateScheduling(&data.SchedulingOptions); err != nil {
+		return err
+	}
+
+	// 按知识库类型补全镜像、端口及数据目录
+	if err := k.applyKnowledgeTemplate(data); err != nil {
+		return err
+	}
+
 	// 创建 PVC（如果需要存储）
 	if data.StorageSize != "" {
 		if err := k.createPVC(data); err != nil {
@@ -124,7 +135,7 @@ func (k *knowledge) createDeployment(data *kubeDto.KnowledgeDeployInput, labels
 		deployment.Spec.Template.Spec.Containers[0].VolumeMounts = []coreV1.VolumeMount{
 			{
 				Name:      "knowledge-data",
-				MountPath: "/data",
+				MountPath: data.DataPath,
 			},
 		}
 		deployment.Spec.Template.Spec.Volumes = []coreV1.Volume{
@@ -140,7 +151,7 @@ func (k *knowledge) createDeployment(data *kubeDto.KnowledgeDeployInput, labels
 	}
 
 	// 添加环境变量（如果绑定了 Ollama）
-	if data.OllamaPodName != "" {
+	if data.OllamaPodName != "" || data.OllamaDeployment != "" {
 		ollamaNamespace := data.NameSpace
 		if data.OllamaNamespace != "" {
 			ollamaNamespace = data.OllamaNamespace
@@ -152,6 +163,10 @@ func (k *knowledge) createDeployment(data *kubeDto.KnowledgeDeployInput, labels
 				Name:  "OLLAMA_POD_NAME",
 				Value: data.OllamaPodName,
 			},
+			coreV1.EnvVar{
+				Name:  "OLLAMA_DEPLOYMENT",
+				Value: data.OllamaDeployment,
+			},
 			coreV1.EnvVar{
 				Name:  "OLLAMA_NAMESPACE",
 				Value: ollamaNamespace,
@@ -171,6 +186,11 @@ func (k *knowledge) createDeployment(data *kubeDto.KnowledgeDeployInput, labels
 	// 不添加健康检查，让 Pod 可以正常启动
 	// 如果知识库服务需要健康检查，可以在部署后手动配置
 
+	// 设置 GPU、容忍、亲和性、优先级及额外环境变量
+	if err := applyScheduling(&deployment.Spec.Template.Spec, &data.SchedulingOptions); err != nil {
+		return err
+	}
+
 	_, err := K8s.ClientSet.AppsV1().Deployments(data.NameSpace).Create(context.TODO(), deployment, metaV1.CreateOptions{})
 	return err
 }
@@ -244,7 +264,7 @@ func (k *knowledge) createDaemonSet(data *kubeDto.KnowledgeDeployInput, labels m
 		daemonSet.Spec.Template.Spec.Containers[0].VolumeMounts = []coreV1.VolumeMount{
 			{
 				Name:      "knowledge-data",
-				MountPath: "/data",
+				MountPath: data.DataPath,
 			},
 		}
 		daemonSet.Spec.Template.Spec.Volumes = []coreV1.Volume{
@@ -260,7 +280,7 @@ func (k *knowledge) createDaemonSet(data *kubeDto.KnowledgeDeployInput, labels m
 	}
 
 	// 添加环境变量（如果绑定了 Ollama）
-	if data.OllamaPodName != "" {
+	if data.OllamaPodName != "" || data.OllamaDeployment != "" {
 		ollamaNamespace := data.NameSpace
 		if data.OllamaNamespace != "" {
 			ollamaNamespace = data.OllamaNamespace
@@ -271,6 +291,10 @@ func (k *knowledge) createDaemonSet(data *kubeDto.KnowledgeDeployInput, labels m
 				Name:  "OLLAMA_POD_NAME",
 				Value: data.OllamaPodName,
 			},
+			coreV1.EnvVar{
+				Name:  "OLLAMA_DEPLOYMENT",
+				Value: data.OllamaDeployment,
+			},
 			coreV1.EnvVar{
 				Name:  "OLLAMA_NAMESPACE",
 				Value: ollamaNamespace,
@@ -287,6 +311,11 @@ func (k *knowledge) createDaemonSet(data *kubeDto.KnowledgeDeployInput, labels m
 		}
 	}
 
+	// 设置 GPU、容忍、亲和性、优先级及额外环境变量
+	if err := applyScheduling(&daemonSet.Spec.Template.Spec, &data.SchedulingOptions); err != nil {
+		return err
+	}
+
 	_, err := K8s.ClientSet.AppsV1().DaemonSets(data.NameSpace).Create(context.TODO(), daemonSet, metaV1.CreateOptions{})
 	return err
 }
@@ -352,707 +381,205 @@ func (k *knowledge) createPVC(data *kubeDto.KnowledgeDeployInput) error {
 	return err
 }
 
-// UploadDocument 上传文档到知识库（支持 ChromaDB、Milvus、Weaviate）
-func (k *knowledge) UploadDocument(podName, namespace, knowledgeType string, fileContent []byte, fileName, collectionName string, chunkSize int) (interface{}, error) {
-	// 根据知识库类型调用不同的上传方法
-	knowledgeType = strings.ToLower(knowledgeType)
-	switch knowledgeType {
-	case "chromadb", "chroma":
-		return k.uploadToChroma(podName, namespace, fileContent, fileName, collectionName, chunkSize)
-	case "milvus":
-		return k.uploadToMilvus(podName, namespace, fileContent, fileName, collectionName, chunkSize)
-	case "weaviate":
-		return k.uploadToWeaviate(podName, namespace, fileContent, fileName, collectionName, chunkSize)
-	default:
-		return nil, fmt.Errorf("不支持的知识库类型: %s，支持的类型: chromadb, milvus, weaviate", knowledgeType)
-	}
-}
-
-// ========== 辅助函数 ==========
-
-// getPodInfo 获取 Pod 信息和端口
-func (k *knowledge) getPodInfo(podName, namespace string, defaultPort int32) (*coreV1.Pod, int32, error) {
-	pod, err := Pod.GetPodDetail(podName, namespace)
-	if err != nil {
-		return nil, 0, fmt.Errorf("获取Pod信息失败: %v", err)
-	}
-
-	if pod.Status.Phase != coreV1.PodRunning {
-		return nil, 0, fmt.Errorf("pod %s 状态为 %s，请等待Pod启动完成", podName, pod.Status.Phase)
-	}
-
-	port := defaultPort
-	if len(pod.Spec.Containers) > 0 {
-		for _, containerPort := range pod.Spec.Containers[0].Ports {
-			if containerPort.Name == "http" || containerPort.ContainerPort == defaultPort {
-				port = containerPort.ContainerPort
-				break
-			}
-		}
-	}
-
-	return pod, port, nil
+// IngestChunk 待写入知识库的一个分块，Index 为分块在文档中的序号
+type IngestChunk struct {
+	Index    int
+	Text     string
+	Metadata map[string]interface{}
 }
 
-// getOllamaInfo 从 Pod 环境变量获取 Ollama 信息
-func (k *knowledge) getOllamaInfo(pod *coreV1.Pod, namespace string) (podName, ollamaNamespace, model string) {
-	if len(pod.Spec.Containers) == 0 {
-		return "", "", ""
-	}
-
-	for _, env := range pod.Spec.Containers[0].Env {
-		switch env.Name {
-		case "OLLAMA_POD_NAME":
-			podName = env.Value
-		case "OLLAMA_NAMESPACE":
-			ollamaNamespace = env.Value
-		case "OLLAMA_MODEL":
-			model = env.Value
-		}
-	}
-
-	if ollamaNamespace == "" {
-		ollamaNamespace = namespace
-	}
-
-	return podName, ollamaNamespace, model
+// IngestPlan 解析并切分后的文档，由调用方保存后按批调用 IngestChunks 写入
+type IngestPlan struct {
+	KnowledgeType string
+	// Collection 为向量数据库中的实际集合名称
+	Collection string
+	Sections   int
+	Chunking   chunker.Options
+	Chunks     []IngestChunk
 }
 
-// splitText 将文本分块
-func (k *knowledge) splitText(text string, chunkSize int) []string {
-	if chunkSize <= 0 {
-		chunkSize = 1000
-	}
-
-	if len(text) <= chunkSize {
-		return []string{text}
-	}
-
-	var chunks []string
-	for i := 0; i < len(text); i += chunkSize {
-		end := i + chunkSize
-		if end > len(text) {
-			end = len(text)
-		}
-		chunks = append(chunks, text[i:end])
-	}
-	return chunks
-}
-
-// generateEmbeddings 使用 Ollama 生成向量嵌入
-func (k *knowledge) generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel string, texts []string) ([][]float64, error) {
-	if ollamaPodName == "" || ollamaModel == "" {
-		return nil, nil // 没有绑定 Ollama，返回 nil
-	}
-
-	embeddings := make([][]float64, 0, len(texts))
-	for _, text := range texts {
-		result, err := Ollama.Embeddings(ollamaPodName, ollamaNamespace, ollamaModel, text)
-		if err != nil {
-			return nil, fmt.Errorf("生成向量失败: %v", err)
-		}
-
-		resultMap, ok := result.(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("ollama 响应格式错误")
-		}
-
-		embeddingInterface, ok := resultMap["embedding"]
-		if !ok {
-			return nil, fmt.Errorf("ollama 响应中缺少 embedding 字段")
-		}
-
-		embeddingSlice, ok := embeddingInterface.([]interface{})
-		if !ok {
-			return nil, fmt.Errorf("embedding 格式错误")
-		}
-
-		embedding := make([]float64, len(embeddingSlice))
-		for i, v := range embeddingSlice {
-			if f, ok := v.(float64); ok {
-				embedding[i] = f
-			} else {
-				return nil, fmt.Errorf("embedding 元素类型错误")
-			}
-		}
-
-		embeddings = append(embeddings, embedding)
+// PrepareIngest 解析上传的文档并切分，同时校验知识库及绑定的向量模型，不生成向量
+// 文档按扩展名或 contentType 解析为纯文本，页码、标题路径、工作表及行号写入分块的 metadata
+// chunking 中的零值字段使用集合的分块配置或默认值，见 chunkingOptions
+func (k *knowledge) PrepareIngest(ctx context.Context, podName, namespace, knowledgeType string, fileContent []byte, fileName, contentType, collectionName string, chunking chunker.Options) (*IngestPlan, error) {
+	if collectionName == "" {
+		collectionName = fileName
 	}
-	return embeddings, nil
-}
-
-// sanitizeCollectionName 清理集合名称
-func (k *knowledge) sanitizeCollectionName(name string) string {
-	var result []rune
-	for _, r := range name {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
-			result = append(result, r)
-		} else {
-			result = append(result, '_')
-		}
+	if len(fileContent) == 0 {
+		return nil, fmt.Errorf("文件内容为空")
 	}
-	return string(result)
-}
-
-// ========== ChromaDB 上传 ==========
-
-// uploadToChroma 上传文档到 ChromaDB
-func (k *knowledge) uploadToChroma(podName, namespace string, fileContent []byte, fileName, collectionName string, chunkSize int) (interface{}, error) {
-	pod, port, err := k.getPodInfo(podName, namespace, 8000)
+	sections, err := docparse.Parse(fileName, contentType, fileContent)
 	if err != nil {
 		return nil, err
 	}
 
-	textContent := string(fileContent)
-	if textContent == "" {
-		return nil, fmt.Errorf("文件内容为空")
-	}
-
-	chunks := k.splitText(textContent, chunkSize)
-	if len(chunks) == 0 {
-		return nil, fmt.Errorf("文件分块后为空")
+	_, backend, pod, err := k.vectorStore(podName, namespace, knowledgeType)
+	if err != nil {
+		return nil, err
 	}
-
-	ollamaPodName, ollamaNamespace, ollamaModel := k.getOllamaInfo(pod, namespace)
-	embeddings, err := k.generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel, chunks)
+	_, ollamaModel, err := k.embedder(pod, namespace)
 	if err != nil {
-		return nil, fmt.Errorf("生成向量嵌入失败: %v", err)
+		return nil, err
 	}
 
-	if collectionName == "" {
-		collectionName = fileName
-	}
 	collectionName = k.sanitizeCollectionName(collectionName)
-
-	// 确保集合存在（现在返回 UUID，但这里不需要，因为 addToChroma 内部会处理）
-	_, err = k.ensureChromaCollection(podName, namespace, port, collectionName)
+	chunking, err = k.chunkingOptions(ctx, pod, namespace, collectionName, chunking, ollamaModel)
 	if err != nil {
-		return nil, fmt.Errorf("创建集合失败: %v", err)
-	}
-
-	// 准备数据
-	ids := make([]string, len(chunks))
-	metadatas := make([]map[string]interface{}, len(chunks))
-	for i := range chunks {
-		ids[i] = fmt.Sprintf("%s_chunk_%d", collectionName, i)
-		metadatas[i] = map[string]interface{}{
-			"source":   fileName,
-			"chunk_id": i,
-		}
+		return nil, err
 	}
-
-	// 添加文档到 Chroma（使用和 Ollama 相同的方式）
-	result, err := k.addToChroma(podName, namespace, port, collectionName, chunks, embeddings, ids, metadatas)
+	texts, metadata, err := chunkSections(sections, chunking)
 	if err != nil {
-		return nil, fmt.Errorf("添加文档到 Chroma 失败: %v", err)
-	}
-
-	return map[string]interface{}{
-		"status":          "success",
-		"message":         "文档上传成功",
-		"knowledge_type":  "chromadb",
-		"collection_name": collectionName,
-		"chunks_count":    len(chunks),
-		"result":          result,
-	}, nil
-}
-
-// ensureChromaCollection 确保 Chroma 集合存在（使用 v2 API），返回集合的 UUID
-func (k *knowledge) ensureChromaCollection(podName, namespace string, port int32, collectionName string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
-	defer cancel()
-
-	// Chroma v2 API 需要 tenant 和 database 参数
-	tenant := "default_tenant"
-	database := "default_database"
-
-	// 先列出所有集合，查找匹配名称的集合
-	listReq := K8s.ClientSet.CoreV1().RESTClient().Get().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/api/v2/tenants/%s/databases/%s/collections", tenant, database))
-
-	listResult := listReq.Do(ctx)
-	if listResult.Error() == nil {
-		body, err := listResult.Raw()
-		if err == nil && len(body) > 0 {
-			var collections []map[string]interface{}
-			if json.Unmarshal(body, &collections) == nil {
-				// 查找匹配名称的集合
-				for _, collection := range collections {
-					if name, ok := collection["name"].(string); ok && name == collectionName {
-						if id, ok := collection["id"].(string); ok {
-							return id, nil // 返回集合的 UUID
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// 集合不存在，创建集合
-	createBody := map[string]interface{}{
-		"name": collectionName,
+		return nil, err
 	}
-	jsonData, err := json.Marshal(createBody)
-	if err != nil {
-		return "", fmt.Errorf("序列化请求体失败: %v", err)
-	}
-
-	createReq := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/api/v2/tenants/%s/databases/%s/collections", tenant, database)).
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	createResult := createReq.Do(ctx)
-	if createResult.Error() != nil {
-		// 检查是否是集合已存在的错误
-		body, _ := createResult.Raw()
-		if len(body) > 0 {
-			var errorResponse map[string]interface{}
-			if json.Unmarshal(body, &errorResponse) == nil {
-				if errMsg, ok := errorResponse["error"].(string); ok {
-					// 如果错误是集合已存在，尝试再次查找
-					if strings.Contains(strings.ToLower(errMsg), "already exists") || strings.Contains(strings.ToLower(errMsg), "duplicate") {
-						// 重新查找集合
-						listResult := listReq.Do(ctx)
-						if listResult.Error() == nil {
-							body, err := listResult.Raw()
-							if err == nil && len(body) > 0 {
-								var collections []map[string]interface{}
-								if json.Unmarshal(body, &collections) == nil {
-									for _, collection := range collections {
-										if name, ok := collection["name"].(string); ok && name == collectionName {
-											if id, ok := collection["id"].(string); ok {
-												return id, nil
-											}
-										}
-									}
-								}
-							}
-						}
-						return "", fmt.Errorf("集合已存在但无法获取ID: %s", errMsg)
-					}
-					return "", fmt.Errorf("创建集合失败: %s", errMsg)
-				}
-			}
-		}
-		return "", fmt.Errorf("创建集合失败: %v", createResult.Error())
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("文件分块后为空")
 	}
 
-	// 获取创建的集合的 UUID
-	body, err := createResult.Raw()
-	if err == nil && len(body) > 0 {
-		var response map[string]interface{}
-		if json.Unmarshal(body, &response) == nil {
-			if id, ok := response["id"].(string); ok {
-				return id, nil
-			}
-		}
+	plan := &IngestPlan{
+		KnowledgeType: backend.Name,
+		Collection:    collectionName,
+		Sections:      len(sections),
+		Chunking:      chunking,
+		Chunks:        make([]IngestChunk, len(texts)),
 	}
-
-	// 如果无法从响应中获取 ID，重新查找
-	listResult = listReq.Do(ctx)
-	if listResult.Error() == nil {
-		body, err := listResult.Raw()
-		if err == nil && len(body) > 0 {
-			var collections []map[string]interface{}
-			if json.Unmarshal(body, &collections) == nil {
-				for _, collection := range collections {
-					if name, ok := collection["name"].(string); ok && name == collectionName {
-						if id, ok := collection["id"].(string); ok {
-							return id, nil
-						}
-					}
-				}
-			}
-		}
+	for i, text := range texts {
+		plan.Chunks[i] = IngestChunk{Index: i, Text: text, Metadata: metadata[i]}
 	}
-
-	return "", fmt.Errorf("创建集合成功但无法获取ID")
+	return plan, nil
 }
 
-// addToChroma 添加文档到 Chroma（使用 v2 API）
-func (k *knowledge) addToChroma(podName, namespace string, port int32, collectionName string, documents []string, embeddings [][]float64, ids []string, metadatas []map[string]interface{}) (interface{}, error) {
-	// Chroma v2 API 需要 tenant 和 database 参数
-	tenant := "default_tenant"
-	database := "default_database"
-
-	// 确保集合存在并获取集合的 UUID
-	collectionUUID, err := k.ensureChromaCollection(podName, namespace, port, collectionName)
-	if err != nil {
-		return nil, fmt.Errorf("确保集合存在失败: %v", err)
-	}
-
-	// Chroma v2 API 要求 embeddings 字段是必需的
-	// embeddings 必须是二维数组（每个文档一个向量数组）或字符串数组（base64 编码）
-	// 如果没有提供 embeddings，返回错误（应该由调用方生成 embeddings）
-	if len(embeddings) == 0 {
-		return nil, fmt.Errorf("embeddings 字段是必需的，请先生成向量嵌入")
-	}
-
-	// 确保 embeddings 数量与 documents 数量一致
-	if len(embeddings) != len(documents) {
-		return nil, fmt.Errorf("embeddings 数量 (%d) 与 documents 数量 (%d) 不一致", len(embeddings), len(documents))
-	}
-
-	requestBody := map[string]interface{}{
-		"ids":        ids,
-		"documents":  documents,
-		"metadatas":  metadatas,
-		"embeddings": embeddings, // 必需字段，必须是二维数组
+// IngestChunks 为一批分块生成向量并写入集合，分块 ID 由文件名及序号组成，重复写入同一文件时覆盖原有分块
+func (k *knowledge) IngestChunks(ctx context.Context, podName, namespace, knowledgeType, collection, fileName string, chunks []IngestChunk) error {
+	if len(chunks) == 0 {
+		return nil
 	}
+	// 集合内容变化后之前的问答可能已过时
+	defer InvalidateAnswerCache(namespace, podName, collection)
 
-	jsonData, err := json.Marshal(requestBody)
+	store, _, pod, err := k.vectorStore(podName, namespace, knowledgeType)
 	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+		return err
 	}
-
-	// 使用 Kubernetes API Server 代理访问 Pod（和 Ollama 相同的方式）
-	// Chroma v2 API 路径: /api/v2/tenants/{tenant}/databases/{database}/collections/{collection_id}/add
-	// 注意：collection_id 必须是 UUID，不是名称
-	req := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/api/v2/tenants/%s/databases/%s/collections/%s/add", tenant, database, collectionUUID)).
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	// 发送请求（使用 context.TODO()，和 Ollama 保持一致）
-	result := req.Do(context.TODO())
-	if result.Error() != nil {
-		return nil, fmt.Errorf("请求 Chroma API 失败: %v", result.Error())
-	}
-
-	// 读取响应
-	body, err := result.Raw()
+	ollamaTarget, ollamaModel, err := k.embedder(pod, namespace)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+		return err
 	}
 
-	// 解析 JSON 响应
-	var responseData map[string]interface{}
-	if err := json.Unmarshal(body, &responseData); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))
-	}
-
-	// 检查是否有错误
-	if errMsg, ok := responseData["error"].(string); ok && errMsg != "" {
-		return nil, fmt.Errorf("chroma API 返回错误: %s", errMsg)
-	}
-
-	return responseData, nil
-}
-
-// ========== Milvus 上传 ==========
-
-// uploadToMilvus 上传文档到 Milvus
-func (k *knowledge) uploadToMilvus(podName, namespace string, fileContent []byte, fileName, collectionName string, chunkSize int) (interface{}, error) {
-	pod, port, err := k.getPodInfo(podName, namespace, 19530)
-	if err != nil {
-		return nil, err
-	}
-
-	textContent := string(fileContent)
-	if textContent == "" {
-		return nil, fmt.Errorf("文件内容为空")
-	}
-
-	chunks := k.splitText(textContent, chunkSize)
-	if len(chunks) == 0 {
-		return nil, fmt.Errorf("文件分块后为空")
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
 	}
-
-	ollamaPodName, ollamaNamespace, ollamaModel := k.getOllamaInfo(pod, namespace)
-	embeddings, err := k.generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel, chunks)
+	embeddings, err := k.generateEmbeddings(ctx, ollamaTarget, ollamaModel, texts)
 	if err != nil {
-		return nil, fmt.Errorf("生成向量嵌入失败: %v", err)
+		return fmt.Errorf("生成向量嵌入失败: %v", err)
 	}
-
-	if embeddings == nil {
-		return nil, fmt.Errorf("milvus 需要向量嵌入，请确保绑定了 Ollama")
+	if err := store.EnsureCollection(ctx, collection, len(embeddings[0])); err != nil {
+		return err
 	}
 
-	if collectionName == "" {
-		collectionName = fileName
-	}
-	collectionName = k.sanitizeCollectionName(collectionName)
-
-	// 确保集合存在
-	if err := k.ensureMilvusCollection(podName, namespace, port, collectionName, len(embeddings[0])); err != nil {
-		return nil, fmt.Errorf("创建集合失败: %v", err)
-	}
-
-	// 准备数据
-	ids := make([]int64, len(chunks))
-	data := make([][]interface{}, len(chunks))
-	for i := range chunks {
-		ids[i] = int64(i)
-		// Milvus 数据格式: [id, text, vector]
-		data[i] = []interface{}{
-			int64(i),
-			chunks[i],
-			embeddings[i],
+	source := k.sanitizeCollectionName(fileName)
+	docs := make([]vectorstore.Document, len(chunks))
+	for i, chunk := range chunks {
+		metadata := map[string]interface{}{
+			"source":   fileName,
+			"chunk_id": chunk.Index,
+		}
+		for key, value := range chunk.Metadata {
+			metadata[key] = value
+		}
+		docs[i] = vectorstore.Document{
+			ID:       fmt.Sprintf("%s_chunk_%d", source, chunk.Index),
+			Text:     chunk.Text,
+			Vector:   embeddings[i],
+			Metadata: metadata,
 		}
 	}
-
-	// 插入数据到 Milvus
-	result, err := k.insertToMilvus(podName, namespace, port, collectionName, data)
-	if err != nil {
-		return nil, fmt.Errorf("插入数据到 Milvus 失败: %v", err)
-	}
-
-	return map[string]interface{}{
-		"status":          "success",
-		"message":         "文档上传成功",
-		"knowledge_type":  "milvus",
-		"collection_name": collectionName,
-		"chunks_count":    len(chunks),
-		"result":          result,
-	}, nil
-}
-
-// ensureMilvusCollection 确保 Milvus 集合存在
-func (k *knowledge) ensureMilvusCollection(podName, namespace string, port int32, collectionName string, vectorDim int) error {
-	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
-	defer cancel()
-
-	// 检查集合是否存在
-	req := K8s.ClientSet.CoreV1().RESTClient().Get().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/v1/collections/%s", collectionName))
-
-	result := req.Do(ctx)
-	if result.Error() == nil {
-		return nil // 集合已存在
-	}
-
-	// 创建集合
-	createBody := map[string]interface{}{
-		"collection_name": collectionName,
-		"dimension":       vectorDim,
-		"metric_type":     "L2",
-	}
-	jsonData, _ := json.Marshal(createBody)
-
-	createReq := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix("/v1/collections").
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	createReq.Do(ctx)
-	return nil
+	return store.Upsert(ctx, collection, docs)
 }
 
-// insertToMilvus 插入数据到 Milvus
-func (k *knowledge) insertToMilvus(podName, namespace string, port int32, collectionName string, data [][]interface{}) (interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Minute)
-	defer cancel()
-
-	requestBody := map[string]interface{}{
-		"collection_name": collectionName,
-		"data":            data,
-	}
+// ========== 辅助函数 ==========
 
-	jsonData, err := json.Marshal(requestBody)
+// getPodInfo 获取 Pod 信息和端口
+func (k *knowledge) getPodInfo(podName, namespace string, defaultPort int32) (*coreV1.Pod, int32, error) {
+	pod, err := Pod.GetPodDetail(podName, namespace)
 	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+		return nil, 0, fmt.Errorf("获取Pod信息失败: %v", err)
 	}
 
-	req := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/v1/collections/%s/insert", collectionName)).
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	result := req.Do(ctx)
-	if result.Error() != nil {
-		return nil, fmt.Errorf("请求 Milvus API 失败: %v", result.Error())
+	if pod.Status.Phase != coreV1.PodRunning {
+		return nil, 0, fmt.Errorf("pod %s 状态为 %s，请等待Pod启动完成", podName, pod.Status.Phase)
 	}
 
-	body, err := result.Raw()
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+	port := defaultPort
+	if len(pod.Spec.Containers) > 0 {
+		for _, containerPort := range pod.Spec.Containers[0].Ports {
+			if containerPort.Name == "http" || containerPort.ContainerPort == defaultPort {
+				port = containerPort.ContainerPort
+				break
+			}
+		}
 	}
 
-	var responseData map[string]interface{}
-	json.Unmarshal(body, &responseData)
-	return responseData, nil
+	return pod, port, nil
 }
 
-// ========== Weaviate 上传 ==========
-
-// uploadToWeaviate 上传文档到 Weaviate
-func (k *knowledge) uploadToWeaviate(podName, namespace string, fileContent []byte, fileName, collectionName string, chunkSize int) (interface{}, error) {
-	pod, port, err := k.getPodInfo(podName, namespace, 8080)
-	if err != nil {
-		return nil, err
+// getOllamaInfo 从 Pod 环境变量获取绑定的 Ollama 信息，绑定了部署时优先按部署访问
+func (k *knowledge) getOllamaInfo(pod *coreV1.Pod, namespace string) (target kubeDto.OllamaTarget, model string) {
+	if len(pod.Spec.Containers) == 0 {
+		return target, ""
 	}
 
-	textContent := string(fileContent)
-	if textContent == "" {
-		return nil, fmt.Errorf("文件内容为空")
+	for _, env := range pod.Spec.Containers[0].Env {
+		switch env.Name {
+		case "OLLAMA_POD_NAME":
+			target.PodName = env.Value
+		case "OLLAMA_DEPLOYMENT":
+			target.Deployment = env.Value
+		case "OLLAMA_NAMESPACE":
+			target.NameSpace = env.Value
+		case "OLLAMA_MODEL":
+			model = env.Value
+		}
 	}
 
-	chunks := k.splitText(textContent, chunkSize)
-	if len(chunks) == 0 {
-		return nil, fmt.Errorf("文件分块后为空")
+	if target.NameSpace == "" {
+		target.NameSpace = namespace
 	}
-
-	ollamaPodName, ollamaNamespace, ollamaModel := k.getOllamaInfo(pod, namespace)
-	embeddings, err := k.generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel, chunks)
-	if err != nil {
-		return nil, fmt.Errorf("生成向量嵌入失败: %v", err)
+	if target.Deployment != "" {
+		target.PodName = ""
 	}
 
-	if collectionName == "" {
-		collectionName = fileName
-	}
-	collectionName = k.sanitizeCollectionName(collectionName)
+	return target, model
+}
 
-	// 确保类存在
-	if err := k.ensureWeaviateClass(podName, namespace, port, collectionName); err != nil {
-		return nil, fmt.Errorf("创建类失败: %v", err)
+// generateEmbeddings 使用 Ollama 生成向量嵌入，启用向量缓存时相同文本不会重复计算
+func (k *knowledge) generateEmbeddings(ctx context.Context, ollamaTarget kubeDto.OllamaTarget, ollamaModel string, texts []string) ([][]float64, error) {
+	if (ollamaTarget.PodName == "" && ollamaTarget.Deployment == "") || ollamaModel == "" {
+		return nil, nil // 没有绑定 Ollama，返回 nil
 	}
 
-	// 批量添加对象
-	objects := make([]map[string]interface{}, len(chunks))
-	for i, chunk := range chunks {
-		obj := map[string]interface{}{
-			"text":   chunk,
-			"source": fileName,
-			"chunk":  i,
-		}
-		if embeddings != nil && i < len(embeddings) {
-			obj["vector"] = embeddings[i]
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		embedding, err := Ollama.EmbedText(ctx, ollamaTarget, ollamaModel, text)
+		if err != nil {
+			return nil, fmt.Errorf("生成向量失败: %v", err)
 		}
-		objects[i] = obj
-	}
-
-	result, err := k.batchAddToWeaviate(podName, namespace, port, collectionName, objects)
-	if err != nil {
-		return nil, fmt.Errorf("添加对象到 Weaviate 失败: %v", err)
-	}
-
-	return map[string]interface{}{
-		"status":          "success",
-		"message":         "文档上传成功",
-		"knowledge_type":  "weaviate",
-		"collection_name": collectionName,
-		"chunks_count":    len(chunks),
-		"result":          result,
-	}, nil
-}
-
-// ensureWeaviateClass 确保 Weaviate 类存在
-func (k *knowledge) ensureWeaviateClass(podName, namespace string, port int32, className string) error {
-	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
-	defer cancel()
-
-	// 检查类是否存在
-	req := K8s.ClientSet.CoreV1().RESTClient().Get().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/v1/schema/%s", className))
-
-	result := req.Do(ctx)
-	if result.Error() == nil {
-		return nil // 类已存在
-	}
-
-	// 创建类
-	createBody := map[string]interface{}{
-		"class": className,
-		"properties": []map[string]interface{}{
-			{"name": "text", "dataType": []string{"text"}},
-			{"name": "source", "dataType": []string{"string"}},
-			{"name": "chunk", "dataType": []string{"int"}},
-		},
+		vectors = append(vectors, embedding)
 	}
-	jsonData, _ := json.Marshal(createBody)
-
-	createReq := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix("/v1/schema").
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	createReq.Do(ctx)
-	return nil
+	return vectors, nil
 }
 
-// batchAddToWeaviate 批量添加对象到 Weaviate
-func (k *knowledge) batchAddToWeaviate(podName, namespace string, port int32, className string, objects []map[string]interface{}) (interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Minute)
-	defer cancel()
-
-	// Weaviate 批量添加
-	things := make([]map[string]interface{}, len(objects))
-	for i, obj := range objects {
-		things[i] = map[string]interface{}{
-			"class":      className,
-			"properties": obj,
-		}
-		if vector, ok := obj["vector"].([]float64); ok {
-			things[i]["vector"] = vector
-			delete(things[i]["properties"].(map[string]interface{}), "vector")
+// sanitizeCollectionName 清理集合名称
+func (k *knowledge) sanitizeCollectionName(name string) string {
+	var result []rune
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			result = append(result, r)
+		} else {
+			result = append(result, '_')
 		}
 	}
-
-	requestBody := map[string]interface{}{
-		"objects": things,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
-	}
-
-	req := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix("/v1/batch/objects").
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	result := req.Do(ctx)
-	if result.Error() != nil {
-		return nil, fmt.Errorf("请求 Weaviate API 失败: %v", result.Error())
-	}
-
-	body, err := result.Raw()
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var responseData map[string]interface{}
-	json.Unmarshal(body, &responseData)
-	return responseData, nil
+	return string(result)
 }
 
 // KnowledgeResp 知识库列表响应
@@ -1063,19 +590,20 @@ type KnowledgeResp struct {
 
 // KnowledgeDeployInfo 知识库部署信息
 type KnowledgeDeployInfo struct {
-	Name            string            `json:"name"`
-	Namespace       string            `json:"namespace"`
-	Type            string            `json:"type"` // deployment 或 daemonset
-	Image           string            `json:"image"`
-	Port            int32             `json:"port"`
-	NodeSelector    map[string]string `json:"node_selector"`
-	Status          string            `json:"status"`
-	Pods            int32             `json:"pods"`
-	ReadyPods       int32             `json:"ready_pods"`
-	OllamaPodName   string            `json:"ollama_pod_name,omitempty"`
-	OllamaModel     string            `json:"ollama_model,omitempty"`
-	OllamaNamespace string            `json:"ollama_namespace,omitempty"`
-	StorageSize     string            `json:"storage_size,omitempty"`
+	Name             string            `json:"name"`
+	Namespace        string            `json:"namespace"`
+	Type             string            `json:"type"` // deployment 或 daemonset
+	Image            string            `json:"image"`
+	Port             int32             `json:"port"`
+	NodeSelector     map[string]string `json:"node_selector"`
+	Status           string            `json:"status"`
+	Pods             int32             `json:"pods"`
+	ReadyPods        int32             `json:"ready_pods"`
+	OllamaPodName    string            `json:"ollama_pod_name,omitempty"`
+	OllamaDeployment string            `json:"ollama_deployment,omitempty"`
+	OllamaModel      string            `json:"ollama_model,omitempty"`
+	OllamaNamespace  string            `json:"ollama_namespace,omitempty"`
+	StorageSize      string            `json:"storage_size,omitempty"`
 }
 
 // ListKnowledge 获取知识库部署列表
@@ -1190,6 +718,8 @@ func (k *knowledge) convertDeploymentToInfo(deploy *appsV1.Deployment, nodeName
 			switch env.Name {
 			case "OLLAMA_POD_NAME":
 				info.OllamaPodName = env.Value
+			case "OLLAMA_DEPLOYMENT":
+				info.OllamaDeployment = env.Value
 			case "OLLAMA_NAMESPACE":
 				info.OllamaNamespace = env.Value
 			case "OLLAMA_MODEL":
@@ -1262,6 +792,8 @@ func (k *knowledge) convertDaemonSetToInfo(ds *appsV1.DaemonSet, nodeName string
 			switch env.Name {
 			case "OLLAMA_POD_NAME":
 				info.OllamaPodName = env.Value
+			case "OLLAMA_DEPLOYMENT":
+				info.OllamaDeployment = env.Value
 			case "OLLAMA_NAMESPACE":
 				info.OllamaNamespace = env.Value
 			case "OLLAMA_MODEL":
@@ -1336,6 +868,7 @@ type KnowledgeDetail struct {
 	Pods             int32                  `json:"pods"`
 	ReadyPods        int32                  `json:"ready_pods"`
 	OllamaPodName    string                 `json:"ollama_pod_name,omitempty"`
+	OllamaDeployment string                 `json:"ollama_deployment,omitempty"`
 	OllamaModel      string                 `json:"ollama_model,omitempty"`
 	OllamaNamespace  string                 `json:"ollama_namespace,omitempty"`
 	StorageSize      string                 `json:"storage_size,omitempty"`
@@ -1379,6 +912,8 @@ func (k *knowledge) convertDeploymentToDetail(deploy *appsV1.Deployment) *Knowle
 			switch env.Name {
 			case "OLLAMA_POD_NAME":
 				detail.OllamaPodName = env.Value
+			case "OLLAMA_DEPLOYMENT":
+				detail.OllamaDeployment = env.Value
 			case "OLLAMA_NAMESPACE":
 				detail.OllamaNamespace = env.Value
 			case "OLLAMA_MODEL":
@@ -1472,6 +1007,8 @@ func (k *knowledge) convertDaemonSetToDetail(ds *appsV1.DaemonSet) *KnowledgeDet
 			switch env.Name {
 			case "OLLAMA_POD_NAME":
 				detail.OllamaPodName = env.Value
+			case "OLLAMA_DEPLOYMENT":
+				detail.OllamaDeployment = env.Value
 			case "OLLAMA_NAMESPACE":
 				detail.OllamaNamespace = env.Value
 			case "OLLAMA_MODEL":
@@ -1534,27 +1071,9 @@ func (k *knowledge) convertDaemonSetToDetail(ds *appsV1.DaemonSet) *KnowledgeDet
 	return detail
 }
 
-// QueryKnowledge 查询知识库（支持 ChromaDB、Milvus、Weaviate）
-func (k *knowledge) QueryKnowledge(podName, namespace, knowledgeType, collectionName, queryText string, topK int) (interface{}, error) {
-	// 根据知识库类型调用不同的查询方法
-	knowledgeType = strings.ToLower(knowledgeType)
-	switch knowledgeType {
-	case "chromadb", "chroma":
-		return k.queryChroma(podName, namespace, collectionName, queryText, topK)
-	case "milvus":
-		return k.queryMilvus(podName, namespace, collectionName, queryText, topK)
-	case "weaviate":
-		return k.queryWeaviate(podName, namespace, collectionName, queryText, topK)
-	default:
-		return nil, fmt.Errorf("不支持的知识库类型: %s，支持的类型: chromadb, milvus, weaviate", knowledgeType)
-	}
-}
-
-// ========== ChromaDB 查询 ==========
-
-// queryChroma 查询 ChromaDB
-func (k *knowledge) queryChroma(podName, namespace, collectionName, queryText string, topK int) (interface{}, error) {
-	pod, port, err := k.getPodInfo(podName, namespace, 8000)
+// QueryKnowledge 查询知识库中与查询文本最相似的 topK 个分块，filter 不为空时只返回元数据匹配的分块
+func (k *knowledge) QueryKnowledge(ctx context.Context, podName, namespace, knowledgeType, collectionName, queryText string, topK int, filter map[string]interface{}) (*KnowledgeQueryResult, error) {
+	store, backend, pod, err := k.vectorStore(podName, namespace, knowledgeType)
 	if err != nil {
 		return nil, err
 	}
@@ -1564,273 +1083,151 @@ func (k *knowledge) queryChroma(podName, namespace, collectionName, queryText st
 	}
 
 	// 获取 Ollama 信息并生成查询向量
-	ollamaPodName, ollamaNamespace, ollamaModel := k.getOllamaInfo(pod, namespace)
-	if ollamaPodName == "" || ollamaModel == "" {
-		return nil, fmt.Errorf("查询需要向量嵌入，请确保知识库绑定了 Ollama")
+	ollamaTarget, ollamaModel, err := k.embedder(pod, namespace)
+	if err != nil {
+		return nil, err
 	}
-
-	embeddings, err := k.generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel, []string{queryText})
+	vector, err := Ollama.EmbedText(ctx, ollamaTarget, ollamaModel, queryText)
 	if err != nil {
 		return nil, fmt.Errorf("生成查询向量失败: %v", err)
 	}
-	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
+	if len(vector) == 0 {
 		return nil, fmt.Errorf("生成的查询向量为空")
 	}
 
 	collectionName = k.sanitizeCollectionName(collectionName)
-
-	// 获取集合的 UUID
-	collectionUUID, err := k.ensureChromaCollection(podName, namespace, port, collectionName)
+	hits, err := store.Query(ctx, collectionName, vectorstore.Query{Vector: vector, TopK: topK, Filter: filter})
 	if err != nil {
-		return nil, fmt.Errorf("获取集合信息失败: %v", err)
-	}
-
-	// Chroma v2 API 查询接口
-	tenant := "default_tenant"
-	database := "default_database"
-
-	requestBody := map[string]interface{}{
-		"query_embeddings": [][]float64{embeddings[0]},
-		"n_results":        topK,
-		"include":          []string{"documents", "metadatas", "distances"},
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
-	}
-
-	req := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix(fmt.Sprintf("/api/v2/tenants/%s/databases/%s/collections/%s/query", tenant, database, collectionUUID)).
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	result := req.Do(context.TODO())
-	if result.Error() != nil {
-		return nil, fmt.Errorf("请求 Chroma API 失败: %v", result.Error())
-	}
-
-	body, err := result.Raw()
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var responseData map[string]interface{}
-	if err := json.Unmarshal(body, &responseData); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))
+		return nil, err
 	}
 
-	// 检查是否有错误
-	if errMsg, ok := responseData["error"].(string); ok && errMsg != "" {
-		return nil, fmt.Errorf("chroma API 返回错误: %s", errMsg)
+	result := &KnowledgeQueryResult{
+		KnowledgeType:  backend.Name,
+		CollectionName: collectionName,
+		QueryText:      queryText,
+		TopK:           topK,
+		Hits:           hits,
 	}
-
-	// 检查结果是否为空，如果为空可能是集合中没有数据
-	if ids, ok := responseData["ids"].([]interface{}); ok && len(ids) > 0 {
-		if idList, ok := ids[0].([]interface{}); ok && len(idList) == 0 {
-			// 结果为空，可能是集合中没有数据或查询向量不匹配
-			// 尝试获取集合信息来确认是否有数据
-			return map[string]interface{}{
-				"status":          "success",
-				"knowledge_type":  "chromadb",
-				"collection_name": collectionName,
-				"query_text":      queryText,
-				"top_k":           topK,
-				"results":         responseData,
-				"warning":         "查询结果为空，请确认：1. 集合中是否有数据；2. 查询向量和存储向量是否使用相同的模型",
-			}, nil
-		}
+	if len(hits) == 0 {
+		result.Hits = []vectorstore.Hit{}
+		result.Warning = "查询结果为空，请确认：1. 集合中是否有数据；2. 查询向量和存储向量是否使用相同的模型"
 	}
-
-	return map[string]interface{}{
-		"status":          "success",
-		"knowledge_type":  "chromadb",
-		"collection_name": collectionName,
-		"query_text":      queryText,
-		"top_k":           topK,
-		"results":         responseData,
-	}, nil
+	return result, nil
 }
 
-// ========== Milvus 查询 ==========
-
-// queryMilvus 查询 Milvus
-func (k *knowledge) queryMilvus(podName, namespace, collectionName, queryText string, topK int) (interface{}, error) {
-	pod, port, err := k.getPodInfo(podName, namespace, 19530)
+// ChatWithKnowledgeBase 结合知识库进行聊天
+// 启用语义缓存时，同一集合、模型及提示词下相似的问题直接返回之前的回答
+func (k *knowledge) ChatWithKnowledgeBase(ctx context.Context, params *kubeDto.ChatWithKBInput) (interface{}, error) {
+	lookup, err := k.lookupAnswer(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-
-	if topK <= 0 {
-		topK = 5
-	}
-
-	// 获取 Ollama 信息并生成查询向量
-	ollamaPodName, ollamaNamespace, ollamaModel := k.getOllamaInfo(pod, namespace)
-	if ollamaPodName == "" || ollamaModel == "" {
-		return nil, fmt.Errorf("查询需要向量嵌入，请确保知识库绑定了 Ollama")
+	if hit := lookup.hit; hit != nil {
+		return map[string]interface{}{
+			"answer":            hit.answer,
+			"related_documents": hit.documents,
+			"question":          params.Question,
+			"top_k":             hit.topK,
+			"cached":            true,
+			"cached_question":   hit.question,
+			"similarity":        lookup.score,
+		}, nil
 	}
 
-	embeddings, err := k.generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel, []string{queryText})
+	messages, documents, topK, err := k.prepareKBChat(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("生成查询向量失败: %v", err)
-	}
-	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
-		return nil, fmt.Errorf("生成的查询向量为空")
-	}
-
-	collectionName = k.sanitizeCollectionName(collectionName)
-
-	// Milvus 使用 HTTP API 进行查询
-	// 注意：Milvus 的 HTTP API 可能因版本而异，这里使用通用的搜索接口
-	requestBody := map[string]interface{}{
-		"collection_name": collectionName,
-		"vector":          embeddings[0],
-		"top_k":           topK,
-		"output_fields":   []string{"id", "text"},
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
-	}
-
-	req := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix("/v1/vector/search").
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	result := req.Do(context.TODO())
-	if result.Error() != nil {
-		return nil, fmt.Errorf("请求 Milvus API 失败: %v", result.Error())
+		return nil, err
 	}
 
-	body, err := result.Raw()
+	// 调用 Ollama Chat API
+	chatResult, err := Ollama.Chat(
+		ctx,
+		params.OllamaTarget(),
+		params.OllamaModel,
+		messages,
+		params.Stream,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var responseData map[string]interface{}
-	if err := json.Unmarshal(body, &responseData); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))
+		return nil, fmt.Errorf("调用模型失败: %v", err)
 	}
+	lookup.store(params.Question, chatResult, documents, topK)
 
+	// 返回结果（包含查询到的文档和模型回答）
 	return map[string]interface{}{
-		"status":          "success",
-		"knowledge_type":  "milvus",
-		"collection_name": collectionName,
-		"query_text":      queryText,
-		"top_k":           topK,
-		"results":         responseData,
+		"answer":            chatResult,
+		"related_documents": documents,
+		"question":          params.Question,
+		"top_k":             topK,
+		"cached":            false,
 	}, nil
 }
 
-// ========== Weaviate 查询 ==========
-
-// queryWeaviate 查询 Weaviate
-func (k *knowledge) queryWeaviate(podName, namespace, collectionName, queryText string, topK int) (interface{}, error) {
-	pod, port, err := k.getPodInfo(podName, namespace, 8080)
+// ChatWithKnowledgeBaseStream 结合知识库流式聊天
+// 先通过 onDocuments 返回检索到的文档，再通过 onChunk 逐块返回模型输出
+// 命中语义缓存时通过 onChunk 一次返回之前的回答，并标记 cached=true
+func (k *knowledge) ChatWithKnowledgeBaseStream(ctx context.Context, params *kubeDto.ChatWithKBInput, onDocuments func(documents []string, topK int) error, onChunk func(chunk map[string]interface{}) error) error {
+	lookup, err := k.lookupAnswer(ctx, params)
 	if err != nil {
-		return nil, err
-	}
-
-	if topK <= 0 {
-		topK = 5
+		return err
 	}
-
-	// 获取 Ollama 信息并生成查询向量
-	ollamaPodName, ollamaNamespace, ollamaModel := k.getOllamaInfo(pod, namespace)
-	if ollamaPodName == "" || ollamaModel == "" {
-		return nil, fmt.Errorf("查询需要向量嵌入，请确保知识库绑定了 Ollama")
+	if hit := lookup.hit; hit != nil {
+		if err := onDocuments(hit.documents, hit.topK); err != nil {
+			return err
+		}
+		return onChunk(cachedAnswerChunk(params.OllamaModel, hit, lookup.score))
 	}
 
-	embeddings, err := k.generateEmbeddings(ollamaPodName, ollamaNamespace, ollamaModel, []string{queryText})
+	messages, documents, topK, err := k.prepareKBChat(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("生成查询向量失败: %v", err)
+		return err
 	}
-	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
-		return nil, fmt.Errorf("生成的查询向量为空")
+	if err := onDocuments(documents, topK); err != nil {
+		return err
 	}
-
-	collectionName = k.sanitizeCollectionName(collectionName)
-
-	// Weaviate 使用 GraphQL 进行查询
-	// 使用向量搜索
-	graphQLQuery := fmt.Sprintf(`{
-		Get {
-			%s(nearVector: {
-				vector: %s
-			}, limit: %d) {
-				text
-				source
-				chunk
-				_additional {
-					distance
-				}
-			}
+	var (
+		answer strings.Builder
+		done   bool
+	)
+	err = Ollama.ChatStream(ctx, params.OllamaTarget(), params.OllamaModel, messages, func(chunk map[string]interface{}) error {
+		if msg, err := ChatMessage(chunk); err == nil {
+			answer.WriteString(msg.Content)
 		}
-	}`, collectionName, k.formatVectorForGraphQL(embeddings[0]), topK)
-
-	jsonData, err := json.Marshal(map[string]interface{}{
-		"query": graphQLQuery,
+		done, _ = chunk["done"].(bool)
+		return onChunk(chunk)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+		return fmt.Errorf("调用模型失败: %v", err)
 	}
-
-	req := K8s.ClientSet.CoreV1().RESTClient().Post().
-		Namespace(namespace).
-		Resource("pods").
-		Name(fmt.Sprintf("%s:%d", podName, port)).
-		SubResource("proxy").
-		Suffix("/v1/graphql").
-		Body(jsonData).
-		SetHeader("Content-Type", "application/json")
-
-	result := req.Do(context.TODO())
-	if result.Error() != nil {
-		return nil, fmt.Errorf("请求 Weaviate API 失败: %v", result.Error())
+	// 只缓存完整输出的回答
+	if done {
+		lookup.store(params.Question, streamAnswer(params.OllamaModel, answer.String()), documents, topK)
 	}
+	return nil
+}
 
-	body, err := result.Raw()
+// prepareKBChat 查询知识库并构建发送给模型的消息列表
+func (k *knowledge) prepareKBChat(ctx context.Context, params *kubeDto.ChatWithKBInput) ([]kubeDto.OllamaChatMessage, []string, int, error) {
+	systemPrompt, documents, topK, err := k.RetrieveSystemPrompt(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+		return nil, nil, 0, err
 	}
 
-	var responseData map[string]interface{}
-	if err := json.Unmarshal(body, &responseData); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))
-	}
-
-	return map[string]interface{}{
-		"status":          "success",
-		"knowledge_type":  "weaviate",
-		"collection_name": collectionName,
-		"query_text":      queryText,
-		"top_k":           topK,
-		"results":         responseData,
-	}, nil
-}
-
-// formatVectorForGraphQL 将向量格式化为 GraphQL 格式
-func (k *knowledge) formatVectorForGraphQL(vector []float64) string {
-	var parts []string
-	for _, v := range vector {
-		parts = append(parts, fmt.Sprintf("%.6f", v))
+	// 构建消息列表
+	messages := []kubeDto.OllamaChatMessage{
+		{
+			Role:    "system",
+			Content: systemPrompt,
+		},
+		{
+			Role:    "user",
+			Content: params.Question,
+		},
 	}
-	return "[" + strings.Join(parts, ",") + "]"
+	return messages, documents, topK, nil
 }
 
-// ChatWithKnowledgeBase 结合知识库进行聊天
-func (k *knowledge) ChatWithKnowledgeBase(params *kubeDto.ChatWithKBInput) (interface{}, error) {
+// RetrieveSystemPrompt 根据问题查询知识库，返回包含相关文档的系统提示词
+func (k *knowledge) RetrieveSystemPrompt(ctx context.Context, params *kubeDto.ChatWithKBInput) (string, []string, int, error) {
 	// 设置默认值
 	topK := params.TopK
 	if topK <= 0 {
@@ -1838,120 +1235,35 @@ func (k *knowledge) ChatWithKnowledgeBase(params *kubeDto.ChatWithKBInput) (inte
 	}
 
 	// 1. 查询知识库获取相关文档
-	queryResult, err := k.QueryKnowledge(
+	result, err := k.QueryKnowledge(
+		ctx,
 		params.KnowledgePodName,
 		params.KnowledgeNamespace,
 		params.KnowledgeType,
 		params.CollectionName,
 		params.Question,
 		topK,
+		params.Filter,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("查询知识库失败: %v", err)
+		return "", nil, 0, fmt.Errorf("查询知识库失败: %v", err)
 	}
 
 	// 2. 从查询结果中提取文档内容
-	documents := k.extractDocumentsFromQueryResult(queryResult, params.KnowledgeType)
+	documents := result.Documents()
 	if len(documents) == 0 {
-		return nil, fmt.Errorf("知识库中未找到相关文档，请确认集合中是否有数据")
+		return "", nil, 0, fmt.Errorf("知识库中未找到相关文档，请确认集合中是否有数据")
 	}
 
-	// 3. 构建包含上下文的系统提示词
-	systemPrompt := k.buildSystemPromptWithContext(params.SystemPrompt, documents)
-
-	// 4. 构建消息列表
-	messages := []kubeDto.OllamaChatMessage{
-		{
-			Role:    "system",
-			Content: systemPrompt,
-		},
-		{
-			Role:    "user",
-			Content: params.Question,
-		},
-	}
-
-	// 5. 调用 Ollama Chat API
-	chatResult, err := Ollama.Chat(
-		params.OllamaPodName,
-		params.OllamaNamespace,
-		params.OllamaModel,
-		messages,
-		params.Stream,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("调用模型失败: %v", err)
-	}
-
-	// 6. 返回结果（包含查询到的文档和模型回答）
-	return map[string]interface{}{
-		"answer":            chatResult,
-		"related_documents": documents,
-		"question":          params.Question,
-		"top_k":             topK,
-	}, nil
-}
-
-// extractDocumentsFromQueryResult 从查询结果中提取文档内容
-func (k *knowledge) extractDocumentsFromQueryResult(queryResult interface{}, knowledgeType string) []string {
-	var documents []string
-
-	queryResultMap, ok := queryResult.(map[string]interface{})
-	if !ok {
-		return documents
-	}
-
-	results, ok := queryResultMap["results"].(map[string]interface{})
-	if !ok {
-		return documents
-	}
-
-	knowledgeType = strings.ToLower(knowledgeType)
-	switch knowledgeType {
-	case "chromadb", "chroma":
-		// ChromaDB 返回格式: {"ids": [[...]], "documents": [[...]], "metadatas": [[...]], "distances": [[...]]}
-		if docs, ok := results["documents"].([]interface{}); ok && len(docs) > 0 {
-			if docList, ok := docs[0].([]interface{}); ok {
-				for _, doc := range docList {
-					if docStr, ok := doc.(string); ok && docStr != "" {
-						documents = append(documents, docStr)
-					}
-				}
-			}
-		}
-	case "milvus":
-		// Milvus 返回格式可能不同，需要根据实际 API 调整
-		if data, ok := results["data"].([]interface{}); ok {
-			for _, item := range data {
-				if itemMap, ok := item.(map[string]interface{}); ok {
-					if text, ok := itemMap["text"].(string); ok && text != "" {
-						documents = append(documents, text)
-					}
-				}
-			}
-		}
-	case "weaviate":
-		// Weaviate 返回格式: {"data": {"Get": {"ClassName": [...]}}}
-		if data, ok := results["data"].(map[string]interface{}); ok {
-			if get, ok := data["Get"].(map[string]interface{}); ok {
-				for _, classData := range get {
-					if items, ok := classData.([]interface{}); ok {
-						for _, item := range items {
-							if itemMap, ok := item.(map[string]interface{}); ok {
-								if props, ok := itemMap["properties"].(map[string]interface{}); ok {
-									if text, ok := props["text"].(string); ok && text != "" {
-										documents = append(documents, text)
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+	// 3. 构建包含上下文的系统提示词，引用了模板时使用模板渲染
+	if params.PromptTemplate != "" {
+		systemPrompt, err := RenderPromptTemplate(params.PromptTemplate, promptVariables(ctx, params.Question, formatDocuments(documents), params.Variables))
+		if err != nil {
+			return "", nil, 0, err
 		}
+		return systemPrompt, documents, topK, nil
 	}
-
-	return documents
+	return k.buildSystemPromptWithContext(params.SystemPrompt, documents), documents, topK, nil
 }
 
 // buildSystemPromptWithContext 构建包含上下文的系统提示词
@@ -1968,11 +1280,18 @@ func (k *knowledge) buildSystemPromptWithContext(customPrompt string, documents
 	}
 
 	prompt.WriteString("相关文档内容：\n")
-	for i, doc := range documents {
-		prompt.WriteString(fmt.Sprintf("文档 %d:\n%s\n\n", i+1, doc))
-	}
+	prompt.WriteString(formatDocuments(documents))
 
 	prompt.WriteString("请基于以上文档内容回答用户的问题。")
 
 	return prompt.String()
 }
+
+// formatDocuments 将检索到的文档按序号拼接为上下文
+func formatDocuments(documents []string) string {
+	var b strings.Builder
+	for i, doc := range documents {
+		b.WriteString(fmt.Sprintf("文档 %d:\n%s\n\n", i+1, doc))
+	}
+	return b.String()
+}
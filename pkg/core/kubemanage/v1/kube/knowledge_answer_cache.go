@@ -0,0 +1,227 @@
+package kube
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// 知识库问答语义缓存默认配置
+const (
+	defaultAnswerThreshold  = 0.95
+	defaultAnswerTTL        = time.Hour
+	defaultAnswerMaxEntries = 1000
+)
+
+// cachedAnswer 一次知识库问答的结果，vector 为问题的向量
+type cachedAnswer struct {
+	key       string
+	question  string
+	vector    []float64
+	answer    interface{}
+	documents []string
+	topK      int
+	expireAt  time.Time
+}
+
+// answerCache 知识库问答语义缓存，按知识库集合分组，向集合上传文档时整组失效
+type answerCache struct {
+	mu         sync.Mutex
+	enabled    bool
+	threshold  float64
+	ttl        time.Duration
+	maxEntries int
+	// collections key 为 answerCollectionKey，同一集合内按写入顺序保存
+	collections map[string][]*cachedAnswer
+}
+
+var kbAnswers = &answerCache{collections: map[string][]*cachedAnswer{}}
+
+// ConfigureAnswerCache 启用知识库问答语义缓存
+// threshold 为问题向量的余弦相似度阈值，maxEntries 为每个集合最多缓存的问答数，超出时淘汰最早的
+func ConfigureAnswerCache(threshold float64, ttl time.Duration, maxEntries int) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultAnswerThreshold
+	}
+	if ttl <= 0 {
+		ttl = defaultAnswerTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultAnswerMaxEntries
+	}
+	kbAnswers.mu.Lock()
+	defer kbAnswers.mu.Unlock()
+	kbAnswers.enabled = true
+	kbAnswers.threshold = threshold
+	kbAnswers.ttl = ttl
+	kbAnswers.maxEntries = maxEntries
+	kbAnswers.collections = map[string][]*cachedAnswer{}
+}
+
+// InvalidateAnswerCache 清除知识库集合的问答缓存，集合中的文档发生变化后调用
+func InvalidateAnswerCache(namespace, podName, collectionName string) {
+	kbAnswers.mu.Lock()
+	defer kbAnswers.mu.Unlock()
+	delete(kbAnswers.collections, answerCollectionKey(namespace, podName, collectionName))
+}
+
+func (c *answerCache) isEnabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enabled
+}
+
+// lookup 在集合中查找 key 相同且问题最相似的未过期问答，相似度低于阈值时返回 nil
+func (c *answerCache) lookup(collection, key string, vector []float64) (*cachedAnswer, float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	var best *cachedAnswer
+	var bestScore float64
+	live := c.collections[collection][:0]
+	for _, entry := range c.collections[collection] {
+		if now.After(entry.expireAt) {
+			continue
+		}
+		live = append(live, entry)
+		if entry.key != key {
+			continue
+		}
+		if score := cosineSimilarity(vector, entry.vector); score >= c.threshold && score > bestScore {
+			best, bestScore = entry, score
+		}
+	}
+	c.collections[collection] = live
+	return best, bestScore
+}
+
+func (c *answerCache) store(collection string, entry *cachedAnswer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.expireAt = time.Now().Add(c.ttl)
+	entries := append(c.collections[collection], entry)
+	if len(entries) > c.maxEntries {
+		entries = entries[len(entries)-c.maxEntries:]
+	}
+	c.collections[collection] = entries
+}
+
+func answerCollectionKey(namespace, podName, collectionName string) string {
+	return namespace + "/" + podName + "/" + Knowledge.sanitizeCollectionName(collectionName)
+}
+
+// answerKey 影响回答内容的参数，只有这些参数相同的问答才能复用
+// 提示词模板引用了当前用户名时，回答按用户区分
+func answerKey(ctx context.Context, params *kubeDto.ChatWithKBInput) string {
+	var user string
+	for _, name := range ExtractPromptVariables(params.PromptTemplate) {
+		if name == PromptVarUser {
+			caller, _ := ctx.Value(usageCallerKey{}).(UsageCaller)
+			user = caller.UserName
+		}
+	}
+	data, _ := json.Marshal([]interface{}{params.OllamaModel, params.TopK, params.Filter, params.SystemPrompt, params.PromptTemplate, params.Variables, user})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// embedQuestion 使用知识库绑定的向量模型生成问题向量，与检索时使用同一模型，检索时会命中向量缓存
+func (k *knowledge) embedQuestion(ctx context.Context, params *kubeDto.ChatWithKBInput, question string) ([]float64, error) {
+	pod, err := Pod.GetPodDetail(params.KnowledgePodName, params.KnowledgeNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("获取Pod信息失败: %v", err)
+	}
+	target, model, err := k.embedder(pod, params.KnowledgeNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return Ollama.EmbedText(ctx, target, model, question)
+}
+
+// answerLookup 一次问答的缓存查找结果，vector 为空时不使用缓存
+type answerLookup struct {
+	collection string
+	key        string
+	vector     []float64
+	hit        *cachedAnswer
+	score      float64
+}
+
+// lookupAnswer 启用语义缓存时查找相似问题的回答，未启用或跳过缓存时返回空的查找结果
+func (k *knowledge) lookupAnswer(ctx context.Context, params *kubeDto.ChatWithKBInput) (*answerLookup, error) {
+	lookup := &answerLookup{}
+	if !kbAnswers.isEnabled() || params.NoCache {
+		return lookup, nil
+	}
+	// 命中缓存时不会调用模型，需要先做输入检查，脱敏后的问题同时用于缓存与推理
+	question, err := checkGuardrail(ctx, params.OllamaModel, GuardrailStageInput, params.Question)
+	if err != nil {
+		return nil, err
+	}
+	params.Question = question
+	lookup.collection = answerCollectionKey(params.KnowledgeNamespace, params.KnowledgePodName, params.CollectionName)
+	lookup.key = answerKey(ctx, params)
+	// 生成问题向量失败时不使用缓存，由后续检索返回错误
+	if lookup.vector, err = k.embedQuestion(ctx, params, question); err == nil {
+		lookup.hit, lookup.score = kbAnswers.lookup(lookup.collection, lookup.key, lookup.vector)
+	}
+	return lookup, nil
+}
+
+// store 缓存模型的回答，answer 为 Ollama chat 响应
+func (l *answerLookup) store(question string, answer interface{}, documents []string, topK int) {
+	if l.vector == nil {
+		return
+	}
+	kbAnswers.store(l.collection, &cachedAnswer{
+		key:       l.key,
+		question:  question,
+		vector:    l.vector,
+		answer:    answer,
+		documents: documents,
+		topK:      topK,
+	})
+}
+
+// streamAnswer 将流式输出拼接的回答构造为 chat 响应，与非流式请求缓存的回答格式一致
+func streamAnswer(model, content string) map[string]interface{} {
+	return map[string]interface{}{
+		"model":   model,
+		"message": map[string]interface{}{"role": "assistant", "content": content},
+		"done":    true,
+	}
+}
+
+// cachedAnswerChunk 将缓存的回答构造为流式输出的最后一个分片
+func cachedAnswerChunk(model string, hit *cachedAnswer, score float64) map[string]interface{} {
+	msg, _ := ChatMessage(hit.answer)
+	chunk := streamAnswer(model, msg.Content)
+	chunk["cached"] = true
+	chunk["cached_question"] = hit.question
+	chunk["similarity"] = score
+	return chunk
+}
+
+// cosineSimilarity 计算两个向量的余弦相似度，维度不同时返回 0
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
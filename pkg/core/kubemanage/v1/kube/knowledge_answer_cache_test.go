@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnswerCacheLookup(t *testing.T) {
+	c := &answerCache{enabled: true, threshold: 0.9, ttl: time.Minute, maxEntries: 2, collections: map[string][]*cachedAnswer{}}
+	collection := answerCollectionKey("ai", "chroma-0", "员工手册.md")
+	c.store(collection, &cachedAnswer{key: "qwen", question: "年假有几天", vector: []float64{1, 0, 0}, answer: "5天"})
+	c.store(collection, &cachedAnswer{key: "qwen", question: "如何报销", vector: []float64{0, 1, 0}, answer: "走OA"})
+
+	hit, score := c.lookup(collection, "qwen", []float64{0.95, 0.1, 0})
+	if hit == nil || hit.answer != "5天" || score < 0.9 {
+		t.Fatalf("expected hit, got %v %v", hit, score)
+	}
+	if hit, _ := c.lookup(collection, "llama", []float64{1, 0, 0}); hit != nil {
+		t.Fatal("answers of another model must not be reused")
+	}
+	if hit, _ := c.lookup(collection, "qwen", []float64{0.6, 0.6, 0.5}); hit != nil {
+		t.Fatal("similarity below threshold must miss")
+	}
+	if hit, _ := c.lookup(answerCollectionKey("ai", "chroma-0", "other"), "qwen", []float64{1, 0, 0}); hit != nil {
+		t.Fatal("answers of another collection must not be reused")
+	}
+
+	// 超出每个集合的条数上限时淘汰最早的问答
+	c.store(collection, &cachedAnswer{key: "qwen", question: "几点下班", vector: []float64{0, 0, 1}, answer: "六点"})
+	if hit, _ := c.lookup(collection, "qwen", []float64{1, 0, 0}); hit != nil {
+		t.Fatal("oldest answer should have been evicted")
+	}
+
+	c.collections[collection][0].expireAt = time.Now().Add(-time.Second)
+	if hit, _ := c.lookup(collection, "qwen", []float64{0, 1, 0}); hit != nil {
+		t.Fatal("expired answer must miss")
+	}
+	if len(c.collections[collection]) != 1 {
+		t.Fatalf("expired answers should be dropped, left %d", len(c.collections[collection]))
+	}
+}
+
+func TestInvalidateAnswerCache(t *testing.T) {
+	saved := kbAnswers
+	kbAnswers = &answerCache{collections: map[string][]*cachedAnswer{}}
+	defer func() { kbAnswers = saved }()
+	ConfigureAnswerCache(0, 0, 0)
+
+	// 上传时使用原始文件名，查询时使用同一名称，清理规则一致才能失效
+	collection := answerCollectionKey("ai", "chroma-0", "员工手册.md")
+	kbAnswers.store(collection, &cachedAnswer{key: "qwen", vector: []float64{1, 0}, answer: "5天"})
+	InvalidateAnswerCache("ai", "chroma-0", "员工手册.md")
+	if hit, _ := kbAnswers.lookup(collection, "qwen", []float64{1, 0}); hit != nil {
+		t.Fatal("answers should be invalidated after upload")
+	}
+}
+
+func TestCachedAnswerChunk(t *testing.T) {
+	// 非流式请求缓存 Ollama chat 响应，流式请求缓存拼接后的回答，命中时都以一个分片返回
+	answers := map[string]interface{}{
+		"chat":   map[string]interface{}{"model": "qwen", "message": map[string]interface{}{"role": "assistant", "content": "5天"}, "done": true, "eval_count": 3},
+		"stream": streamAnswer("qwen", "5天"),
+	}
+	for name, answer := range answers {
+		chunk := cachedAnswerChunk("qwen", &cachedAnswer{question: "年假有几天", answer: answer}, 0.97)
+		msg, err := ChatMessage(chunk)
+		if err != nil || msg.Content != "5天" {
+			t.Errorf("%s: message = %+v, err = %v", name, msg, err)
+		}
+		if chunk["done"] != true || chunk["cached"] != true || chunk["cached_question"] != "年假有几天" || chunk["similarity"] != 0.97 {
+			t.Errorf("%s: chunk = %v", name, chunk)
+		}
+	}
+}
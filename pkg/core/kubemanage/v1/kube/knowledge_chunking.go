@@ -0,0 +1,87 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	coreV1 "k8s.io/api/core/v1"
+
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+	"github.com/noovertime7/kubemanage/pkg/docparse"
+)
+
+// chunkingLookup 查找知识库集合的分块配置，由上层在启动时注入，未配置时返回 nil
+var chunkingLookup func(ctx context.Context, namespace, knowledge, collection string) (*chunker.Options, error)
+
+// SetChunkingLookup 设置集合分块配置的查找回调，每次上传文档时调用
+func SetChunkingLookup(fn func(ctx context.Context, namespace, knowledge, collection string) (*chunker.Options, error)) {
+	chunkingLookup = fn
+}
+
+// SanitizeCollectionName 返回集合在向量数据库中的实际名称，集合的分块配置按该名称保存
+func SanitizeCollectionName(name string) string {
+	return Knowledge.sanitizeCollectionName(name)
+}
+
+// chunkingOptions 确定上传使用的分块参数
+// 上传时指定了与集合配置不同的策略时只使用上传参数，否则以集合配置为基础，上传时指定的非零字段覆盖对应字段；
+// token 上限不超过向量模型的最大输入长度
+func (k *knowledge) chunkingOptions(ctx context.Context, pod *coreV1.Pod, namespace, collection string, upload chunker.Options, embedModel string) (chunker.Options, error) {
+	var opts chunker.Options
+	if chunkingLookup != nil {
+		saved, err := chunkingLookup(ctx, namespace, pod.Labels["name"], collection)
+		if err != nil {
+			return opts, fmt.Errorf("查询集合分块配置失败: %v", err)
+		}
+		if saved != nil {
+			opts = *saved
+		}
+	}
+
+	if upload.Strategy != "" && upload.Strategy != opts.Strategy {
+		opts = upload
+	} else {
+		if upload.ChunkSize > 0 {
+			opts.ChunkSize = upload.ChunkSize
+		}
+		if upload.ChunkOverlap > 0 {
+			opts.ChunkOverlap = upload.ChunkOverlap
+		}
+		if upload.MaxTokens > 0 {
+			opts.MaxTokens = upload.MaxTokens
+		}
+	}
+
+	if limit := chunker.ModelTokenLimit(embedModel); limit > 0 && (opts.MaxTokens == 0 || opts.MaxTokens > limit) {
+		opts.MaxTokens = limit
+	}
+	return opts.Normalize()
+}
+
+// chunkSections 切分文档的各个段落，分块的 metadata 为所在段落的 metadata，markdown 策略下加上分块所在的标题路径
+func chunkSections(sections []docparse.Section, opts chunker.Options) ([]string, []map[string]interface{}, error) {
+	var (
+		chunks    []string
+		chunkMeta []map[string]interface{}
+	)
+	for _, section := range sections {
+		parts, err := chunker.Split(section.Text, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, part := range parts {
+			metadata := section.Metadata
+			if part.Heading != "" {
+				metadata = map[string]interface{}{docparse.MetaHeading: part.Heading}
+				for key, value := range section.Metadata {
+					if key != docparse.MetaHeading {
+						metadata[key] = value
+					}
+				}
+			}
+			chunks = append(chunks, part.Text)
+			chunkMeta = append(chunkMeta, metadata)
+		}
+	}
+	return chunks, chunkMeta, nil
+}
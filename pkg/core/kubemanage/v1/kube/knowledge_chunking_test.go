@@ -0,0 +1,73 @@
+package kube
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+	"github.com/noovertime7/kubemanage/pkg/docparse"
+)
+
+func TestChunkingOptions(t *testing.T) {
+	defer SetChunkingLookup(nil)
+	SetChunkingLookup(func(ctx context.Context, namespace, knowledge, collection string) (*chunker.Options, error) {
+		if namespace == "ai" && knowledge == "docs" && collection == "handbook_md" {
+			return &chunker.Options{Strategy: chunker.StrategyMarkdown, ChunkSize: 800, ChunkOverlap: 50}, nil
+		}
+		return nil, nil
+	})
+	pod := &coreV1.Pod{ObjectMeta: metaV1.ObjectMeta{Labels: map[string]string{"name": "docs"}}}
+
+	cases := []struct {
+		name       string
+		collection string
+		upload     chunker.Options
+		model      string
+		want       chunker.Options
+	}{
+		{"defaults", "other", chunker.Options{}, "qwen2.5:7b", chunker.Options{Strategy: chunker.StrategyRecursive, ChunkSize: chunker.DefaultChunkSize}},
+		{"collection", "handbook_md", chunker.Options{}, "bge-m3", chunker.Options{Strategy: chunker.StrategyMarkdown, ChunkSize: 800, ChunkOverlap: 50, MaxTokens: 8192}},
+		{"upload overrides fields", "handbook_md", chunker.Options{ChunkSize: 300}, "", chunker.Options{Strategy: chunker.StrategyMarkdown, ChunkSize: 300, ChunkOverlap: 50}},
+		{"upload strategy replaces collection", "handbook_md", chunker.Options{Strategy: chunker.StrategyFixed, ChunkSize: 200}, "", chunker.Options{Strategy: chunker.StrategyFixed, ChunkSize: 200}},
+		{"model limit caps max tokens", "other", chunker.Options{Strategy: chunker.StrategyToken, MaxTokens: 4096}, "all-minilm", chunker.Options{Strategy: chunker.StrategyToken, ChunkSize: 256, MaxTokens: 256}},
+	}
+	for _, c := range cases {
+		got, err := Knowledge.chunkingOptions(context.Background(), pod, "ai", c.collection, c.upload, c.model)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: options = %+v, want %+v", c.name, got, c.want)
+		}
+	}
+
+	if _, err := Knowledge.chunkingOptions(context.Background(), pod, "ai", "other", chunker.Options{ChunkSize: 100, ChunkOverlap: 100}, ""); err == nil {
+		t.Error("overlap not smaller than chunk size should be rejected")
+	}
+}
+
+func TestChunkSections(t *testing.T) {
+	sections := []docparse.Section{
+		{Text: "第一页的内容。", Metadata: map[string]interface{}{docparse.MetaPage: 1}},
+		{Text: "# 部署\n执行 install.sh。\n## FAQ\nSee the docs.", Metadata: map[string]interface{}{docparse.MetaPage: 2}},
+	}
+	chunks, meta, err := chunkSections(sections, chunker.Options{Strategy: chunker.StrategyMarkdown, ChunkSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"第一页的内容。", "# 部署\n执行 install.sh。", "## FAQ\nSee the docs."}; !reflect.DeepEqual(chunks, want) {
+		t.Fatalf("chunks = %q, want %q", chunks, want)
+	}
+	want := []map[string]interface{}{
+		{docparse.MetaPage: 1},
+		{docparse.MetaPage: 2, docparse.MetaHeading: "部署"},
+		{docparse.MetaPage: 2, docparse.MetaHeading: "部署 > FAQ"},
+	}
+	if !reflect.DeepEqual(meta, want) {
+		t.Fatalf("metadata = %v, want %v", meta, want)
+	}
+}
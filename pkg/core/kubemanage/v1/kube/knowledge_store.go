@@ -0,0 +1,132 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/vectorstore"
+)
+
+// KnowledgeQueryResult 知识库查询结果，Hits 按相似度降序
+type KnowledgeQueryResult struct {
+	KnowledgeType  string            `json:"knowledge_type"`
+	CollectionName string            `json:"collection_name"`
+	QueryText      string            `json:"query_text"`
+	TopK           int               `json:"top_k"`
+	Hits           []vectorstore.Hit `json:"hits"`
+	Warning        string            `json:"warning,omitempty"`
+}
+
+// Documents 返回命中分块的文本，忽略空文本
+func (r *KnowledgeQueryResult) Documents() []string {
+	var documents []string
+	for _, hit := range r.Hits {
+		if hit.Text != "" {
+			documents = append(documents, hit.Text)
+		}
+	}
+	return documents
+}
+
+// vectorStore 按知识库类型创建访问该 Pod 的向量库
+func (k *knowledge) vectorStore(podName, namespace, knowledgeType string) (vectorstore.VectorStore, *vectorstore.Backend, *coreV1.Pod, error) {
+	backend, err := vectorstore.Lookup(knowledgeType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pod, port, err := k.getPodInfo(podName, namespace, backend.DefaultPort)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	env, err := podEnv(pod)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cfg := vectorstore.Config{
+		Transport: &podProxyTransport{podName: podName, namespace: namespace, port: port},
+		// 非 HTTP 协议的后端（如 pgvector）无法经过 API Server 代理，直接连接 Pod IP
+		Addr: net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))),
+		Env:  env,
+		Key:  namespace + "/" + podName,
+	}
+	return backend.New(cfg), backend, pod, nil
+}
+
+// podEnv 读取知识库容器的环境变量，引用 Secret 的变量读取 Secret 中的值
+func podEnv(pod *coreV1.Pod) (map[string]string, error) {
+	env := map[string]string{}
+	if len(pod.Spec.Containers) == 0 {
+		return env, nil
+	}
+	for _, item := range pod.Spec.Containers[0].Env {
+		if item.ValueFrom == nil || item.ValueFrom.SecretKeyRef == nil {
+			env[item.Name] = item.Value
+			continue
+		}
+		ref := item.ValueFrom.SecretKeyRef
+		secret, err := K8s.ClientSet.CoreV1().Secrets(pod.Namespace).Get(context.TODO(), ref.Name, metaV1.GetOptions{})
+		if err != nil {
+			if ref.Optional != nil && *ref.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("读取环境变量 %s 引用的Secret失败: %v", item.Name, err)
+		}
+		env[item.Name] = string(secret.Data[ref.Key])
+	}
+	return env, nil
+}
+
+// embedder 返回知识库绑定的 Ollama 及向量模型，写入与查询必须使用同一模型
+func (k *knowledge) embedder(pod *coreV1.Pod, namespace string) (kubeDto.OllamaTarget, string, error) {
+	target, model := k.getOllamaInfo(pod, namespace)
+	if (target.PodName == "" && target.Deployment == "") || model == "" {
+		return target, model, fmt.Errorf("知识库需要向量嵌入，请确保知识库绑定了 Ollama")
+	}
+	return target, model, nil
+}
+
+// podProxyTransport 通过 API Server 的 Pod 代理访问向量数据库，与 Ollama 相同的方式
+type podProxyTransport struct {
+	podName   string
+	namespace string
+	port      int32
+}
+
+func (t *podProxyTransport) Do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("请求路径无效: %v", err)
+	}
+	req := K8s.ClientSet.CoreV1().RESTClient().Verb(method).
+		Namespace(t.namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%d", t.podName, t.port)).
+		SubResource("proxy").
+		Suffix(u.Path)
+	// 查询参数需要单独设置，拼在 Suffix 中会被转义
+	for key, values := range u.Query() {
+		for _, value := range values {
+			req = req.Param(key, value)
+		}
+	}
+	if body != nil {
+		req = req.Body(body).SetHeader("Content-Type", "application/json")
+	}
+	result := req.Do(ctx)
+	raw, err := result.Raw()
+	if err != nil {
+		var code int
+		if result.StatusCode(&code); code != 0 {
+			return nil, &vectorstore.StatusError{Code: code, Body: string(raw)}
+		}
+		return nil, fmt.Errorf("请求向量数据库失败: %v", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,127 @@
+package kube
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/vectorstore"
+)
+
+// knowledgeDataPath 未指定数据目录时存储卷的挂载目录
+const knowledgeDataPath = "/data"
+
+// knowledgeTemplate 知识库类型的内置部署模板
+type knowledgeTemplate struct {
+	Image    string
+	Port     int32
+	DataPath string
+	Env      []coreV1.EnvVar
+	// Password 为 true 时生成数据库密码保存到 Secret，通过 POSTGRES_PASSWORD 注入
+	Password bool
+}
+
+// knowledgeTemplates 按 vectorstore 后端名称索引，其他类型需要手动指定镜像和端口
+var knowledgeTemplates = map[string]knowledgeTemplate{
+	"qdrant": {
+		Image:    "qdrant/qdrant:v1.12.4",
+		Port:     6333,
+		DataPath: "/qdrant/storage",
+	},
+	"pgvector": {
+		Image:    "pgvector/pgvector:pg16",
+		Port:     5432,
+		DataPath: "/var/lib/postgresql/data",
+		Env: []coreV1.EnvVar{
+			{Name: "POSTGRES_USER", Value: "kubemanage"},
+			{Name: "POSTGRES_DB", Value: "knowledge"},
+			// 存储卷根目录可能存在 lost+found，数据放在子目录中
+			{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
+		},
+		Password: true,
+	},
+}
+
+// applyKnowledgeTemplate 按知识库类型补全镜像、端口、数据目录及环境变量，用户指定的值优先
+func (k *knowledge) applyKnowledgeTemplate(data *kubeDto.KnowledgeDeployInput) error {
+	if data.KnowledgeType != "" {
+		backend, err := vectorstore.Lookup(data.KnowledgeType)
+		if err != nil {
+			return err
+		}
+		tpl, ok := knowledgeTemplates[backend.Name]
+		if !ok && (data.Image == "" || data.Port == 0) {
+			return fmt.Errorf("知识库类型 %s 没有内置部署模板，请指定镜像和端口", backend.Name)
+		}
+		if data.Image == "" {
+			data.Image = tpl.Image
+		}
+		if data.Port == 0 {
+			data.Port = tpl.Port
+		}
+		if data.DataPath == "" {
+			data.DataPath = tpl.DataPath
+		}
+		env := append([]coreV1.EnvVar{}, tpl.Env...)
+		if tpl.Password && !hasEnv(data.Env, "POSTGRES_PASSWORD") {
+			secretName, err := k.ensurePasswordSecret(data)
+			if err != nil {
+				return fmt.Errorf("创建数据库密码Secret失败: %v", err)
+			}
+			env = append(env, coreV1.EnvVar{
+				Name: "POSTGRES_PASSWORD",
+				ValueFrom: &coreV1.EnvVarSource{SecretKeyRef: &coreV1.SecretKeySelector{
+					LocalObjectReference: coreV1.LocalObjectReference{Name: secretName},
+					Key:                  "password",
+				}},
+			})
+		}
+		data.Env = mergeEnv(env, data.Env)
+	}
+	if data.DataPath == "" {
+		data.DataPath = knowledgeDataPath
+	}
+	return nil
+}
+
+// ensurePasswordSecret 创建保存数据库密码的 Secret，已存在时沿用原密码，重新部署后仍能访问原有数据
+func (k *knowledge) ensurePasswordSecret(data *kubeDto.KnowledgeDeployInput) (string, error) {
+	name := fmt.Sprintf("%s-pgvector", data.Name)
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	secret := &coreV1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      name,
+			Namespace: data.NameSpace,
+			Labels: map[string]string{
+				"app":     "knowledge",
+				"name":    data.Name,
+				"managed": "kubemanage",
+			},
+		},
+		Type:       coreV1.SecretTypeOpaque,
+		StringData: map[string]string{"password": hex.EncodeToString(buf)},
+	}
+	_, err := K8s.ClientSet.CoreV1().Secrets(data.NameSpace).Create(context.TODO(), secret, metaV1.CreateOptions{})
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return "", err
+	}
+	return name, nil
+}
+
+func hasEnv(envs []coreV1.EnvVar, name string) bool {
+	for _, env := range envs {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
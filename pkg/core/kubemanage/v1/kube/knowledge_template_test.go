@@ -0,0 +1,84 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+func TestDeployKnowledgeTemplate(t *testing.T) {
+	K8s.ClientSet = fake.NewSimpleClientset()
+	in := &kubeDto.KnowledgeDeployInput{
+		Name:          "kb-pg",
+		NameSpace:     "ai",
+		KnowledgeType: "postgres",
+		StorageSize:   "10Gi",
+		DeployType:    "deployment",
+		SchedulingOptions: kubeDto.SchedulingOptions{
+			Env: []coreV1.EnvVar{{Name: "POSTGRES_DB", Value: "handbook"}},
+		},
+	}
+	if err := Knowledge.DeployKnowledge(in); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	deploy, err := K8s.ClientSet.AppsV1().Deployments("ai").Get(context.TODO(), "kb-pg", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	container := deploy.Spec.Template.Spec.Containers[0]
+	if container.Image != "pgvector/pgvector:pg16" || container.Ports[0].ContainerPort != 5432 {
+		t.Errorf("template not applied: %s %d", container.Image, container.Ports[0].ContainerPort)
+	}
+	if container.VolumeMounts[0].MountPath != "/var/lib/postgresql/data" {
+		t.Errorf("mount path = %s", container.VolumeMounts[0].MountPath)
+	}
+	env := map[string]coreV1.EnvVar{}
+	for _, item := range container.Env {
+		env[item.Name] = item
+	}
+	if env["POSTGRES_DB"].Value != "handbook" || env["POSTGRES_USER"].Value != "kubemanage" {
+		t.Errorf("env not merged: %+v", container.Env)
+	}
+	ref := env["POSTGRES_PASSWORD"].ValueFrom
+	if ref == nil || ref.SecretKeyRef == nil || ref.SecretKeyRef.Name != "kb-pg-pgvector" {
+		t.Fatalf("password should come from secret: %+v", env["POSTGRES_PASSWORD"])
+	}
+	secret, err := K8s.ClientSet.CoreV1().Secrets("ai").Get(context.TODO(), "kb-pg-pgvector", metaV1.GetOptions{})
+	if err != nil || len(secret.StringData["password"]) != 32 {
+		t.Fatalf("secret: %+v %v", secret, err)
+	}
+
+	// 重新部署沿用已有的密码
+	K8s.ClientSet.AppsV1().Deployments("ai").Delete(context.TODO(), "kb-pg", metaV1.DeleteOptions{})
+	K8s.ClientSet.CoreV1().Services("ai").Delete(context.TODO(), "kb-pg-svc", metaV1.DeleteOptions{})
+	K8s.ClientSet.CoreV1().PersistentVolumeClaims("ai").Delete(context.TODO(), "kb-pg-pvc", metaV1.DeleteOptions{})
+	in.Image, in.Port, in.DataPath = "", 0, ""
+	if err := Knowledge.DeployKnowledge(in); err != nil {
+		t.Fatalf("redeploy: %v", err)
+	}
+	again, _ := K8s.ClientSet.CoreV1().Secrets("ai").Get(context.TODO(), "kb-pg-pgvector", metaV1.GetOptions{})
+	if again.StringData["password"] != secret.StringData["password"] {
+		t.Error("redeploy should keep the existing password")
+	}
+}
+
+func TestDeployKnowledgeWithoutTemplate(t *testing.T) {
+	K8s.ClientSet = fake.NewSimpleClientset()
+	in := &kubeDto.KnowledgeDeployInput{Name: "kb", NameSpace: "ai", KnowledgeType: "chromadb", DeployType: "deployment"}
+	if err := Knowledge.DeployKnowledge(in); err == nil {
+		t.Fatal("chromadb has no template, image and port are required")
+	}
+	in = &kubeDto.KnowledgeDeployInput{Name: "kb", NameSpace: "ai", Image: "chromadb/chroma:latest", Port: 8000, StorageSize: "1Gi", DeployType: "daemonset"}
+	if err := Knowledge.DeployKnowledge(in); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	ds, _ := K8s.ClientSet.AppsV1().DaemonSets("ai").Get(context.TODO(), "kb", metaV1.GetOptions{})
+	if path := ds.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath; path != "/data" {
+		t.Errorf("default mount path = %s", path)
+	}
+}
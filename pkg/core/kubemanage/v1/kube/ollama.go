@@ -1,9 +1,12 @@
 package kube
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 
 	appsV1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
@@ -33,6 +36,8 @@ type OllamaDeploymentInfo struct {
 	Status       string            `json:"status"`
 	Pods         int32             `json:"pods"`
 	ReadyPods    int32             `json:"ready_pods"`
+	// ModelDrift 模型目录与各副本实际模型的差异，未声明模型目录时为空
+	ModelDrift *kubeDto.OllamaModelDrift `json:"model_drift,omitempty"`
 }
 
 // DeployOllama 部署Ollama到指定节点
@@ -48,6 +53,10 @@ func (o *ollama) DeployOllama(data *kubeDto.OllamaDeployInput) error {
 		labels[k] = v
 	}
 
+	if err := validateScheduling(&data.SchedulingOptions); err != nil {
+		return err
+	}
+
 	// 创建 PVC（如果需要存储）
 	if data.StorageSize != "" {
 		if err := o.createPVC(data); err != nil {
@@ -189,6 +198,11 @@ func (o *ollama) createDeployment(data *kubeDto.OllamaDeployInput, labels map[st
 		TimeoutSeconds:      3,
 	}
 
+	// 设置 GPU、容忍、亲和性、优先级及额外环境变量
+	if err := applyScheduling(&deployment.Spec.Template.Spec, &data.SchedulingOptions); err != nil {
+		return err
+	}
+
 	_, err := K8s.ClientSet.AppsV1().Deployments(data.NameSpace).Create(context.TODO(), deployment, metaV1.CreateOptions{})
 	return err
 }
@@ -313,6 +327,11 @@ func (o *ollama) createDaemonSet(data *kubeDto.OllamaDeployInput, labels map[str
 		TimeoutSeconds:      3,
 	}
 
+	// 设置 GPU、容忍、亲和性、优先级及额外环境变量
+	if err := applyScheduling(&daemonSet.Spec.Template.Spec, &data.SchedulingOptions); err != nil {
+		return err
+	}
+
 	_, err := K8s.ClientSet.AppsV1().DaemonSets(data.NameSpace).Create(context.TODO(), daemonSet, metaV1.CreateOptions{})
 	return err
 }
@@ -614,8 +633,8 @@ func (o *ollama) PullModel(podName, namespace, modelName string) error {
 	return nil
 }
 
-// GetModelList 获取指定 Pod 的模型列表
-func (o *ollama) GetModelList(podName, namespace string) (interface{}, error) {
+// getModelList 获取指定 Pod 的模型列表
+func (o *ollama) getModelList(ctx context.Context, podName, namespace string) (interface{}, error) {
 	// 获取 Pod 信息以确定端口
 	pod, err := Pod.GetPodDetail(podName, namespace)
 	if err != nil {
@@ -648,7 +667,7 @@ func (o *ollama) GetModelList(podName, namespace string) (interface{}, error) {
 		Suffix("/api/tags")
 
 	// 发送请求
-	result := req.Do(context.TODO())
+	result := req.Do(ctx)
 	if result.Error() != nil {
 		return nil, fmt.Errorf("请求Ollama API失败: %v", result.Error())
 	}
@@ -668,8 +687,8 @@ func (o *ollama) GetModelList(podName, namespace string) (interface{}, error) {
 	return responseData, nil
 }
 
-// DeleteModel 删除指定 Pod 中的模型
-func (o *ollama) DeleteModel(podName, namespace, modelName string) error {
+// deleteModel 删除指定 Pod 中的模型
+func (o *ollama) deleteModel(ctx context.Context, podName, namespace, modelName string) error {
 	// 获取 Pod 信息以确定端口
 	pod, err := Pod.GetPodDetail(podName, namespace)
 	if err != nil {
@@ -714,7 +733,7 @@ func (o *ollama) DeleteModel(podName, namespace, modelName string) error {
 		SetHeader("Content-Type", "application/json")
 
 	// 发送请求
-	result := req.Do(context.TODO())
+	result := req.Do(ctx)
 	if result.Error() != nil {
 		return fmt.Errorf("请求Ollama API失败: %v", result.Error())
 	}
@@ -736,8 +755,8 @@ func (o *ollama) DeleteModel(podName, namespace, modelName string) error {
 	return nil
 }
 
-// GetModelDetail 获取指定 Pod 中模型的详情
-func (o *ollama) GetModelDetail(podName, namespace, modelName string) (interface{}, error) {
+// getModelDetail 获取指定 Pod 中模型的详情
+func (o *ollama) getModelDetail(ctx context.Context, podName, namespace, modelName string) (interface{}, error) {
 	// 获取 Pod 信息以确定端口
 	pod, err := Pod.GetPodDetail(podName, namespace)
 	if err != nil {
@@ -782,7 +801,7 @@ func (o *ollama) GetModelDetail(podName, namespace, modelName string) (interface
 		SetHeader("Content-Type", "application/json")
 
 	// 发送请求
-	result := req.Do(context.TODO())
+	result := req.Do(ctx)
 	if result.Error() != nil {
 		return nil, fmt.Errorf("请求Ollama API失败: %v", result.Error())
 	}
@@ -802,8 +821,8 @@ func (o *ollama) GetModelDetail(podName, namespace, modelName string) (interface
 	return responseData, nil
 }
 
-// Chat 调用指定 Pod 上的模型进行聊天
-func (o *ollama) Chat(podName, namespace, model string, messages []kubeDto.OllamaChatMessage, stream bool) (interface{}, error) {
+// chat 调用指定 Pod 上的模型进行聊天
+func (o *ollama) chat(ctx context.Context, podName, namespace, model string, messages []kubeDto.OllamaChatMessage, stream bool) (interface{}, error) {
 	// 获取 Pod 信息以确定端口
 	pod, err := Pod.GetPodDetail(podName, namespace)
 	if err != nil {
@@ -832,6 +851,7 @@ func (o *ollama) Chat(podName, namespace, model string, messages []kubeDto.Ollam
 		"messages": messages,
 		"stream":   stream,
 	}
+	applyInferenceOptions(ctx, requestBody)
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("序列化请求体失败: %v", err)
@@ -849,7 +869,7 @@ func (o *ollama) Chat(podName, namespace, model string, messages []kubeDto.Ollam
 		SetHeader("Content-Type", "application/json")
 
 	// 发送请求
-	result := req.Do(context.TODO())
+	result := req.Do(ctx)
 	if result.Error() != nil {
 		return nil, fmt.Errorf("请求Ollama API失败: %v", result.Error())
 	}
@@ -879,8 +899,8 @@ func (o *ollama) Chat(podName, namespace, model string, messages []kubeDto.Ollam
 	return responseData, nil
 }
 
-// Embeddings 调用指定 Pod 上的模型生成文本向量嵌入
-func (o *ollama) Embeddings(podName, namespace, model, prompt string) (interface{}, error) {
+// embeddings 调用指定 Pod 上的模型生成文本向量嵌入
+func (o *ollama) embeddings(ctx context.Context, podName, namespace, model, prompt string) (interface{}, error) {
 	// 获取 Pod 信息以确定端口
 	pod, err := Pod.GetPodDetail(podName, namespace)
 	if err != nil {
@@ -925,7 +945,7 @@ func (o *ollama) Embeddings(podName, namespace, model, prompt string) (interface
 		SetHeader("Content-Type", "application/json")
 
 	// 发送请求
-	result := req.Do(context.TODO())
+	result := req.Do(ctx)
 	if result.Error() != nil {
 		return nil, fmt.Errorf("请求Ollama API失败: %v", result.Error())
 	}
@@ -949,3 +969,121 @@ func (o *ollama) Embeddings(podName, namespace, model, prompt string) (interface
 
 	return responseData, nil
 }
+
+// chatStream 以流式方式调用指定 Pod 上的模型进行聊天，每收到一个分片回调一次 onChunk
+// ctx 被取消（例如客户端断开连接）时会同时中断到 Ollama 的上游请求
+func (o *ollama) chatStream(ctx context.Context, podName, namespace, model string, messages []kubeDto.OllamaChatMessage, onChunk func(chunk map[string]interface{}) error) error {
+	port, err := o.getPodPort(podName, namespace)
+	if err != nil {
+		return err
+	}
+
+	// 准备请求体
+	requestBody := map[string]interface{}{
+		"model":    model,
+		"messages": messages,
+		"stream":   true,
+	}
+	applyInferenceOptions(ctx, requestBody)
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	// 使用 Kubernetes API Server 代理访问 Pod，通过 Stream 逐块读取响应而不是整体缓冲
+	body, err := K8s.ClientSet.CoreV1().RESTClient().Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%d", podName, port)).
+		SubResource("proxy").
+		Suffix("/api/chat").
+		Body(jsonData).
+		SetHeader("Content-Type", "application/json").
+		Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("请求Ollama API失败: %v", err)
+	}
+	defer body.Close()
+
+	return readNDJSON(body, onChunk)
+}
+
+// getPodPort 检查 Pod 状态并获取 Ollama 服务端口
+func (o *ollama) getPodPort(podName, namespace string) (int32, error) {
+	pod, err := Pod.GetPodDetail(podName, namespace)
+	if err != nil {
+		return 0, fmt.Errorf("获取Pod信息失败: %v", err)
+	}
+
+	if pod.Status.Phase != coreV1.PodRunning {
+		return 0, fmt.Errorf("pod %s 状态为 %s，请等待Pod启动完成", podName, pod.Status.Phase)
+	}
+
+	var port int32 = 11434
+	if len(pod.Spec.Containers) > 0 {
+		for _, containerPort := range pod.Spec.Containers[0].Ports {
+			if containerPort.Name == "http" || containerPort.ContainerPort == 11434 {
+				port = containerPort.ContainerPort
+				break
+			}
+		}
+	}
+	return port, nil
+}
+
+// readNDJSON 逐行解析 Ollama 返回的 NDJSON 流，遇到 error 字段时立即返回错误
+func readNDJSON(r io.Reader, fn func(chunk map[string]interface{}) error) error {
+	scanner := bufio.NewScanner(r)
+	// 单行可能包含较长的回答内容，放宽默认 64KB 的限制
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk map[string]interface{}
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %v, line: %s", err, string(line))
+		}
+		if errMsg, ok := chunk["error"].(string); ok && errMsg != "" {
+			return fmt.Errorf("ollama API返回错误: %s", errMsg)
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// PullModelStream 以流式方式拉取模型，每收到一条进度信息回调一次 onProgress
+// ctx 被取消时会中断下载请求
+func (o *ollama) PullModelStream(ctx context.Context, podName, namespace, modelName string, onProgress func(progress map[string]interface{}) error) error {
+	port, err := o.getPodPort(podName, namespace)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"name":   modelName,
+		"stream": true,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	body, err := K8s.ClientSet.CoreV1().RESTClient().Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%d", podName, port)).
+		SubResource("proxy").
+		Suffix("/api/pull").
+		Body(jsonData).
+		SetHeader("Content-Type", "application/json").
+		Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("请求Ollama API失败: %v", err)
+	}
+	defer body.Close()
+
+	return readNDJSON(body, onProgress)
+}
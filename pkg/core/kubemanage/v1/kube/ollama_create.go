@@ -0,0 +1,327 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+const (
+	// ollamaContainer kubemanage 部署的 Ollama 容器名称
+	ollamaContainer = "ollama"
+	// ollamaBlobDir Ollama 容器中模型文件的存放目录，与 /api/create 引用的 sha256 摘要对应
+	ollamaBlobDir = "/root/.ollama/models/blobs"
+)
+
+// ParseModelfile 将 Modelfile 解析为 /api/create 的请求参数
+// 支持 FROM、SYSTEM、TEMPLATE、LICENSE、PARAMETER、ADAPTER、MESSAGE 指令，多行内容使用 """ 包裹
+// FROM 及 ADAPTER 可以是模型名称或上传接口返回的 sha256 摘要，不支持引用服务器上的本地文件
+func ParseModelfile(content string) (map[string]interface{}, error) {
+	req := map[string]interface{}{}
+	parameters := map[string]interface{}{}
+	var messages []map[string]string
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		command := strings.ToUpper(fields[0])
+		var args string
+		if len(fields) > 1 {
+			args = strings.TrimSpace(fields[1])
+		}
+
+		// 多行内容
+		if strings.HasSuffix(args, `"""`) && len(args) >= 6 && strings.HasPrefix(args, `"""`) {
+			args = args[3 : len(args)-3]
+		} else if strings.HasPrefix(args, `"""`) {
+			var b strings.Builder
+			b.WriteString(strings.TrimPrefix(args, `"""`))
+			closed := false
+			for i++; i < len(lines); i++ {
+				if idx := strings.Index(lines[i], `"""`); idx >= 0 {
+					b.WriteString("\n" + lines[i][:idx])
+					closed = true
+					break
+				}
+				b.WriteString("\n" + lines[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("Modelfile 中 %s 的多行内容缺少结束的 \"\"\"", command)
+			}
+			args = strings.TrimPrefix(b.String(), "\n")
+		} else if unquoted, err := strconv.Unquote(args); err == nil && strings.HasPrefix(args, `"`) {
+			args = unquoted
+		}
+
+		switch command {
+		case "FROM":
+			if strings.HasPrefix(args, ".") || strings.HasPrefix(args, "/") || strings.HasPrefix(args, "~") {
+				return nil, fmt.Errorf("FROM 不支持本地文件 %s，请先通过上传接口上传模型文件", args)
+			}
+			req["from"] = args
+		case "SYSTEM":
+			req["system"] = args
+		case "TEMPLATE":
+			req["template"] = args
+		case "LICENSE":
+			req["license"] = args
+		case "ADAPTER":
+			if !strings.HasPrefix(args, "sha256:") {
+				return nil, fmt.Errorf("ADAPTER 仅支持上传接口返回的 sha256 摘要，当前为 %s", args)
+			}
+			req["adapters"] = map[string]string{"adapter.gguf": args}
+		case "PARAMETER":
+			kv := strings.SplitN(args, " ", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("PARAMETER 格式错误: %s", args)
+			}
+			name, value := strings.ToLower(kv[0]), strings.TrimSpace(kv[1])
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			if name == "stop" {
+				stops, _ := parameters[name].([]string)
+				parameters[name] = append(stops, value)
+				continue
+			}
+			parameters[name] = parseParameterValue(value)
+		case "MESSAGE":
+			kv := strings.SplitN(args, " ", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("MESSAGE 格式错误: %s", args)
+			}
+			messages = append(messages, map[string]string{"role": kv[0], "content": kv[1]})
+		default:
+			return nil, fmt.Errorf("不支持的 Modelfile 指令: %s", fields[0])
+		}
+	}
+	if len(parameters) > 0 {
+		req["parameters"] = parameters
+	}
+	if len(messages) > 0 {
+		req["messages"] = messages
+	}
+	return req, nil
+}
+
+// parseParameterValue 按整数、浮点数、布尔值、字符串的顺序解析参数值
+func parseParameterValue(value string) interface{} {
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
+
+// CreateModel 通过 /api/create 创建自定义模型，指定部署时在每个就绪副本上创建
+// req 为 /api/create 的请求参数，模型名称由 modelName 指定
+func (o *ollama) CreateModel(ctx context.Context, target kubeDto.OllamaTarget, modelName string, req map[string]interface{}) error {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{}
+	for k, v := range req {
+		body[k] = v
+	}
+	body["model"] = modelName
+	body["stream"] = true
+	if _, ok := body["from"]; !ok && body["files"] == nil {
+		return fmt.Errorf("创建模型需要指定 FROM 或上传的模型文件")
+	}
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %v", err)
+	}
+	for _, pod := range pods {
+		if err := o.postStream(ctx, pod, target.NameSpace, "/api/create", jsonData); err != nil {
+			return fmt.Errorf("pod %s: %v", pod, err)
+		}
+	}
+	ollamaModels.invalidate()
+	return nil
+}
+
+// CopyModel 通过 /api/copy 复制模型，指定部署时在每个就绪副本上复制
+func (o *ollama) CopyModel(ctx context.Context, target kubeDto.OllamaTarget, source, destination string) error {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(map[string]string{"source": source, "destination": destination})
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %v", err)
+	}
+	for _, pod := range pods {
+		port, err := o.getPodPort(pod, target.NameSpace)
+		if err != nil {
+			return fmt.Errorf("pod %s: %v", pod, err)
+		}
+		result := K8s.ClientSet.CoreV1().RESTClient().Post().
+			Namespace(target.NameSpace).
+			Resource("pods").
+			Name(fmt.Sprintf("%s:%d", pod, port)).
+			SubResource("proxy").
+			Suffix("/api/copy").
+			Body(jsonData).
+			SetHeader("Content-Type", "application/json").
+			Do(ctx)
+		if result.Error() != nil {
+			return fmt.Errorf("pod %s: 请求Ollama API失败: %v", pod, result.Error())
+		}
+	}
+	ollamaModels.invalidate()
+	return nil
+}
+
+// UploadBlob 通过 exec 将模型文件写入每个就绪副本的模型目录，返回文件的 sha256 摘要
+// open 每次调用返回文件内容的新 Reader，写入完成后才重命名为 sha256-<digest>，避免 Ollama 读到不完整的文件
+func (o *ollama) UploadBlob(ctx context.Context, target kubeDto.OllamaTarget, open func() (io.ReadCloser, error)) (string, error) {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	var digest string
+	for _, pod := range pods {
+		podDigest, err := o.uploadBlob(ctx, pod, target.NameSpace, open)
+		if err != nil {
+			return "", fmt.Errorf("pod %s: %v", pod, err)
+		}
+		if digest != "" && digest != podDigest {
+			return "", fmt.Errorf("pod %s: 文件摘要不一致，请重新上传", pod)
+		}
+		digest = podDigest
+	}
+	return digest, nil
+}
+
+func (o *ollama) uploadBlob(ctx context.Context, podName, namespace string, open func() (io.ReadCloser, error)) (string, error) {
+	src, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	tmp := path.Join(ollamaBlobDir, ".upload-"+utils.GetSnowflakeID())
+	hash := sha256.New()
+	stdin := io.TeeReader(bufio.NewReaderSize(src, 1<<20), hash)
+	// 请求取消后仍需清理临时文件
+	cleanup := func() {
+		_, _ = ExecInPod(context.WithoutCancel(ctx), podName, namespace, ollamaContainer, []string{"rm", "-f", tmp}, nil)
+	}
+	if _, err := ExecInPod(ctx, podName, namespace, ollamaContainer, []string{"sh", "-c", fmt.Sprintf("mkdir -p %s && cat > %s", ollamaBlobDir, tmp)}, stdin); err != nil {
+		cleanup()
+		return "", fmt.Errorf("上传模型文件失败: %v", err)
+	}
+
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	blob := path.Join(ollamaBlobDir, strings.Replace(digest, ":", "-", 1))
+	if _, err := ExecInPod(ctx, podName, namespace, ollamaContainer, []string{"mv", "-f", tmp, blob}, nil); err != nil {
+		cleanup()
+		return "", fmt.Errorf("保存模型文件失败: %v", err)
+	}
+	return digest, nil
+}
+
+// postStream 以流式方式调用 Ollama API 并等待完成，遇到 error 字段时返回错误
+func (o *ollama) postStream(ctx context.Context, podName, namespace, suffix string, jsonData []byte) error {
+	var status string
+	if err := o.proxyStream(ctx, podName, namespace, suffix, jsonData, func(chunk map[string]interface{}) error {
+		status, _ = chunk["status"].(string)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if status != "success" {
+		return fmt.Errorf("未完成，最后状态: %s", status)
+	}
+	return nil
+}
+
+// CreateModelFromInput 合并 Modelfile 与结构化参数后创建模型
+func (o *ollama) CreateModelFromInput(ctx context.Context, in *kubeDto.OllamaCreateModelInput) error {
+	req, err := createModelRequest(in)
+	if err != nil {
+		return err
+	}
+	return o.CreateModel(ctx, in.OllamaTarget, in.ModelName, req)
+}
+
+// createModelRequest 合并 Modelfile 与结构化参数，结构化参数优先
+// FROM 为上传接口返回的 sha256 摘要时转换为 files 引用
+func createModelRequest(in *kubeDto.OllamaCreateModelInput) (map[string]interface{}, error) {
+	req, err := ParseModelfile(in.Modelfile)
+	if err != nil {
+		return nil, err
+	}
+	if in.From != "" {
+		req["from"] = in.From
+	}
+	if from, _ := req["from"].(string); strings.HasPrefix(from, "sha256:") {
+		req["files"] = map[string]string{"model.gguf": from}
+		delete(req, "from")
+	}
+	if in.System != "" {
+		req["system"] = in.System
+	}
+	if in.Template != "" {
+		req["template"] = in.Template
+	}
+	if in.Quantize != "" {
+		req["quantize"] = in.Quantize
+	}
+	if len(in.Parameters) > 0 {
+		parameters, _ := req["parameters"].(map[string]interface{})
+		if parameters == nil {
+			parameters = map[string]interface{}{}
+		}
+		for k, v := range in.Parameters {
+			parameters[k] = v
+		}
+		req["parameters"] = parameters
+	}
+	return req, nil
+}
+
+// UploadModel 上传 GGUF 文件到目标的每个就绪副本，指定模型名称时使用该文件注册模型
+func (o *ollama) UploadModel(ctx context.Context, in *kubeDto.OllamaUploadModelInput, fileName string, open func() (io.ReadCloser, error)) (map[string]interface{}, error) {
+	// 先解析 Modelfile，避免上传大文件后才发现格式错误
+	req, err := ParseModelfile(in.Modelfile)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := o.UploadBlob(ctx, in.OllamaTarget(), open)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{"digest": digest, "file_name": fileName}
+	if in.ModelName == "" {
+		return result, nil
+	}
+	delete(req, "from")
+	req["files"] = map[string]string{path.Base(fileName): digest}
+	if err := o.CreateModel(ctx, in.OllamaTarget(), in.ModelName, req); err != nil {
+		return nil, fmt.Errorf("文件已上传（%s），注册模型失败: %v", digest, err)
+	}
+	result["model"] = in.ModelName
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+func TestParseModelfile(t *testing.T) {
+	req, err := ParseModelfile(`# internal fine-tune
+FROM sha256:abc
+PARAMETER temperature 0.2
+PARAMETER num_ctx 8192
+PARAMETER stop "<|end|>"
+PARAMETER stop "</s>"
+SYSTEM """
+You are an SRE assistant.
+Answer briefly."""
+MESSAGE user ping
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req["from"] != "sha256:abc" {
+		t.Errorf("from = %v", req["from"])
+	}
+	if req["system"] != "You are an SRE assistant.\nAnswer briefly." {
+		t.Errorf("system = %q", req["system"])
+	}
+	want := map[string]interface{}{
+		"temperature": 0.2,
+		"num_ctx":     int64(8192),
+		"stop":        []string{"<|end|>", "</s>"},
+	}
+	if !reflect.DeepEqual(req["parameters"], want) {
+		t.Errorf("parameters = %#v", req["parameters"])
+	}
+	if msgs, _ := req["messages"].([]map[string]string); len(msgs) != 1 || msgs[0]["content"] != "ping" {
+		t.Errorf("messages = %#v", req["messages"])
+	}
+
+	for _, bad := range []string{"FROM ./model.gguf", "SYSTEM \"\"\"unterminated", "ADAPTER ./lora.gguf", "UNKNOWN x"} {
+		if _, err := ParseModelfile(bad); err == nil {
+			t.Errorf("ParseModelfile(%q) expected error", bad)
+		}
+	}
+}
+
+func TestCreateModelRequest(t *testing.T) {
+	cases := map[string]struct {
+		in        kubeDto.OllamaCreateModelInput
+		wantFrom  interface{}
+		wantFiles interface{}
+	}{
+		"modelfile digest": {
+			in:        kubeDto.OllamaCreateModelInput{Modelfile: "FROM sha256:abc\nSYSTEM hi"},
+			wantFiles: map[string]string{"model.gguf": "sha256:abc"},
+		},
+		"input digest": {
+			in:        kubeDto.OllamaCreateModelInput{Modelfile: "FROM qwen3", From: "sha256:def"},
+			wantFiles: map[string]string{"model.gguf": "sha256:def"},
+		},
+		"input model overrides modelfile digest": {
+			in:       kubeDto.OllamaCreateModelInput{Modelfile: "FROM sha256:abc", From: "qwen3"},
+			wantFrom: "qwen3",
+		},
+	}
+	for name, c := range cases {
+		req, err := createModelRequest(&c.in)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if req["from"] != c.wantFrom || !reflect.DeepEqual(req["files"], c.wantFiles) {
+			t.Errorf("%s: from = %v, files = %v", name, req["from"], req["files"])
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+type inferenceOptionsKey struct{}
+
+// SchemaViolationError 模型输出不符合请求的 JSON Schema，Output 为最后一次输出
+type SchemaViolationError struct {
+	Output   string
+	Attempts int
+	Err      error
+}
+
+func (e *SchemaViolationError) Error() string {
+	return fmt.Sprintf("模型输出不符合 JSON Schema（共尝试 %d 次）: %v", e.Attempts, e.Err)
+}
+
+func (e *SchemaViolationError) Unwrap() error {
+	return e.Err
+}
+
+// WithInferenceOptions 将输出格式与模型参数附加到 context，后续的聊天与补全请求都会透传给 Ollama
+func WithInferenceOptions(ctx context.Context, opts kubeDto.OllamaInferenceOptions) context.Context {
+	return context.WithValue(ctx, inferenceOptionsKey{}, opts)
+}
+
+func inferenceOptions(ctx context.Context) kubeDto.OllamaInferenceOptions {
+	opts, _ := ctx.Value(inferenceOptionsKey{}).(kubeDto.OllamaInferenceOptions)
+	return opts
+}
+
+// applyInferenceOptions 将 context 中的 format、options 与 tools 写入 Ollama 请求体
+func applyInferenceOptions(ctx context.Context, requestBody map[string]interface{}) {
+	opts := inferenceOptions(ctx)
+	if len(opts.Format) > 0 && !bytes.Equal(opts.Format, []byte("null")) {
+		requestBody["format"] = opts.Format
+	}
+	if len(opts.Options) > 0 {
+		requestBody["options"] = opts.Options
+	}
+	if len(opts.Tools) > 0 {
+		requestBody["tools"] = opts.Tools
+	}
+}
+
+// CompileFormat 校验 format 参数，为 JSON Schema 时返回编译后的 Schema，为空或 "json" 时返回 nil
+func CompileFormat(format json.RawMessage) (*jsonschema.Resolved, error) {
+	format = bytes.TrimSpace(format)
+	if len(format) == 0 || bytes.Equal(format, []byte("null")) {
+		return nil, nil
+	}
+	var name string
+	if err := json.Unmarshal(format, &name); err == nil {
+		if name != "json" {
+			return nil, fmt.Errorf("format 只支持 \"json\" 或 JSON Schema 对象")
+		}
+		return nil, nil
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(format, &schema); err != nil {
+		return nil, fmt.Errorf("format 不是合法的 JSON Schema: %v", err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("format 不是合法的 JSON Schema: %v", err)
+	}
+	return resolved, nil
+}
+
+// validateOutput 校验模型输出是否为符合 Schema 的 JSON
+func validateOutput(schema *jsonschema.Resolved, output string) error {
+	var instance interface{}
+	if err := json.Unmarshal([]byte(output), &instance); err != nil {
+		return fmt.Errorf("输出不是合法的 JSON: %v", err)
+	}
+	return schema.Validate(instance)
+}
+
+// withSchemaRetry 执行 call 并校验输出，不符合 Schema 时将错误反馈给 call 重试
+// call 的 violation 参数为上一次的输出及校验错误，首次调用时为 nil
+func withSchemaRetry(ctx context.Context, call func(violation *SchemaViolationError) (output string, result interface{}, err error)) (interface{}, error) {
+	opts := inferenceOptions(ctx)
+	schema, err := CompileFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	var violation *SchemaViolationError
+	for attempt := 1; ; attempt++ {
+		output, result, err := call(violation)
+		if err != nil || schema == nil {
+			return result, err
+		}
+		verr := validateOutput(schema, output)
+		if verr == nil {
+			return result, nil
+		}
+		violation = &SchemaViolationError{Output: output, Attempts: attempt, Err: verr}
+		if attempt > opts.SchemaRetries {
+			return nil, violation
+		}
+	}
+}
+
+// schemaFeedback 重试时提示模型修正输出
+func schemaFeedback(violation *SchemaViolationError) string {
+	return fmt.Sprintf("上一次的回答不符合要求的 JSON Schema: %v。请修正后只返回符合 Schema 的 JSON，不要包含其他内容。", violation.Err)
+}
+
+// chatContent 提取 Ollama chat 响应中的文本内容
+func chatContent(result interface{}) string {
+	resp, _ := result.(map[string]interface{})
+	if msg, ok := resp["message"].(map[string]interface{}); ok {
+		content, _ := msg["content"].(string)
+		return content
+	}
+	return ""
+}
+
+// ChatMessage 将 Ollama chat 响应中的 message 转换为聊天消息，包括模型返回的工具调用
+func ChatMessage(result interface{}) (kubeDto.OllamaChatMessage, error) {
+	var msg kubeDto.OllamaChatMessage
+	resp, _ := result.(map[string]interface{})
+	raw, ok := resp["message"]
+	if !ok {
+		return msg, fmt.Errorf("响应中没有 message 字段")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return msg, fmt.Errorf("解析模型消息失败: %v", err)
+	}
+	return msg, nil
+}
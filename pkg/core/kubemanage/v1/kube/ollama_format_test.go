@@ -0,0 +1,71 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+const triageSchema = `{
+	"type": "object",
+	"properties": {
+		"priority": {"type": "string", "enum": ["P0", "P1", "P2"]},
+		"team": {"type": "string"}
+	},
+	"required": ["priority", "team"]
+}`
+
+func TestCompileFormat(t *testing.T) {
+	for _, format := range []string{``, `null`, `"json"`} {
+		schema, err := CompileFormat(json.RawMessage(format))
+		if err != nil || schema != nil {
+			t.Errorf("CompileFormat(%q) = %v, %v", format, schema, err)
+		}
+	}
+	if _, err := CompileFormat(json.RawMessage(`"yaml"`)); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if _, err := CompileFormat(json.RawMessage(`{"type": 1}`)); err == nil {
+		t.Error("expected error for invalid schema")
+	}
+	if _, err := CompileFormat(json.RawMessage(triageSchema)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithSchemaRetry(t *testing.T) {
+	ctx := WithInferenceOptions(context.Background(), kubeDto.OllamaInferenceOptions{
+		Format:        json.RawMessage(triageSchema),
+		SchemaRetries: 2,
+	})
+	outputs := []string{`not json`, `{"priority": "P9", "team": "sre"}`, `{"priority": "P1", "team": "sre"}`}
+	var calls int
+	result, err := withSchemaRetry(ctx, func(violation *SchemaViolationError) (string, interface{}, error) {
+		if (calls == 0) != (violation == nil) {
+			t.Errorf("call %d: violation = %v", calls, violation)
+		}
+		output := outputs[calls]
+		calls++
+		return output, output, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || result != outputs[2] {
+		t.Errorf("calls = %d, result = %v", calls, result)
+	}
+
+	calls = 0
+	ctx = WithInferenceOptions(context.Background(), kubeDto.OllamaInferenceOptions{Format: json.RawMessage(triageSchema)})
+	_, err = withSchemaRetry(ctx, func(*SchemaViolationError) (string, interface{}, error) {
+		calls++
+		return `{"team": "sre"}`, nil, nil
+	})
+	var violation *SchemaViolationError
+	if !errors.As(err, &violation) || calls != 1 || violation.Attempts != 1 {
+		t.Errorf("calls = %d, err = %v", calls, err)
+	}
+}
@@ -0,0 +1,159 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// GenerateRequest Ollama /api/generate 请求参数，format 与 options 通过 WithInferenceOptions 传递
+type GenerateRequest struct {
+	Prompt string
+	System string
+	Raw    bool
+}
+
+// Generate 调用目标 Ollama 上的模型进行文本补全，model 为虚拟模型时按路由策略选择目标
+// context 中指定了 JSON Schema 输出格式时校验模型输出，不符合时在提示词后附加错误信息重试，输入与输出都会经过护栏检查
+func (o *ollama) Generate(ctx context.Context, target kubeDto.OllamaTarget, model string, req GenerateRequest) (interface{}, error) {
+	req, err := guardGenerate(ctx, model, req)
+	if err != nil {
+		return nil, err
+	}
+	prompt := req.Prompt
+	result, err := withSchemaRetry(ctx, func(violation *SchemaViolationError) (string, interface{}, error) {
+		attempt := req
+		if violation != nil {
+			attempt.Prompt = fmt.Sprintf("%s\n\n%s", prompt, schemaFeedback(violation))
+		}
+		var result map[string]interface{}
+		call := func(ctx context.Context, target kubeDto.OllamaTarget, model string) (err error) {
+			result, err = o.generateTarget(ctx, target, model, attempt)
+			return err
+		}
+		var err error
+		if route := o.LookupRoute(ctx, model); route != nil {
+			err = o.route(ctx, route, func(ctx context.Context, t RouteTarget, _ func()) error {
+				return call(ctx, t.OllamaTarget, t.Model)
+			})
+		} else {
+			err = call(ctx, target, model)
+		}
+		response, _ := result["response"].(string)
+		return response, result, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, guardOutput(ctx, model, result)
+}
+
+// GenerateStream 以流式方式调用目标 Ollama 上的模型进行文本补全，流式输出不做 Schema 校验
+func (o *ollama) GenerateStream(ctx context.Context, target kubeDto.OllamaTarget, model string, req GenerateRequest, onChunk func(chunk map[string]interface{}) error) error {
+	req, err := guardGenerate(ctx, model, req)
+	if err != nil {
+		return err
+	}
+	onChunk = guardStream(ctx, model, onChunk)
+	route := o.LookupRoute(ctx, model)
+	if route == nil {
+		return o.generateStreamTarget(ctx, target, model, req, onChunk)
+	}
+	var sent bool
+	var streamErr error
+	err = o.route(ctx, route, func(ctx context.Context, t RouteTarget, firstChunk func()) error {
+		err := o.generateStreamTarget(ctx, t.OllamaTarget, t.Model, req, func(chunk map[string]interface{}) error {
+			if !sent {
+				sent = true
+				firstChunk()
+			}
+			return onChunk(chunk)
+		})
+		if err != nil && sent {
+			streamErr = err
+			return nil
+		}
+		return err
+	})
+	if streamErr != nil {
+		return streamErr
+	}
+	return err
+}
+
+func (o *ollama) generateTarget(ctx context.Context, target kubeDto.OllamaTarget, model string, req GenerateRequest) (result map[string]interface{}, err error) {
+	jsonData, err := generateBody(ctx, model, req, false)
+	if err != nil {
+		return nil, err
+	}
+	err = o.tryPods(ctx, target, func(podName string) error {
+		start := time.Now()
+		result, err = o.proxyJSON(ctx, podName, target.NameSpace, "POST", "/api/generate", jsonData)
+		if err == nil {
+			recordUsage(ctx, UsageKindGenerate, target, podName, model, result, start)
+		}
+		return err
+	})
+	return result, err
+}
+
+func (o *ollama) generateStreamTarget(ctx context.Context, target kubeDto.OllamaTarget, model string, req GenerateRequest, onChunk func(chunk map[string]interface{}) error) error {
+	jsonData, err := generateBody(ctx, model, req, true)
+	if err != nil {
+		return err
+	}
+	var sent bool
+	var streamErr error
+	err = o.tryPods(ctx, target, func(podName string) error {
+		start := time.Now()
+		err := o.proxyStream(ctx, podName, target.NameSpace, "/api/generate", jsonData, func(chunk map[string]interface{}) error {
+			sent = true
+			if done, _ := chunk["done"].(bool); done {
+				recordUsage(ctx, UsageKindGenerate, target, podName, model, chunk, start)
+			}
+			return onChunk(chunk)
+		})
+		if err != nil && sent {
+			streamErr = err
+			return nil
+		}
+		return err
+	})
+	if streamErr != nil {
+		return streamErr
+	}
+	return err
+}
+
+// guardGenerate 检查提示词及系统提示词
+func guardGenerate(ctx context.Context, model string, req GenerateRequest) (GenerateRequest, error) {
+	var err error
+	if req.Prompt, err = checkGuardrail(ctx, model, GuardrailStageInput, req.Prompt); err != nil {
+		return req, err
+	}
+	req.System, err = checkGuardrail(ctx, model, GuardrailStageInput, req.System)
+	return req, err
+}
+
+func generateBody(ctx context.Context, model string, req GenerateRequest, stream bool) ([]byte, error) {
+	requestBody := map[string]interface{}{
+		"model":  model,
+		"prompt": req.Prompt,
+		"stream": stream,
+	}
+	if req.System != "" {
+		requestBody["system"] = req.System
+	}
+	if req.Raw {
+		requestBody["raw"] = true
+	}
+	applyInferenceOptions(ctx, requestBody)
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+	return jsonData, nil
+}
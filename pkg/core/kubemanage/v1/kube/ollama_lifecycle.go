@@ -0,0 +1,141 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	ollamaTypeDeployment = "deployment"
+	ollamaTypeDaemonSet  = "daemonset"
+)
+
+// DeleteOllama 删除 Ollama 部署及其 Service，keepPVC 为 false 时同时删除模型存储 PVC
+func (o *ollama) DeleteOllama(name, namespace string, keepPVC bool) error {
+	kind, err := o.workloadType(name, namespace)
+	if err != nil {
+		return err
+	}
+
+	propagation := metaV1.DeletePropagationBackground
+	opts := metaV1.DeleteOptions{PropagationPolicy: &propagation}
+	if kind == ollamaTypeDaemonSet {
+		err = K8s.ClientSet.AppsV1().DaemonSets(namespace).Delete(context.TODO(), name, opts)
+	} else {
+		err = K8s.ClientSet.AppsV1().Deployments(namespace).Delete(context.TODO(), name, opts)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("删除%s失败: %v", kind, err)
+	}
+
+	if err := K8s.ClientSet.CoreV1().Services(namespace).Delete(context.TODO(), fmt.Sprintf("%s-svc", name), metaV1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("删除Service失败: %v", err)
+	}
+
+	if !keepPVC {
+		if err := K8s.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), fmt.Sprintf("%s-pvc", name), metaV1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("删除PVC失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// ScaleOllama 调整 Ollama Deployment 副本数，DaemonSet 由节点数决定不支持扩缩容
+func (o *ollama) ScaleOllama(name, namespace string, replicas int32) (int32, error) {
+	kind, err := o.workloadType(name, namespace)
+	if err != nil {
+		return 0, err
+	}
+	if kind == ollamaTypeDaemonSet {
+		return 0, fmt.Errorf("%s 为 DaemonSet，不支持扩缩容", name)
+	}
+
+	scale, err := K8s.ClientSet.AppsV1().Deployments(namespace).GetScale(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	scale.Spec.Replicas = replicas
+	newScale, err := K8s.ClientSet.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), name, scale, metaV1.UpdateOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return newScale.Spec.Replicas, nil
+}
+
+// UpgradeOllama 滚动升级 Ollama 镜像
+func (o *ollama) UpgradeOllama(name, namespace, image string) error {
+	return o.patchTemplate(name, namespace, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []map[string]interface{}{
+				{"name": "ollama", "image": image},
+			},
+		},
+	})
+}
+
+// RestartOllama 通过修改 Pod 模板注解触发滚动重启，与 kubectl rollout restart 行为一致
+func (o *ollama) RestartOllama(name, namespace string) error {
+	return o.patchTemplate(name, namespace, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				"kubectl.kubernetes.io/restartedAt": time.Now().Format(time.RFC3339),
+			},
+		},
+	})
+}
+
+// patchTemplate 对 Deployment 或 DaemonSet 的 Pod 模板打补丁
+func (o *ollama) patchTemplate(name, namespace string, template map[string]interface{}) error {
+	kind, err := o.workloadType(name, namespace)
+	if err != nil {
+		return err
+	}
+	patchByte, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"template": template},
+	})
+	if err != nil {
+		return err
+	}
+	if kind == ollamaTypeDaemonSet {
+		_, err = K8s.ClientSet.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{})
+	} else {
+		_, err = K8s.ClientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{})
+	}
+	return err
+}
+
+// workloadType 判断 Ollama 部署是 Deployment 还是 DaemonSet，只处理 kubemanage 创建的资源
+func (o *ollama) workloadType(name, namespace string) (string, error) {
+	deploy, err := K8s.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err == nil {
+		if !isManagedOllama(deploy.Labels) {
+			return "", fmt.Errorf("%s 不是由 kubemanage 管理的 Ollama 部署", name)
+		}
+		return ollamaTypeDeployment, nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", err
+	}
+
+	ds, err := K8s.ClientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("ollama 部署 %s/%s 不存在", namespace, name)
+		}
+		return "", err
+	}
+	if !isManagedOllama(ds.Labels) {
+		return "", fmt.Errorf("%s 不是由 kubemanage 管理的 Ollama 部署", name)
+	}
+	return ollamaTypeDaemonSet, nil
+}
+
+func isManagedOllama(labels map[string]string) bool {
+	return labels["app"] == "ollama" && labels["managed"] == "kubemanage"
+}
@@ -0,0 +1,235 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// ollamaManagedSelector 由 kubemanage 部署的 Ollama Pod 标签
+const ollamaManagedSelector = "app=ollama,managed=kubemanage"
+
+// modelCacheTTL 模型列表缓存时间，避免每次请求都遍历所有 Pod
+const modelCacheTTL = 30 * time.Second
+
+// ErrModelNotFound 没有任何就绪的 Ollama Pod 提供该模型
+var ErrModelNotFound = errors.New("model not found")
+
+// ModelEndpoint 提供某个模型的 Ollama Pod
+type ModelEndpoint struct {
+	Model     string `json:"model"`
+	PodName   string `json:"pod_name"`
+	Namespace string `json:"namespace"`
+}
+
+type modelCache struct {
+	mu        sync.Mutex
+	endpoints []ModelEndpoint
+	expireAt  time.Time
+	next      uint64
+	// generation 每次 invalidate 加一，刷新期间缓存失效时不写入刷新结果
+	generation uint64
+	// refresh 合并并发的刷新请求，刷新期间不持有 mu
+	refresh singleflight.Group
+}
+
+var ollamaModels modelCache
+
+// invalidate 模型发生变化后使缓存失效，下次请求时重新获取
+func (c *modelCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireAt = time.Time{}
+	c.generation++
+}
+
+// ListManagedModels 列出所有 managed=kubemanage 的就绪 Ollama Pod 上已拉取的模型
+func (o *ollama) ListManagedModels(ctx context.Context) ([]ModelEndpoint, error) {
+	ollamaModels.mu.Lock()
+	if time.Now().Before(ollamaModels.expireAt) {
+		defer ollamaModels.mu.Unlock()
+		return ollamaModels.endpoints, nil
+	}
+	ollamaModels.mu.Unlock()
+
+	// 列出 Pod 及逐个请求 /api/tags 耗时较长，不持有锁，避免阻塞 invalidate 及其他读取
+	// 刷新结果由多个请求共享，不随发起刷新的请求取消
+	v, err, _ := ollamaModels.refresh.Do("", func() (interface{}, error) {
+		ollamaModels.mu.Lock()
+		generation := ollamaModels.generation
+		ollamaModels.mu.Unlock()
+
+		endpoints, err := o.listManagedModels(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		ollamaModels.mu.Lock()
+		defer ollamaModels.mu.Unlock()
+		if ollamaModels.generation == generation {
+			ollamaModels.endpoints = endpoints
+			ollamaModels.expireAt = time.Now().Add(modelCacheTTL)
+		}
+		return endpoints, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]ModelEndpoint), nil
+}
+
+func (o *ollama) listManagedModels(ctx context.Context) ([]ModelEndpoint, error) {
+	pods, err := K8s.ClientSet.CoreV1().Pods("").List(ctx, metaV1.ListOptions{LabelSelector: ollamaManagedSelector})
+	if err != nil {
+		return nil, fmt.Errorf("获取Ollama Pod列表失败: %v", err)
+	}
+
+	var endpoints []ModelEndpoint
+	for _, pod := range pods.Items {
+		if !isPodReady(&pod) {
+			continue
+		}
+		models, err := o.modelNames(ctx, pod.Name, pod.Namespace)
+		if err != nil {
+			// 单个 Pod 不可用时跳过，不影响其他 Pod
+			continue
+		}
+		for _, model := range models {
+			endpoints = append(endpoints, ModelEndpoint{Model: model, PodName: pod.Name, Namespace: pod.Namespace})
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Model != endpoints[j].Model {
+			return endpoints[i].Model < endpoints[j].Model
+		}
+		return endpoints[i].PodName < endpoints[j].PodName
+	})
+	return endpoints, nil
+}
+
+// ResolveModel 根据模型名称选择一个提供该模型的 Pod，多个 Pod 时轮询
+// 未带 tag 的名称按 :latest 匹配
+func (o *ollama) ResolveModel(ctx context.Context, model string) (*ModelEndpoint, error) {
+	endpoints, err := o.ListManagedModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []ModelEndpoint
+	for _, e := range endpoints {
+		if modelNameMatch(e.Model, model) {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	idx := atomic.AddUint64(&ollamaModels.next, 1)
+	endpoint := candidates[idx%uint64(len(candidates))]
+	return &endpoint, nil
+}
+
+// modelNames 获取 Pod 上已拉取的模型名称
+func (o *ollama) modelNames(ctx context.Context, podName, namespace string) ([]string, error) {
+	data, err := o.getModelList(ctx, podName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if resp, ok := data.(map[string]interface{}); ok {
+		if models, ok := resp["models"].([]interface{}); ok {
+			for _, m := range models {
+				if item, ok := m.(map[string]interface{}); ok {
+					if name, ok := item["name"].(string); ok && name != "" {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// ModelDrift 对比模型目录与部署中每个就绪副本实际拉取的模型
+func (o *ollama) ModelDrift(ctx context.Context, target kubeDto.OllamaTarget, desired []string) (*kubeDto.OllamaModelDrift, error) {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pods)
+
+	drift := &kubeDto.OllamaModelDrift{Desired: desired, InSync: true, CheckedAt: time.Now()}
+	for _, pod := range pods {
+		podDrift := kubeDto.OllamaPodDrift{PodName: pod, Missing: []string{}, Extra: []string{}}
+		names, err := o.modelNames(ctx, pod, target.NameSpace)
+		if err != nil {
+			podDrift.Error = err.Error()
+			drift.InSync = false
+			drift.Pods = append(drift.Pods, podDrift)
+			continue
+		}
+		for _, want := range desired {
+			if !containsModel(names, want, false) {
+				podDrift.Missing = append(podDrift.Missing, want)
+			}
+		}
+		for _, name := range names {
+			if !containsModel(desired, name, true) {
+				podDrift.Extra = append(podDrift.Extra, name)
+			}
+		}
+		if len(podDrift.Missing) > 0 || len(podDrift.Extra) > 0 {
+			drift.InSync = false
+		}
+		drift.Pods = append(drift.Pods, podDrift)
+	}
+	return drift, nil
+}
+
+// containsModel 判断模型列表中是否包含指定模型，reverse 为 true 时 list 为请求的名称
+func containsModel(list []string, name string, reverse bool) bool {
+	for _, item := range list {
+		if reverse && modelNameMatch(name, item) || !reverse && modelNameMatch(item, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// modelNameMatch 判断 Ollama 模型名称是否与请求的名称一致
+func modelNameMatch(ollamaName, requested string) bool {
+	if ollamaName == requested {
+		return true
+	}
+	if !strings.Contains(requested, ":") {
+		return ollamaName == requested+":latest"
+	}
+	return false
+}
+
+// isPodReady 判断 Pod 是否运行且就绪
+func isPodReady(pod *coreV1.Pod) bool {
+	if pod.Status.Phase != coreV1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == coreV1.PodReady {
+			return cond.Status == coreV1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Target 转换为推理目标
+func (e *ModelEndpoint) Target() kubeDto.OllamaTarget {
+	return kubeDto.OllamaTarget{PodName: e.PodName, NameSpace: e.Namespace}
+}
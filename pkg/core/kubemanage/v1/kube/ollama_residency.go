@@ -0,0 +1,203 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// LoadedModels 获取目标每个就绪副本通过 /api/ps 报告的已加载模型
+func (o *ollama) LoadedModels(ctx context.Context, target kubeDto.OllamaTarget) ([]kubeDto.OllamaPodResidency, error) {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pods)
+	out := make([]kubeDto.OllamaPodResidency, 0, len(pods))
+	for _, podName := range pods {
+		pod, err := K8s.ClientSet.CoreV1().Pods(target.NameSpace).Get(ctx, podName, metaV1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("获取Pod信息失败: %v", err)
+		}
+		out = append(out, o.podResidency(ctx, pod))
+	}
+	return out, nil
+}
+
+// ResidencyOverview 汇总所有 managed=kubemanage 的就绪 Ollama 副本加载的模型，按节点分组
+func (o *ollama) ResidencyOverview(ctx context.Context) ([]kubeDto.OllamaNodeResidency, error) {
+	pods, err := K8s.ClientSet.CoreV1().Pods("").List(ctx, metaV1.ListOptions{LabelSelector: ollamaManagedSelector})
+	if err != nil {
+		return nil, fmt.Errorf("获取Ollama Pod列表失败: %v", err)
+	}
+
+	nodes := map[string]*kubeDto.OllamaNodeResidency{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !isPodReady(pod) {
+			continue
+		}
+		node, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			node = &kubeDto.OllamaNodeResidency{NodeName: pod.Spec.NodeName}
+			if n, err := K8s.ClientSet.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metaV1.GetOptions{}); err == nil {
+				node.MemoryAllocatable = n.Status.Allocatable.Memory().Value()
+				if gpu, ok := n.Status.Allocatable[resourceNvidiaGPU]; ok {
+					node.GPUAllocatable = gpu.Value()
+				}
+			}
+			nodes[pod.Spec.NodeName] = node
+		}
+		residency := o.podResidency(ctx, pod)
+		node.Size += residency.Size
+		node.SizeVRAM += residency.SizeVRAM
+		node.Pods = append(node.Pods, residency)
+	}
+
+	out := make([]kubeDto.OllamaNodeResidency, 0, len(nodes))
+	for _, node := range nodes {
+		sort.Slice(node.Pods, func(i, j int) bool {
+			return node.Pods[i].Namespace+"/"+node.Pods[i].PodName < node.Pods[j].Namespace+"/"+node.Pods[j].PodName
+		})
+		out = append(out, *node)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeName < out[j].NodeName })
+	return out, nil
+}
+
+// LoadModel 在目标每个就绪副本上预加载模型，keepAlive 为空时使用 Ollama 的默认驻留时间
+func (o *ollama) LoadModel(ctx context.Context, target kubeDto.OllamaTarget, modelName, keepAlive string) error {
+	body := map[string]interface{}{"model": modelName}
+	if keepAlive != "" {
+		value, err := parseKeepAlive(keepAlive)
+		if err != nil {
+			return err
+		}
+		body["keep_alive"] = value
+	}
+	return o.generateOnPods(ctx, target, body)
+}
+
+// UnloadModel 立即从目标每个就绪副本的内存中卸载模型
+func (o *ollama) UnloadModel(ctx context.Context, target kubeDto.OllamaTarget, modelName string) error {
+	return o.generateOnPods(ctx, target, map[string]interface{}{"model": modelName, "keep_alive": 0})
+}
+
+// generateOnPods 发送不带 prompt 的 /api/generate 请求，Ollama 只加载或卸载模型而不生成内容
+func (o *ollama) generateOnPods(ctx context.Context, target kubeDto.OllamaTarget, body map[string]interface{}) error {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return err
+	}
+	body["stream"] = false
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %v", err)
+	}
+	for _, pod := range pods {
+		if _, err := o.proxyJSON(ctx, pod, target.NameSpace, "POST", "/api/generate", jsonData); err != nil {
+			return fmt.Errorf("pod %s: %v", pod, err)
+		}
+	}
+	return nil
+}
+
+// podResidency 获取单个副本加载的模型，请求失败时记录在 Error 中
+func (o *ollama) podResidency(ctx context.Context, pod *coreV1.Pod) kubeDto.OllamaPodResidency {
+	residency := kubeDto.OllamaPodResidency{
+		PodName:    pod.Name,
+		Namespace:  pod.Namespace,
+		Deployment: pod.Labels["name"],
+		NodeName:   pod.Spec.NodeName,
+		Models:     []kubeDto.OllamaLoadedModel{},
+	}
+	resp, err := o.proxyJSON(ctx, pod.Name, pod.Namespace, "GET", "/api/ps", nil)
+	if err != nil {
+		residency.Error = err.Error()
+		return residency
+	}
+	raw, _ := json.Marshal(resp["models"])
+	var models []kubeDto.OllamaLoadedModel
+	if err := json.Unmarshal(raw, &models); err != nil {
+		residency.Error = fmt.Sprintf("解析响应失败: %v", err)
+		return residency
+	}
+	for _, m := range models {
+		residency.Size += m.Size
+		residency.SizeVRAM += m.SizeVRAM
+	}
+	if models != nil {
+		residency.Models = models
+	}
+	return residency
+}
+
+// proxyJSON 通过 API Server 代理调用 Ollama API 并解析 JSON 响应
+func (o *ollama) proxyJSON(ctx context.Context, podName, namespace, verb, suffix string, jsonData []byte) (map[string]interface{}, error) {
+	port, err := o.getPodPort(podName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	req := K8s.ClientSet.CoreV1().RESTClient().Verb(verb).
+		Namespace(namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%d", podName, port)).
+		SubResource("proxy").
+		Suffix(suffix)
+	if jsonData != nil {
+		req = req.Body(jsonData).SetHeader("Content-Type", "application/json")
+	}
+	body, err := req.Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("请求Ollama API失败: %v", err)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))
+	}
+	if errMsg, ok := resp["error"].(string); ok && errMsg != "" {
+		return nil, fmt.Errorf("ollama API返回错误: %s", errMsg)
+	}
+	return resp, nil
+}
+
+// proxyStream 通过 API Server 代理以流式方式调用 Ollama API，每收到一行 NDJSON 回调一次 onChunk
+func (o *ollama) proxyStream(ctx context.Context, podName, namespace, suffix string, jsonData []byte, onChunk func(chunk map[string]interface{}) error) error {
+	port, err := o.getPodPort(podName, namespace)
+	if err != nil {
+		return err
+	}
+	body, err := K8s.ClientSet.CoreV1().RESTClient().Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%s:%d", podName, port)).
+		SubResource("proxy").
+		Suffix(suffix).
+		Body(jsonData).
+		SetHeader("Content-Type", "application/json").
+		Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("请求Ollama API失败: %v", err)
+	}
+	defer body.Close()
+	return readNDJSON(body, onChunk)
+}
+
+// parseKeepAlive 校验 keep_alive，整数按秒传递，其余按时长字符串传递
+func parseKeepAlive(keepAlive string) (interface{}, error) {
+	if seconds, err := strconv.Atoi(keepAlive); err == nil {
+		return seconds, nil
+	}
+	if _, err := time.ParseDuration(keepAlive); err != nil {
+		return nil, fmt.Errorf("keep_alive 格式错误: %s，示例: 10m、24h、-1", keepAlive)
+	}
+	return keepAlive, nil
+}
@@ -0,0 +1,144 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// 虚拟模型的路由策略
+const (
+	RouteStrategyOrdered  = "ordered"  // 按顺序尝试，前面的目标失败时才使用后面的目标
+	RouteStrategyWeighted = "weighted" // 按权重随机排列目标，失败时依次尝试其余目标
+)
+
+// RouteTarget 虚拟模型的一个目标，Timeout 为 0 表示不限制
+// 流式请求的超时只作用于首个分片之前，避免中断正在生成的长回答
+type RouteTarget struct {
+	kubeDto.OllamaTarget
+	Model   string
+	Weight  int
+	Timeout time.Duration
+}
+
+// ModelRoute 虚拟模型，将一个名称映射到多个部署+模型目标
+type ModelRoute struct {
+	Name     string
+	Strategy string
+	Targets  []RouteTarget
+}
+
+// modelRouter 根据名称查找虚拟模型，由上层在启动时注入，不存在时返回 nil
+var modelRouter func(ctx context.Context, name string) *ModelRoute
+
+// SetModelRouter 设置虚拟模型查找回调，回调需自行缓存，每次推理请求都会调用
+func SetModelRouter(fn func(ctx context.Context, name string) *ModelRoute) {
+	modelRouter = fn
+}
+
+// LookupRoute 查找虚拟模型，name 不是虚拟模型时返回 nil
+func (o *ollama) LookupRoute(ctx context.Context, name string) *ModelRoute {
+	if modelRouter == nil || name == "" {
+		return nil
+	}
+	return modelRouter(ctx, name)
+}
+
+// route 按路由策略依次在目标上执行 fn，目标 Pod 未就绪、请求失败或超时时切换到下一个目标
+// fn 收到首个输出时调用 firstChunk 停止超时计时
+func (o *ollama) route(ctx context.Context, route *ModelRoute, fn func(ctx context.Context, target RouteTarget, firstChunk func()) error) error {
+	var errs []string
+	for _, target := range route.order() {
+		if err := o.targetHealthy(ctx, target.OllamaTarget); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target.describe(), err))
+			continue
+		}
+
+		targetCtx, cancel := context.WithCancel(ctx)
+		var timer *time.Timer
+		if target.Timeout > 0 {
+			timer = time.AfterFunc(target.Timeout, cancel)
+		}
+		stop := func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+		err := fn(targetCtx, target, stop)
+		stop()
+		timedOut := targetCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if timedOut {
+			err = fmt.Errorf("超过 %s 未响应", target.Timeout)
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", target.describe(), err))
+		// 请求已被取消时不再尝试其他目标
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("虚拟模型 %s 的全部目标均不可用: %s", route.Name, strings.Join(errs, "; "))
+}
+
+// targetHealthy 指定 Pod 的目标需处于就绪状态，部署目标由 ReadyPods 过滤未就绪的副本
+func (o *ollama) targetHealthy(ctx context.Context, target kubeDto.OllamaTarget) error {
+	if target.PodName == "" || target.Deployment != "" {
+		return nil
+	}
+	pod, err := K8s.ClientSet.CoreV1().Pods(target.NameSpace).Get(ctx, target.PodName, metaV1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if !isPodReady(pod) {
+		return fmt.Errorf("pod 状态为 %s，未就绪", pod.Status.Phase)
+	}
+	return nil
+}
+
+// order 按路由策略返回目标的尝试顺序
+func (r *ModelRoute) order() []RouteTarget {
+	if r.Strategy != RouteStrategyWeighted {
+		return r.Targets
+	}
+	remaining := append([]RouteTarget(nil), r.Targets...)
+	ordered := make([]RouteTarget, 0, len(remaining))
+	for len(remaining) > 0 {
+		total := 0
+		for _, t := range remaining {
+			total += t.weight()
+		}
+		n := rand.Intn(total)
+		for i, t := range remaining {
+			if n -= t.weight(); n < 0 {
+				ordered = append(ordered, t)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return ordered
+}
+
+func (t RouteTarget) weight() int {
+	if t.Weight <= 0 {
+		return 1
+	}
+	return t.Weight
+}
+
+func (t RouteTarget) describe() string {
+	name := t.Deployment
+	if name == "" {
+		name = t.PodName
+	}
+	return fmt.Sprintf("%s/%s(%s)", t.NameSpace, name, t.Model)
+}
@@ -0,0 +1,96 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+func routeTarget(deployment, model string, weight int, timeout time.Duration) RouteTarget {
+	return RouteTarget{
+		OllamaTarget: kubeDto.OllamaTarget{Deployment: deployment, NameSpace: "ai"},
+		Model:        model,
+		Weight:       weight,
+		Timeout:      timeout,
+	}
+}
+
+func TestModelRouteFailover(t *testing.T) {
+	route := &ModelRoute{Name: "chat", Strategy: RouteStrategyOrdered, Targets: []RouteTarget{
+		routeTarget("gpu", "llama3:70b", 0, 0),
+		routeTarget("slow", "llama3:8b", 0, 20*time.Millisecond),
+		routeTarget("cpu", "qwen2:7b", 0, 0),
+	}}
+	var tried []string
+	err := Ollama.route(context.Background(), route, func(ctx context.Context, target RouteTarget, _ func()) error {
+		tried = append(tried, target.Deployment)
+		switch target.Deployment {
+		case "gpu":
+			return errors.New("connection refused")
+		case "slow":
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tried, ",") != "gpu,slow,cpu" {
+		t.Errorf("tried = %v", tried)
+	}
+
+	err = Ollama.route(context.Background(), route, func(ctx context.Context, target RouteTarget, firstChunk func()) error {
+		firstChunk()
+		return errors.New("boom")
+	})
+	if err == nil || !strings.Contains(err.Error(), "ai/cpu(qwen2:7b): boom") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestModelRouteFirstChunkStopsTimeout(t *testing.T) {
+	route := &ModelRoute{Name: "chat", Targets: []RouteTarget{routeTarget("gpu", "llama3", 0, 10*time.Millisecond)}}
+	err := Ollama.route(context.Background(), route, func(ctx context.Context, target RouteTarget, firstChunk func()) error {
+		firstChunk()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModelRouteWeightedOrder(t *testing.T) {
+	route := &ModelRoute{Strategy: RouteStrategyWeighted, Targets: []RouteTarget{
+		routeTarget("a", "m", 9, 0),
+		routeTarget("b", "m", 1, 0),
+		routeTarget("c", "m", 0, 0),
+	}}
+	first := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		order := route.order()
+		if len(order) != 3 {
+			t.Fatalf("order = %v", order)
+		}
+		seen := map[string]bool{}
+		for _, target := range order {
+			seen[target.Deployment] = true
+		}
+		if len(seen) != 3 {
+			t.Fatalf("order = %v", order)
+		}
+		first[order[0].Deployment]++
+	}
+	if first["a"] < first["b"] || first["a"] < first["c"] {
+		t.Errorf("first = %v", first)
+	}
+}
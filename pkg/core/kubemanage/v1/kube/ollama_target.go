@@ -0,0 +1,271 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// targetCursors 每个 Ollama 部署的轮询游标，key 为 namespace/deployment
+var targetCursors sync.Map
+
+// ReadyPods 返回推理目标对应的就绪 Pod，按轮询顺序排列
+// 指定 PodName 时直接返回该 Pod，不做就绪检查，便于调试
+func (o *ollama) ReadyPods(ctx context.Context, target kubeDto.OllamaTarget) ([]string, error) {
+	if target.NameSpace == "" {
+		return nil, fmt.Errorf("namespace 不能为空")
+	}
+	if target.PodName != "" {
+		return []string{target.PodName}, nil
+	}
+	if target.Deployment == "" {
+		return nil, fmt.Errorf("pod_name 和 deployment 不能同时为空")
+	}
+
+	selector, err := o.podSelector(target.Deployment, target.NameSpace)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := K8s.ClientSet.CoreV1().Pods(target.NameSpace).List(ctx, metaV1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("获取Ollama Pod列表失败: %v", err)
+	}
+	var ready []string
+	for i := range pods.Items {
+		if isPodReady(&pods.Items[i]) {
+			ready = append(ready, pods.Items[i].Name)
+		}
+	}
+	if len(ready) == 0 {
+		return nil, fmt.Errorf("ollama 部署 %s/%s 没有就绪的副本", target.NameSpace, target.Deployment)
+	}
+
+	// 从游标位置开始轮询，使请求分散到各个副本
+	cursor, _ := targetCursors.LoadOrStore(target.NameSpace+"/"+target.Deployment, new(uint64))
+	start := int(atomic.AddUint64(cursor.(*uint64), 1) % uint64(len(ready)))
+	return append(ready[start:], ready[:start]...), nil
+}
+
+// podSelector 获取 Ollama 部署的 Pod 标签选择器
+func (o *ollama) podSelector(name, namespace string) (string, error) {
+	kind, err := o.workloadType(name, namespace)
+	if err != nil {
+		return "", err
+	}
+	var selector *metaV1.LabelSelector
+	if kind == ollamaTypeDaemonSet {
+		ds, err := K8s.ClientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = ds.Spec.Selector
+	} else {
+		deploy, err := K8s.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = deploy.Spec.Selector
+	}
+	return metaV1.FormatLabelSelector(selector), nil
+}
+
+// tryPods 依次在就绪副本上执行 fn，失败时换下一个副本重试
+func (o *ollama) tryPods(ctx context.Context, target kubeDto.OllamaTarget, fn func(podName string) error) error {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, pod := range pods {
+		if lastErr = fn(pod); lastErr == nil {
+			return nil
+		}
+		// 请求已被取消时不再重试
+		if ctx.Err() != nil {
+			return lastErr
+		}
+	}
+	if len(pods) > 1 {
+		return fmt.Errorf("全部 %d 个副本请求失败，最后一次错误: %v", len(pods), lastErr)
+	}
+	return lastErr
+}
+
+// GetModelList 获取目标 Ollama 的模型列表
+func (o *ollama) GetModelList(ctx context.Context, target kubeDto.OllamaTarget) (result interface{}, err error) {
+	err = o.tryPods(ctx, target, func(podName string) error {
+		result, err = o.getModelList(ctx, podName, target.NameSpace)
+		return err
+	})
+	return result, err
+}
+
+// DeleteModel 删除目标 Ollama 中的模型，指定部署时会从每个就绪副本中删除
+func (o *ollama) DeleteModel(ctx context.Context, target kubeDto.OllamaTarget, modelName string) error {
+	pods, err := o.ReadyPods(ctx, target)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if err := o.deleteModel(ctx, pod, target.NameSpace, modelName); err != nil {
+			return fmt.Errorf("pod %s: %v", pod, err)
+		}
+	}
+	return nil
+}
+
+// GetModelDetail 获取目标 Ollama 中模型的详情
+func (o *ollama) GetModelDetail(ctx context.Context, target kubeDto.OllamaTarget, modelName string) (result interface{}, err error) {
+	err = o.tryPods(ctx, target, func(podName string) error {
+		result, err = o.getModelDetail(ctx, podName, target.NameSpace, modelName)
+		return err
+	})
+	return result, err
+}
+
+// Chat 调用目标 Ollama 上的模型进行聊天，model 为虚拟模型时按路由策略选择目标
+// context 中指定了 JSON Schema 输出格式时校验模型输出，不符合时把错误反馈给模型重试
+// 输入与输出都会经过护栏检查
+func (o *ollama) Chat(ctx context.Context, target kubeDto.OllamaTarget, model string, messages []kubeDto.OllamaChatMessage, stream bool) (interface{}, error) {
+	messages, err := guardInput(ctx, model, messages)
+	if err != nil {
+		return nil, err
+	}
+	if stream {
+		result, err := o.chatRouted(ctx, target, model, messages, stream)
+		if err != nil {
+			return nil, err
+		}
+		raw, _ := result.(string)
+		return guardRawStream(ctx, model, raw)
+	}
+	result, err := withSchemaRetry(ctx, func(violation *SchemaViolationError) (string, interface{}, error) {
+		if violation != nil {
+			messages = append(messages[:len(messages):len(messages)],
+				kubeDto.OllamaChatMessage{Role: "assistant", Content: violation.Output},
+				kubeDto.OllamaChatMessage{Role: "user", Content: schemaFeedback(violation)},
+			)
+		}
+		result, err := o.chatRouted(ctx, target, model, messages, false)
+		return chatContent(result), result, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, guardOutput(ctx, model, result)
+}
+
+func (o *ollama) chatRouted(ctx context.Context, target kubeDto.OllamaTarget, model string, messages []kubeDto.OllamaChatMessage, stream bool) (result interface{}, err error) {
+	if route := o.LookupRoute(ctx, model); route != nil {
+		err = o.route(ctx, route, func(ctx context.Context, t RouteTarget, _ func()) error {
+			result, err = o.chatTarget(ctx, t.OllamaTarget, t.Model, messages, stream)
+			return err
+		})
+		return result, err
+	}
+	return o.chatTarget(ctx, target, model, messages, stream)
+}
+
+func (o *ollama) chatTarget(ctx context.Context, target kubeDto.OllamaTarget, model string, messages []kubeDto.OllamaChatMessage, stream bool) (result interface{}, err error) {
+	err = o.tryPods(ctx, target, func(podName string) error {
+		start := time.Now()
+		result, err = o.chat(ctx, podName, target.NameSpace, model, messages, stream)
+		if err == nil {
+			resp, _ := result.(map[string]interface{})
+			recordUsage(ctx, UsageKindChat, target, podName, model, resp, start)
+		}
+		return err
+	})
+	return result, err
+}
+
+// ChatStream 以流式方式调用目标 Ollama 上的模型进行聊天，model 为虚拟模型时按路由策略选择目标
+// 只有在尚未向调用方输出任何分片时才会换副本或目标重试，避免输出重复内容
+func (o *ollama) ChatStream(ctx context.Context, target kubeDto.OllamaTarget, model string, messages []kubeDto.OllamaChatMessage, onChunk func(chunk map[string]interface{}) error) error {
+	messages, err := guardInput(ctx, model, messages)
+	if err != nil {
+		return err
+	}
+	onChunk = guardStream(ctx, model, onChunk)
+	route := o.LookupRoute(ctx, model)
+	if route == nil {
+		return o.chatStreamTarget(ctx, target, model, messages, onChunk)
+	}
+	var sent bool
+	var streamErr error
+	err = o.route(ctx, route, func(ctx context.Context, t RouteTarget, firstChunk func()) error {
+		err := o.chatStreamTarget(ctx, t.OllamaTarget, t.Model, messages, func(chunk map[string]interface{}) error {
+			if !sent {
+				sent = true
+				firstChunk()
+			}
+			return onChunk(chunk)
+		})
+		if err != nil && sent {
+			streamErr = err
+			return nil
+		}
+		return err
+	})
+	if streamErr != nil {
+		return streamErr
+	}
+	return err
+}
+
+func (o *ollama) chatStreamTarget(ctx context.Context, target kubeDto.OllamaTarget, model string, messages []kubeDto.OllamaChatMessage, onChunk func(chunk map[string]interface{}) error) error {
+	var sent bool
+	var streamErr error
+	err := o.tryPods(ctx, target, func(podName string) error {
+		start := time.Now()
+		err := o.chatStream(ctx, podName, target.NameSpace, model, messages, func(chunk map[string]interface{}) error {
+			sent = true
+			// 最后一个分片携带 token 数及耗时
+			if done, _ := chunk["done"].(bool); done {
+				recordUsage(ctx, UsageKindChat, target, podName, model, chunk, start)
+			}
+			return onChunk(chunk)
+		})
+		if err != nil && sent {
+			// 已经输出过内容，记录错误并终止重试
+			streamErr = err
+			return nil
+		}
+		return err
+	})
+	if streamErr != nil {
+		return streamErr
+	}
+	return err
+}
+
+// Embeddings 调用目标 Ollama 上的模型生成文本向量嵌入，model 为虚拟模型时按路由策略选择目标
+func (o *ollama) Embeddings(ctx context.Context, target kubeDto.OllamaTarget, model, prompt string) (result interface{}, err error) {
+	if route := o.LookupRoute(ctx, model); route != nil {
+		err = o.route(ctx, route, func(ctx context.Context, t RouteTarget, _ func()) error {
+			result, err = o.embeddingsTarget(ctx, t.OllamaTarget, t.Model, prompt)
+			return err
+		})
+		return result, err
+	}
+	return o.embeddingsTarget(ctx, target, model, prompt)
+}
+
+func (o *ollama) embeddingsTarget(ctx context.Context, target kubeDto.OllamaTarget, model, prompt string) (result interface{}, err error) {
+	err = o.tryPods(ctx, target, func(podName string) error {
+		start := time.Now()
+		result, err = o.embeddings(ctx, podName, target.NameSpace, model, prompt)
+		if err == nil {
+			resp, _ := result.(map[string]interface{})
+			recordUsage(ctx, UsageKindEmbeddings, target, podName, model, embeddingUsage(resp, prompt), start)
+		}
+		return err
+	})
+	return result, err
+}
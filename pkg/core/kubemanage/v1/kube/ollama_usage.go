@@ -0,0 +1,111 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/chunker"
+)
+
+// 推理调用类型
+const (
+	UsageKindChat       = "chat"
+	UsageKindEmbeddings = "embeddings"
+	UsageKindGenerate   = "generate"
+)
+
+type usageCallerKey struct{}
+
+// UsageCaller 推理调用方，用于用量归属
+type UsageCaller struct {
+	UserID   int
+	UserName string
+	Source   string // 调用入口，如 api、openai、knowledge
+}
+
+// UsageRecord 一次推理调用的用量，时长单位为毫秒
+type UsageRecord struct {
+	Caller             UsageCaller
+	Kind               string
+	Model              string
+	Namespace          string
+	Deployment         string
+	PodName            string
+	PromptTokens       int64
+	CompletionTokens   int64
+	TotalDuration      int64
+	LoadDuration       int64
+	PromptEvalDuration int64
+	EvalDuration       int64
+}
+
+// usageRecorder 用量记录回调，由上层在启动时注入
+var usageRecorder func(record UsageRecord)
+
+// SetUsageRecorder 设置用量记录回调，回调需自行处理耗时操作，避免阻塞推理请求
+func SetUsageRecorder(fn func(record UsageRecord)) {
+	usageRecorder = fn
+}
+
+// WithUsageCaller 将调用方附加到 context，后续推理调用的用量归属于该调用方
+func WithUsageCaller(ctx context.Context, caller UsageCaller) context.Context {
+	return context.WithValue(ctx, usageCallerKey{}, caller)
+}
+
+// recordUsage 根据 Ollama 响应中的 prompt_eval_count、eval_count 及各阶段耗时记录用量
+// 响应中没有耗时信息时（如 embeddings 接口）使用本地计时
+func recordUsage(ctx context.Context, kind string, target kubeDto.OllamaTarget, podName, model string, resp map[string]interface{}, start time.Time) {
+	if usageRecorder == nil {
+		return
+	}
+	caller, _ := ctx.Value(usageCallerKey{}).(UsageCaller)
+	record := UsageRecord{
+		Caller:     caller,
+		Kind:       kind,
+		Model:      model,
+		Namespace:  target.NameSpace,
+		Deployment: target.Deployment,
+		PodName:    podName,
+	}
+	if resp != nil {
+		record.PromptTokens = int64Of(resp["prompt_eval_count"])
+		record.CompletionTokens = int64Of(resp["eval_count"])
+		record.TotalDuration = int64Of(resp["total_duration"]) / int64(time.Millisecond)
+		record.LoadDuration = int64Of(resp["load_duration"]) / int64(time.Millisecond)
+		record.PromptEvalDuration = int64Of(resp["prompt_eval_duration"]) / int64(time.Millisecond)
+		record.EvalDuration = int64Of(resp["eval_duration"]) / int64(time.Millisecond)
+	}
+	if record.TotalDuration == 0 {
+		record.TotalDuration = time.Since(start).Milliseconds()
+	}
+	usageRecorder(record)
+}
+
+// EmbeddingPromptTokens 返回 embeddings 调用的输入 token 数，/api/embeddings 不返回 prompt_eval_count，按输入文本估算
+func EmbeddingPromptTokens(resp map[string]interface{}, prompt string) int {
+	if count, ok := resp["prompt_eval_count"].(float64); ok {
+		return int(count)
+	}
+	return chunker.EstimateTokens(prompt)
+}
+
+// embeddingUsage 补全 embeddings 响应中的输入 token 数，与 EmbeddingPromptTokens 一致
+func embeddingUsage(resp map[string]interface{}, prompt string) map[string]interface{} {
+	if _, ok := resp["prompt_eval_count"]; ok {
+		return resp
+	}
+	usage := make(map[string]interface{}, len(resp)+1)
+	for k, v := range resp {
+		if k != "embedding" {
+			usage[k] = v
+		}
+	}
+	usage["prompt_eval_count"] = float64(EmbeddingPromptTokens(resp, prompt))
+	return usage
+}
+
+func int64Of(v interface{}) int64 {
+	f, _ := v.(float64)
+	return int64(f)
+}
@@ -0,0 +1,32 @@
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+func TestRecordEmbeddingUsage(t *testing.T) {
+	var records []UsageRecord
+	SetUsageRecorder(func(record UsageRecord) { records = append(records, record) })
+	defer SetUsageRecorder(nil)
+
+	ctx := WithUsageCaller(context.Background(), UsageCaller{UserName: "admin", Source: "knowledge"})
+	target := kubeDto.OllamaTarget{Deployment: "ollama", NameSpace: "ai"}
+	// /api/embeddings 只返回向量，按输入估算 token 数
+	resp := map[string]interface{}{"embedding": []interface{}{0.1, 0.2}}
+	recordUsage(ctx, UsageKindEmbeddings, target, "ollama-0", "bge-m3", embeddingUsage(resp, "部署 Ollama"), time.Now())
+	if _, ok := resp["prompt_eval_count"]; ok {
+		t.Error("response must not be modified")
+	}
+	recordUsage(ctx, UsageKindEmbeddings, target, "ollama-0", "bge-m3", embeddingUsage(map[string]interface{}{"prompt_eval_count": 7.0}, "部署 Ollama"), time.Now())
+
+	if len(records) != 2 || records[0].PromptTokens != 4 || records[1].PromptTokens != 7 {
+		t.Fatalf("records = %+v", records)
+	}
+	if records[0].Caller.Source != "knowledge" || records[0].Kind != UsageKindEmbeddings || records[0].CompletionTokens != 0 {
+		t.Errorf("record = %+v", records[0])
+	}
+}
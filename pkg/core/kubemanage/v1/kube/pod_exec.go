@@ -0,0 +1,73 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecInPod 通过 SPDY exec 在容器中执行命令，stdin 不为空时作为命令的标准输入，返回标准输出
+// ctx 取消时关闭标准输入，命令读到 EOF 后结束，此时返回 ctx 的错误；不读取标准输入的命令会执行完成
+func ExecInPod(ctx context.Context, podName, namespace, container string, command []string, stdin io.Reader) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	req := K8s.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&coreV1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(K8s.Config, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("创建exec连接失败: %v", err)
+	}
+	if stdin != nil {
+		stdin = cancelableReader(ctx, stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if closer, ok := stdin.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", ctxErr
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// cancelableReader 通过管道转发 r，ctx 取消时关闭管道，正在阻塞的读取立即返回
+// 调用方读取结束后需关闭返回的 Reader，使转发的 goroutine 退出
+func cancelableReader(ctx context.Context, r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	stop := context.AfterFunc(ctx, func() { _ = pw.CloseWithError(ctx.Err()) })
+	go func() {
+		defer stop()
+		_, err := io.Copy(pw, r)
+		_ = pw.CloseWithError(err)
+	}()
+	return pr
+}
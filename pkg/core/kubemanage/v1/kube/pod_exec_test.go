@@ -0,0 +1,26 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCancelableReader(t *testing.T) {
+	data, err := io.ReadAll(cancelableReader(context.Background(), strings.NewReader("gguf")))
+	if err != nil || string(data) != "gguf" {
+		t.Fatalf("data = %q, err = %v", data, err)
+	}
+
+	// 上传的源文件读取阻塞时，取消后读取立即返回
+	src, _ := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := cancelableReader(ctx, src)
+	defer r.Close()
+	cancel()
+	if _, err := r.Read(make([]byte, 8)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("read after cancel: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// 提示词模板内置变量，渲染时自动填充，同名的自定义变量不会覆盖
+const (
+	PromptVarContext  = "context"  // 知识库检索到的文档
+	PromptVarQuestion = "question" // 用户问题
+	PromptVarUser     = "user"     // 当前用户名
+)
+
+var promptVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ExtractPromptVariables 按出现顺序返回模板中的变量名，重复的只返回一次
+func ExtractPromptVariables(content string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, match := range promptVariablePattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// RenderPromptTemplate 使用变量替换模板中的 {{name}}，缺少变量时返回错误
+func RenderPromptTemplate(content string, vars map[string]string) (string, error) {
+	var missing []string
+	for _, name := range ExtractPromptVariables(content) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("提示词模板缺少变量: %s", strings.Join(missing, ", "))
+	}
+	return promptVariablePattern.ReplaceAllStringFunc(content, func(s string) string {
+		return vars[promptVariablePattern.FindStringSubmatch(s)[1]]
+	}), nil
+}
+
+// ApplyPromptTemplate 渲染模板并作为系统提示词，替换消息列表中原有的系统消息
+// question 取最后一条用户消息，context 为空
+func ApplyPromptTemplate(ctx context.Context, messages []kubeDto.OllamaChatMessage, ref kubeDto.PromptTemplateRef) ([]kubeDto.OllamaChatMessage, error) {
+	var question string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			question = messages[i].Content
+			break
+		}
+	}
+	systemPrompt, err := RenderPromptTemplate(ref.PromptTemplate, promptVariables(ctx, question, "", ref.Variables))
+	if err != nil {
+		return nil, err
+	}
+	out := []kubeDto.OllamaChatMessage{{Role: "system", Content: systemPrompt}}
+	for _, msg := range messages {
+		if msg.Role != "system" {
+			out = append(out, msg)
+		}
+	}
+	return out, nil
+}
+
+// promptVariables 合并自定义变量与内置变量，用户名取自 context 中的调用方
+func promptVariables(ctx context.Context, question, documents string, custom map[string]string) map[string]string {
+	vars := make(map[string]string, len(custom)+3)
+	for k, v := range custom {
+		vars[k] = v
+	}
+	caller, _ := ctx.Value(usageCallerKey{}).(UsageCaller)
+	vars[PromptVarContext] = documents
+	vars[PromptVarQuestion] = question
+	vars[PromptVarUser] = caller.UserName
+	return vars
+}
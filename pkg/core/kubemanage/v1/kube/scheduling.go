@@ -0,0 +1,95 @@
+package kube
+
+import (
+	"fmt"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// resourceNvidiaGPU NVIDIA device plugin 注册的扩展资源名称
+const resourceNvidiaGPU coreV1.ResourceName = "nvidia.com/gpu"
+
+// validateScheduling 在创建任何资源之前校验调度选项，避免部署到一半失败
+func validateScheduling(opts *kubeDto.SchedulingOptions) error {
+	_, err := extendedResources(opts)
+	return err
+}
+
+// extendedResources 解析 GPU 及其他扩展资源的数量
+func extendedResources(opts *kubeDto.SchedulingOptions) (coreV1.ResourceList, error) {
+	list := coreV1.ResourceList{}
+	if opts.GPU != "" {
+		quantity, err := resource.ParseQuantity(opts.GPU)
+		if err != nil {
+			return nil, fmt.Errorf("gpu 数量格式错误: %v", err)
+		}
+		list[resourceNvidiaGPU] = quantity
+	}
+	for name, value := range opts.ExtendedResources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("扩展资源 %s 数量格式错误: %v", name, err)
+		}
+		list[coreV1.ResourceName(name)] = quantity
+	}
+	return list, nil
+}
+
+// applyScheduling 将调度选项渲染到 Pod 模板，资源和环境变量作用于第一个容器
+func applyScheduling(spec *coreV1.PodSpec, opts *kubeDto.SchedulingOptions) error {
+	resources, err := extendedResources(opts)
+	if err != nil {
+		return err
+	}
+	container := &spec.Containers[0]
+	if len(resources) > 0 {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = coreV1.ResourceList{}
+		}
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = coreV1.ResourceList{}
+		}
+		// 扩展资源不允许超售，requests 必须与 limits 相等
+		for name, quantity := range resources {
+			container.Resources.Limits[name] = quantity
+			container.Resources.Requests[name] = quantity
+		}
+	}
+
+	if len(opts.Tolerations) > 0 {
+		spec.Tolerations = append(spec.Tolerations, opts.Tolerations...)
+	}
+	if opts.NodeAffinity != nil || opts.PodAffinity != nil || opts.PodAntiAffinity != nil {
+		spec.Affinity = &coreV1.Affinity{
+			NodeAffinity:    opts.NodeAffinity,
+			PodAffinity:     opts.PodAffinity,
+			PodAntiAffinity: opts.PodAntiAffinity,
+		}
+	}
+	if opts.PriorityClassName != "" {
+		spec.PriorityClassName = opts.PriorityClassName
+	}
+	container.Env = mergeEnv(container.Env, opts.Env)
+	return nil
+}
+
+// mergeEnv 追加环境变量，与已有变量同名时覆盖
+func mergeEnv(envs []coreV1.EnvVar, extra []coreV1.EnvVar) []coreV1.EnvVar {
+	for _, env := range extra {
+		replaced := false
+		for i := range envs {
+			if envs[i].Name == env.Name {
+				envs[i] = env
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
@@ -0,0 +1,198 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+func testSchedulingOptions() kubeDto.SchedulingOptions {
+	return kubeDto.SchedulingOptions{
+		GPU:               "1",
+		ExtendedResources: map[string]string{"example.com/fpga": "2"},
+		Tolerations: []coreV1.Toleration{{
+			Key:      "nvidia.com/gpu",
+			Operator: coreV1.TolerationOpExists,
+			Effect:   coreV1.TaintEffectNoSchedule,
+		}},
+		NodeAffinity: &coreV1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &coreV1.NodeSelector{
+				NodeSelectorTerms: []coreV1.NodeSelectorTerm{{
+					MatchExpressions: []coreV1.NodeSelectorRequirement{{
+						Key:      "pool",
+						Operator: coreV1.NodeSelectorOpIn,
+						Values:   []string{"gpu"},
+					}},
+				}},
+			},
+		},
+		PodAntiAffinity: &coreV1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []coreV1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: coreV1.PodAffinityTerm{
+					LabelSelector: &metaV1.LabelSelector{MatchLabels: map[string]string{"app": "ollama"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				},
+			}},
+		},
+		PriorityClassName: "inference",
+		Env: []coreV1.EnvVar{
+			{Name: "OLLAMA_NUM_PARALLEL", Value: "4"},
+			{Name: "OLLAMA_KEEP_ALIVE", Value: "24h"},
+		},
+	}
+}
+
+func assertScheduling(t *testing.T, spec coreV1.PodSpec, wantEnv map[string]string) {
+	t.Helper()
+	container := spec.Containers[0]
+	for name, want := range map[coreV1.ResourceName]string{resourceNvidiaGPU: "1", "example.com/fpga": "2"} {
+		limit := container.Resources.Limits[name]
+		request := container.Resources.Requests[name]
+		if limit.Cmp(resource.MustParse(want)) != 0 || request.Cmp(resource.MustParse(want)) != 0 {
+			t.Errorf("resource %s: limit %s request %s, want %s", name, limit.String(), request.String(), want)
+		}
+	}
+	if len(spec.Tolerations) != 1 || spec.Tolerations[0].Key != "nvidia.com/gpu" {
+		t.Errorf("unexpected tolerations: %+v", spec.Tolerations)
+	}
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.PodAntiAffinity == nil {
+		t.Fatalf("affinity not rendered: %+v", spec.Affinity)
+	}
+	if spec.Affinity.PodAffinity != nil {
+		t.Errorf("pod affinity should be empty")
+	}
+	if spec.PriorityClassName != "inference" {
+		t.Errorf("priority class = %q", spec.PriorityClassName)
+	}
+	env := make(map[string]string)
+	for _, e := range container.Env {
+		if _, ok := env[e.Name]; ok {
+			t.Errorf("duplicate env %s", e.Name)
+		}
+		env[e.Name] = e.Value
+	}
+	for name, want := range wantEnv {
+		if env[name] != want {
+			t.Errorf("env %s = %q, want %q", name, env[name], want)
+		}
+	}
+}
+
+func TestDeployOllamaScheduling(t *testing.T) {
+	K8s.ClientSet = fake.NewSimpleClientset()
+	in := &kubeDto.OllamaDeployInput{
+		Name:              "ollama-gpu",
+		NameSpace:         "ai",
+		Image:             "ollama/ollama:latest",
+		Port:              11434,
+		Cpu:               "4",
+		Memory:            "16Gi",
+		DeployType:        "deployment",
+		SchedulingOptions: testSchedulingOptions(),
+	}
+	// 同名时覆盖默认环境变量
+	in.Env = append(in.Env, coreV1.EnvVar{Name: "OLLAMA_HOST", Value: "0.0.0.0:8080"})
+	if err := Ollama.DeployOllama(in); err != nil {
+		t.Fatalf("deploy ollama: %v", err)
+	}
+	deploy, err := K8s.ClientSet.AppsV1().Deployments("ai").Get(context.TODO(), "ollama-gpu", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	spec := deploy.Spec.Template.Spec
+	assertScheduling(t, spec, map[string]string{
+		"OLLAMA_HOST":         "0.0.0.0:8080",
+		"OLLAMA_NUM_PARALLEL": "4",
+		"OLLAMA_KEEP_ALIVE":   "24h",
+	})
+	cpu := spec.Containers[0].Resources.Limits[coreV1.ResourceCPU]
+	if cpu.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("cpu limit = %s, want 4", cpu.String())
+	}
+}
+
+func TestDeployOllamaDaemonSetScheduling(t *testing.T) {
+	K8s.ClientSet = fake.NewSimpleClientset()
+	in := &kubeDto.OllamaDeployInput{
+		Name:              "ollama-ds",
+		NameSpace:         "ai",
+		Image:             "ollama/ollama:latest",
+		Port:              11434,
+		DeployType:        "daemonset",
+		SchedulingOptions: testSchedulingOptions(),
+	}
+	if err := Ollama.DeployOllama(in); err != nil {
+		t.Fatalf("deploy ollama: %v", err)
+	}
+	ds, err := K8s.ClientSet.AppsV1().DaemonSets("ai").Get(context.TODO(), "ollama-ds", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get daemonset: %v", err)
+	}
+	assertScheduling(t, ds.Spec.Template.Spec, map[string]string{
+		"OLLAMA_HOST":         "0.0.0.0:11434",
+		"OLLAMA_NUM_PARALLEL": "4",
+	})
+}
+
+func TestDeployKnowledgeScheduling(t *testing.T) {
+	K8s.ClientSet = fake.NewSimpleClientset()
+	for _, deployType := range []string{"deployment", "daemonset"} {
+		in := &kubeDto.KnowledgeDeployInput{
+			Name:              "kb-" + deployType,
+			NameSpace:         "ai",
+			Image:             "chromadb/chroma:latest",
+			Port:              8000,
+			OllamaDeployment:  "ollama-gpu",
+			DeployType:        deployType,
+			SchedulingOptions: testSchedulingOptions(),
+		}
+		if err := Knowledge.DeployKnowledge(in); err != nil {
+			t.Fatalf("deploy knowledge %s: %v", deployType, err)
+		}
+		var spec coreV1.PodSpec
+		if deployType == "daemonset" {
+			ds, err := K8s.ClientSet.AppsV1().DaemonSets("ai").Get(context.TODO(), in.Name, metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("get daemonset: %v", err)
+			}
+			spec = ds.Spec.Template.Spec
+		} else {
+			deploy, err := K8s.ClientSet.AppsV1().Deployments("ai").Get(context.TODO(), in.Name, metaV1.GetOptions{})
+			if err != nil {
+				t.Fatalf("get deployment: %v", err)
+			}
+			spec = deploy.Spec.Template.Spec
+		}
+		assertScheduling(t, spec, map[string]string{
+			"OLLAMA_DEPLOYMENT":   "ollama-gpu",
+			"OLLAMA_NUM_PARALLEL": "4",
+		})
+	}
+}
+
+func TestDeployInvalidSchedulingCreatesNothing(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	K8s.ClientSet = client
+	in := &kubeDto.OllamaDeployInput{
+		Name:              "ollama-bad",
+		NameSpace:         "ai",
+		Image:             "ollama/ollama:latest",
+		Port:              11434,
+		StorageSize:       "10Gi",
+		DeployType:        "deployment",
+		SchedulingOptions: kubeDto.SchedulingOptions{GPU: "one"},
+	}
+	if err := Ollama.DeployOllama(in); err == nil {
+		t.Fatal("expected error for invalid gpu quantity")
+	}
+	if actions := client.Actions(); len(actions) != 0 {
+		t.Errorf("expected no api calls, got %d", len(actions))
+	}
+}
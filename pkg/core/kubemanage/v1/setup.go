@@ -9,6 +9,8 @@ import (
 
 	"github.com/noovertime7/kubemanage/cmd/app/config"
 	"github.com/noovertime7/kubemanage/cmd/app/options"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/ai"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
 	"github.com/noovertime7/kubemanage/pkg/logger"
 	"github.com/noovertime7/kubemanage/pkg/mcpclient"
 )
@@ -30,9 +32,76 @@ func Setup(o *options.Options) {
 	if err := mcpclient.InitFromConfig(config.SysConfig.MCP); err != nil {
 		Log.ErrorWithErr("初始化 MCP 客户端失败", err)
 	}
+	kube.SetUsageRecorder(CoreV1.AI().Usage().Record)
+	kube.SetModelRouter(CoreV1.AI().ModelRoute().Lookup)
+	kube.SetGuardrail(CoreV1.AI().Guardrail().Check)
+	kube.SetChunkingLookup(CoreV1.AI().KnowledgeChunking().Lookup)
+	if config.SysConfig.AI.EmbeddingCache.Enable {
+		setupEmbeddingCache()
+	}
+	if opts := config.SysConfig.AI.AnswerCache; opts.Enable {
+		kube.ConfigureAnswerCache(opts.Threshold, time.Duration(opts.TTL)*time.Minute, opts.MaxEntries)
+	}
+	ingest := config.SysConfig.AI.Ingest
+	ai.ConfigureIngest(ai.IngestOptions{
+		BatchSize:     ingest.BatchSize,
+		Concurrency:   ingest.Concurrency,
+		MaxRetries:    ingest.MaxRetries,
+		RetryInterval: time.Duration(ingest.RetryInterval) * time.Second,
+	})
+	startIngestResume()
+	if err := CoreV1.AI().PullJob().FailInterruptedJobs(runtime.SystemContext); err != nil {
+		Log.ErrorWithErr("标记中断的模型拉取任务失败", err)
+	}
+	if err := CoreV1.AI().Benchmark().FailInterruptedJobs(runtime.SystemContext); err != nil {
+		Log.ErrorWithErr("标记中断的基准测试任务失败", err)
+	}
 	if config.SysConfig.CMDB.HostCheck.HostCheckEnable {
 		startChecker()
 	}
+	if config.SysConfig.AI.Catalog.SyncEnable {
+		startCatalogSync()
+	}
+}
+
+func setupEmbeddingCache() {
+	opts := config.SysConfig.AI.EmbeddingCache
+	capacity := opts.Capacity
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	var store kube.EmbeddingStore
+	if opts.Persistent {
+		store = CoreV1.AI().EmbeddingCache()
+	}
+	kube.ConfigureEmbeddingCache(capacity, store)
+}
+
+func startCatalogSync() {
+	duration := config.SysConfig.AI.Catalog.SyncDuration
+	if duration <= 0 {
+		duration = 5
+	}
+	handler := wait.NewDefaultBackoff(time.Duration(duration) * time.Minute)
+	Log.Infof("start model catalog sync every %d minutes...", duration)
+	go func() {
+		wait.BackoffUntil(func() {
+			if err := CoreV1.AI().Catalog().Reconcile(runtime.SystemContext); err != nil {
+				Log.ErrorWithErr("model catalog sync err", err)
+			}
+		}, handler, true, runtime.SystemContext.Done())
+	}()
+}
+
+// startIngestResume 定期接管租约已过期的文档入库任务，包括服务重启前未完成的任务及其他已停止实例上的任务
+func startIngestResume() {
+	go func() {
+		wait.BackoffUntil(func() {
+			if err := CoreV1.AI().IngestJob().ResumeJobs(runtime.SystemContext); err != nil {
+				Log.ErrorWithErr("恢复未完成的文档入库任务失败", err)
+			}
+		}, wait.NewDefaultBackoff(time.Minute), true, runtime.SystemContext.Done())
+	}()
 }
 
 func startChecker() {
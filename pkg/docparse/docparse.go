@@ -0,0 +1,222 @@
+// Package docparse 从上传的文档中提取纯文本及结构信息，供知识库分块及向量化使用
+package docparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 结构信息在 Section.Metadata 中的键，写入向量库后可用于过滤
+const (
+	// MetaPage PDF 页码，从 1 开始
+	MetaPage = "page"
+	// MetaHeading 标题路径，如 "安装 > 离线安装"
+	MetaHeading = "heading"
+	// MetaSheet 表格所在的工作表
+	MetaSheet = "sheet"
+	// MetaRow 表格行号，从 1 开始，包含表头行
+	MetaRow = "row"
+)
+
+// maxDecodedSize 压缩包内单个文件或压缩流解压后的最大大小，防止解压炸弹
+const maxDecodedSize = 64 << 20
+
+// ErrNoText 文档中没有可提取的文本
+var ErrNoText = errors.New("文档中没有可提取的文本")
+
+// Section 文档中按结构划分的一段文本，Metadata 记录该段在文档中的位置
+type Section struct {
+	Text     string                 `json:"text"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Format 一种文档格式，按扩展名或 MIME 类型识别
+type Format struct {
+	Name       string
+	Extensions []string
+	MIMETypes  []string
+	Parse      func(content []byte) ([]Section, error)
+}
+
+var formats []*Format
+
+func register(f *Format) {
+	formats = append(formats, f)
+}
+
+// Detect 按文件扩展名识别文档格式，没有扩展名时按 Content-Type 识别
+func Detect(fileName, contentType string) (*Format, error) {
+	if ext := strings.ToLower(path.Ext(fileName)); ext != "" {
+		for _, f := range formats {
+			for _, e := range f.Extensions {
+				if e == ext {
+					return f, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("不支持的文件类型: %s，支持的类型: %s", ext, strings.Join(Extensions(), ", "))
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		for _, f := range formats {
+			for _, m := range f.MIMETypes {
+				if m == mediaType {
+					return f, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("无法识别文件 %s 的类型，请使用以下扩展名: %s", fileName, strings.Join(Extensions(), ", "))
+}
+
+// Parse 识别文档格式并提取文本，忽略没有内容的段落
+func Parse(fileName, contentType string, content []byte) ([]Section, error) {
+	f, err := Detect(fileName, contentType)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := parseSafely(f, content)
+	if err != nil {
+		return nil, fmt.Errorf("解析%s文件失败: %v", f.Name, err)
+	}
+	out := sections[:0]
+	for _, s := range sections {
+		if s.Text = cleanText(s.Text); s.Text != "" {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNoText
+	}
+	return out, nil
+}
+
+// parseSafely 调用格式的解析函数，文件内容异常导致的 panic 转为错误返回
+func parseSafely(f *Format, content []byte) (sections []Section, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			sections, err = nil, fmt.Errorf("文件内容损坏: %v", r)
+		}
+	}()
+	return f.Parse(content)
+}
+
+// Extensions 支持的文件扩展名
+func Extensions() []string {
+	var exts []string
+	for _, f := range formats {
+		exts = append(exts, f.Extensions...)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// decodeText 将文本文件转为 UTF-8，不是 UTF-8 时按 GB18030 解码（Excel 导出的中文 CSV 通常为 GBK）
+func decodeText(content []byte) (string, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if bytes.IndexByte(content, 0) >= 0 {
+		return "", errors.New("文件包含二进制内容，不是文本文件")
+	}
+	if utf8.Valid(content) {
+		return string(content), nil
+	}
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(content)
+	if err != nil || bytes.ContainsRune(decoded, utf8.RuneError) {
+		return "", errors.New("无法识别文件编码，请转换为 UTF-8")
+	}
+	return string(decoded), nil
+}
+
+// cleanText 统一换行，去除行尾空白及多余空行
+func cleanText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, isSpace)
+		if strings.TrimSpace(line) == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\u00a0' || r == '\u3000' || r == '\f' || r == '\v'
+}
+
+// headingPath 当前所在的标题层级
+type headingPath struct {
+	levels []int
+	titles []string
+}
+
+// push 进入 level 级标题，同级及更低级的标题出栈
+func (h *headingPath) push(level int, title string) {
+	for len(h.levels) > 0 && h.levels[len(h.levels)-1] >= level {
+		h.levels = h.levels[:len(h.levels)-1]
+		h.titles = h.titles[:len(h.titles)-1]
+	}
+	h.levels = append(h.levels, level)
+	h.titles = append(h.titles, title)
+}
+
+func (h *headingPath) String() string {
+	return strings.Join(h.titles, " > ")
+}
+
+// sectionBuilder 按标题切分段落，每个标题下的内容为一段，段落以标题开头，只有标题没有内容时不生成段落
+type sectionBuilder struct {
+	headings headingPath
+	title    string
+	body     strings.Builder
+	sections []Section
+}
+
+func (b *sectionBuilder) heading(level int, title string) {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return
+	}
+	b.flush()
+	b.headings.push(level, title)
+	b.title = title
+}
+
+func (b *sectionBuilder) line(text string) {
+	b.body.WriteString(text)
+	b.body.WriteString("\n")
+}
+
+func (b *sectionBuilder) flush() {
+	defer b.body.Reset()
+	if strings.TrimSpace(b.body.String()) == "" {
+		return
+	}
+	s := Section{Text: b.body.String()}
+	if b.title != "" {
+		s.Text = b.title + "\n" + s.Text
+		s.Metadata = map[string]interface{}{MetaHeading: b.headings.String()}
+	}
+	b.sections = append(b.sections, s)
+}
+
+func (b *sectionBuilder) result() []Section {
+	b.flush()
+	return b.sections
+}
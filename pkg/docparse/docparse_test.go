@@ -0,0 +1,138 @@
+package docparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		fileName, contentType, want string
+	}{
+		{"manual.PDF", "", "PDF"},
+		{"notes.md", "application/octet-stream", "Markdown"},
+		{"report.xlsx", "", "Excel"},
+		{"upload", "text/html; charset=utf-8", "HTML"},
+		{"blob", "text/plain", "文本"},
+	}
+	for _, c := range cases {
+		f, err := Detect(c.fileName, c.contentType)
+		if err != nil || f.Name != c.want {
+			t.Errorf("Detect(%q, %q) = %v, %v, want %s", c.fileName, c.contentType, f, err, c.want)
+		}
+	}
+	for _, name := range []string{"setup.exe", "old.doc", "photo.png"} {
+		if _, err := Detect(name, ""); err == nil || !strings.Contains(err.Error(), ".pdf") {
+			t.Errorf("Detect(%q) should list supported types, got %v", name, err)
+		}
+	}
+	if _, err := Detect("upload", "application/octet-stream"); err == nil {
+		t.Error("unknown content type without extension should be rejected")
+	}
+}
+
+func TestParsePlainText(t *testing.T) {
+	sections, err := Parse("a.txt", "", []byte("\xef\xbb\xbf第一行  \r\n\r\n\r\n第二行\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 1 || sections[0].Text != "第一行\n\n第二行" || sections[0].Metadata != nil {
+		t.Fatalf("sections = %+v", sections)
+	}
+	if _, err := Parse("a.txt", "", []byte("bin\x00ary")); err == nil {
+		t.Error("binary content should be rejected")
+	}
+	if _, err := Parse("a.txt", "", []byte(" \n\t\n")); err != ErrNoText {
+		t.Errorf("blank file: %v", err)
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	doc := `---
+title: 运维手册
+---
+前言内容
+
+# 安装
+
+## 离线安装 ##
+下载 [安装包](https://example.com/pkg.tgz) 后解压。
+![架构图](arch.png)
+
+` + "```bash\n# 这不是标题\ntar xzf pkg.tgz\n```" + `
+
+## 在线安装
+<!-- TODO -->
+执行 install.sh
+
+# Upgrade
+Run the upgrade job.
+`
+	sections, err := Parse("ops.md", "", []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Text: "前言内容"},
+		{Text: "离线安装\n下载 安装包 后解压。\n架构图\n\n```bash\n# 这不是标题\ntar xzf pkg.tgz\n```", Metadata: map[string]interface{}{MetaHeading: "安装 > 离线安装"}},
+		{Text: "在线安装\n\n执行 install.sh", Metadata: map[string]interface{}{MetaHeading: "安装 > 在线安装"}},
+		{Text: "Upgrade\nRun the upgrade job.", Metadata: map[string]interface{}{MetaHeading: "Upgrade"}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Fatalf("sections =\n%#v\nwant\n%#v", sections, want)
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	doc := `<html><head><title>ignored</title><style>p{}</style></head><body>
+<nav><a href="/">首页</a></nav>
+<h1>产品 <small>手册</small></h1>
+<p>Hello<b>World</b> and   <i>more</i>&nbsp;text.</p>
+<h2>参数</h2>
+<table><tr><th>名称</th><th>默认值</th></tr>
+<tr><td>replicas</td><td>1</td></tr></table>
+<script>alert(1)</script>
+<pre>line 1
+  line 2</pre>
+</body></html>`
+	sections, err := Parse("page.html", "", []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Text: "产品 手册\nHelloWorld and more text.", Metadata: map[string]interface{}{MetaHeading: "产品 手册"}},
+		{Text: "参数\n名称 | 默认值\nreplicas | 1\nline 1\n  line 2", Metadata: map[string]interface{}{MetaHeading: "产品 手册 > 参数"}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Fatalf("sections =\n%#v\nwant\n%#v", sections, want)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("姓名,部门,备注\n张三,研发,\"多行\n备注\"\n\n李四,,\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := Parse("staff.csv", "", []byte(gbk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Text: "姓名: 张三\n部门: 研发\n备注: 多行\n备注", Metadata: map[string]interface{}{MetaRow: 2}},
+		{Text: "姓名: 李四", Metadata: map[string]interface{}{MetaRow: 5}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Fatalf("sections =\n%#v\nwant\n%#v", sections, want)
+	}
+
+	sections, err = Parse("metrics.tsv", "", []byte("name\tvalue\textra\ncpu\t80\tx\ty\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sections[0].Text != "name: cpu\nvalue: 80\nextra: x\n第4列: y" {
+		t.Fatalf("tsv = %q", sections[0].Text)
+	}
+}
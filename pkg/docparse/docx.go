@@ -0,0 +1,172 @@
+package docparse
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register(&Format{
+		Name:       "Word",
+		Extensions: []string{".docx"},
+		MIMETypes:  []string{"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		Parse:      parseDOCX,
+	})
+}
+
+// docxHeadingStyle 内置标题样式名称，中文 Word 中样式 ID 可能为数字，需要按名称判断
+var docxHeadingStyle = regexp.MustCompile(`(?i)^heading\s*([1-9])$`)
+
+type docxStyles struct {
+	Styles []struct {
+		ID   string `xml:"styleId,attr"`
+		Name struct {
+			Val string `xml:"val,attr"`
+		} `xml:"name"`
+		OutlineLvl *struct {
+			Val int `xml:"val,attr"`
+		} `xml:"pPr>outlineLvl"`
+	} `xml:"style"`
+}
+
+// headingLevels 样式 ID 对应的标题级别，大纲级别 0 为一级标题
+func (s *docxStyles) headingLevels() map[string]int {
+	levels := map[string]int{}
+	for _, style := range s.Styles {
+		if m := docxHeadingStyle.FindStringSubmatch(style.Name.Val); m != nil {
+			levels[style.ID], _ = strconv.Atoi(m[1])
+		} else if strings.EqualFold(style.Name.Val, "title") {
+			levels[style.ID] = 1
+		} else if style.OutlineLvl != nil && style.OutlineLvl.Val < 9 {
+			levels[style.ID] = style.OutlineLvl.Val + 1
+		}
+	}
+	return levels
+}
+
+// parseDOCX 按标题样式切分正文，表格逐行提取，单元格以 | 分隔
+func parseDOCX(content []byte) ([]Section, error) {
+	zr, err := openZip(content)
+	if err != nil {
+		return nil, err
+	}
+	var styles docxStyles
+	if err := unmarshalZipXML(zr, "word/styles.xml", &styles, false); err != nil {
+		return nil, err
+	}
+	levels := styles.headingLevels()
+	data, err := readZipFile(zr, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, errors.New("缺少 word/document.xml")
+	}
+
+	var (
+		b      sectionBuilder
+		para   strings.Builder
+		level  int
+		inText bool
+		// 表格可能嵌套，每层记录当前行的单元格及单元格内容
+		rows  [][]string
+		cells []*strings.Builder
+	)
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				level = 0
+			case "pStyle":
+				if l, ok := levels[xmlAttr(t, "val")]; ok {
+					level = l
+				}
+			case "outlineLvl":
+				if l, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && l < 9 {
+					level = l + 1
+				}
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			case "tr":
+				rows = append(rows, nil)
+			case "tc":
+				cells = append(cells, &strings.Builder{})
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := para.String()
+				switch {
+				case len(cells) > 0:
+					cell := cells[len(cells)-1]
+					if cell.Len() > 0 {
+						cell.WriteString(" ")
+					}
+					cell.WriteString(strings.TrimSpace(text))
+				case level > 0:
+					b.heading(level, text)
+				default:
+					b.line(text)
+				}
+			case "tc":
+				if len(cells) == 0 || len(rows) == 0 {
+					break
+				}
+				cell := cells[len(cells)-1].String()
+				cells = cells[:len(cells)-1]
+				rows[len(rows)-1] = append(rows[len(rows)-1], cell)
+			case "tr":
+				if len(rows) == 0 {
+					break
+				}
+				cellTexts := rows[len(rows)-1]
+				rows = rows[:len(rows)-1]
+				if isEmptyRow(cellTexts) {
+					break
+				}
+				row := strings.Join(cellTexts, " | ")
+				if len(cells) > 0 {
+					// 嵌套表格的行写入外层单元格
+					cells[len(cells)-1].WriteString(" " + row)
+				} else {
+					b.line(row)
+				}
+			}
+		}
+	}
+	return b.result(), nil
+}
+
+func xmlAttr(t xml.StartElement, local string) string {
+	for _, attr := range t.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package docparse
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func init() {
+	register(&Format{
+		Name:       "HTML",
+		Extensions: []string{".html", ".htm"},
+		MIMETypes:  []string{"text/html", "application/xhtml+xml"},
+		Parse:      parseHTML,
+	})
+}
+
+// htmlSkipped 不包含正文的元素
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Iframe: true, atom.Nav: true,
+	atom.Button: true, atom.Select: true, atom.Textarea: true,
+}
+
+// htmlBlocks 块级元素，前后换行
+var htmlBlocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Table: true, atom.Tr: true, atom.Figure: true, atom.Figcaption: true, atom.Hr: true,
+	atom.Br: true, atom.Form: true, atom.Fieldset: true, atom.Caption: true, atom.Details: true,
+	atom.Summary: true,
+}
+
+var htmlHeadings = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// parseHTML 按 h1-h6 切分，去除脚本、样式及导航，表格单元格以 | 分隔
+func parseHTML(content []byte) ([]Section, error) {
+	text, err := decodeText(content)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	w := &htmlWriter{}
+	w.walk(doc)
+	w.newline()
+	return w.b.result(), nil
+}
+
+type htmlWriter struct {
+	b    sectionBuilder
+	line bytes.Buffer
+	pre  int
+}
+
+func (w *htmlWriter) newline() {
+	if w.line.Len() > 0 {
+		w.b.line(w.line.String())
+		w.line.Reset()
+	}
+}
+
+func (w *htmlWriter) text(s string) {
+	if w.pre > 0 {
+		lines := strings.Split(s, "\n")
+		for i, l := range lines {
+			if i > 0 {
+				w.newline()
+			}
+			w.line.WriteString(l)
+		}
+		return
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			w.space()
+		}
+		return
+	}
+	if first, _ := utf8.DecodeRuneInString(s); unicode.IsSpace(first) {
+		w.space()
+	}
+	w.line.WriteString(strings.Join(fields, " "))
+	if last, _ := utf8.DecodeLastRuneInString(s); unicode.IsSpace(last) {
+		w.space()
+	}
+}
+
+func (w *htmlWriter) space() {
+	if b := w.line.Bytes(); len(b) > 0 && b[len(b)-1] != ' ' {
+		w.line.WriteByte(' ')
+	}
+}
+
+func (w *htmlWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.CommentNode:
+		return
+	case html.ElementNode:
+		if htmlSkipped[n.DataAtom] {
+			return
+		}
+		if level, ok := htmlHeadings[n.DataAtom]; ok {
+			w.newline()
+			w.b.heading(level, nodeText(n))
+			return
+		}
+	}
+
+	block := n.Type == html.ElementNode && htmlBlocks[n.DataAtom]
+	if block {
+		w.newline()
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre--
+	}
+	switch {
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		if nextElement(n) != nil {
+			w.line.WriteString(" | ")
+		}
+	case block:
+		w.newline()
+	}
+}
+
+// nodeText 元素内的全部文本
+func nodeText(n *html.Node) string {
+	var buf strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return buf.String()
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for c := n.NextSibling; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package docparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+
+func wordParagraph(style, text string) string {
+	var pPr string
+	if style != "" {
+		pPr = `<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`
+	}
+	return `<w:p>` + pPr + `<w:r><w:t xml:space="preserve">` + text + `</w:t></w:r></w:p>`
+}
+
+func TestParseDOCX(t *testing.T) {
+	// 中文版 Word 的标题样式 ID 为数字，只能通过样式名称识别
+	styles := `<w:styles ` + wordNS + `>
+<w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/></w:style>
+<w:style w:type="paragraph" w:styleId="2"><w:name w:val="heading 2"/></w:style>
+<w:style w:type="paragraph" w:styleId="a3"><w:name w:val="List Paragraph"/></w:style>
+</w:styles>`
+	document := `<w:document ` + wordNS + `><w:body>` +
+		wordParagraph("", "封面说明") +
+		wordParagraph("1", "部署") +
+		wordParagraph("2", "资源要求") +
+		`<w:p><w:r><w:t>至少</w:t></w:r><w:r><w:delText>两</w:delText><w:t>4</w:t></w:r><w:r><w:tab/><w:t>核</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc>` + wordParagraph("", "组件") + `</w:tc><w:tc>` + wordParagraph("", "内存") + `</w:tc></w:tr>` +
+		`<w:tr><w:tc>` + wordParagraph("", "api") + wordParagraph("", "server") + `</w:tc><w:tc>` + wordParagraph("", "2Gi") + `</w:tc></w:tr></w:tbl>` +
+		wordParagraph("a3", "列表项") +
+		`<w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t>FAQ</w:t></w:r></w:p>` +
+		wordParagraph("", "Contact the platform team.") +
+		`</w:body></w:document>`
+	content := zipFiles(t, map[string]string{"word/document.xml": document, "word/styles.xml": styles})
+
+	sections, err := Parse("部署手册.docx", "", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Text: "封面说明"},
+		{Text: "资源要求\n至少4\t核\n组件 | 内存\napi server | 2Gi\n列表项", Metadata: map[string]interface{}{MetaHeading: "部署 > 资源要求"}},
+		{Text: "FAQ\nContact the platform team.", Metadata: map[string]interface{}{MetaHeading: "FAQ"}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Fatalf("sections =\n%#v\nwant\n%#v", sections, want)
+	}
+
+	if _, err := Parse("legacy.docx", "", []byte("\xd0\xcf\x11\xe0 not a zip")); err == nil || !strings.Contains(err.Error(), ".doc") {
+		t.Fatalf("legacy word file: %v", err)
+	}
+}
+
+func TestParseXLSX(t *testing.T) {
+	workbook := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="节点" sheetId="1" r:id="rId1"/><sheet name="hidden" sheetId="2" state="hidden" r:id="rId2"/><sheet name="Quota" sheetId="3" r:id="rId3"/></sheets></workbook>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="worksheets/sheet2.xml"/><Relationship Id="rId3" Target="/xl/worksheets/sheet3.xml"/></Relationships>`
+	shared := `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>主机名</t></si><si><t>GPU</t></si><si><r><t>node</t></r><r><t>-1</t></r></si></sst>`
+	sheet1 := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3" t="inlineStr"><is><t>备用</t></is></c></row>
+<row r="4"><c r="B4" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`
+	sheet3 := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="2"><c r="A2" t="str"><v>team</v></c><c r="B2" t="str"><v>limit</v></c></row>
+<row r="3"><c r="A3" t="str"><v>ai</v></c><c r="B3"><f>SUM(1,2)</f><v>3</v></c></row>
+</sheetData></worksheet>`
+	content := zipFiles(t, map[string]string{
+		"xl/workbook.xml":            workbook,
+		"xl/_rels/workbook.xml.rels": rels,
+		"xl/sharedStrings.xml":       shared,
+		"xl/worksheets/sheet1.xml":   sheet1,
+		"xl/worksheets/sheet2.xml":   `<worksheet><sheetData><row r="1"><c t="inlineStr"><is><t>secret</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet3.xml":   sheet3,
+	})
+
+	sections, err := Parse("inventory.xlsx", "", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Text: "主机名: node-1\n第3列: 备用", Metadata: map[string]interface{}{MetaSheet: "节点", MetaRow: 3}},
+		{Text: "GPU: TRUE", Metadata: map[string]interface{}{MetaSheet: "节点", MetaRow: 4}},
+		{Text: "team: ai\nlimit: 3", Metadata: map[string]interface{}{MetaSheet: "Quota", MetaRow: 3}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Fatalf("sections =\n%#v\nwant\n%#v", sections, want)
+	}
+}
@@ -0,0 +1,722 @@
+package docparse
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+func init() {
+	register(&Format{
+		Name:       "PDF",
+		Extensions: []string{".pdf"},
+		MIMETypes:  []string{"application/pdf"},
+		Parse:      parsePDF,
+	})
+}
+
+// parsePDF 逐页提取文本，每页一段并记录页码；扫描件没有文本层，需要先 OCR
+func parsePDF(content []byte) ([]Section, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, "\x00\t\n\r "), []byte("%PDF-")) {
+		return nil, errors.New("文件不是有效的 PDF")
+	}
+	doc := loadPDF(content)
+	if doc.trailer["Encrypt"] != nil {
+		return nil, errors.New("PDF 已加密，请先解除密码保护")
+	}
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return nil, errors.New("PDF 中没有找到页面")
+	}
+	var sections []Section
+	for i, page := range pages {
+		text := doc.pageText(page)
+		if text == "" {
+			continue
+		}
+		sections = append(sections, Section{Text: text, Metadata: map[string]interface{}{MetaPage: i + 1}})
+	}
+	if len(sections) == 0 {
+		return nil, errors.New("PDF 中没有可提取的文本，可能是扫描件，请先进行 OCR")
+	}
+	return sections, nil
+}
+
+type (
+	pdfName    string
+	pdfString  []byte
+	pdfKeyword string
+	pdfRef     struct{ Num, Gen int }
+	pdfDict    map[pdfName]interface{}
+	pdfArray   []interface{}
+)
+
+// pdfStream 流对象，Data 为未解码的原始数据
+type pdfStream struct {
+	Dict pdfDict
+	Data []byte
+}
+
+// pdfMaxDepth 数组及字典的最大嵌套层数
+const pdfMaxDepth = 64
+
+// pdfLexer 解析 PDF 对象语法，同时用于页面内容流及 CMap
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// regular 读取到下一个空白或分隔符
+func (l *pdfLexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	return l.data[start:l.pos]
+}
+
+// next 读取下一个对象，数组及字典递归解析，"整数 整数 R" 解析为引用，其余单词返回 pdfKeyword
+func (l *pdfLexer) next() (interface{}, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		return pdfName(unescapeName(l.regular())), nil
+	case c == '(':
+		l.pos++
+		return l.literalString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dict()
+	case c == '<':
+		l.pos++
+		return l.hexString(), nil
+	case c == '[':
+		l.pos++
+		return l.array()
+	case isPDFDelim(c):
+		l.pos++
+		if c == '>' && l.pos < len(l.data) && l.data[l.pos] == '>' {
+			l.pos++
+			return pdfKeyword(">>"), nil
+		}
+		return pdfKeyword([]byte{c}), nil
+	}
+	word := l.regular()
+	if len(word) == 0 {
+		l.pos++
+		return pdfKeyword([]byte{c}), nil
+	}
+	if f, err := strconv.ParseFloat(string(word), 64); err == nil {
+		if !bytes.ContainsAny(word, ".eE") {
+			if ref, ok := l.ref(int(f)); ok {
+				return ref, nil
+			}
+		}
+		return f, nil
+	}
+	switch string(word) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(word), nil
+}
+
+// ref 尝试将 num 与其后的 "gen R" 解析为引用，失败时恢复读取位置
+func (l *pdfLexer) ref(num int) (pdfRef, bool) {
+	save := l.pos
+	l.skipSpace()
+	gen, err := strconv.Atoi(string(l.regular()))
+	if err == nil {
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelim(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{Num: num, Gen: gen}, true
+		}
+	}
+	l.pos = save
+	return pdfRef{}, false
+}
+
+func (l *pdfLexer) array() (interface{}, error) {
+	if l.depth++; l.depth > pdfMaxDepth {
+		return nil, errors.New("PDF 对象嵌套过深")
+	}
+	defer func() { l.depth-- }()
+	arr := pdfArray{}
+	for {
+		obj, err := l.next()
+		if err != nil {
+			return arr, err
+		}
+		if obj == pdfKeyword("]") {
+			return arr, nil
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (l *pdfLexer) dict() (interface{}, error) {
+	if l.depth++; l.depth > pdfMaxDepth {
+		return nil, errors.New("PDF 对象嵌套过深")
+	}
+	defer func() { l.depth-- }()
+	dict := pdfDict{}
+	for {
+		key, err := l.next()
+		if err != nil {
+			return dict, err
+		}
+		if key == pdfKeyword(">>") {
+			return dict, nil
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue
+		}
+		value, err := l.next()
+		if err != nil {
+			return dict, err
+		}
+		if value == pdfKeyword(">>") {
+			return dict, nil
+		}
+		dict[name] = value
+	}
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+		case '\r':
+			// 字符串中的换行统一为 \n
+			if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+				l.pos++
+			}
+			c = '\n'
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	n, _ := hex.Decode(out, digits)
+	return out[:n]
+}
+
+func unescapeName(b []byte) string {
+	if !bytes.ContainsRune(b, '#') {
+		return string(b)
+	}
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+// pdfDoc 按顺序扫描文件中的全部对象，不依赖 xref 表，增量更新时后出现的对象覆盖之前的
+type pdfDoc struct {
+	objects map[int]interface{}
+	trailer pdfDict
+	fonts   map[int]*pdfFont
+	// decoded 已解码的流数据总大小，同一个流可以被多个页面或在 Contents 中重复引用
+	decoded int
+}
+
+// maxPDFDecodedSize 单个 PDF 所有流解码后的总大小上限
+const maxPDFDecodedSize = 4 * maxDecodedSize
+
+// PNG 预测器参数上限
+const (
+	maxPredictorBPC    = 16
+	maxPredictorColors = 32
+)
+
+var (
+	pdfObjectHeader = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfTrailer      = []byte("trailer")
+	pdfEndStream    = []byte("endstream")
+)
+
+func loadPDF(data []byte) *pdfDoc {
+	doc := &pdfDoc{objects: map[int]interface{}{}, trailer: pdfDict{}, fonts: map[int]*pdfFont{}}
+	for pos := 0; pos < len(data); {
+		loc := pdfObjectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		l := &pdfLexer{data: data, pos: pos + loc[1]}
+		obj, err := l.next()
+		if err != nil {
+			pos += loc[1]
+			continue
+		}
+		if dict, ok := obj.(pdfDict); ok {
+			if stream, end := readStream(data, l.pos, dict); stream != nil {
+				obj = stream
+				l.pos = end
+			}
+		}
+		doc.objects[num] = obj
+		if stream, ok := obj.(*pdfStream); ok {
+			switch stream.Dict["Type"] {
+			case pdfName("ObjStm"):
+				doc.loadObjectStream(stream)
+			case pdfName("XRef"):
+				doc.mergeTrailer(stream.Dict)
+			}
+		}
+		pos = l.pos
+	}
+	for pos := 0; ; {
+		idx := bytes.Index(data[pos:], pdfTrailer)
+		if idx < 0 {
+			break
+		}
+		l := &pdfLexer{data: data, pos: pos + idx + len(pdfTrailer)}
+		if dict, err := l.next(); err == nil {
+			if d, ok := dict.(pdfDict); ok {
+				doc.mergeTrailer(d)
+			}
+		}
+		pos += idx + len(pdfTrailer)
+	}
+	return doc
+}
+
+// readStream dict 之后为 stream 关键字时读取流数据，Length 不可用时查找 endstream
+func readStream(data []byte, pos int, dict pdfDict) (*pdfStream, int) {
+	l := &pdfLexer{data: data, pos: pos}
+	l.skipSpace()
+	if !bytes.HasPrefix(data[l.pos:], []byte("stream")) {
+		return nil, pos
+	}
+	start := l.pos + len("stream")
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+	// Length 超出剩余数据时按 endstream 查找，先比较再转换，避免超大数值转换为 int 后溢出
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && n <= float64(len(data)-start) {
+		end := start + int(n)
+		if bytes.HasPrefix(bytes.TrimLeft(data[end:], "\r\n \t"), pdfEndStream) {
+			return &pdfStream{Dict: dict, Data: data[start:end]}, end
+		}
+	}
+	idx := bytes.Index(data[start:], pdfEndStream)
+	if idx < 0 {
+		return &pdfStream{Dict: dict, Data: data[start:]}, len(data)
+	}
+	end := start + idx
+	return &pdfStream{Dict: dict, Data: bytes.TrimRight(data[start:end], "\r\n")}, end + len(pdfEndStream)
+}
+
+func (d *pdfDoc) mergeTrailer(dict pdfDict) {
+	for _, key := range []pdfName{"Root", "Encrypt"} {
+		if v, ok := dict[key]; ok {
+			d.trailer[key] = v
+		}
+	}
+}
+
+// loadObjectStream 展开 PDF 1.5 对象流中压缩存储的对象
+func (d *pdfDoc) loadObjectStream(stream *pdfStream) {
+	data, err := d.decode(stream)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(stream.Dict["N"]).(float64)
+	first, _ := d.resolve(stream.Dict["First"]).(float64)
+	header := &pdfLexer{data: data}
+	for i := 0; i < int(n); i++ {
+		num, err1 := header.next()
+		offset, err2 := header.next()
+		if err1 != nil || err2 != nil {
+			return
+		}
+		numF, _ := num.(float64)
+		offsetF, _ := offset.(float64)
+		pos := int(first) + int(offsetF)
+		if pos < 0 || pos >= len(data) {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: pos}
+		if obj, err := l.next(); err == nil {
+			d.objects[int(numF)] = obj
+		}
+	}
+}
+
+// resolve 解析引用，非引用直接返回
+func (d *pdfDoc) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.objects[ref.Num]
+	}
+	return nil
+}
+
+func (d *pdfDoc) dict(v interface{}) pdfDict {
+	switch t := d.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.Dict
+	}
+	return nil
+}
+
+// decode 按 Filter 解码流数据，解码结果计入文档的解码总量
+func (d *pdfDoc) decode(stream *pdfStream) ([]byte, error) {
+	data, err := d.decodeFilters(stream)
+	if err != nil {
+		return nil, err
+	}
+	if d.decoded += len(data); d.decoded > maxPDFDecodedSize {
+		return nil, fmt.Errorf("PDF 流解码后总大小超过 %d MB", maxPDFDecodedSize>>20)
+	}
+	return data, nil
+}
+
+// decodeLimit 单个流解码后允许的最大大小
+func (d *pdfDoc) decodeLimit() int {
+	if remain := maxPDFDecodedSize - d.decoded; remain < maxDecodedSize {
+		return remain
+	}
+	return maxDecodedSize
+}
+
+func (d *pdfDoc) decodeFilters(stream *pdfStream) ([]byte, error) {
+	data := stream.Data
+	filters := d.resolve(stream.Dict["Filter"])
+	parms := d.resolve(stream.Dict["DecodeParms"])
+	list, ok := filters.(pdfArray)
+	if !ok {
+		list = pdfArray{filters}
+	}
+	parmList, ok := parms.(pdfArray)
+	if !ok {
+		parmList = pdfArray{parms}
+	}
+	for i, f := range list {
+		var parm pdfDict
+		if i < len(parmList) {
+			parm = d.dict(parmList[i])
+		}
+		var err error
+		switch d.resolve(f) {
+		case nil:
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			if data, err = inflate(data, d.decodeLimit()); err == nil {
+				data, err = d.unpredict(data, parm)
+			}
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			l := &pdfLexer{data: append(bytes.TrimSpace(data), '>')}
+			data = l.hexString()
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("不支持的 PDF 压缩方式: %v", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate 解压 zlib 数据，最多解压 limit 字节，流末尾损坏时保留已解压的内容
+func inflate(data []byte, limit int) ([]byte, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("PDF 流解码后总大小超过 %d MB", maxPDFDecodedSize>>20)
+	}
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if len(out) > limit {
+		return nil, fmt.Errorf("PDF 流解压后超过 %d MB", limit>>20)
+	}
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, len(data))
+	n, _, err := ascii85.Decode(out, data, true)
+	return out[:n], err
+}
+
+// unpredict 还原 PNG 预测器（Predictor >= 10），xref 流及部分内容流使用
+func (d *pdfDoc) unpredict(data []byte, parm pdfDict) ([]byte, error) {
+	predictor, _ := d.resolve(parm["Predictor"]).(float64)
+	if predictor < 10 {
+		return data, nil
+	}
+	// 参数先按上限检查再转换为 int，每列至少占 1 字节，Columns 不会超过解码后的长度
+	columns, colors, bpc := 1, 1, 8
+	if v, ok := d.resolve(parm["Columns"]).(float64); ok && v > 0 {
+		if v > float64(len(data)) {
+			return nil, fmt.Errorf("PNG 预测器 Columns 无效: %v", v)
+		}
+		columns = int(v)
+	}
+	if v, ok := d.resolve(parm["Colors"]).(float64); ok && v > 0 {
+		if v > maxPredictorColors {
+			return nil, fmt.Errorf("PNG 预测器 Colors 无效: %v", v)
+		}
+		colors = int(v)
+	}
+	if v, ok := d.resolve(parm["BitsPerComponent"]).(float64); ok && v > 0 {
+		if v > maxPredictorBPC {
+			return nil, fmt.Errorf("PNG 预测器 BitsPerComponent 无效: %v", v)
+		}
+		bpc = int(v)
+	}
+	bpp := (colors*bpc + 7) / 8
+	rowLen := (columns*colors*bpc + 7) / 8
+	if rowLen > len(data) {
+		return nil, fmt.Errorf("PNG 预测器每行 %d 字节，超过数据长度 %d", rowLen, len(data))
+	}
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for i := 0; i+1+rowLen <= len(data); i += 1 + rowLen {
+		kind, row := data[i], append([]byte(nil), data[i+1:i+1+rowLen]...)
+		for j := range row {
+			var left, upLeft byte
+			if j >= bpp {
+				left, upLeft = row[j-bpp], prev[j-bpp]
+			}
+			up := prev[j]
+			switch kind {
+			case 1:
+				row[j] += left
+			case 2:
+				row[j] += up
+			case 3:
+				row[j] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[j] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// pdfPage 页面及其（可能继承自上级节点的）资源
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages 按页面树顺序返回所有页面
+func (d *pdfDoc) pages() []pdfPage {
+	root := d.dict(d.trailer["Root"])
+	if root == nil {
+		for _, obj := range d.objects {
+			if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+				root = dict
+				break
+			}
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	var (
+		pages   []pdfPage
+		visited = map[int]bool{}
+		walk    func(node interface{}, resources pdfDict, depth int)
+	)
+	walk = func(node interface{}, resources pdfDict, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.Num] {
+				return
+			}
+			visited[ref.Num] = true
+		}
+		dict := d.dict(node)
+		if dict == nil || depth > pdfMaxDepth {
+			return
+		}
+		if res := d.dict(dict["Resources"]); res != nil {
+			resources = res
+		}
+		if kids, ok := d.resolve(dict["Kids"]).(pdfArray); ok {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+			return
+		}
+		pages = append(pages, pdfPage{dict: dict, resources: resources})
+	}
+	walk(root["Pages"], nil, 0)
+	return pages
+}
+
+// pageText 解码页面的全部内容流并提取文本
+func (d *pdfDoc) pageText(page pdfPage) string {
+	var contents []byte
+	streams, ok := d.resolve(page.dict["Contents"]).(pdfArray)
+	if !ok {
+		streams = pdfArray{page.dict["Contents"]}
+	}
+	for _, s := range streams {
+		stream, ok := d.resolve(s).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := d.decode(stream)
+		if err != nil {
+			continue
+		}
+		contents = append(contents, data...)
+		contents = append(contents, '\n')
+	}
+	w := &pdfTextWriter{doc: d}
+	w.run(contents, page.resources, 0)
+	return cleanText(w.buf.String())
+}
@@ -0,0 +1,180 @@
+package docparse
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pdfBuilder 生成测试用的 PDF，不写 xref 表，解析时不依赖 xref
+type pdfBuilder struct {
+	buf bytes.Buffer
+}
+
+func newPDFBuilder() *pdfBuilder {
+	b := &pdfBuilder{}
+	b.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	return b
+}
+
+func (b *pdfBuilder) obj(num int, body string) {
+	fmt.Fprintf(&b.buf, "%d 0 obj\n%s\nendobj\n", num, body)
+}
+
+// stream 写入流对象，compress 时使用 FlateDecode，length 为空时写入实际长度
+func (b *pdfBuilder) stream(num int, dict string, data []byte, compress bool, length string) {
+	if compress {
+		var z bytes.Buffer
+		w := zlib.NewWriter(&z)
+		w.Write(data)
+		w.Close()
+		data = z.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	if length == "" {
+		length = fmt.Sprint(len(data))
+	}
+	fmt.Fprintf(&b.buf, "%d 0 obj\n<< %s /Length %s >>\nstream\n", num, dict, length)
+	b.buf.Write(data)
+	b.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (b *pdfBuilder) bytes(trailer string) []byte {
+	fmt.Fprintf(&b.buf, "trailer\n%s\n%%%%EOF\n", trailer)
+	return b.buf.Bytes()
+}
+
+const testToUnicode = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0001> <5B89>
+<0002> <88C5>
+endbfchar
+2 beginbfrange
+<0010> <0011> [<6B65> <9AA4>]
+<0020> <0022> <0031>
+endbfrange
+endcmap
+end
+end`
+
+func TestParsePDF(t *testing.T) {
+	b := newPDFBuilder()
+	b.obj(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	b.obj(2, "<< /Type /Pages /Kids [3 0 R 4 0 R 9 0 R] /Count 3 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> /XObject << /Fm1 13 0 R >> >> >>")
+	b.obj(3, "<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>")
+	b.obj(4, "<< /Type /Page /Parent 2 0 R /Contents [8 0 R] >>")
+	b.obj(9, "<< /Type /Page /Parent 2 0 R /Contents 10 0 R >>")
+	b.obj(6, "<< /Type /Font /Subtype /Type0 /BaseFont /SimSun /Encoding /Identity-H /ToUnicode 11 0 R >>")
+	b.stream(11, "", []byte(testToUnicode), true, "")
+	// 西文字体放在对象流中，\037 通过 Differences 映射为 fi 连字
+	font := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /BaseEncoding /WinAnsiEncoding /Differences [31 /fi] >> >>"
+	header := "5 0 "
+	b.stream(12, fmt.Sprintf("/Type /ObjStm /N 1 /First %d", len(header)), []byte(header+font), true, "")
+	page1 := "q BI /W 1 /H 1 /CS /G /BPC 8 ID \xff\x00) EI Q\n" +
+		"BT /F1 12 Tf 72 720 Td (Installation Guide) Tj 0 -14 Td [(Run the) -300 (installer) 120 (.)] TJ\n" +
+		"0 -14 Td (\\037le \\(ready\\)) Tj ET\n/Fm1 Do"
+	b.stream(7, "", []byte(page1), false, "14 0 R")
+	b.obj(14, fmt.Sprint(len(page1)))
+	b.stream(13, "/Type /XObject /Subtype /Form /BBox [0 0 100 100]", []byte("BT /F1 8 Tf 1 0 0 1 72 30 Tm (Confidential) Tj ET"), false, "")
+	page2 := "BT /F2 10.5 Tf 1 0 0 1 72 700 Tm <00010002> Tj 1 0 0 1 120 700 Tm <00100011> Tj\n" +
+		"1 0 0 1 72 680 Tm [<0020> 50 <00210022>] TJ ET"
+	b.stream(8, "", []byte(page2), true, "")
+	b.stream(10, "", []byte("q 100 0 0 100 0 0 cm /Im1 Do Q"), false, "")
+	content := b.bytes("<< /Size 15 /Root 1 0 R >>")
+
+	sections, err := Parse("manual.pdf", "application/pdf", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Text: "Installation Guide\nRun the installer.\nfile (ready)\nConfidential", Metadata: map[string]interface{}{MetaPage: 1}},
+		{Text: "安装步骤\n123", Metadata: map[string]interface{}{MetaPage: 2}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Fatalf("sections =\n%#v\nwant\n%#v", sections, want)
+	}
+}
+
+func TestParsePDFErrors(t *testing.T) {
+	if _, err := Parse("fake.pdf", "", []byte("hello")); err == nil {
+		t.Error("non-pdf content should be rejected")
+	}
+
+	b := newPDFBuilder()
+	b.obj(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	b.obj(2, "<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	b.obj(3, "<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>")
+	b.stream(4, "", []byte("q 100 0 0 100 0 0 cm /Im1 Do Q"), false, "")
+	scanned := b.bytes("<< /Size 5 /Root 1 0 R >>")
+	if _, err := Parse("scan.pdf", "", scanned); err == nil || !strings.Contains(err.Error(), "OCR") {
+		t.Errorf("scanned pdf: %v", err)
+	}
+
+	encrypted := bytes.Replace(scanned, []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt 9 0 R"), 1)
+	if _, err := Parse("secret.pdf", "", encrypted); err == nil || !strings.Contains(err.Error(), "加密") {
+		t.Errorf("encrypted pdf: %v", err)
+	}
+}
+
+func TestParsePDFMalformed(t *testing.T) {
+	cases := map[string][]byte{
+		"huge length":     []byte("%PDF-1.7\n4 0 obj\n<< /Length 1e19 >>\nstream\nabc\nendstream\nendobj\n"),
+		"negative length": []byte("%PDF-1.7\n4 0 obj\n<< /Length -1e19 >>\nstream\nabc\nendstream\nendobj\n"),
+	}
+	for _, predictor := range []string{
+		"/Predictor 12 /Columns 1e19",
+		"/Predictor 12 /Colors 1e19",
+		"/Predictor 12 /BitsPerComponent 1e19",
+		"/Predictor 12 /Columns 1000 /Colors 32 /BitsPerComponent 16",
+	} {
+		b := newPDFBuilder()
+		b.obj(1, "<< /Type /Catalog /Pages 2 0 R >>")
+		b.obj(2, "<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+		b.obj(3, "<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>")
+		b.stream(4, "/DecodeParms << "+predictor+" >>", []byte("\x00BT (text) Tj ET"), true, "")
+		cases[predictor] = b.bytes("<< /Size 5 /Root 1 0 R >>")
+	}
+	for name, content := range cases {
+		if _, err := Parse("bad.pdf", "", content); err == nil {
+			t.Errorf("%s: malformed pdf should be rejected", name)
+		}
+	}
+}
+
+func TestPDFDecodeLimit(t *testing.T) {
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	w.Write(make([]byte, maxDecodedSize/16))
+	w.Close()
+	stream := &pdfStream{Dict: pdfDict{"Filter": pdfName("FlateDecode")}, Data: z.Bytes()}
+
+	// 同一个流被重复引用时，累计解码量超过上限后不再解码
+	doc := &pdfDoc{objects: map[int]interface{}{}}
+	for i := 0; ; i++ {
+		if _, err := doc.decode(stream); err != nil {
+			if i != maxPDFDecodedSize/(maxDecodedSize/16) {
+				t.Fatalf("decode stopped after %d streams: %v", i, err)
+			}
+			break
+		}
+	}
+}
+
+func TestParseRecover(t *testing.T) {
+	register(&Format{Name: "Panic", Extensions: []string{".panic"}, Parse: func([]byte) ([]Section, error) {
+		var s []Section
+		return []Section{s[1]}, nil
+	}})
+	defer func() { formats = formats[:len(formats)-1] }()
+	if _, err := Parse("bad.panic", "", nil); err == nil {
+		t.Error("panic in parser should be returned as error")
+	}
+}
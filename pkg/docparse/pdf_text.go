@@ -0,0 +1,499 @@
+package docparse
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// pdfFont 将字符串中的字符编码转换为 Unicode
+// 优先使用 ToUnicode，其次为预定义的 Unicode/GBK CMap 及简单字体的编码表
+type pdfFont struct {
+	toUnicode *pdfCMap
+	// composite 复合字体，没有 ToUnicode 且使用 Identity 编码时无法还原文本
+	composite bool
+	charset   string
+	encoding  map[byte]string
+}
+
+const (
+	pdfCharsetUTF16 = "utf16"
+	pdfCharsetUTF8  = "utf8"
+	pdfCharsetGBK   = "gbk"
+)
+
+func (f *pdfFont) decode(s []byte) string {
+	switch {
+	case f == nil:
+		out, _ := charmap.Windows1252.NewDecoder().Bytes(s)
+		return string(out)
+	case f.toUnicode != nil:
+		return f.toUnicode.decode(s)
+	case f.charset == pdfCharsetUTF16:
+		return decodeUTF16(s)
+	case f.charset == pdfCharsetUTF8:
+		return string(s)
+	case f.charset == pdfCharsetGBK:
+		out, _ := simplifiedchinese.GB18030.NewDecoder().Bytes(s)
+		return string(out)
+	case f.composite:
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range s {
+		if v, ok := f.encoding[c]; ok {
+			b.WriteString(v)
+			continue
+		}
+		b.WriteRune(charmap.Windows1252.DecodeByte(c))
+	}
+	return b.String()
+}
+
+func decodeUTF16(s []byte) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// font 创建字体，通过引用的字体对象按对象号缓存
+func (d *pdfDoc) font(v interface{}) *pdfFont {
+	ref, isRef := v.(pdfRef)
+	if isRef {
+		if f, ok := d.fonts[ref.Num]; ok {
+			return f
+		}
+	}
+	dict := d.dict(v)
+	if dict == nil {
+		return nil
+	}
+	f := &pdfFont{}
+	if stream, ok := d.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := d.decode(stream); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+	if dict["Subtype"] == pdfName("Type0") {
+		f.composite = true
+		if name, ok := d.resolve(dict["Encoding"]).(pdfName); ok {
+			f.charset = cmapCharset(string(name))
+		}
+	} else {
+		f.encoding = d.simpleEncoding(d.resolve(dict["Encoding"]))
+	}
+	if isRef {
+		d.fonts[ref.Num] = f
+	}
+	return f
+}
+
+// cmapCharset 预定义 CMap 对应的字符集，Identity 等 CID 编码返回空
+func cmapCharset(name string) string {
+	switch {
+	case strings.Contains(name, "UCS2"), strings.Contains(name, "UTF16"):
+		return pdfCharsetUTF16
+	case strings.Contains(name, "UTF8"):
+		return pdfCharsetUTF8
+	case strings.HasPrefix(name, "GB"):
+		return pdfCharsetGBK
+	}
+	return ""
+}
+
+// simpleEncoding 简单字体的编码差异表，基础编码按 WinAnsi 处理
+func (d *pdfDoc) simpleEncoding(v interface{}) map[byte]string {
+	enc := map[byte]string{}
+	var base interface{} = v
+	if dict, ok := v.(pdfDict); ok {
+		base = d.resolve(dict["BaseEncoding"])
+		if diffs, ok := d.resolve(dict["Differences"]).(pdfArray); ok {
+			code := 0
+			for _, item := range diffs {
+				switch t := d.resolve(item).(type) {
+				case float64:
+					code = int(t)
+				case pdfName:
+					if code >= 0 && code < 256 {
+						if s := glyphText(string(t)); s != "" {
+							enc[byte(code)] = s
+						}
+					}
+					code++
+				}
+			}
+		}
+	}
+	if base == pdfName("MacRomanEncoding") {
+		for c := 128; c < 256; c++ {
+			if _, ok := enc[byte(c)]; !ok {
+				enc[byte(c)] = string(charmap.Macintosh.DecodeByte(byte(c)))
+			}
+		}
+	}
+	return enc
+}
+
+// glyphNames 常见的 Adobe 字形名称，其余字形名称按 uniXXXX 或单个字符处理
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$", "percent": "%",
+	"ampersand": "&", "quotesingle": "'", "quoteright": "’", "quoteleft": "‘", "parenleft": "(",
+	"parenright": ")", "asterisk": "*", "plus": "+", "comma": ",", "hyphen": "-", "period": ".",
+	"slash": "/", "zero": "0", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5",
+	"six": "6", "seven": "7", "eight": "8", "nine": "9", "colon": ":", "semicolon": ";", "less": "<",
+	"equal": "=", "greater": ">", "question": "?", "at": "@", "bracketleft": "[", "backslash": "\\",
+	"bracketright": "]", "asciicircum": "^", "underscore": "_", "grave": "`", "braceleft": "{",
+	"bar": "|", "braceright": "}", "asciitilde": "~", "bullet": "•", "endash": "–",
+	"emdash": "—", "quotedblleft": "“", "quotedblright": "”", "ellipsis": "…",
+	"fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl", "trademark": "™",
+	"copyright": "©", "registered": "®", "degree": "°", "minus": "−",
+	"periodcentered": "·", "section": "§", "paragraph": "¶", "dagger": "†",
+	"multiply": "×", "divide": "÷", "nbspace": " ",
+}
+
+var glyphUnicode = regexp.MustCompile(`^(?:uni([0-9A-Fa-f]{4})|u([0-9A-Fa-f]{4,6}))$`)
+
+func glyphText(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	if s, ok := glyphNames[name]; ok {
+		return s
+	}
+	if m := glyphUnicode.FindStringSubmatch(name); m != nil {
+		v, _ := strconv.ParseUint(m[1]+m[2], 16, 32)
+		return string(rune(v))
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		return name
+	}
+	return ""
+}
+
+// pdfCMap ToUnicode CMap，codes 按编码字节数分别保存
+type pdfCMap struct {
+	lengths []int
+	chars   map[int]map[uint32]string
+	ranges  []pdfCMapRange
+}
+
+type pdfCMapRange struct {
+	n      int
+	lo, hi uint32
+	// base 目标文本，范围内按偏移递增最后一个字符；list 不为空时按偏移取数组元素
+	base []rune
+	list []string
+}
+
+func parseCMap(data []byte) *pdfCMap {
+	m := &pdfCMap{chars: map[int]map[uint32]string{}}
+	seen := map[int]bool{}
+	addLen := func(n int) {
+		if n > 0 && n <= 4 && !seen[n] {
+			seen[n] = true
+			m.lengths = append(m.lengths, n)
+		}
+	}
+	l := &pdfLexer{data: data}
+	var operands []interface{}
+	for {
+		obj, err := l.next()
+		if err != nil {
+			break
+		}
+		kw, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].(pdfString); ok {
+					addLen(len(lo))
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(src) == 0 || len(src) > 4 {
+					continue
+				}
+				if m.chars[len(src)] == nil {
+					m.chars[len(src)] = map[uint32]string{}
+				}
+				m.chars[len(src)][codeValue(src)] = decodeUTF16(dst)
+				addLen(len(src))
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				r := pdfCMapRange{n: len(lo), lo: codeValue(lo), hi: codeValue(hi)}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					r.base = []rune(decodeUTF16(dst))
+				case pdfArray:
+					for _, item := range dst {
+						s, _ := item.(pdfString)
+						r.list = append(r.list, decodeUTF16(s))
+					}
+				default:
+					continue
+				}
+				m.ranges = append(m.ranges, r)
+				addLen(len(lo))
+			}
+		}
+		operands = operands[:0]
+	}
+	if len(m.lengths) == 0 {
+		m.lengths = []int{2}
+	}
+	sort.Ints(m.lengths)
+	return m
+}
+
+func codeValue(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func (m *pdfCMap) lookup(n int, code uint32) (string, bool) {
+	if s, ok := m.chars[n][code]; ok {
+		return s, true
+	}
+	for _, r := range m.ranges {
+		if r.n != n || code < r.lo || code > r.hi {
+			continue
+		}
+		offset := int(code - r.lo)
+		if r.list != nil {
+			if offset < len(r.list) {
+				return r.list[offset], true
+			}
+			return "", false
+		}
+		if len(r.base) == 0 {
+			return "", false
+		}
+		out := append([]rune(nil), r.base...)
+		out[len(out)-1] += rune(offset)
+		return string(out), true
+	}
+	return "", false
+}
+
+// decode 按编码长度从短到长匹配，无法映射的编码跳过
+func (m *pdfCMap) decode(s []byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		matched := false
+		for _, n := range m.lengths {
+			if i+n > len(s) {
+				continue
+			}
+			if text, ok := m.lookup(n, codeValue(s[i:i+n])); ok {
+				b.WriteString(text)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i += m.lengths[0]
+		}
+	}
+	return b.String()
+}
+
+// pdfTextWriter 解释页面内容流中的文本操作符，按文本位置的纵坐标变化换行
+type pdfTextWriter struct {
+	doc  *pdfDoc
+	buf  strings.Builder
+	font *pdfFont
+	// y 当前文本行的纵坐标，scale 为文本矩阵的纵向缩放
+	y, scale float64
+	lastY    float64
+	written  bool
+	newline  bool
+	space    bool
+}
+
+// pdfInlineImageEnd 内嵌图片数据的结束标记
+var pdfInlineImageEnd = regexp.MustCompile(`\sEI(?:\s|$)`)
+
+// run 解释内容流，depth 限制表单 XObject 的嵌套层数
+func (w *pdfTextWriter) run(content []byte, resources pdfDict, depth int) {
+	if depth > 8 {
+		return
+	}
+	fonts := w.doc.dict(resources["Font"])
+	xobjects := w.doc.dict(resources["XObject"])
+	l := &pdfLexer{data: content}
+	var operands []interface{}
+	for {
+		obj, err := l.next()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "BT":
+			w.y, w.scale = 0, 1
+			w.space = true
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					w.font = w.doc.font(fonts[name])
+				}
+			}
+		case "Tm":
+			if len(operands) == 6 {
+				d, _ := operands[3].(float64)
+				f, _ := operands[5].(float64)
+				w.scale = d
+				if w.scale == 0 {
+					w.scale = 1
+				}
+				w.y = f
+				w.space = true
+			}
+		case "Td", "TD":
+			if len(operands) == 2 {
+				tx, _ := operands[0].(float64)
+				ty, _ := operands[1].(float64)
+				if ty != 0 {
+					w.y += ty * math.Abs(w.scale)
+				} else if tx != 0 {
+					w.space = true
+				}
+			}
+		case "T*":
+			w.newline = true
+		case "Tj":
+			if len(operands) == 1 {
+				w.show(operands[0])
+			}
+		case "'":
+			w.newline = true
+			if len(operands) == 1 {
+				w.show(operands[0])
+			}
+		case "\"":
+			w.newline = true
+			if len(operands) == 3 {
+				w.show(operands[2])
+			}
+		case "TJ":
+			if len(operands) == 1 {
+				items, _ := operands[0].(pdfArray)
+				for _, item := range items {
+					// 字间距调整超过字宽的 15% 视为单词间隔，正常的字偶距调整远小于该值
+					if n, ok := item.(float64); ok && n < -150 {
+						w.space = true
+						continue
+					}
+					w.show(item)
+				}
+			}
+		case "Do":
+			if len(operands) == 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					w.form(xobjects[name], resources, depth)
+				}
+			}
+		case "ID":
+			// 跳过内嵌图片的二进制数据
+			if loc := pdfInlineImageEnd.FindIndex(content[l.pos:]); loc != nil {
+				l.pos += loc[1]
+			} else {
+				l.pos = len(content)
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// form 表单 XObject 中可能包含文本，如页眉页脚模板
+func (w *pdfTextWriter) form(v interface{}, resources pdfDict, depth int) {
+	stream, ok := w.doc.resolve(v).(*pdfStream)
+	if !ok || stream.Dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, err := w.doc.decode(stream)
+	if err != nil {
+		return
+	}
+	if res := w.doc.dict(stream.Dict["Resources"]); res != nil {
+		resources = res
+	}
+	font, y, scale := w.font, w.y, w.scale
+	w.run(data, resources, depth+1)
+	w.font, w.y, w.scale = font, y, scale
+}
+
+func (w *pdfTextWriter) show(v interface{}) {
+	s, ok := v.(pdfString)
+	if !ok {
+		return
+	}
+	text := w.font.decode(s)
+	text = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\n' && r != '\t') {
+			return -1
+		}
+		return r
+	}, text)
+	if text == "" {
+		return
+	}
+	if w.written && math.Abs(w.y-w.lastY) > 1 {
+		w.newline = true
+	}
+	switch {
+	case !w.written:
+	case w.newline:
+		w.buf.WriteByte('\n')
+	case w.space:
+		last, _ := utf8.DecodeLastRuneInString(w.buf.String())
+		first, _ := utf8.DecodeRuneInString(text)
+		if needsSpace(last, first) {
+			w.buf.WriteByte(' ')
+		}
+	}
+	w.buf.WriteString(text)
+	w.written, w.newline, w.space = true, false, false
+	w.lastY = w.y
+}
+
+// needsSpace 相邻的两段文本之间是否需要补充空格，中日韩文字之间不加空格
+func needsSpace(a, b rune) bool {
+	return !unicode.IsSpace(a) && !unicode.IsSpace(b) && !isCJK(a) && !isCJK(b)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
@@ -0,0 +1,306 @@
+package docparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register(&Format{
+		Name:       "CSV",
+		Extensions: []string{".csv", ".tsv"},
+		MIMETypes:  []string{"text/csv", "text/tab-separated-values"},
+		Parse:      parseCSV,
+	})
+	register(&Format{
+		Name:       "Excel",
+		Extensions: []string{".xlsx"},
+		MIMETypes:  []string{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		Parse:      parseXLSX,
+	})
+}
+
+// tableRow 表格中的一行，Number 为在文件中的行号
+type tableRow struct {
+	Number int
+	Cells  []string
+}
+
+// tableSections 第一个非空行为表头，其余每行生成一段 "列名: 值" 文本
+func tableSections(sheet string, rows []tableRow) []Section {
+	var (
+		header   []string
+		sections []Section
+	)
+	for _, row := range rows {
+		if isEmptyRow(row.Cells) {
+			continue
+		}
+		if header == nil {
+			header = row.Cells
+			continue
+		}
+		var lines []string
+		for i, cell := range row.Cells {
+			if cell = strings.TrimSpace(cell); cell == "" {
+				continue
+			}
+			name := ""
+			if i < len(header) {
+				name = strings.TrimSpace(header[i])
+			}
+			if name == "" {
+				name = fmt.Sprintf("第%d列", i+1)
+			}
+			lines = append(lines, name+": "+cell)
+		}
+		meta := map[string]interface{}{MetaRow: row.Number}
+		if sheet != "" {
+			meta[MetaSheet] = sheet
+		}
+		sections = append(sections, Section{Text: strings.Join(lines, "\n"), Metadata: meta})
+	}
+	return sections
+}
+
+func isEmptyRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCSV 按内容推断分隔符，支持逗号、制表符及分号
+func parseCSV(content []byte) ([]Section, error) {
+	text, err := decodeText(content)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = csvDelimiter(text)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows []tableRow
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		rows = append(rows, tableRow{Number: line, Cells: record})
+	}
+	return tableSections("", rows), nil
+}
+
+// csvDelimiter 取第一行中出现次数最多的分隔符
+func csvDelimiter(text string) rune {
+	first := text
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		first = text[:i]
+	}
+	best, count := ',', strings.Count(first, ",")
+	for _, d := range []rune{'\t', ';'} {
+		if n := strings.Count(first, string(d)); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+// readZipFile 读取 Office 文档中的文件，不存在时返回 nil
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, maxDecodedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxDecodedSize {
+			return nil, fmt.Errorf("%s 解压后超过 %d MB", name, maxDecodedSize>>20)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+func openZip(content []byte) (*zip.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, errors.New("文件已损坏或不是 Office Open XML 格式（不支持 .xls/.doc，请另存为 .xlsx/.docx）")
+	}
+	return zr, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name  string `xml:"name,attr"`
+		State string `xml:"state,attr"`
+		RID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R  string   `xml:"r,attr"`
+			T  string   `xml:"t,attr"`
+			V  string   `xml:"v"`
+			Is xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// parseXLSX 按工作表逐行提取，跳过隐藏的工作表，公式单元格使用缓存的计算结果
+func parseXLSX(content []byte) ([]Section, error) {
+	zr, err := openZip(content)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		workbook xlsxWorkbook
+		rels     xlsxRelationships
+		shared   struct {
+			Items []xlsxText `xml:"si"`
+		}
+	)
+	if err := unmarshalZipXML(zr, "xl/workbook.xml", &workbook, true); err != nil {
+		return nil, err
+	}
+	if err := unmarshalZipXML(zr, "xl/_rels/workbook.xml.rels", &rels, true); err != nil {
+		return nil, err
+	}
+	if err := unmarshalZipXML(zr, "xl/sharedStrings.xml", &shared, false); err != nil {
+		return nil, err
+	}
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	var sections []Section
+	for _, s := range workbook.Sheets {
+		if s.State == "hidden" || s.State == "veryHidden" || targets[s.RID] == "" {
+			continue
+		}
+		var sheet xlsxSheet
+		if err := unmarshalZipXML(zr, targets[s.RID], &sheet, true); err != nil {
+			return nil, err
+		}
+		rows := make([]tableRow, 0, len(sheet.Rows))
+		for i, row := range sheet.Rows {
+			number := row.R
+			if number == 0 {
+				number = i + 1
+			}
+			var cells []string
+			for j, c := range row.Cells {
+				col := j
+				if idx := columnIndex(c.R); idx >= 0 && idx < xlsxMaxColumns {
+					col = idx
+				}
+				for len(cells) <= col {
+					cells = append(cells, "")
+				}
+				switch c.T {
+				case "s":
+					if idx, err := strconv.Atoi(c.V); err == nil && idx < len(shared.Items) {
+						cells[col] = shared.Items[idx].String()
+					}
+				case "inlineStr":
+					cells[col] = c.Is.String()
+				case "b":
+					cells[col] = strings.ToUpper(strconv.FormatBool(c.V == "1"))
+				default:
+					cells[col] = c.V
+				}
+			}
+			rows = append(rows, tableRow{Number: number, Cells: cells})
+		}
+		sections = append(sections, tableSections(s.Name, rows)...)
+	}
+	return sections, nil
+}
+
+// xlsxMaxColumns Excel 工作表的最大列数
+const xlsxMaxColumns = 16384
+
+// columnIndex 单元格引用的列序号，如 C7 为 2，没有列号时返回 -1
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+func unmarshalZipXML(zr *zip.Reader, name string, v interface{}, required bool) error {
+	data, err := readZipFile(zr, name)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		if required {
+			return fmt.Errorf("缺少 %s", name)
+		}
+		return nil
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %v", name, err)
+	}
+	return nil
+}
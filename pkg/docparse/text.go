@@ -0,0 +1,92 @@
+package docparse
+
+import (
+	"regexp"
+	"strings"
+)
+
+func init() {
+	register(&Format{
+		Name:       "文本",
+		Extensions: []string{".txt", ".text", ".log"},
+		MIMETypes:  []string{"text/plain"},
+		Parse:      parsePlainText,
+	})
+	register(&Format{
+		Name:       "Markdown",
+		Extensions: []string{".md", ".markdown"},
+		MIMETypes:  []string{"text/markdown", "text/x-markdown"},
+		Parse:      parseMarkdown,
+	})
+}
+
+func parsePlainText(content []byte) ([]Section, error) {
+	text, err := decodeText(content)
+	if err != nil {
+		return nil, err
+	}
+	return []Section{{Text: text}}, nil
+}
+
+var (
+	mdHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdFence   = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	mdImage   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink    = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// parseMarkdown 按 ATX 标题（# 标题）切分，代码块内的 # 不作为标题，链接与图片只保留文字
+func parseMarkdown(content []byte) ([]Section, error) {
+	text, err := decodeText(content)
+	if err != nil {
+		return nil, err
+	}
+	text = mdComment.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "")
+	lines := strings.Split(text, "\n")
+	lines = skipFrontMatter(lines)
+
+	var (
+		b     sectionBuilder
+		fence string
+	)
+	for _, line := range lines {
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence[:1]) && len(m[1]) >= len(fence):
+				fence = ""
+			}
+			b.line(line)
+			continue
+		}
+		if fence == "" {
+			if m := mdHeading.FindStringSubmatch(line); m != nil {
+				b.heading(len(m[1]), cleanInline(m[2]))
+				continue
+			}
+			line = cleanInline(line)
+		}
+		b.line(line)
+	}
+	return b.result(), nil
+}
+
+// skipFrontMatter 去除文件开头 --- 包裹的 YAML 元数据
+func skipFrontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+func cleanInline(s string) string {
+	s = mdImage.ReplaceAllString(s, "$1")
+	return mdLink.ReplaceAllString(s, "$1")
+}
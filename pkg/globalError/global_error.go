@@ -27,6 +27,10 @@ const (
 
 	LoginErr  = 30101
 	LogoutErr = 30102
+
+	AIRateLimitError   = 40101 // 推理请求过于频繁
+	AIConcurrencyError = 40102 // 推理并发数超出限制
+	AITokenQuotaError  = 40103 // 每日 token 额度已用完
 )
 
 // 3、定义errorCode对应的文本信息
@@ -46,6 +50,10 @@ var codeTag = map[int]string{
 
 	LoginErr:  "登录失败",
 	LogoutErr: "注销失败",
+
+	AIRateLimitError:   "推理请求过于频繁，请稍后重试",
+	AIConcurrencyError: "同时进行的推理请求过多，请等待当前请求完成",
+	AITokenQuotaError:  "今日 token 额度已用完",
 }
 
 func GetErrorMsg(code int) string {
@@ -0,0 +1,163 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	Register(Backend{Name: "chromadb", Aliases: []string{"chroma"}, DefaultPort: 8000, New: newChroma})
+}
+
+// chromaCollections Chroma v2 API 默认租户与数据库下的集合路径
+const chromaCollections = "/api/v2/tenants/default_tenant/databases/default_database/collections"
+
+// chroma ChromaDB v2 REST API，集合使用余弦距离，Score 为 1-距离
+type chroma struct {
+	transport Transport
+}
+
+func newChroma(cfg Config) VectorStore {
+	return &chroma{transport: cfg.Transport}
+}
+
+type chromaCollection struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Dimension *int   `json:"dimension"`
+}
+
+func (c *chroma) EnsureCollection(ctx context.Context, collection string, dimension int) error {
+	in := map[string]interface{}{
+		"name":          collection,
+		"metadata":      map[string]interface{}{"hnsw:space": "cosine"},
+		"get_or_create": true,
+	}
+	if err := call(ctx, c.transport, http.MethodPost, chromaCollections, in, nil); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	return nil
+}
+
+// collection 按名称获取集合，Chroma 的数据操作需要使用集合 ID
+func (c *chroma) collection(ctx context.Context, name string) (*chromaCollection, error) {
+	var out chromaCollection
+	if err := call(ctx, c.transport, http.MethodGet, chromaCollections+"/"+name, nil, &out); err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("集合 %s 不存在", name)
+		}
+		return nil, fmt.Errorf("获取集合信息失败: %w", err)
+	}
+	return &out, nil
+}
+
+func (c *chroma) Upsert(ctx context.Context, collection string, docs []Document) error {
+	col, err := c.collection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(docs))
+	documents := make([]string, len(docs))
+	embeddings := make([][]float64, len(docs))
+	metadatas := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+		documents[i] = doc.Text
+		embeddings[i] = doc.Vector
+		metadatas[i] = doc.Metadata
+	}
+	in := map[string]interface{}{
+		"ids":        ids,
+		"documents":  documents,
+		"embeddings": embeddings,
+		"metadatas":  metadatas,
+	}
+	if err := call(ctx, c.transport, http.MethodPost, chromaCollections+"/"+col.ID+"/upsert", in, nil); err != nil {
+		return fmt.Errorf("写入 Chroma 失败: %w", err)
+	}
+	return nil
+}
+
+func (c *chroma) Query(ctx context.Context, collection string, query Query) ([]Hit, error) {
+	if err := validateFilter(query.Filter); err != nil {
+		return nil, err
+	}
+	col, err := c.collection(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	in := map[string]interface{}{
+		"query_embeddings": [][]float64{query.Vector},
+		"n_results":        query.TopK,
+		"include":          []string{"documents", "metadatas", "distances"},
+	}
+	if len(query.Filter) > 0 {
+		in["where"] = chromaWhere(query.Filter)
+	}
+	// 每个查询向量对应一组结果，这里只有一个查询向量
+	var out struct {
+		IDs       [][]string                 `json:"ids"`
+		Documents [][]*string                `json:"documents"`
+		Metadatas [][]map[string]interface{} `json:"metadatas"`
+		Distances [][]float64                `json:"distances"`
+	}
+	if err := call(ctx, c.transport, http.MethodPost, chromaCollections+"/"+col.ID+"/query", in, &out); err != nil {
+		return nil, fmt.Errorf("查询 Chroma 失败: %w", err)
+	}
+	if len(out.IDs) == 0 {
+		return nil, nil
+	}
+	hits := make([]Hit, len(out.IDs[0]))
+	for i, id := range out.IDs[0] {
+		hits[i].ID = id
+		if len(out.Documents) > 0 && i < len(out.Documents[0]) && out.Documents[0][i] != nil {
+			hits[i].Text = *out.Documents[0][i]
+		}
+		if len(out.Metadatas) > 0 && i < len(out.Metadatas[0]) {
+			hits[i].Metadata = out.Metadatas[0][i]
+		}
+		if len(out.Distances) > 0 && i < len(out.Distances[0]) {
+			hits[i].Score = 1 - out.Distances[0][i]
+		}
+	}
+	return hits, nil
+}
+
+func (c *chroma) Delete(ctx context.Context, collection string, ids []string) error {
+	col, err := c.collection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	if err := call(ctx, c.transport, http.MethodPost, chromaCollections+"/"+col.ID+"/delete", map[string]interface{}{"ids": ids}, nil); err != nil {
+		return fmt.Errorf("删除 Chroma 数据失败: %w", err)
+	}
+	return nil
+}
+
+func (c *chroma) Stats(ctx context.Context, collection string) (Stats, error) {
+	col, err := c.collection(ctx, collection)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Collection: collection}
+	if col.Dimension != nil {
+		stats.Dimension = *col.Dimension
+	}
+	if err := call(ctx, c.transport, http.MethodGet, chromaCollections+"/"+col.ID+"/count", nil, &stats.Count); err != nil {
+		return stats, fmt.Errorf("获取 Chroma 集合数量失败: %w", err)
+	}
+	return stats, nil
+}
+
+// chromaWhere 转换为 Chroma where 条件，多个条件使用 $and 组合
+func chromaWhere(filter map[string]interface{}) map[string]interface{} {
+	conditions := make([]interface{}, 0, len(filter))
+	for _, key := range sortedKeys(filter) {
+		conditions = append(conditions, map[string]interface{}{key: map[string]interface{}{"$eq": filter[key]}})
+	}
+	if len(conditions) == 1 {
+		return conditions[0].(map[string]interface{})
+	}
+	return map[string]interface{}{"$and": conditions}
+}
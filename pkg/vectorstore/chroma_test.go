@@ -0,0 +1,93 @@
+package vectorstore
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// chromaStandIn 模拟 Chroma v2 API，集合 ID 为 "id-" 加集合名称
+func chromaStandIn(s *standIn, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	path := strings.TrimPrefix(r.URL.Path, chromaCollections)
+	switch {
+	case r.Method == http.MethodPost && path == "":
+		name, _ := body["name"].(string)
+		if _, ok := s.collections[name]; !ok {
+			s.collections[name] = &memCollection{docs: map[string]Document{}}
+		}
+		writeJSON(w, map[string]interface{}{"id": "id-" + name, "name": name})
+		return
+	case r.Method == http.MethodGet && !strings.Contains(path[1:], "/"):
+		name := path[1:]
+		if _, ok := s.collections[name]; !ok {
+			http.Error(w, `{"error":"NotFoundError"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"id": "id-" + name, "name": name, "dimension": 3})
+		return
+	}
+
+	parts := strings.SplitN(path[1:], "/", 2)
+	col := s.collections[strings.TrimPrefix(parts[0], "id-")]
+	if col == nil || len(parts) != 2 {
+		http.Error(w, `{"error":"NotFoundError"}`, http.StatusNotFound)
+		return
+	}
+	switch parts[1] {
+	case "upsert":
+		ids, documents := strs(body["ids"]), strs(body["documents"])
+		embeddings, _ := body["embeddings"].([]interface{})
+		metadatas, _ := body["metadatas"].([]interface{})
+		for i, id := range ids {
+			metadata, _ := metadatas[i].(map[string]interface{})
+			col.docs[id] = Document{ID: id, Text: documents[i], Vector: floats(embeddings[i]), Metadata: metadata}
+		}
+		writeJSON(w, map[string]interface{}{})
+	case "query":
+		queries, _ := body["query_embeddings"].([]interface{})
+		topK, _ := body["n_results"].(float64)
+		var ids, documents []interface{}
+		var metadatas, distances []interface{}
+		filter := map[string]interface{}{}
+		if where, ok := body["where"].(map[string]interface{}); ok {
+			conditions := []interface{}{where}
+			if and, ok := where["$and"].([]interface{}); ok {
+				conditions = and
+			}
+			for _, item := range conditions {
+				for key, cond := range item.(map[string]interface{}) {
+					filter[key] = cond.(map[string]interface{})["$eq"]
+				}
+			}
+		}
+		for _, doc := range col.search(floats(queries[0]), int(topK), filter) {
+			ids = append(ids, doc.ID)
+			documents = append(documents, doc.Text)
+			metadatas = append(metadatas, doc.Metadata)
+			distances = append(distances, 1-doc.score)
+		}
+		writeJSON(w, map[string]interface{}{
+			"ids":       []interface{}{ids},
+			"documents": []interface{}{documents},
+			"metadatas": []interface{}{metadatas},
+			"distances": []interface{}{distances},
+		})
+	case "delete":
+		for _, id := range strs(body["ids"]) {
+			delete(col.docs, id)
+		}
+		writeJSON(w, map[string]interface{}{})
+	case "count":
+		writeJSON(w, len(col.docs))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestChroma(t *testing.T) {
+	backend, err := Lookup("chroma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseStore(t, backend.New(Config{Transport: newStandIn(t, chromaStandIn)}))
+}
@@ -0,0 +1,192 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Register(Backend{Name: "milvus", DefaultPort: 19530, New: newMilvus})
+}
+
+// milvus 保留字段，metadata 中的同名字段写入时会被忽略
+const (
+	milvusIDField     = "id"
+	milvusVectorField = "vector"
+	milvusTextField   = "text"
+	milvusScoreField  = "distance"
+)
+
+// milvusMaxIDLength 字符串主键的最大长度
+const milvusMaxIDLength = 512
+
+// milvus Milvus RESTful v1 API，与已部署的知识库使用相同的接口：
+// 集合管理使用 /v1/collections，检索及删除使用 /v1/vector
+// 集合使用字符串主键、余弦相似度，metadata 写入动态字段
+type milvus struct {
+	transport Transport
+}
+
+func newMilvus(cfg Config) VectorStore {
+	return &milvus{transport: cfg.Transport}
+}
+
+// milvusResponse Milvus 接口统一响应，HTTP 状态码为 200 时 code 不为 0 或 200 表示失败
+type milvusResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (m *milvus) call(ctx context.Context, method, path string, in, out interface{}) error {
+	var resp milvusResponse
+	if err := call(ctx, m.transport, method, path, in, &resp); err != nil {
+		return err
+	}
+	switch {
+	case resp.Code >= http.StatusBadRequest && resp.Code < 600:
+		// 以 HTTP 状态码表示的错误，集合不存在时为 404
+		return &StatusError{Code: resp.Code, Body: resp.Message}
+	case resp.Code != 0 && resp.Code != http.StatusOK:
+		return fmt.Errorf("milvus 返回错误 %d: %s", resp.Code, resp.Message)
+	}
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w, data: %s", err, string(resp.Data))
+	}
+	return nil
+}
+
+// milvusCollection 集合信息，GET /v1/collections/{name} 的响应
+type milvusCollection struct {
+	Dimension   int         `json:"dimension"`
+	NumEntities json.Number `json:"num_entities"`
+}
+
+func (m *milvus) describe(ctx context.Context, collection string) (milvusCollection, error) {
+	var out milvusCollection
+	return out, m.call(ctx, http.MethodGet, "/v1/collections/"+url.PathEscape(collection), nil, &out)
+}
+
+func (m *milvus) EnsureCollection(ctx context.Context, collection string, dimension int) error {
+	_, err := m.describe(ctx, collection)
+	if err == nil {
+		return nil
+	}
+	if !IsNotFound(err) {
+		return fmt.Errorf("检查集合失败: %w", err)
+	}
+	in := map[string]interface{}{
+		"collection_name":      collection,
+		"dimension":            dimension,
+		"metric_type":          "COSINE",
+		"primary_field":        milvusIDField,
+		"id_type":              "VarChar",
+		"max_length":           milvusMaxIDLength,
+		"vector_field":         milvusVectorField,
+		"enable_dynamic_field": true,
+	}
+	if err := m.call(ctx, http.MethodPost, "/v1/collections", in, nil); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	return nil
+}
+
+// Upsert 先删除同 ID 的分块再写入，v1 接口的 insert 不会覆盖已存在的主键
+func (m *milvus) Upsert(ctx context.Context, collection string, docs []Document) error {
+	ids := make([]string, len(docs))
+	data := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		row := make(map[string]interface{}, len(doc.Metadata)+3)
+		for k, v := range doc.Metadata {
+			row[k] = v
+		}
+		row[milvusIDField] = doc.ID
+		row[milvusVectorField] = doc.Vector
+		row[milvusTextField] = doc.Text
+		ids[i] = doc.ID
+		data[i] = row
+	}
+	if err := m.Delete(ctx, collection, ids); err != nil {
+		return err
+	}
+	in := map[string]interface{}{"collection_name": collection, "data": data}
+	if err := m.call(ctx, http.MethodPost, "/v1/collections/"+url.PathEscape(collection)+"/insert", in, nil); err != nil {
+		return fmt.Errorf("写入 Milvus 失败: %w", err)
+	}
+	return nil
+}
+
+func (m *milvus) Query(ctx context.Context, collection string, query Query) ([]Hit, error) {
+	if err := validateFilter(query.Filter); err != nil {
+		return nil, err
+	}
+	in := map[string]interface{}{
+		"collection_name": collection,
+		"vector":          query.Vector,
+		"top_k":           query.TopK,
+		"output_fields":   []string{"*"},
+	}
+	if len(query.Filter) > 0 {
+		in["filter"] = milvusFilter(query.Filter)
+	}
+	var rows []map[string]interface{}
+	if err := m.call(ctx, http.MethodPost, "/v1/vector/search", in, &rows); err != nil {
+		return nil, fmt.Errorf("查询 Milvus 失败: %w", err)
+	}
+	hits := make([]Hit, len(rows))
+	for i, row := range rows {
+		// COSINE 度量下 distance 即相似度
+		hits[i].Score, _ = row[milvusScoreField].(float64)
+		hits[i].Text, _ = row[milvusTextField].(string)
+		hits[i].ID = fmt.Sprint(row[milvusIDField])
+		for k, v := range row {
+			switch k {
+			case milvusIDField, milvusVectorField, milvusTextField, milvusScoreField:
+				continue
+			}
+			if hits[i].Metadata == nil {
+				hits[i].Metadata = map[string]interface{}{}
+			}
+			hits[i].Metadata[k] = v
+		}
+	}
+	return hits, nil
+}
+
+func (m *milvus) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	in := map[string]interface{}{"collection_name": collection, "id": ids}
+	if err := m.call(ctx, http.MethodPost, "/v1/vector/delete", in, nil); err != nil {
+		return fmt.Errorf("删除 Milvus 数据失败: %w", err)
+	}
+	return nil
+}
+
+func (m *milvus) Stats(ctx context.Context, collection string) (Stats, error) {
+	stats := Stats{Collection: collection}
+	info, err := m.describe(ctx, collection)
+	if err != nil {
+		return stats, fmt.Errorf("获取 Milvus 集合信息失败: %w", err)
+	}
+	stats.Count, _ = info.NumEntities.Int64()
+	stats.Dimension = info.Dimension
+	return stats, nil
+}
+
+// milvusFilter 转换为 Milvus 布尔表达式，metadata 保存在动态字段中，可直接按字段名过滤
+func milvusFilter(filter map[string]interface{}) string {
+	conditions := make([]string, 0, len(filter))
+	for _, key := range sortedKeys(filter) {
+		conditions = append(conditions, fmt.Sprintf("%s == %s", key, literal(filter[key])))
+	}
+	return strings.Join(conditions, " and ")
+}
@@ -0,0 +1,90 @@
+package vectorstore
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var milvusCondition = regexp.MustCompile(`(\w+) == ("(?:[^"\\]|\\.)*"|\S+)`)
+
+// milvusStandIn 模拟 Milvus RESTful v1 API，集合不存在时返回 404
+func milvusStandIn(s *standIn, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	name, _ := body["collection_name"].(string)
+	if r.Method == http.MethodGet || strings.HasSuffix(r.URL.Path, "/insert") {
+		name = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/collections/"), "/insert")
+	}
+	col := s.collections[name]
+	if col == nil && r.URL.Path != "/v1/collections" {
+		http.Error(w, "collection not found", http.StatusNotFound)
+		return
+	}
+	var data interface{}
+	switch {
+	case r.Method == http.MethodGet:
+		data = map[string]interface{}{"collection_name": name, "dimension": col.dimension, "num_entities": len(col.docs)}
+	case r.URL.Path == "/v1/collections":
+		if body["id_type"] != "VarChar" || body["metric_type"] != "COSINE" {
+			writeJSON(w, map[string]interface{}{"code": 1100, "message": "unexpected schema"})
+			return
+		}
+		dim, _ := body["dimension"].(float64)
+		s.collections[name] = &memCollection{dimension: int(dim), docs: map[string]Document{}}
+	case strings.HasSuffix(r.URL.Path, "/insert"):
+		rows, _ := body["data"].([]interface{})
+		for _, item := range rows {
+			row, _ := item.(map[string]interface{})
+			if _, ok := col.docs[row["id"].(string)]; ok {
+				writeJSON(w, map[string]interface{}{"code": 1100, "message": "duplicate primary key"})
+				return
+			}
+			doc := Document{ID: row["id"].(string), Text: row["text"].(string), Vector: floats(row["vector"]), Metadata: map[string]interface{}{}}
+			for k, v := range row {
+				if k != "id" && k != "text" && k != "vector" {
+					doc.Metadata[k] = v
+				}
+			}
+			col.docs[doc.ID] = doc
+		}
+		data = map[string]interface{}{"insertCount": len(rows)}
+	case r.URL.Path == "/v1/vector/search":
+		topK, _ := body["top_k"].(float64)
+		var rows []interface{}
+		filter := map[string]interface{}{}
+		expr, _ := body["filter"].(string)
+		for _, match := range milvusCondition.FindAllStringSubmatch(expr, -1) {
+			filter[match[1]] = parseLiteral(match[2])
+		}
+		for _, doc := range col.search(floats(body["vector"]), int(topK), filter) {
+			row := map[string]interface{}{"id": doc.ID, "text": doc.Text, "distance": doc.score}
+			for k, v := range doc.Metadata {
+				row[k] = v
+			}
+			rows = append(rows, row)
+		}
+		data = rows
+	case r.URL.Path == "/v1/vector/delete":
+		for _, id := range strs(body["id"]) {
+			delete(col.docs, id)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"code": 200, "data": data})
+}
+
+func TestMilvus(t *testing.T) {
+	backend, err := Lookup("milvus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := backend.New(Config{Transport: newStandIn(t, milvusStandIn)})
+	exerciseStore(t, store)
+	stats, err := store.Stats(context.Background(), "handbook")
+	if err != nil || stats.Dimension != 3 {
+		t.Fatalf("dimension: %+v %v", stats, err)
+	}
+}
@@ -0,0 +1,284 @@
+package vectorstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgconn"
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+func init() {
+	Register(Backend{Name: "pgvector", Aliases: []string{"postgres"}, DefaultPort: 5432, New: newPGVector})
+}
+
+// pgvector 默认连接配置，与官方 postgres 镜像的环境变量一致
+const (
+	pgvectorDefaultUser = "postgres"
+	// pgvectorTablePrefix 集合对应的表名前缀，避免与业务表冲突
+	pgvectorTablePrefix = "kb_"
+	// pgUndefinedTable 表不存在的错误码
+	pgUndefinedTable = "42P01"
+	// pgvectorMaxIndexDimension HNSW 及 IVFFlat 索引支持的最大维度，超过时不建索引，查询时全表扫描
+	pgvectorMaxIndexDimension = 2000
+)
+
+// pgPool 知识库实例的连接池，Pod 重建后地址或账号变化时关闭旧连接池
+type pgPool struct {
+	dsn string
+	db  *sql.DB
+}
+
+var (
+	pgMu    sync.Mutex
+	pgPools = map[string]*pgPool{}
+)
+
+// pgvector PostgreSQL + pgvector 扩展，直连 Config.Addr，账号读取容器的 POSTGRES_USER/POSTGRES_PASSWORD/POSTGRES_DB
+// 每个集合对应一张表，按余弦距离建立 HNSW 索引（维度不超过 2000 时），metadata 保存为 jsonb，Score 为 1-余弦距离
+type pgvector struct {
+	key string
+	dsn string
+	err error
+}
+
+func newPGVector(cfg Config) VectorStore {
+	if cfg.Addr == "" {
+		return &pgvector{err: errors.New("pgvector 需要数据库地址")}
+	}
+	user := cfg.Env["POSTGRES_USER"]
+	if user == "" {
+		user = pgvectorDefaultUser
+	}
+	database := cfg.Env["POSTGRES_DB"]
+	if database == "" {
+		database = user
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, cfg.Env["POSTGRES_PASSWORD"]),
+		Host:     cfg.Addr,
+		Path:     "/" + database,
+		RawQuery: "sslmode=disable&connect_timeout=10",
+	}
+	key := cfg.Key
+	if key == "" {
+		key = cfg.Addr
+	}
+	return &pgvector{key: key, dsn: dsn.String()}
+}
+
+// db 按知识库实例复用连接池，连接串变化时关闭旧连接池，已开始的查询执行完后才会断开
+func (p *pgvector) db() (*sql.DB, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	pgMu.Lock()
+	defer pgMu.Unlock()
+	if pool, ok := pgPools[p.key]; ok {
+		if pool.dsn == p.dsn {
+			return pool.db, nil
+		}
+		delete(pgPools, p.key)
+		_ = pool.db.Close()
+	}
+	db, err := sql.Open("pgx", p.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("连接 pgvector 失败: %w", err)
+	}
+	db.SetMaxOpenConns(5)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+	pgPools[p.key] = &pgPool{dsn: p.dsn, db: db}
+	return db, nil
+}
+
+// pgTable 集合对应的表名，已加引号
+func pgTable(collection string) string {
+	return `"` + strings.ReplaceAll(pgvectorTablePrefix+collection, `"`, `""`) + `"`
+}
+
+// pgVector 格式化为 pgvector 的文本表示，如 [1,2,3]
+func pgVector(vector []float64) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// pgError 将表不存在的错误转换为集合不存在
+func pgError(collection, action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable {
+		return fmt.Errorf("集合 %s 不存在", collection)
+	}
+	return fmt.Errorf("%s失败: %w", action, pgClassify(err))
+}
+
+// pgStatusError 携带 PostgreSQL 错误码的错误
+type pgStatusError struct {
+	err  error
+	code string
+}
+
+func (e *pgStatusError) Error() string { return e.err.Error() }
+
+func (e *pgStatusError) Unwrap() error { return e.err }
+
+// Permanent PostgreSQL 错误码 22 类为数据异常（包括向量维度不一致），42 类为语法错误或权限不足
+func (e *pgStatusError) Permanent() bool {
+	return strings.HasPrefix(e.code, "22") || strings.HasPrefix(e.code, "42")
+}
+
+// pgClassify 为 PostgreSQL 返回的错误附加错误码，供 IsPermanent 判断
+func pgClassify(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return &pgStatusError{err: err, code: pgErr.Code}
+	}
+	return err
+}
+
+func (p *pgvector) EnsureCollection(ctx context.Context, collection string, dimension int) error {
+	db, err := p.db()
+	if err != nil {
+		return err
+	}
+	table := pgTable(collection)
+	index := `"` + strings.ReplaceAll(pgvectorTablePrefix+collection+"_embedding_idx", `"`, `""`) + `"`
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	text TEXT NOT NULL,
+	metadata JSONB NOT NULL DEFAULT '{}',
+	embedding vector(%d) NOT NULL
+)`, table, dimension),
+	}
+	if dimension <= pgvectorMaxIndexDimension {
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (embedding vector_cosine_ops)", index, table))
+	}
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("创建集合失败: %w", pgClassify(err))
+		}
+	}
+	return nil
+}
+
+func (p *pgvector) Upsert(ctx context.Context, collection string, docs []Document) error {
+	db, err := p.db()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("写入 pgvector 失败: %w", pgClassify(err))
+	}
+	defer tx.Rollback()
+	stmt := fmt.Sprintf(`INSERT INTO %s (id, text, metadata, embedding) VALUES ($1, $2, $3::jsonb, $4::vector)
+ON CONFLICT (id) DO UPDATE SET text = EXCLUDED.text, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, pgTable(collection))
+	for _, doc := range docs {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("序列化 metadata 失败: %w", err)
+		}
+		if doc.Metadata == nil {
+			metadata = []byte("{}")
+		}
+		if _, err := tx.ExecContext(ctx, stmt, doc.ID, doc.Text, string(metadata), pgVector(doc.Vector)); err != nil {
+			return pgError(collection, "写入 pgvector ", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("写入 pgvector 失败: %w", pgClassify(err))
+	}
+	return nil
+}
+
+func (p *pgvector) Query(ctx context.Context, collection string, query Query) ([]Hit, error) {
+	if err := validateFilter(query.Filter); err != nil {
+		return nil, err
+	}
+	db, err := p.db()
+	if err != nil {
+		return nil, err
+	}
+	filter := []byte("{}")
+	if len(query.Filter) > 0 {
+		if filter, err = json.Marshal(query.Filter); err != nil {
+			return nil, fmt.Errorf("序列化过滤条件失败: %w", err)
+		}
+	}
+	// metadata @> filter 即 metadata 包含全部过滤字段且取值相等
+	stmt := fmt.Sprintf(`SELECT id, text, metadata, 1 - (embedding <=> $1::vector) AS score FROM %s
+WHERE metadata @> $2::jsonb ORDER BY embedding <=> $1::vector LIMIT $3`, pgTable(collection))
+	rows, err := db.QueryContext(ctx, stmt, pgVector(query.Vector), string(filter), query.TopK)
+	if err != nil {
+		return nil, pgError(collection, "查询 pgvector ", err)
+	}
+	defer rows.Close()
+	var hits []Hit
+	for rows.Next() {
+		var hit Hit
+		var metadata []byte
+		if err := rows.Scan(&hit.ID, &hit.Text, &metadata, &hit.Score); err != nil {
+			return nil, fmt.Errorf("读取查询结果失败: %w", err)
+		}
+		if len(metadata) > 0 {
+			_ = json.Unmarshal(metadata, &hit.Metadata)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取查询结果失败: %w", err)
+	}
+	return hits, nil
+}
+
+func (p *pgvector) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db, err := p.db()
+	if err != nil {
+		return err
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id
+	}
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)", pgTable(collection), strings.Join(placeholders, ","))
+	if _, err := db.ExecContext(ctx, stmt, args...); err != nil {
+		return pgError(collection, "删除 pgvector 数据", err)
+	}
+	return nil
+}
+
+func (p *pgvector) Stats(ctx context.Context, collection string) (Stats, error) {
+	stats := Stats{Collection: collection}
+	db, err := p.db()
+	if err != nil {
+		return stats, err
+	}
+	table := pgTable(collection)
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM "+table).Scan(&stats.Count); err != nil {
+		return stats, pgError(collection, "获取 pgvector 集合数量", err)
+	}
+	// vector(n) 列的 atttypmod 即维度
+	err = db.QueryRowContext(ctx, "SELECT atttypmod FROM pg_attribute WHERE attrelid = $1::regclass AND attname = 'embedding'", table).Scan(&stats.Dimension)
+	if err != nil {
+		return stats, fmt.Errorf("获取 pgvector 集合维度失败: %w", pgClassify(err))
+	}
+	return stats, nil
+}
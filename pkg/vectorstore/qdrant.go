@@ -0,0 +1,165 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	Register(Backend{Name: "qdrant", DefaultPort: 6333, New: newQdrant})
+}
+
+// qdrant 保留的 payload 字段，metadata 中的同名字段写入时会被覆盖
+const (
+	qdrantTextPayload = "text"
+	qdrantIDPayload   = "doc_id"
+)
+
+// qdrant Qdrant REST API，集合使用余弦距离，Score 即相似度
+// 点 ID 必须为整数或 UUID，由分块 ID 生成，原始 ID 与文本保存在 payload 中
+type qdrant struct {
+	transport Transport
+}
+
+func newQdrant(cfg Config) VectorStore {
+	return &qdrant{transport: cfg.Transport}
+}
+
+type qdrantCollectionInfo struct {
+	PointsCount *int64 `json:"points_count"`
+	Config      struct {
+		Params struct {
+			Vectors struct {
+				Size int `json:"size"`
+			} `json:"vectors"`
+		} `json:"params"`
+	} `json:"config"`
+}
+
+func (q *qdrant) info(ctx context.Context, collection string) (*qdrantCollectionInfo, error) {
+	var out struct {
+		Result qdrantCollectionInfo `json:"result"`
+	}
+	if err := call(ctx, q.transport, http.MethodGet, "/collections/"+collection, nil, &out); err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("集合 %s 不存在", collection)
+		}
+		return nil, fmt.Errorf("获取集合信息失败: %w", err)
+	}
+	return &out.Result, nil
+}
+
+func (q *qdrant) EnsureCollection(ctx context.Context, collection string, dimension int) error {
+	err := call(ctx, q.transport, http.MethodGet, "/collections/"+collection, nil, nil)
+	if err == nil {
+		return nil
+	}
+	if !IsNotFound(err) {
+		return fmt.Errorf("获取集合信息失败: %w", err)
+	}
+	in := map[string]interface{}{
+		"vectors": map[string]interface{}{"size": dimension, "distance": "Cosine"},
+	}
+	if err := call(ctx, q.transport, http.MethodPut, "/collections/"+collection, in, nil); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	return nil
+}
+
+func (q *qdrant) Upsert(ctx context.Context, collection string, docs []Document) error {
+	points := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		payload := make(map[string]interface{}, len(doc.Metadata)+2)
+		for k, v := range doc.Metadata {
+			payload[k] = v
+		}
+		payload[qdrantTextPayload] = doc.Text
+		payload[qdrantIDPayload] = doc.ID
+		points[i] = map[string]interface{}{
+			"id":      stableUUID(doc.ID),
+			"vector":  doc.Vector,
+			"payload": payload,
+		}
+	}
+	// wait=true 写入完成后再返回，随后的查询能立即读到
+	if err := call(ctx, q.transport, http.MethodPut, "/collections/"+collection+"/points?wait=true", map[string]interface{}{"points": points}, nil); err != nil {
+		return fmt.Errorf("写入 Qdrant 失败: %w", err)
+	}
+	return nil
+}
+
+func (q *qdrant) Query(ctx context.Context, collection string, query Query) ([]Hit, error) {
+	if err := validateFilter(query.Filter); err != nil {
+		return nil, err
+	}
+	in := map[string]interface{}{
+		"vector":       query.Vector,
+		"limit":        query.TopK,
+		"with_payload": true,
+	}
+	if len(query.Filter) > 0 {
+		must := make([]interface{}, 0, len(query.Filter))
+		for _, key := range sortedKeys(query.Filter) {
+			must = append(must, map[string]interface{}{"key": key, "match": map[string]interface{}{"value": query.Filter[key]}})
+		}
+		in["filter"] = map[string]interface{}{"must": must}
+	}
+	var out struct {
+		Result []struct {
+			ID      interface{}            `json:"id"`
+			Score   float64                `json:"score"`
+			Payload map[string]interface{} `json:"payload"`
+		} `json:"result"`
+	}
+	if err := call(ctx, q.transport, http.MethodPost, "/collections/"+collection+"/points/search", in, &out); err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("集合 %s 不存在", collection)
+		}
+		return nil, fmt.Errorf("查询 Qdrant 失败: %w", err)
+	}
+	hits := make([]Hit, len(out.Result))
+	for i, point := range out.Result {
+		hits[i].ID = fmt.Sprint(point.ID)
+		hits[i].Score = point.Score
+		for k, v := range point.Payload {
+			switch k {
+			case qdrantTextPayload:
+				hits[i].Text, _ = v.(string)
+			case qdrantIDPayload:
+				if id, ok := v.(string); ok && id != "" {
+					hits[i].ID = id
+				}
+			default:
+				if hits[i].Metadata == nil {
+					hits[i].Metadata = map[string]interface{}{}
+				}
+				hits[i].Metadata[k] = v
+			}
+		}
+	}
+	return hits, nil
+}
+
+func (q *qdrant) Delete(ctx context.Context, collection string, ids []string) error {
+	points := make([]string, len(ids))
+	for i, id := range ids {
+		points[i] = stableUUID(id)
+	}
+	if err := call(ctx, q.transport, http.MethodPost, "/collections/"+collection+"/points/delete?wait=true", map[string]interface{}{"points": points}, nil); err != nil {
+		return fmt.Errorf("删除 Qdrant 数据失败: %w", err)
+	}
+	return nil
+}
+
+func (q *qdrant) Stats(ctx context.Context, collection string) (Stats, error) {
+	info, err := q.info(ctx, collection)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Collection: collection, Dimension: info.Config.Params.Vectors.Size}
+	if info.PointsCount != nil {
+		stats.Count = *info.PointsCount
+	}
+	return stats, nil
+}
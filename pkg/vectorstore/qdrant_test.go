@@ -0,0 +1,81 @@
+package vectorstore
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// qdrantStandIn 模拟 Qdrant REST API，集合不存在时返回 404
+func qdrantStandIn(s *standIn, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/collections/"), "/")
+	name, action := parts[0], strings.Join(parts[1:], "/")
+	col := s.collections[name]
+	if col == nil && !(r.Method == http.MethodPut && action == "") {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]interface{}{"status": map[string]interface{}{"error": "Not found: Collection `" + name + "` doesn't exist!"}})
+		return
+	}
+	var result interface{} = true
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		vectors := map[string]interface{}{"size": col.dimension, "distance": "Cosine"}
+		result = map[string]interface{}{"points_count": len(col.docs), "config": map[string]interface{}{"params": map[string]interface{}{"vectors": vectors}}}
+	case action == "" && r.Method == http.MethodPut:
+		vectors, _ := body["vectors"].(map[string]interface{})
+		if vectors["distance"] != "Cosine" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		size, _ := vectors["size"].(float64)
+		s.collections[name] = &memCollection{dimension: int(size), docs: map[string]Document{}}
+	case action == "points" && r.Method == http.MethodPut:
+		if r.URL.Query().Get("wait") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		points, _ := body["points"].([]interface{})
+		for _, item := range points {
+			point, _ := item.(map[string]interface{})
+			payload, _ := point["payload"].(map[string]interface{})
+			id, _ := point["id"].(string)
+			col.docs[id] = Document{ID: id, Vector: floats(point["vector"]), Metadata: payload}
+		}
+	case action == "points/search":
+		filter := map[string]interface{}{}
+		if f, ok := body["filter"].(map[string]interface{}); ok {
+			for _, item := range f["must"].([]interface{}) {
+				cond := item.(map[string]interface{})
+				filter[cond["key"].(string)] = cond["match"].(map[string]interface{})["value"]
+			}
+		}
+		limit, _ := body["limit"].(float64)
+		var points []interface{}
+		for _, doc := range col.search(floats(body["vector"]), int(limit), filter) {
+			points = append(points, map[string]interface{}{"id": doc.ID, "score": doc.score, "payload": doc.Metadata})
+		}
+		result = points
+	case action == "points/delete":
+		for _, id := range strs(body["points"]) {
+			delete(col.docs, id)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"status": "ok", "result": result})
+}
+
+func TestQdrant(t *testing.T) {
+	backend, err := Lookup("qdrant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := backend.New(Config{Transport: newStandIn(t, qdrantStandIn)})
+	exerciseStore(t, store)
+	stats, err := store.Stats(context.Background(), "handbook")
+	if err != nil || stats.Dimension != 3 {
+		t.Fatalf("dimension: %+v %v", stats, err)
+	}
+}
@@ -0,0 +1,101 @@
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Transport 向向量数据库发送请求并返回响应体，响应状态码不是 2xx 时返回 *StatusError
+type Transport interface {
+	Do(ctx context.Context, method, path string, body []byte) ([]byte, error)
+}
+
+// StatusError 向量数据库返回的非 2xx 响应
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.Code, strings.TrimSpace(e.Body))
+}
+
+// Permanent 4xx 响应表示请求本身不合法，超时及限流除外
+func (e *StatusError) Permanent() bool {
+	return e.Code >= 400 && e.Code < 500 && e.Code != http.StatusRequestTimeout && e.Code != http.StatusTooManyRequests
+}
+
+// IsNotFound 判断错误是否为 404 响应
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound
+}
+
+// NewHTTPTransport 直接通过 HTTP 访问 baseURL，client 为 nil 时使用 http.DefaultClient
+func NewHTTPTransport(baseURL string, client *http.Client) Transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpTransport{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+type httpTransport struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (t *httpTransport) Do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{Code: resp.StatusCode, Body: string(data)}
+	}
+	return data, nil
+}
+
+// call 以 JSON 发送 in，并将响应解析到 out，in 或 out 为 nil 时忽略
+func call(ctx context.Context, t Transport, method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("序列化请求体失败: %v", err)
+		}
+		body = data
+	}
+	data, err := t.Do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析响应失败: %v, body: %s", err, string(data))
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Document 写入向量库的一个分块，ID 相同的分块会被覆盖
+type Document struct {
+	ID       string                 `json:"id"`
+	Text     string                 `json:"text"`
+	Vector   []float64              `json:"-"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Query 向量查询参数
+type Query struct {
+	Vector []float64
+	TopK   int
+	// Filter 按 metadata 等值过滤，多个条件同时满足，值为字符串、数字或布尔
+	Filter map[string]interface{}
+}
+
+// Hit 查询命中的分块，Score 为相似度，越大越相似
+type Hit struct {
+	ID       string                 `json:"id"`
+	Text     string                 `json:"text"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Score    float64                `json:"score"`
+}
+
+// Stats 集合统计信息，Dimension 为 0 表示后端未返回维度
+type Stats struct {
+	Collection string `json:"collection"`
+	Count      int64  `json:"count"`
+	Dimension  int    `json:"dimension"`
+}
+
+// VectorStore 向量数据库，集合名称由调用方清理为字母、数字、下划线及中划线
+type VectorStore interface {
+	// EnsureCollection 确保集合存在，不存在时按 dimension 维、余弦相似度创建
+	EnsureCollection(ctx context.Context, collection string, dimension int) error
+	// Upsert 写入分块，已存在的 ID 会被覆盖
+	Upsert(ctx context.Context, collection string, docs []Document) error
+	// Query 返回与查询向量最相似的 TopK 个分块，按 Score 降序
+	Query(ctx context.Context, collection string, query Query) ([]Hit, error)
+	Delete(ctx context.Context, collection string, ids []string) error
+	Stats(ctx context.Context, collection string) (Stats, error)
+}
+
+// Config 创建向量库实例的参数，各后端按需使用
+type Config struct {
+	// Transport 访问向量数据库的 HTTP 接口
+	Transport Transport
+	// Addr 直连向量数据库的地址 host:port，用于非 HTTP 协议的后端
+	Addr string
+	// Env 向量数据库容器的环境变量，用于读取连接账号等配置
+	Env map[string]string
+	// Key 知识库实例的标识，如 namespace/pod，直连的后端按 Key 复用连接池，为空时使用 Addr
+	Key string
+}
+
+// Backend 向量数据库类型，由各后端在 init 中注册
+type Backend struct {
+	// Name 知识库类型名称，Aliases 为兼容的别名
+	Name    string
+	Aliases []string
+	// DefaultPort Pod 未声明端口时使用的服务端口
+	DefaultPort int32
+	New         func(cfg Config) VectorStore
+}
+
+var (
+	mu       sync.RWMutex
+	backends = map[string]*Backend{}
+)
+
+// Register 注册向量数据库类型，名称重复时 panic
+func Register(backend Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range append([]string{backend.Name}, backend.Aliases...) {
+		name = strings.ToLower(name)
+		if _, ok := backends[name]; ok {
+			panic(fmt.Sprintf("vectorstore: backend %s already registered", name))
+		}
+		backends[name] = &backend
+	}
+}
+
+// Lookup 按名称或别名查找向量数据库类型，不区分大小写
+func Lookup(name string) (*Backend, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if backend, ok := backends[strings.ToLower(name)]; ok {
+		return backend, nil
+	}
+	return nil, fmt.Errorf("不支持的知识库类型: %s，支持的类型: %s", name, strings.Join(names(), ", "))
+}
+
+// Names 返回已注册的向量数据库类型名称，不包含别名
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names()
+}
+
+func names() []string {
+	var out []string
+	for key, backend := range backends {
+		if key == strings.ToLower(backend.Name) {
+			out = append(out, backend.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsPermanent 判断错误是否由请求本身不合法导致，如向量维度与集合不一致，重试也不会成功
+// 各后端通过错误的 Permanent 方法自行判断
+func IsPermanent(err error) bool {
+	var p interface{ Permanent() bool }
+	return errors.As(err, &p) && p.Permanent()
+}
+
+// filterKeyPattern 过滤字段名会拼接到部分后端的查询表达式中，只允许标识符
+var filterKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateFilter 校验过滤条件的字段名及取值类型
+func validateFilter(filter map[string]interface{}) error {
+	for key, value := range filter {
+		if !filterKeyPattern.MatchString(key) {
+			return fmt.Errorf("过滤字段 %s 不合法，只能包含字母、数字及下划线", key)
+		}
+		switch value.(type) {
+		case string, bool, float64, float32, int, int64:
+		default:
+			return fmt.Errorf("过滤字段 %s 的值只能为字符串、数字或布尔", key)
+		}
+	}
+	return nil
+}
+
+// sortedKeys 按字段名排序，生成稳定的查询表达式
+func sortedKeys(filter map[string]interface{}) []string {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stableUUID 由分块 ID 生成稳定的 UUID，用于只接受 UUID 作为主键的后端，重复写入时覆盖同一对象
+func stableUUID(id string) string {
+	return uuid.NewV5(uuid.NamespaceURL, id).String()
+}
+
+// literal 将过滤值格式化为表达式字面量，字符串使用双引号转义
+func literal(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
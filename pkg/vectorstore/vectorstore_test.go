@@ -0,0 +1,211 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memCollection 测试用的内存集合，按余弦相似度检索
+type memCollection struct {
+	dimension int
+	docs      map[string]Document
+}
+
+type scoredDoc struct {
+	Document
+	score float64
+}
+
+func (c *memCollection) search(vector []float64, topK int, filter map[string]interface{}) []scoredDoc {
+	var out []scoredDoc
+	for _, doc := range c.docs {
+		if matchFilter(doc.Metadata, filter) {
+			out = append(out, scoredDoc{Document: doc, score: cosine(vector, doc.Vector)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].score > out[j].score })
+	if len(out) > topK {
+		out = out[:topK]
+	}
+	return out
+}
+
+func matchFilter(metadata, filter map[string]interface{}) bool {
+	for key, value := range filter {
+		if fmt.Sprint(metadata[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLiteral 解析表达式中的字面量，与 literal 对应
+func parseLiteral(s string) interface{} {
+	if strings.HasPrefix(s, `"`) {
+		v, _ := strconv.Unquote(s)
+		return v
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// standIn 向量数据库的进程内替身，handler 在持有锁时调用
+type standIn struct {
+	mu          sync.Mutex
+	collections map[string]*memCollection
+}
+
+func newStandIn(t *testing.T, handler func(s *standIn, w http.ResponseWriter, r *http.Request, body map[string]interface{})) Transport {
+	s := &standIn{collections: map[string]*memCollection{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		handler(s, w, r, body)
+	}))
+	t.Cleanup(srv.Close)
+	return NewHTTPTransport(srv.URL, srv.Client())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func floats(v interface{}) []float64 {
+	list, _ := v.([]interface{})
+	out := make([]float64, len(list))
+	for i, item := range list {
+		out[i], _ = item.(float64)
+	}
+	return out
+}
+
+func strs(v interface{}) []string {
+	list, _ := v.([]interface{})
+	out := make([]string, len(list))
+	for i, item := range list {
+		out[i], _ = item.(string)
+	}
+	return out
+}
+
+// exerciseStore 对后端执行完整的建集合、写入、覆盖、查询、删除及统计流程
+func exerciseStore(t *testing.T, store VectorStore) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := store.Query(ctx, "handbook", Query{Vector: []float64{1, 0, 0}, TopK: 1}); err == nil {
+		t.Fatal("query on a missing collection should fail")
+	}
+	if err := store.EnsureCollection(ctx, "handbook", 3); err != nil {
+		t.Fatalf("ensure: %v", err)
+	}
+	if err := store.EnsureCollection(ctx, "handbook", 3); err != nil {
+		t.Fatalf("ensure existing: %v", err)
+	}
+	docs := []Document{
+		{ID: "leave_0", Text: "年假每年5天", Vector: []float64{1, 0, 0}, Metadata: map[string]interface{}{"source": "leave.md"}},
+		{ID: "leave_1", Text: "old text", Vector: []float64{0, 1, 0}, Metadata: map[string]interface{}{"source": "leave.md"}},
+		{ID: "travel_0", Text: "Travel expenses are reimbursed monthly", Vector: []float64{0, 0, 1}, Metadata: map[string]interface{}{"source": "travel.md"}},
+	}
+	if err := store.Upsert(ctx, "handbook", docs); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	// 相同 ID 覆盖原有分块
+	if err := store.Upsert(ctx, "handbook", []Document{{ID: "leave_1", Text: "病假需要证明", Vector: []float64{0, 1, 0}, Metadata: map[string]interface{}{"source": "leave.md"}}}); err != nil {
+		t.Fatalf("upsert overwrite: %v", err)
+	}
+
+	hits, err := store.Query(ctx, "handbook", Query{Vector: []float64{0.9, 0.1, 0}, TopK: 2})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(hits) != 2 || hits[0].ID != "leave_0" || hits[0].Text != "年假每年5天" {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+	if hits[0].Score <= hits[1].Score || hits[0].Score < 0.9 {
+		t.Fatalf("hits should be ordered by similarity: %+v", hits)
+	}
+	if hits[0].Metadata["source"] != "leave.md" {
+		t.Fatalf("metadata not returned: %+v", hits[0].Metadata)
+	}
+	if hits[1].Text != "病假需要证明" {
+		t.Fatalf("upsert should overwrite existing id: %+v", hits[1])
+	}
+
+	hits, err = store.Query(ctx, "handbook", Query{Vector: []float64{1, 0, 0}, TopK: 3, Filter: map[string]interface{}{"source": "travel.md"}})
+	if err != nil {
+		t.Fatalf("filtered query: %v", err)
+	}
+	if len(hits) != 1 || hits[0].ID != "travel_0" {
+		t.Fatalf("filter not applied: %+v", hits)
+	}
+	if _, err := store.Query(ctx, "handbook", Query{Vector: []float64{1, 0, 0}, TopK: 3, Filter: map[string]interface{}{"source or 1": "x"}}); err == nil {
+		t.Fatal("invalid filter key should be rejected")
+	}
+
+	if err := store.Delete(ctx, "handbook", []string{"leave_0"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	stats, err := store.Stats(ctx, "handbook")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Count != 2 || stats.Collection != "handbook" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"chromadb", "Chroma", "milvus", "weaviate", "qdrant", "pgvector"} {
+		if _, err := Lookup(name); err != nil {
+			t.Fatalf("lookup %s: %v", name, err)
+		}
+	}
+	_, err := Lookup("faiss")
+	if err == nil || !strings.Contains(err.Error(), "chromadb, milvus, pgvector, qdrant, weaviate") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("写入 Qdrant 失败: %w", &StatusError{Code: http.StatusBadRequest, Body: "wrong vector dimension"}), true},
+		{fmt.Errorf("写入 Chroma 失败: %w", &StatusError{Code: http.StatusTooManyRequests}), false},
+		{&StatusError{Code: http.StatusServiceUnavailable}, false},
+		{fmt.Errorf("connection refused"), false},
+	}
+	for _, c := range cases {
+		if got := IsPermanent(c.err); got != c.want {
+			t.Errorf("IsPermanent(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(Backend{Name: "weaviate", DefaultPort: 8080, New: newWeaviate})
+}
+
+// weaviate 保留属性，metadata 中的同名字段写入时会被忽略
+const (
+	weaviateTextProperty = "text"
+	weaviateIDProperty   = "doc_id"
+)
+
+// weaviateScalarTypes 可以直接在 GraphQL 中查询的属性类型
+var weaviateScalarTypes = map[string]bool{
+	"text": true, "string": true, "int": true, "number": true, "boolean": true, "date": true, "uuid": true,
+	"text[]": true, "string[]": true, "int[]": true, "number[]": true, "boolean[]": true, "date[]": true, "uuid[]": true,
+}
+
+// weaviate Weaviate REST 及 GraphQL API，类使用外部向量与余弦距离，Score 为 1-距离
+// 对象 ID 必须为 UUID，由分块 ID 生成，原始 ID 保存在 doc_id 属性
+type weaviate struct {
+	transport Transport
+}
+
+func newWeaviate(cfg Config) VectorStore {
+	return &weaviate{transport: cfg.Transport}
+}
+
+// weaviateClass 类名须以大写字母开头，且只能包含字母、数字及下划线
+func weaviateClass(collection string) string {
+	name := strings.ReplaceAll(collection, "-", "_")
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+type weaviateSchema struct {
+	Class      string `json:"class"`
+	Properties []struct {
+		Name     string   `json:"name"`
+		DataType []string `json:"dataType"`
+	} `json:"properties"`
+}
+
+func (w *weaviate) schema(ctx context.Context, class string) (*weaviateSchema, error) {
+	var out weaviateSchema
+	if err := call(ctx, w.transport, http.MethodGet, "/v1/schema/"+class, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (w *weaviate) EnsureCollection(ctx context.Context, collection string, dimension int) error {
+	class := weaviateClass(collection)
+	_, err := w.schema(ctx, class)
+	if err == nil {
+		return nil
+	}
+	if !IsNotFound(err) {
+		return fmt.Errorf("获取类信息失败: %w", err)
+	}
+	in := map[string]interface{}{
+		"class":             class,
+		"vectorizer":        "none",
+		"vectorIndexConfig": map[string]interface{}{"distance": "cosine"},
+		"properties": []map[string]interface{}{
+			{"name": weaviateTextProperty, "dataType": []string{"text"}},
+			{"name": weaviateIDProperty, "dataType": []string{"text"}},
+		},
+	}
+	if err := call(ctx, w.transport, http.MethodPost, "/v1/schema", in, nil); err != nil {
+		return fmt.Errorf("创建类失败: %w", err)
+	}
+	return nil
+}
+
+func (w *weaviate) Upsert(ctx context.Context, collection string, docs []Document) error {
+	class := weaviateClass(collection)
+	objects := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		properties := make(map[string]interface{}, len(doc.Metadata)+2)
+		for k, v := range doc.Metadata {
+			properties[k] = v
+		}
+		properties[weaviateTextProperty] = doc.Text
+		properties[weaviateIDProperty] = doc.ID
+		objects[i] = map[string]interface{}{
+			"class":      class,
+			"id":         stableUUID(doc.ID),
+			"vector":     doc.Vector,
+			"properties": properties,
+		}
+	}
+	// 批量接口按对象返回结果，部分对象失败时 HTTP 状态码仍为 200
+	var out []struct {
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+	if err := call(ctx, w.transport, http.MethodPost, "/v1/batch/objects", map[string]interface{}{"objects": objects}, &out); err != nil {
+		return fmt.Errorf("写入 Weaviate 失败: %w", err)
+	}
+	for _, item := range out {
+		if item.Result.Errors != nil && len(item.Result.Errors.Error) > 0 {
+			return fmt.Errorf("写入 Weaviate 失败: %s", item.Result.Errors.Error[0].Message)
+		}
+	}
+	return nil
+}
+
+// weaviateGraphQLResponse GraphQL 响应，查询失败时 HTTP 状态码仍为 200
+type weaviateGraphQLResponse struct {
+	Data   map[string]map[string][]map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (w *weaviate) graphQL(ctx context.Context, query string) (*weaviateGraphQLResponse, error) {
+	var out weaviateGraphQLResponse
+	if err := call(ctx, w.transport, http.MethodPost, "/v1/graphql", map[string]interface{}{"query": query}, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Errors) > 0 {
+		return nil, fmt.Errorf("weaviate GraphQL 返回错误: %s", out.Errors[0].Message)
+	}
+	return &out, nil
+}
+
+func (w *weaviate) Query(ctx context.Context, collection string, query Query) ([]Hit, error) {
+	if err := validateFilter(query.Filter); err != nil {
+		return nil, err
+	}
+	class := weaviateClass(collection)
+	schema, err := w.schema(ctx, class)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("集合 %s 不存在", collection)
+		}
+		return nil, fmt.Errorf("获取类信息失败: %w", err)
+	}
+	// metadata 由 Weaviate 自动添加为属性，查询全部可直接读取的属性
+	var fields []string
+	for _, property := range schema.Properties {
+		if len(property.DataType) == 1 && weaviateScalarTypes[property.DataType[0]] {
+			fields = append(fields, property.Name)
+		}
+	}
+	args := fmt.Sprintf("nearVector: {vector: %s}, limit: %d", formatVector(query.Vector), query.TopK)
+	if len(query.Filter) > 0 {
+		args += ", where: " + weaviateWhere(query.Filter)
+	}
+	gql := fmt.Sprintf(`{ Get { %s(%s) { %s _additional { id distance } } } }`, class, args, strings.Join(fields, " "))
+	out, err := w.graphQL(ctx, gql)
+	if err != nil {
+		return nil, fmt.Errorf("查询 Weaviate 失败: %w", err)
+	}
+	items := out.Data["Get"][class]
+	hits := make([]Hit, len(items))
+	for i, item := range items {
+		if additional, ok := item["_additional"].(map[string]interface{}); ok {
+			hits[i].ID, _ = additional["id"].(string)
+			if distance, ok := additional["distance"].(float64); ok {
+				hits[i].Score = 1 - distance
+			}
+		}
+		for k, v := range item {
+			switch k {
+			case "_additional":
+			case weaviateTextProperty:
+				hits[i].Text, _ = v.(string)
+			case weaviateIDProperty:
+				if id, ok := v.(string); ok && id != "" {
+					hits[i].ID = id
+				}
+			default:
+				if v == nil {
+					continue
+				}
+				if hits[i].Metadata == nil {
+					hits[i].Metadata = map[string]interface{}{}
+				}
+				hits[i].Metadata[k] = v
+			}
+		}
+	}
+	return hits, nil
+}
+
+func (w *weaviate) Delete(ctx context.Context, collection string, ids []string) error {
+	class := weaviateClass(collection)
+	for _, id := range ids {
+		err := call(ctx, w.transport, http.MethodDelete, "/v1/objects/"+class+"/"+stableUUID(id), nil, nil)
+		if err != nil && !IsNotFound(err) {
+			return fmt.Errorf("删除 Weaviate 对象失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *weaviate) Stats(ctx context.Context, collection string) (Stats, error) {
+	class := weaviateClass(collection)
+	stats := Stats{Collection: collection}
+	out, err := w.graphQL(ctx, fmt.Sprintf(`{ Aggregate { %s { meta { count } } } }`, class))
+	if err != nil {
+		return stats, fmt.Errorf("获取 Weaviate 类统计失败: %w", err)
+	}
+	if items := out.Data["Aggregate"][class]; len(items) > 0 {
+		if meta, ok := items[0]["meta"].(map[string]interface{}); ok {
+			if count, ok := meta["count"].(float64); ok {
+				stats.Count = int64(count)
+			}
+		}
+	}
+	return stats, nil
+}
+
+// formatVector 将向量格式化为 GraphQL 数组
+func formatVector(vector []float64) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// weaviateWhere 转换为 GraphQL where 参数，多个条件使用 And 组合
+func weaviateWhere(filter map[string]interface{}) string {
+	operands := make([]string, 0, len(filter))
+	for _, key := range sortedKeys(filter) {
+		valueField := "valueNumber"
+		switch filter[key].(type) {
+		case string:
+			valueField = "valueText"
+		case bool:
+			valueField = "valueBoolean"
+		}
+		operands = append(operands, fmt.Sprintf(`{path: [%q], operator: Equal, %s: %s}`, key, valueField, literal(filter[key])))
+	}
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return "{operator: And, operands: [" + strings.Join(operands, ", ") + "]}"
+}
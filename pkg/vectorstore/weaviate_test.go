@@ -0,0 +1,139 @@
+package vectorstore
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	weaviateGetQuery       = regexp.MustCompile(`Get \{ (\w+)\(nearVector: \{vector: (\[[^\]]*\])\}, limit: (\d+)(.*?)\) \{ ([\w ]*)_additional`)
+	weaviateCondition      = regexp.MustCompile(`path: \["(\w+)"\], operator: Equal, value\w+: ("(?:[^"\\]|\\.)*"|[^}\s]+)`)
+	weaviateAggregateQuery = regexp.MustCompile(`Aggregate \{ (\w+) `)
+)
+
+// weaviateStandIn 模拟 Weaviate REST 及 GraphQL API，写入对象时自动为新属性添加 schema
+func weaviateStandIn(s *standIn, w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/schema/"):
+		class := strings.TrimPrefix(r.URL.Path, "/v1/schema/")
+		col := s.collections[class]
+		if col == nil {
+			http.NotFound(w, r)
+			return
+		}
+		properties := []interface{}{
+			map[string]interface{}{"name": "text", "dataType": []string{"text"}},
+			map[string]interface{}{"name": "doc_id", "dataType": []string{"text"}},
+			// 对象类型的属性不能直接在 GraphQL 中查询
+			map[string]interface{}{"name": "extra", "dataType": []string{"object"}},
+		}
+		for _, doc := range col.docs {
+			for k := range doc.Metadata {
+				properties = append(properties, map[string]interface{}{"name": k, "dataType": []string{"text"}})
+			}
+			break
+		}
+		writeJSON(w, map[string]interface{}{"class": class, "properties": properties})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/schema":
+		class, _ := body["class"].(string)
+		if class == "" || class[0] < 'A' || class[0] > 'Z' {
+			http.Error(w, `{"error":[{"message":"class name must start with an uppercase letter"}]}`, http.StatusUnprocessableEntity)
+			return
+		}
+		s.collections[class] = &memCollection{docs: map[string]Document{}}
+		writeJSON(w, body)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/batch/objects":
+		objects, _ := body["objects"].([]interface{})
+		var results []interface{}
+		for _, item := range objects {
+			object, _ := item.(map[string]interface{})
+			col := s.collections[object["class"].(string)]
+			properties, _ := object["properties"].(map[string]interface{})
+			docID, _ := properties["doc_id"].(string)
+			if col == nil || object["id"] != stableUUID(docID) {
+				results = append(results, map[string]interface{}{"result": map[string]interface{}{"errors": map[string]interface{}{"error": []interface{}{map[string]interface{}{"message": "invalid object"}}}}})
+				continue
+			}
+			doc := Document{ID: docID, Vector: floats(object["vector"]), Metadata: map[string]interface{}{}}
+			for k, v := range properties {
+				switch k {
+				case "text":
+					doc.Text, _ = v.(string)
+				case "doc_id":
+				default:
+					doc.Metadata[k] = v
+				}
+			}
+			col.docs[docID] = doc
+			results = append(results, map[string]interface{}{"id": object["id"], "result": map[string]interface{}{}})
+		}
+		writeJSON(w, results)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/graphql":
+		query, _ := body["query"].(string)
+		if m := weaviateGetQuery.FindStringSubmatch(query); m != nil {
+			col := s.collections[m[1]]
+			var vector []float64
+			_ = json.Unmarshal([]byte(m[2]), &vector)
+			limit, _ := strconv.Atoi(m[3])
+			var items []interface{}
+			filter := map[string]interface{}{}
+			for _, cond := range weaviateCondition.FindAllStringSubmatch(m[4], -1) {
+				filter[cond[1]] = parseLiteral(cond[2])
+			}
+			for _, doc := range col.search(vector, limit, filter) {
+				item := map[string]interface{}{"_additional": map[string]interface{}{"id": stableUUID(doc.ID), "distance": 1 - doc.score}}
+				for _, field := range strings.Fields(m[5]) {
+					switch field {
+					case "text":
+						item[field] = doc.Text
+					case "doc_id":
+						item[field] = doc.ID
+					default:
+						item[field] = doc.Metadata[field]
+					}
+				}
+				items = append(items, item)
+			}
+			writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"Get": map[string]interface{}{m[1]: items}}})
+			return
+		}
+		if m := weaviateAggregateQuery.FindStringSubmatch(query); m != nil && s.collections[m[1]] != nil {
+			count := len(s.collections[m[1]].docs)
+			writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"Aggregate": map[string]interface{}{m[1]: []interface{}{map[string]interface{}{"meta": map[string]interface{}{"count": count}}}}}})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"errors": []interface{}{map[string]interface{}{"message": "unsupported query"}}})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/objects/"):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/objects/"), "/")
+		if col := s.collections[parts[0]]; col != nil {
+			for id := range col.docs {
+				if stableUUID(id) == parts[1] {
+					delete(col.docs, id)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestWeaviate(t *testing.T) {
+	backend, err := Lookup("weaviate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseStore(t, backend.New(Config{Transport: newStandIn(t, weaviateStandIn)}))
+}
+
+func TestWeaviateClass(t *testing.T) {
+	if got := weaviateClass("employee-handbook"); got != "Employee_handbook" {
+		t.Fatalf("got %s", got)
+	}
+}
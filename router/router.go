@@ -1,17 +1,22 @@
 package router
 
 import (
+	"go.uber.org/zap"
+
+	"github.com/noovertime7/kubemanage/cmd/app/config"
 	"github.com/noovertime7/kubemanage/cmd/app/options"
 	"github.com/noovertime7/kubemanage/controller/api"
 	"github.com/noovertime7/kubemanage/controller/authority"
 	"github.com/noovertime7/kubemanage/controller/cmdb"
 	"github.com/noovertime7/kubemanage/controller/kubeController"
 	"github.com/noovertime7/kubemanage/controller/menu"
+	"github.com/noovertime7/kubemanage/controller/openai"
 	"github.com/noovertime7/kubemanage/controller/operation"
 	"github.com/noovertime7/kubemanage/controller/other"
 	"github.com/noovertime7/kubemanage/controller/system"
 	"github.com/noovertime7/kubemanage/controller/user"
 	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/logger"
 )
 
 func InstallRouters(opt *options.Options) {
@@ -31,4 +36,12 @@ func InstallRouters(opt *options.Options) {
 		// cmdb相关
 		cmdb.NewCMDBRouter(apiGroup)
 	}
+	if config.SysConfig.OpenAI.Enable {
+		// OpenAI 兼容接口，挂载在 /v1 下并使用 API Key 认证
+		if err := config.SysConfig.OpenAI.Validate(); err != nil {
+			logger.LG.Error("OpenAI 兼容接口未启用", zap.Error(err))
+		} else {
+			openai.NewOpenAIRouter(opt.GinEngine.Group("/v1"))
+		}
+	}
 }
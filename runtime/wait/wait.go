@@ -64,6 +64,53 @@ func BackoffUntil(f func(), backoff BackoffManager, sliding bool, stopCh <-chan
 	}
 }
 
+// ErrWaitTimeout 重试次数用完仍未满足条件
+var ErrWaitTimeout = errors.New("timed out waiting for the condition")
+
+// Backoff 指数退避参数，每次等待 Duration 后乘以 Factor，不超过 Cap（为 0 时不限制），Steps 为最多尝试次数
+type Backoff struct {
+	Duration time.Duration
+	Factor   float64
+	Cap      time.Duration
+	Steps    int
+}
+
+// Step 返回本次的等待时间并计算下次的等待时间
+func (b *Backoff) Step() time.Duration {
+	duration := b.Duration
+	if b.Factor > 0 {
+		b.Duration = time.Duration(float64(b.Duration) * b.Factor)
+		if b.Cap > 0 && b.Duration > b.Cap {
+			b.Duration = b.Cap
+		}
+	}
+	if b.Steps > 0 {
+		b.Steps--
+	}
+	return duration
+}
+
+// ExponentialBackoffWithContext 按 backoff 重复执行 condition，直到条件满足、condition 返回错误、次数用完或 ctx 结束
+// 次数用完时返回 ErrWaitTimeout，ctx 结束时返回 ctx.Err()
+func ExponentialBackoffWithContext(ctx context.Context, backoff Backoff, condition ConditionWithContextFunc) error {
+	for backoff.Steps > 0 {
+		if done, err := runConditionWithCrashProtectionWithContext(ctx, condition); err != nil || done {
+			return err
+		}
+		if backoff.Steps == 1 {
+			break
+		}
+		t := time.NewTimer(backoff.Step())
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return ErrWaitTimeout
+}
+
 type ConditionFunc func() (done bool, err error)
 
 // WithContext converts a ConditionFunc into a ConditionWithContextFunc
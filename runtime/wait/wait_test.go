@@ -1,6 +1,7 @@
 package wait
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -26,3 +27,29 @@ func TestPollImmediateUntil(t *testing.T) {
 	}
 	t.Log("quit")
 }
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := Backoff{Duration: time.Millisecond, Factor: 2, Cap: 3 * time.Millisecond, Steps: 4}
+	var attempts int
+	err := ExponentialBackoffWithContext(context.Background(), backoff, func(ctx context.Context) (bool, error) {
+		attempts++
+		return false, nil
+	})
+	if err != ErrWaitTimeout || attempts != 4 {
+		t.Fatalf("err = %v, attempts = %d", err, attempts)
+	}
+
+	attempts = 0
+	err = ExponentialBackoffWithContext(context.Background(), backoff, func(ctx context.Context) (bool, error) {
+		attempts++
+		return attempts == 2, nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("err = %v, attempts = %d", err, attempts)
+	}
+
+	durations := []time.Duration{backoff.Step(), backoff.Step(), backoff.Step()}
+	if durations[0] != time.Millisecond || durations[1] != 2*time.Millisecond || durations[2] != 3*time.Millisecond {
+		t.Fatalf("durations = %v", durations)
+	}
+}